@@ -13,8 +13,10 @@ import (
 	"strconv"
 	"sync"
 	"time"
+	_ "time/tzdata" // timezones of the users do not depend on the host
 
 	"github.com/CTSDM/gogym/internal/api"
+	"github.com/CTSDM/gogym/internal/api/insight"
 	"github.com/CTSDM/gogym/internal/auth"
 	"github.com/CTSDM/gogym/internal/database"
 	"github.com/jackc/pgx/v5"
@@ -97,6 +99,13 @@ func run(ctx context.Context, w io.Writer, fCheckEnv func(string) (string, bool)
 	}()
 
 	var wg sync.WaitGroup
+	wg.Go(func() {
+		logger.Info("starting insights job...", slog.Duration("interval", env.insightsInterval))
+		insight.RunJob(ctx, dbPool, dbQueries, insight.JobConfig{
+			Weeks:    env.insightsWeeks,
+			Interval: env.insightsInterval,
+		}, logger)
+	})
 	wg.Go(func() {
 		<-ctx.Done()
 		timeout := 10 * time.Second
@@ -129,6 +138,8 @@ type envConfig struct {
 	dbHostPort           string
 	database             string
 	serverPort           string
+	insightsWeeks        int
+	insightsInterval     time.Duration
 }
 
 func loadEnvConfig(fn func(string) (string, bool)) (*envConfig, error) {
@@ -191,6 +202,24 @@ func loadEnvConfig(fn func(string) (string, bool)) (*envConfig, error) {
 		return nil, fmt.Errorf("server port was not found on the env file")
 	}
 
+	// Insights job variables
+	insightsWeeksStr, ok := fn("INSIGHTS_WEEKS")
+	if !ok {
+		return nil, fmt.Errorf("insights weeks was not found on the env file")
+	}
+	insightsWeeks, err := strconv.Atoi(insightsWeeksStr)
+	if err != nil || insightsWeeks < 1 {
+		return nil, fmt.Errorf("could not parse insights weeks into a positive integer: %s", insightsWeeksStr)
+	}
+	insightsIntervalStr, ok := fn("INSIGHTS_INTERVAL")
+	if !ok {
+		return nil, fmt.Errorf("insights interval was not found on the env file")
+	}
+	insightsInterval, err := strconv.Atoi(insightsIntervalStr)
+	if err != nil || insightsInterval < 1 {
+		return nil, fmt.Errorf("could not parse insights interval into a positive integer: %s", insightsIntervalStr)
+	}
+
 	return &envConfig{
 		adminUsername:        adminUsername,
 		adminPassword:        adminPassword,
@@ -203,6 +232,8 @@ func loadEnvConfig(fn func(string) (string, bool)) (*envConfig, error) {
 		jwtDuration:          jwtDurationInt,
 		refreshTokenDuration: refreshTokenDurationInt,
 		serverPort:           serverPort,
+		insightsWeeks:        insightsWeeks,
+		insightsInterval:     time.Duration(insightsInterval) * time.Second,
 	}, nil
 
 }
@@ -0,0 +1,431 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/goal"
+	"github.com/CTSDM/gogym/internal/api/set"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/validation"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Version of the backup document, restores of other versions are rejected.
+// It is increased when a change of the document breaks older restores.
+const Version = 1
+
+// how the data of the backup is restored
+const (
+	// the sessions of the backup replace the ones with the same id, the rest of the data is kept
+	ModeMerge = "merge"
+	// the sessions, goals and measurements of the user are deleted before restoring
+	ModeReplace = "replace"
+)
+
+var Modes = []string{ModeMerge, ModeReplace}
+
+// document holds all the data of a user. Weights and bodyweights are in kilograms,
+// the unit logs were entered in is kept. Exercises are referenced by their name in the catalogue
+// so backups can be restored in other servers. Personal records are not part of it, they are
+// detected again on restore.
+type document struct {
+	Version      int                 `json:"version"`
+	CreatedAt    time.Time           `json:"created_at"`
+	Profile      backupProfile       `json:"profile"`
+	Sessions     []backupSession     `json:"sessions"`
+	Goals        []backupGoal        `json:"goals"`
+	Measurements []backupMeasurement `json:"measurements"`
+}
+
+type backupProfile struct {
+	Country                    string `json:"country,omitempty"`
+	Birthday                   string `json:"birthday,omitempty"`
+	PreferredUnit              string `json:"preferred_unit"`
+	Timezone                   string `json:"timezone"`
+	DisplayName                string `json:"display_name,omitempty"`
+	LeaderboardOptIn           bool   `json:"leaderboard_opt_in"`
+	LeaderboardShareCountry    bool   `json:"leaderboard_share_country"`
+	LeaderboardShareAge        bool   `json:"leaderboard_share_age"`
+	LeaderboardShareBodyweight bool   `json:"leaderboard_share_bodyweight"`
+}
+
+type backupSession struct {
+	ID              uuid.UUID   `json:"id"`
+	Name            string      `json:"name"`
+	Date            string      `json:"date"`
+	StartTimestamp  *time.Time  `json:"start_timestamp,omitempty"`
+	DurationMinutes *int16      `json:"duration_minutes,omitempty"`
+	Tags            []string    `json:"tags"`
+	Notes           string      `json:"notes,omitempty"`
+	RPE             *int16      `json:"rpe,omitempty"`
+	SleepQuality    *int16      `json:"sleep_quality,omitempty"`
+	Bodyweight      *float64    `json:"bodyweight,omitempty"`
+	Mood            *int16      `json:"mood,omitempty"`
+	Location        string      `json:"location,omitempty"`
+	Sets            []backupSet `json:"sets"`
+
+	date time.Time
+}
+
+type backupSet struct {
+	Order    int32       `json:"order"`
+	Exercise string      `json:"exercise"`
+	SetType  string      `json:"set_type"`
+	RestTime *int32      `json:"rest_time,omitempty"`
+	GroupKey string      `json:"group_key,omitempty"`
+	Logs     []backupLog `json:"logs"`
+}
+
+type backupLog struct {
+	Order          int32    `json:"order"`
+	Weight         *float64 `json:"weight,omitempty"`
+	WeightUnit     string   `json:"weight_unit"`
+	Reps           int32    `json:"reps"`
+	RPE            *float64 `json:"rpe,omitempty"`
+	RIR            *int16   `json:"rir,omitempty"`
+	Tempo          string   `json:"tempo,omitempty"`
+	ReachedFailure bool     `json:"reached_failure"`
+	PartialReps    int16    `json:"partial_reps"`
+	Notes          string   `json:"notes,omitempty"`
+}
+
+type backupGoal struct {
+	Type       string   `json:"type"`
+	Exercise   string   `json:"exercise,omitempty"`
+	Target     float64  `json:"target"`
+	Baseline   *float64 `json:"baseline,omitempty"`
+	StartDate  string   `json:"start_date"`
+	TargetDate string   `json:"target_date"`
+	Status     string   `json:"status"`
+	StatusDate string   `json:"status_date,omitempty"`
+	Notes      string   `json:"notes,omitempty"`
+}
+
+type backupMeasurement struct {
+	Date       string   `json:"date"`
+	Bodyweight *float64 `json:"bodyweight,omitempty"`
+	BodyFat    *float64 `json:"body_fat,omitempty"`
+	Neck       *float64 `json:"neck,omitempty"`
+	Chest      *float64 `json:"chest,omitempty"`
+	Waist      *float64 `json:"waist,omitempty"`
+	Hips       *float64 `json:"hips,omitempty"`
+	Arm        *float64 `json:"arm,omitempty"`
+	Thigh      *float64 `json:"thigh,omitempty"`
+	Calf       *float64 `json:"calf,omitempty"`
+	Notes      string   `json:"notes,omitempty"`
+}
+
+// Valid checks the structure of the document, the ranges of the values are enforced by the database
+// and the exercises are resolved against the catalogue on restore
+func (d *document) Valid(ctx context.Context) map[string]string {
+	problems := make(map[string]string)
+	if d.Version != Version {
+		problems["version"] = fmt.Sprintf("invalid version: only version %d is supported", Version)
+		return problems
+	}
+
+	// profile validation
+	p := d.Profile
+	if !units.Valid(p.PreferredUnit) {
+		problems["profile.preferred_unit"] = "invalid preferred_unit: " + units.ErrInvalidUnit.Error()
+	}
+	if _, err := time.LoadLocation(p.Timezone); err != nil || p.Timezone == "" || p.Timezone == "Local" {
+		problems["profile.timezone"] = "invalid timezone: timezone must be an IANA timezone name"
+	}
+	if p.Country != "" {
+		if err := validation.String(p.Country, apiconstants.MinCountryLength, apiconstants.MaxCountryLength); err != nil {
+			problems["profile.country"] = "invalid country: " + err.Error()
+		}
+	}
+	if p.Birthday != "" {
+		if _, err := validation.Date(p.Birthday, apiconstants.DATE_LAYOUT, nil, nil); err != nil {
+			problems["profile.birthday"] = "invalid birthday: " + err.Error()
+		}
+	}
+	if p.DisplayName != "" {
+		if err := validation.String(p.DisplayName, apiconstants.MinDisplayNameLength, apiconstants.MaxDisplayNameLength); err != nil {
+			problems["profile.display_name"] = "invalid display_name: " + err.Error()
+		}
+	}
+
+	// sessions validation, ids identify the sessions on later restores
+	ids := make(map[uuid.UUID]bool, len(d.Sessions))
+	for i := range d.Sessions {
+		s := &d.Sessions[i]
+		key := fmt.Sprintf("sessions[%d]", i)
+		if s.ID == uuid.Nil {
+			problems[key+".id"] = "invalid id: id is required"
+		} else if ids[s.ID] {
+			problems[key+".id"] = "invalid id: id is repeated"
+		}
+		ids[s.ID] = true
+		if err := validation.String(s.Name, apiconstants.MinSessionNameLength, apiconstants.MaxSessionNameLength); err != nil {
+			problems[key+".name"] = "invalid name: " + err.Error()
+		}
+		date, err := validation.Date(s.Date, apiconstants.DATE_LAYOUT, nil, nil)
+		if err != nil {
+			problems[key+".date"] = "invalid date: " + err.Error()
+		}
+		s.date = date
+		validateSets(problems, key, s.Sets)
+	}
+
+	// goals validation
+	for i, g := range d.Goals {
+		key := fmt.Sprintf("goals[%d]", i)
+		if !slices.Contains(goal.Types, g.Type) {
+			problems[key+".type"] = "invalid type: type must be one of " + strings.Join(goal.Types, ", ")
+		}
+		if !slices.Contains(goal.Statuses, g.Status) {
+			problems[key+".status"] = "invalid status: status must be one of " + strings.Join(goal.Statuses, ", ")
+		}
+		for name, value := range map[string]string{"start_date": g.StartDate, "target_date": g.TargetDate} {
+			if _, err := validation.Date(value, apiconstants.DATE_LAYOUT, nil, nil); err != nil {
+				problems[key+"."+name] = fmt.Sprintf("invalid %s: %s", name, err.Error())
+			}
+		}
+		if g.StatusDate != "" {
+			if _, err := validation.Date(g.StatusDate, apiconstants.DATE_LAYOUT, nil, nil); err != nil {
+				problems[key+".status_date"] = "invalid status_date: " + err.Error()
+			}
+		}
+	}
+
+	// measurements validation, there is one per day
+	dates := make(map[string]bool, len(d.Measurements))
+	for i, m := range d.Measurements {
+		key := fmt.Sprintf("measurements[%d]", i)
+		if _, err := validation.Date(m.Date, apiconstants.DATE_LAYOUT, nil, nil); err != nil {
+			problems[key+".date"] = "invalid date: " + err.Error()
+		} else if dates[m.Date] {
+			problems[key+".date"] = "invalid date: date is repeated"
+		}
+		dates[m.Date] = true
+	}
+
+	return problems
+}
+
+func validateSets(problems map[string]string, sessionKey string, sets []backupSet) {
+	orders := make(map[int32]bool, len(sets))
+	for i, s := range sets {
+		key := fmt.Sprintf("%s.sets[%d]", sessionKey, i)
+		if s.Order <= 0 {
+			problems[key+".order"] = "invalid order: order must be positive"
+		} else if orders[s.Order] {
+			problems[key+".order"] = "invalid order: order is repeated"
+		}
+		orders[s.Order] = true
+		if s.Exercise == "" {
+			problems[key+".exercise"] = "invalid exercise: exercise is required"
+		}
+		if !slices.Contains(set.SetTypes, s.SetType) {
+			problems[key+".set_type"] = "invalid set_type: set_type must be one of " + strings.Join(set.SetTypes, ", ")
+		}
+
+		logOrders := make(map[int32]bool, len(s.Logs))
+		for j, l := range s.Logs {
+			logKey := fmt.Sprintf("%s.logs[%d]", key, j)
+			if l.Order <= 0 {
+				problems[logKey+".order"] = "invalid order: order must be positive"
+			} else if logOrders[l.Order] {
+				problems[logKey+".order"] = "invalid order: order is repeated"
+			}
+			logOrders[l.Order] = true
+			if l.Reps <= 0 {
+				problems[logKey+".reps"] = "invalid reps: reps must be positive"
+			}
+			if l.Weight != nil && *l.Weight < 0 {
+				problems[logKey+".weight"] = "invalid weight: weight can not be negative"
+			}
+			if !units.Valid(l.WeightUnit) {
+				problems[logKey+".weight_unit"] = "invalid weight_unit: " + units.ErrInvalidUnit.Error()
+			}
+			if l.RPE != nil && (*l.RPE < apiconstants.MinRPE || *l.RPE > apiconstants.MaxRPE) {
+				problems[logKey+".rpe"] = fmt.Sprintf("invalid rpe: rpe must be between %d and %d",
+					apiconstants.MinRPE, apiconstants.MaxRPE)
+			}
+			if l.RIR != nil && (*l.RIR < 0 || *l.RIR > apiconstants.MaxRIR) {
+				problems[logKey+".rir"] = fmt.Sprintf("invalid rir: rir must be between 0 and %d", apiconstants.MaxRIR)
+			}
+		}
+	}
+}
+
+// newDocument builds the backup of the user, sessions are sorted by date and hold their sets and logs
+func newDocument(
+	user database.User,
+	sessions []database.Session,
+	sets []database.Set,
+	logs []database.Log,
+	goals []database.Goal,
+	measurements []database.BodyMeasurement,
+	exercises map[int32]string,
+) document {
+	doc := document{
+		Version:   Version,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		Profile: backupProfile{
+			Country:                    user.Country.String,
+			PreferredUnit:              user.PreferredUnit,
+			Timezone:                   user.Timezone,
+			DisplayName:                user.DisplayName.String,
+			LeaderboardOptIn:           user.LeaderboardOptIn,
+			LeaderboardShareCountry:    user.LeaderboardShareCountry,
+			LeaderboardShareAge:        user.LeaderboardShareAge,
+			LeaderboardShareBodyweight: user.LeaderboardShareBodyweight,
+		},
+		Sessions:     make([]backupSession, 0, len(sessions)),
+		Goals:        make([]backupGoal, 0, len(goals)),
+		Measurements: make([]backupMeasurement, 0, len(measurements)),
+	}
+	if user.Birthday.Valid {
+		doc.Profile.Birthday = user.Birthday.Time.Format(apiconstants.DATE_LAYOUT)
+	}
+
+	logsBySet := make(map[int64][]backupLog, len(sets))
+	for _, l := range logs {
+		logsBySet[l.SetID] = append(logsBySet[l.SetID], backupLog{
+			Order:          l.LogsOrder,
+			Weight:         float8(l.Weight),
+			WeightUnit:     l.WeightUnit,
+			Reps:           l.Reps,
+			RPE:            float8(l.Rpe),
+			RIR:            int2(l.Rir),
+			Tempo:          l.Tempo.String,
+			ReachedFailure: l.ReachedFailure,
+			PartialReps:    l.PartialReps,
+			Notes:          l.Notes.String,
+		})
+	}
+	setsBySession := make(map[uuid.UUID][]backupSet, len(sessions))
+	for _, s := range sets {
+		var restTime *int32
+		if s.RestTime.Valid {
+			restTime = &s.RestTime.Int32
+		}
+		logs := logsBySet[s.ID]
+		if logs == nil {
+			logs = []backupLog{}
+		}
+		setsBySession[s.SessionID] = append(setsBySession[s.SessionID], backupSet{
+			Order:    s.SetOrder,
+			Exercise: exercises[s.ExerciseID],
+			SetType:  s.SetType,
+			RestTime: restTime,
+			GroupKey: s.GroupKey.String,
+			Logs:     logs,
+		})
+	}
+
+	for _, s := range sessions {
+		session := backupSession{
+			ID:              s.ID,
+			Name:            s.Name,
+			Date:            s.Date.Time.Format(apiconstants.DATE_LAYOUT),
+			DurationMinutes: int2(s.DurationMinutes),
+			Tags:            s.Tags,
+			Notes:           s.Notes.String,
+			RPE:             int2(s.Rpe),
+			SleepQuality:    int2(s.SleepQuality),
+			Bodyweight:      float8(s.Bodyweight),
+			Mood:            int2(s.Mood),
+			Location:        s.Location.String,
+			Sets:            setsBySession[s.ID],
+		}
+		if s.StartTimestamp.Valid {
+			start := s.StartTimestamp.Time
+			session.StartTimestamp = &start
+		}
+		if session.Tags == nil {
+			session.Tags = []string{}
+		}
+		if session.Sets == nil {
+			session.Sets = []backupSet{}
+		}
+		doc.Sessions = append(doc.Sessions, session)
+	}
+	slices.SortStableFunc(doc.Sessions, func(a, b backupSession) int {
+		return strings.Compare(a.Date, b.Date)
+	})
+
+	for _, g := range goals {
+		backupGoal := backupGoal{
+			Type:       g.GoalType,
+			Target:     g.Target,
+			Baseline:   float8(g.Baseline),
+			StartDate:  g.StartDate.Time.Format(apiconstants.DATE_LAYOUT),
+			TargetDate: g.TargetDate.Time.Format(apiconstants.DATE_LAYOUT),
+			Status:     g.Status,
+			Notes:      g.Notes.String,
+		}
+		if g.ExerciseID.Valid {
+			backupGoal.Exercise = exercises[g.ExerciseID.Int32]
+		}
+		if g.StatusDate.Valid {
+			backupGoal.StatusDate = g.StatusDate.Time.Format(apiconstants.DATE_LAYOUT)
+		}
+		doc.Goals = append(doc.Goals, backupGoal)
+	}
+
+	for _, m := range measurements {
+		doc.Measurements = append(doc.Measurements, backupMeasurement{
+			Date:       m.Date.Time.Format(apiconstants.DATE_LAYOUT),
+			Bodyweight: float8(m.Bodyweight),
+			BodyFat:    float8(m.BodyFat),
+			Neck:       float8(m.Neck),
+			Chest:      float8(m.Chest),
+			Waist:      float8(m.Waist),
+			Hips:       float8(m.Hips),
+			Arm:        float8(m.Arm),
+			Thigh:      float8(m.Thigh),
+			Calf:       float8(m.Calf),
+			Notes:      m.Notes.String,
+		})
+	}
+	return doc
+}
+
+func float8(value pgtype.Float8) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
+
+func int2(value pgtype.Int2) *int16 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Int16
+}
+
+func float8Param(value *float64) pgtype.Float8 {
+	if value == nil {
+		return pgtype.Float8{}
+	}
+	return pgtype.Float8{Float64: *value, Valid: true}
+}
+
+func int2Param(value *int16) pgtype.Int2 {
+	if value == nil {
+		return pgtype.Int2{}
+	}
+	return pgtype.Int2{Int16: *value, Valid: true}
+}
+
+func textParam(value string) pgtype.Text {
+	return pgtype.Text{String: value, Valid: value != ""}
+}
+
+func dateParam(value string) pgtype.Date {
+	date, err := time.Parse(apiconstants.DATE_LAYOUT, value)
+	return pgtype.Date{Time: date, Valid: err == nil && value != ""}
+}
@@ -0,0 +1,112 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validDocument = `{
+	"version": 1,
+	"created_at": "2025-03-10T08:00:00Z",
+	"profile": {"preferred_unit": "kg", "timezone": "Europe/Madrid", "country": "Spain"},
+	"sessions": [{
+		"id": "6f1c9a4e-2b1d-4c55-9f0a-1c2b3d4e5f60",
+		"name": "legs",
+		"date": "2025-03-01",
+		"tags": [],
+		"sets": [{
+			"order": 1,
+			"exercise": "squat",
+			"set_type": "working",
+			"logs": [{"order": 1, "weight": 100, "weight_unit": "kg", "reps": 5}]
+		}]
+	}],
+	"goals": [{"type": "strength", "exercise": "squat", "target": 120, "start_date": "2025-03-01", "target_date": "2025-06-01", "status": "active"}],
+	"measurements": [{"date": "2025-03-01", "bodyweight": 80}]
+}`
+
+func TestDocumentValid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		modify  func(d *document)
+		errKeys []string
+	}{
+		{
+			name:   "valid document",
+			modify: func(d *document) {},
+		},
+		{
+			name:    "unsupported version",
+			modify:  func(d *document) { d.Version = 2 },
+			errKeys: []string{"version"},
+		},
+		{
+			name: "invalid profile",
+			modify: func(d *document) {
+				d.Profile.PreferredUnit = "stone"
+				d.Profile.Timezone = "Local"
+				d.Profile.Birthday = "01/01/1990"
+			},
+			errKeys: []string{"profile.preferred_unit", "profile.timezone", "profile.birthday"},
+		},
+		{
+			name: "repeated session ids",
+			modify: func(d *document) {
+				d.Sessions = append(d.Sessions, d.Sessions[0])
+			},
+			errKeys: []string{"sessions[1].id"},
+		},
+		{
+			name: "invalid session",
+			modify: func(d *document) {
+				d.Sessions[0].Name = ""
+				d.Sessions[0].Date = "yesterday"
+			},
+			errKeys: []string{"sessions[0].name", "sessions[0].date"},
+		},
+		{
+			name: "invalid sets and logs",
+			modify: func(d *document) {
+				s := &d.Sessions[0].Sets[0]
+				s.SetType = "heavy"
+				s.Exercise = ""
+				s.Logs = append(s.Logs, s.Logs[0])
+				s.Logs[0].Reps = 0
+				s.Logs[0].WeightUnit = ""
+			},
+			errKeys: []string{
+				"sessions[0].sets[0].set_type",
+				"sessions[0].sets[0].exercise",
+				"sessions[0].sets[0].logs[0].reps",
+				"sessions[0].sets[0].logs[0].weight_unit",
+				"sessions[0].sets[0].logs[1].order",
+			},
+		},
+		{
+			name: "invalid goals and measurements",
+			modify: func(d *document) {
+				d.Goals[0].Type = "speed"
+				d.Goals[0].Status = "paused"
+				d.Measurements = append(d.Measurements, d.Measurements[0])
+			},
+			errKeys: []string{"goals[0].type", "goals[0].status", "measurements[1].date"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var d document
+			require.NoError(t, json.Unmarshal([]byte(validDocument), &d))
+			tc.modify(&d)
+			problems := d.Valid(context.Background())
+			assert.Len(t, problems, len(tc.errKeys), problems)
+			for _, key := range tc.errKeys {
+				assert.Contains(t, problems, key)
+			}
+		})
+	}
+}
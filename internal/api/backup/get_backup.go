@@ -0,0 +1,97 @@
+package backup
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// HandlerGetBackup returns all the data of the user as a versioned JSON document,
+// it can be restored with HandlerRestore
+func HandlerGetBackup(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("get backup failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		user, err := db.GetUser(r.Context(), userID)
+		if err == pgx.ErrNoRows {
+			util.RespondWithError(w, r, http.StatusNotFound, "user not found", err)
+			return
+		} else if err != nil {
+			reqLogger.Error("get backup failed - get user database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		sessions, err := db.GetSessionsByUserID(r.Context(), userID)
+		if err != nil {
+			reqLogger.Error("get backup failed - get sessions database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		sessionIDs := make([]uuid.UUID, len(sessions))
+		for i, s := range sessions {
+			sessionIDs[i] = s.ID
+		}
+		sets, err := db.GetSetsBySessionIDs(r.Context(), sessionIDs)
+		if err != nil {
+			reqLogger.Error("get backup failed - get sets database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		setIDs := make([]int64, len(sets))
+		for i, s := range sets {
+			setIDs[i] = s.ID
+		}
+		logs, err := db.GetLogsBySetIDs(r.Context(), setIDs)
+		if err != nil {
+			reqLogger.Error("get backup failed - get logs database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		goals, err := db.GetGoalsByUserID(r.Context(), userID)
+		if err != nil {
+			reqLogger.Error("get backup failed - get goals database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		measurements, err := db.GetBodyMeasurementsByUserID(r.Context(), userID)
+		if err != nil {
+			reqLogger.Error("get backup failed - get measurements database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		exercises, err := db.GetExercises(r.Context())
+		if err != nil {
+			reqLogger.Error("get backup failed - get exercises database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		exerciseNames := make(map[int32]string, len(exercises))
+		for _, e := range exercises {
+			exerciseNames[e.ID] = e.Name
+		}
+
+		doc := newDocument(user, sessions, sets, logs, goals, measurements, exerciseNames)
+		filename := "gogym-backup-" + time.Now().UTC().Format(apiconstants.DATE_LAYOUT) + ".json"
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+		reqLogger.Info("get backup success", slog.Int("sessions", len(doc.Sessions)), slog.Int("logs", len(logs)))
+		util.RespondWithJSON(w, r, http.StatusOK, doc)
+	}
+}
@@ -0,0 +1,396 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/record"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/api/validation"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// size limit of the restored document
+const maxBackupSize = 50 << 20
+
+type restoreRes struct {
+	Mode         string `json:"mode"`
+	Sessions     int    `json:"sessions"`
+	Sets         int    `json:"sets"`
+	Logs         int    `json:"logs"`
+	Goals        int    `json:"goals"` // goals already recorded are not restored again on merges
+	Measurements int    `json:"measurements"`
+}
+
+// HandlerRestore restores a backup of HandlerGetBackup in a single transaction. Restores are idempotent:
+// sessions keep their ids and replace the ones of previous restores, measurements replace the ones
+// of the same day and goals already recorded are skipped.
+func HandlerRestore(pool *pgxpool.Pool, db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("restore failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		mode := ModeMerge
+		if value := r.URL.Query().Get("mode"); value != "" {
+			mode = value
+		}
+		if !slices.Contains(Modes, mode) {
+			reqLogger.Debug("restore failed - invalid mode", slog.String("mode", mode))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, map[string]string{
+				"mode": "invalid mode: mode must be one of " + strings.Join(Modes, ", "),
+			})
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("mode", mode))
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxBackupSize)
+		doc, problems, err := validation.DecodeValid[*document](r)
+		if len(problems) > 0 {
+			reqLogger.Debug("restore failed - validation errors", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		} else if err != nil {
+			reqLogger.Debug("restore failed - invalid payload", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusBadRequest, "invalid payload", err)
+			return
+		}
+
+		// exercises are referenced by name, every one of them must be in the catalogue
+		exercises, err := db.GetExercises(r.Context())
+		if err != nil {
+			reqLogger.Error("restore failed - get exercises database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		// names shared by several exercises can not be resolved, they are kept with a zero id
+		exerciseIDs := make(map[string]int32, len(exercises))
+		for _, e := range exercises {
+			name := strings.ToLower(e.Name)
+			if _, ok := exerciseIDs[name]; ok {
+				exerciseIDs[name] = 0
+				continue
+			}
+			exerciseIDs[name] = e.ID
+		}
+		problems = resolveExercises(doc, exerciseIDs)
+		if len(problems) > 0 {
+			reqLogger.Debug("restore failed - exercises not found", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		}
+
+		tx, err := pool.Begin(r.Context())
+		if err != nil {
+			reqLogger.Error("restore failed - transaction start error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		defer tx.Rollback(r.Context())
+
+		res, err := restore(r.Context(), db.WithTx(tx), userID, doc, mode, exerciseIDs)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) {
+				switch {
+				case pgErr.Code == "23505" && strings.Contains(pgErr.ConstraintName, "display_name"):
+					reqLogger.Debug("restore failed - display name taken")
+					util.RespondWithError(w, r, http.StatusConflict, "display name already taken", err)
+					return
+				case pgErr.Code == "23514" || pgErr.Code == "23505":
+					reqLogger.Debug("restore failed - constraint violation", slog.String("constraint", pgErr.ConstraintName))
+					util.RespondWithJSON(w, r, http.StatusBadRequest, map[string]string{
+						"backup": fmt.Sprintf("invalid backup: %s violates the constraint %s", pgErr.TableName, pgErr.ConstraintName),
+					})
+					return
+				}
+			}
+			reqLogger.Error("restore failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		if err := tx.Commit(r.Context()); err != nil {
+			reqLogger.Error("restore failed - transaction commit error", slog.String("error", err.Error()))
+			err = fmt.Errorf("could not commit the transaction: %w", err)
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		reqLogger.Info("restore success", slog.Int("sessions", res.Sessions), slog.Int("logs", res.Logs))
+		util.RespondWithJSON(w, r, http.StatusOK, res)
+	}
+}
+
+// resolveExercises checks that every exercise of the document is a single exercise of the catalogue
+func resolveExercises(doc *document, exerciseIDs map[string]int32) map[string]string {
+	problems := make(map[string]string)
+	for i, s := range doc.Sessions {
+		for j, set := range s.Sets {
+			if msg := resolveExercise(set.Exercise, exerciseIDs); msg != "" {
+				problems[fmt.Sprintf("sessions[%d].sets[%d].exercise", i, j)] = msg
+			}
+		}
+	}
+	for i, g := range doc.Goals {
+		if g.Exercise == "" {
+			continue
+		}
+		if msg := resolveExercise(g.Exercise, exerciseIDs); msg != "" {
+			problems[fmt.Sprintf("goals[%d].exercise", i)] = msg
+		}
+	}
+	return problems
+}
+
+func resolveExercise(name string, exerciseIDs map[string]int32) string {
+	id, ok := exerciseIDs[strings.ToLower(name)]
+	if !ok {
+		return fmt.Sprintf("invalid exercise: exercise %q not found", name)
+	} else if id == 0 {
+		return fmt.Sprintf("invalid exercise: several exercises are named %q", name)
+	}
+	return ""
+}
+
+func restore(
+	ctx context.Context,
+	q *database.Queries,
+	userID uuid.UUID,
+	doc *document,
+	mode string,
+	exerciseIDs map[string]int32,
+) (restoreRes, error) {
+	res := restoreRes{Mode: mode}
+	p := doc.Profile
+	if _, err := q.RestoreUserProfile(ctx, database.RestoreUserProfileParams{
+		Country:                    textParam(p.Country),
+		Birthday:                   dateParam(p.Birthday),
+		PreferredUnit:              p.PreferredUnit,
+		Timezone:                   p.Timezone,
+		DisplayName:                textParam(p.DisplayName),
+		LeaderboardOptIn:           p.LeaderboardOptIn,
+		LeaderboardShareCountry:    p.LeaderboardShareCountry,
+		LeaderboardShareAge:        p.LeaderboardShareAge,
+		LeaderboardShareBodyweight: p.LeaderboardShareBodyweight,
+		ID:                         userID,
+	}); err != nil {
+		return res, fmt.Errorf("restore profile: %w", err)
+	}
+
+	// the sessions of the backup replace the ones restored before, the personal records
+	// of their logs are deleted with them and detected again
+	if mode == ModeReplace {
+		if err := q.DeleteSessionsByUserID(ctx, userID); err != nil {
+			return res, fmt.Errorf("delete sessions: %w", err)
+		}
+		if err := q.DeleteGoalsByUserID(ctx, userID); err != nil {
+			return res, fmt.Errorf("delete goals: %w", err)
+		}
+		if err := q.DeleteBodyMeasurementsByUserID(ctx, userID); err != nil {
+			return res, fmt.Errorf("delete measurements: %w", err)
+		}
+	} else {
+		ids := make([]uuid.UUID, 0, 2*len(doc.Sessions))
+		for _, s := range doc.Sessions {
+			ids = append(ids, s.ID, accountSessionID(userID, s.ID))
+		}
+		if err := q.DeleteSessionsByIDs(ctx, database.DeleteSessionsByIDsParams{UserID: userID, Ids: ids}); err != nil {
+			return res, fmt.Errorf("delete sessions: %w", err)
+		}
+	}
+
+	// sessions are restored in chronological order so the personal records are detected as they were set
+	sessions := slices.Clone(doc.Sessions)
+	slices.SortStableFunc(sessions, func(a, b backupSession) int {
+		if c := a.date.Compare(b.date); c != 0 {
+			return c
+		}
+		if a.StartTimestamp == nil || b.StartTimestamp == nil {
+			return 0
+		}
+		return a.StartTimestamp.Compare(*b.StartTimestamp)
+	})
+	for _, s := range sessions {
+		if err := restoreSession(ctx, q, userID, s, exerciseIDs, &res); err != nil {
+			return res, err
+		}
+	}
+
+	// goals have no identity of their own, the ones already recorded are skipped
+	existing, err := q.GetGoalsByUserID(ctx, userID)
+	if err != nil {
+		return res, fmt.Errorf("get goals: %w", err)
+	}
+	recorded := make(map[string]bool, len(existing))
+	for _, g := range existing {
+		recorded[goalKey(g.GoalType, g.ExerciseID, g.Target, g.StartDate, g.TargetDate)] = true
+	}
+	for _, g := range doc.Goals {
+		exerciseID := pgtype.Int4{}
+		if g.Exercise != "" {
+			exerciseID = pgtype.Int4{Int32: exerciseIDs[strings.ToLower(g.Exercise)], Valid: true}
+		}
+		startDate, targetDate := dateParam(g.StartDate), dateParam(g.TargetDate)
+		key := goalKey(g.Type, exerciseID, g.Target, startDate, targetDate)
+		if recorded[key] {
+			continue
+		}
+		recorded[key] = true
+
+		created, err := q.CreateGoal(ctx, database.CreateGoalParams{
+			UserID:     userID,
+			GoalType:   g.Type,
+			ExerciseID: exerciseID,
+			Target:     g.Target,
+			Baseline:   float8Param(g.Baseline),
+			StartDate:  startDate,
+			TargetDate: targetDate,
+			Notes:      textParam(g.Notes),
+		})
+		if err != nil {
+			return res, fmt.Errorf("create goal: %w", err)
+		}
+		if err := q.UpdateGoalStatus(ctx, database.UpdateGoalStatusParams{
+			Status:     g.Status,
+			StatusDate: dateParam(g.StatusDate),
+			ID:         created.ID,
+		}); err != nil {
+			return res, fmt.Errorf("update goal status: %w", err)
+		}
+		res.Goals++
+	}
+
+	for _, m := range doc.Measurements {
+		if _, err := q.UpsertBodyMeasurement(ctx, database.UpsertBodyMeasurementParams{
+			UserID:     userID,
+			Date:       dateParam(m.Date),
+			Bodyweight: float8Param(m.Bodyweight),
+			BodyFat:    float8Param(m.BodyFat),
+			Neck:       float8Param(m.Neck),
+			Chest:      float8Param(m.Chest),
+			Waist:      float8Param(m.Waist),
+			Hips:       float8Param(m.Hips),
+			Arm:        float8Param(m.Arm),
+			Thigh:      float8Param(m.Thigh),
+			Calf:       float8Param(m.Calf),
+			Notes:      textParam(m.Notes),
+		}); err != nil {
+			return res, fmt.Errorf("restore measurement: %w", err)
+		}
+		res.Measurements++
+	}
+
+	return res, nil
+}
+
+func restoreSession(
+	ctx context.Context,
+	q *database.Queries,
+	userID uuid.UUID,
+	s backupSession,
+	exerciseIDs map[string]int32,
+	res *restoreRes,
+) error {
+	startTimestamp := pgtype.Timestamp{}
+	if s.StartTimestamp != nil {
+		startTimestamp = pgtype.Timestamp{Time: s.StartTimestamp.UTC(), Valid: true}
+	}
+	params := database.RestoreSessionParams{
+		ID:              s.ID,
+		Name:            s.Name,
+		Date:            pgtype.Date{Time: s.date, Valid: true},
+		StartTimestamp:  startTimestamp,
+		DurationMinutes: int2Param(s.DurationMinutes),
+		UserID:          userID,
+		Tags:            s.Tags,
+		Notes:           textParam(s.Notes),
+		Rpe:             int2Param(s.RPE),
+		SleepQuality:    int2Param(s.SleepQuality),
+		Bodyweight:      float8Param(s.Bodyweight),
+		Mood:            int2Param(s.Mood),
+		Location:        textParam(s.Location),
+	}
+	session, err := q.RestoreSession(ctx, params)
+	if err == pgx.ErrNoRows {
+		// the id belongs to a session of another user, e.g. the backup of another account
+		params.ID = accountSessionID(userID, s.ID)
+		session, err = q.RestoreSession(ctx, params)
+	}
+	if err != nil {
+		return fmt.Errorf("restore session: %w", err)
+	}
+	res.Sessions++
+
+	for _, s := range s.Sets {
+		restTime := pgtype.Int4{}
+		if s.RestTime != nil {
+			restTime = pgtype.Int4{Int32: *s.RestTime, Valid: true}
+		}
+		newSet, err := q.CreateSet(ctx, database.CreateSetParams{
+			SetOrder:   s.Order,
+			RestTime:   restTime,
+			SessionID:  session.ID,
+			ExerciseID: exerciseIDs[strings.ToLower(s.Exercise)],
+			SetType:    s.SetType,
+			GroupKey:   textParam(s.GroupKey),
+		})
+		if err != nil {
+			return fmt.Errorf("create set: %w", err)
+		}
+		res.Sets++
+
+		for _, l := range s.Logs {
+			log, err := q.CreateLog(ctx, database.CreateLogParams{
+				Weight:         float8Param(l.Weight),
+				Reps:           l.Reps,
+				LogsOrder:      l.Order,
+				ExerciseID:     newSet.ExerciseID,
+				SetID:          newSet.ID,
+				Rpe:            float8Param(l.RPE),
+				Rir:            int2Param(l.RIR),
+				Tempo:          textParam(l.Tempo),
+				ReachedFailure: l.ReachedFailure,
+				PartialReps:    l.PartialReps,
+				Notes:          textParam(l.Notes),
+				WeightUnit:     l.WeightUnit,
+			})
+			if err != nil {
+				return fmt.Errorf("create log: %w", err)
+			}
+			if _, err := record.Detect(ctx, q, userID, log); err != nil {
+				return fmt.Errorf("detect records: %w", err)
+			}
+			res.Logs++
+		}
+	}
+	return nil
+}
+
+// accountSessionID is the id of a session restored by a user other than its owner. It is the same on
+// every restore of the backup, so merges replace the sessions restored before instead of adding them again.
+func accountSessionID(userID, sessionID uuid.UUID) uuid.UUID {
+	return uuid.NewSHA1(userID, sessionID[:])
+}
+
+func goalKey(goalType string, exerciseID pgtype.Int4, target float64, startDate, targetDate pgtype.Date) string {
+	return fmt.Sprintf("%s|%d|%v|%g|%s|%s", goalType, exerciseID.Int32, exerciseID.Valid, target,
+		startDate.Time.Format(apiconstants.DATE_LAYOUT), targetDate.Time.Format(apiconstants.DATE_LAYOUT))
+}
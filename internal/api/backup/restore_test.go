@@ -0,0 +1,280 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createHistory records a session with warm-up and working sets, a goal and a measurement
+func createHistory(t *testing.T, db *database.Queries, userID uuid.UUID, squatID int32) {
+	t.Helper()
+	ctx := context.Background()
+	session, err := db.CreateSession(ctx, database.CreateSessionParams{
+		Name:            "legs",
+		Date:            pgtype.Date{Time: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		StartTimestamp:  pgtype.Timestamp{Time: time.Date(2025, time.March, 1, 9, 30, 0, 0, time.UTC), Valid: true},
+		DurationMinutes: pgtype.Int2{Int16: 60, Valid: true},
+		UserID:          userID,
+		Tags:            []string{"strength"},
+		Notes:           pgtype.Text{String: "felt strong", Valid: true},
+		Rpe:             pgtype.Int2{Int16: 8, Valid: true},
+		Bodyweight:      pgtype.Float8{Float64: 80, Valid: true},
+	})
+	require.NoError(t, err)
+	warmUp, err := db.CreateSet(ctx, database.CreateSetParams{
+		SetOrder:   1,
+		SessionID:  session.ID,
+		ExerciseID: squatID,
+		SetType:    "warm_up",
+	})
+	require.NoError(t, err)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 5, 1, squatID, warmUp.ID, 60)
+	setID := testutil.CreateSetDBTestHelper(t, db, session.ID, squatID)
+	_, err = db.CreateLog(ctx, database.CreateLogParams{
+		Weight:         pgtype.Float8{Float64: 102.058, Valid: true},
+		Reps:           5,
+		LogsOrder:      1,
+		ExerciseID:     squatID,
+		SetID:          setID,
+		Rpe:            pgtype.Float8{Float64: 8.5, Valid: true},
+		Rir:            pgtype.Int2{Int16: 1, Valid: true},
+		Tempo:          pgtype.Text{String: "3-1-X-0", Valid: true},
+		ReachedFailure: false,
+		Notes:          pgtype.Text{String: "belt", Valid: true},
+		WeightUnit:     "lb",
+	})
+	require.NoError(t, err)
+
+	goal, err := db.CreateGoal(ctx, database.CreateGoalParams{
+		UserID:     userID,
+		GoalType:   "strength",
+		ExerciseID: pgtype.Int4{Int32: squatID, Valid: true},
+		Target:     100,
+		StartDate:  pgtype.Date{Time: time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		TargetDate: pgtype.Date{Time: time.Date(2025, time.May, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.UpdateGoalStatus(ctx, database.UpdateGoalStatusParams{
+		Status:     "achieved",
+		StatusDate: pgtype.Date{Time: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		ID:         goal.ID,
+	}))
+	_, err = db.CreateBodyMeasurement(ctx, database.CreateBodyMeasurementParams{
+		UserID:     userID,
+		Date:       pgtype.Date{Time: time.Date(2025, time.March, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+		Bodyweight: pgtype.Float8{Float64: 80.5, Valid: true},
+		Waist:      pgtype.Float8{Float64: 84, Valid: true},
+	})
+	require.NoError(t, err)
+}
+
+func getBackup(t *testing.T, db *database.Queries, userID uuid.UUID) document {
+	t.Helper()
+	req, err := http.NewRequest("GET", "/test", nil)
+	require.NoError(t, err)
+	req = req.WithContext(util.ContextWithUser(req.Context(), userID))
+	rr := httptest.NewRecorder()
+
+	middleware.RequestID(HandlerGetBackup(db, logger)).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Contains(t, rr.Header().Get("Content-Disposition"), "attachment")
+	var doc document
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&doc))
+	doc.CreatedAt = time.Time{}
+	return doc
+}
+
+func restoreBackup(t *testing.T, db *database.Queries, userID uuid.UUID, query string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	req, err := http.NewRequest("POST", "/test"+query, bytes.NewReader(body))
+	require.NoError(t, err)
+	req = req.WithContext(util.ContextWithUser(req.Context(), userID))
+	rr := httptest.NewRecorder()
+
+	middleware.RequestID(HandlerRestore(dbPool, db, logger)).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestHandlerGetBackup(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	squatID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+	createHistory(t, db, user.ID, squatID)
+
+	doc := getBackup(t, db, user.ID)
+	assert.Equal(t, Version, doc.Version)
+	assert.Equal(t, "kg", doc.Profile.PreferredUnit)
+	require.Len(t, doc.Sessions, 1)
+	session := doc.Sessions[0]
+	assert.Equal(t, "legs", session.Name)
+	assert.Equal(t, "2025-03-01", session.Date)
+	assert.Equal(t, []string{"strength"}, session.Tags)
+	require.Len(t, session.Sets, 2)
+	assert.Equal(t, "warm_up", session.Sets[0].SetType)
+	assert.Equal(t, "squat", session.Sets[1].Exercise)
+	require.Len(t, session.Sets[1].Logs, 1)
+	log := session.Sets[1].Logs[0]
+	assert.Equal(t, 102.058, *log.Weight)
+	assert.Equal(t, "lb", log.WeightUnit)
+	assert.Equal(t, "3-1-X-0", log.Tempo)
+	require.Len(t, doc.Goals, 1)
+	assert.Equal(t, "achieved", doc.Goals[0].Status)
+	assert.Equal(t, "squat", doc.Goals[0].Exercise)
+	require.Len(t, doc.Measurements, 1)
+	assert.Equal(t, 84.0, *doc.Measurements[0].Waist)
+
+	// users without data get an empty backup
+	other := testutil.CreateUserDBTestHelper(t, db, "otheruser", "testpassword", false)
+	empty := getBackup(t, db, other.ID)
+	assert.Empty(t, empty.Sessions)
+	assert.Empty(t, empty.Goals)
+	assert.Empty(t, empty.Measurements)
+}
+
+func TestHandlerRestore(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	squatID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+	createHistory(t, db, user.ID, squatID)
+	original := getBackup(t, db, user.ID)
+	body, err := json.Marshal(original)
+	require.NoError(t, err)
+
+	t.Run("merge is idempotent", func(t *testing.T) {
+		for range 2 {
+			rr := restoreBackup(t, db, user.ID, "", body)
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			var res restoreRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+			assert.Equal(t, restoreRes{Mode: ModeMerge, Sessions: 1, Sets: 2, Logs: 2, Measurements: 1}, res)
+			assert.Equal(t, original, getBackup(t, db, user.ID))
+		}
+	})
+
+	t.Run("merge keeps the sessions not in the backup", func(t *testing.T) {
+		testutil.CreateSessionDBTestHelper(t, db, "extra", user.ID)
+		rr := restoreBackup(t, db, user.ID, "?mode=merge", body)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Len(t, getBackup(t, db, user.ID).Sessions, 2)
+	})
+
+	t.Run("replace deletes the sessions not in the backup", func(t *testing.T) {
+		rr := restoreBackup(t, db, user.ID, "?mode=replace", body)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var res restoreRes
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		assert.Equal(t, restoreRes{Mode: ModeReplace, Sessions: 1, Sets: 2, Logs: 2, Goals: 1, Measurements: 1}, res)
+		assert.Equal(t, original, getBackup(t, db, user.ID))
+	})
+
+	t.Run("restore into another account", func(t *testing.T) {
+		other := testutil.CreateUserDBTestHelper(t, db, "otheruser", "testpassword", false)
+		rr := restoreBackup(t, db, other.ID, "?mode=replace", body)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		// the session ids belong to the first user, the restored sessions get new ones
+		restored := getBackup(t, db, other.ID)
+		require.Len(t, restored.Sessions, 1)
+		assert.NotEqual(t, original.Sessions[0].ID, restored.Sessions[0].ID)
+		restored.Sessions[0].ID = original.Sessions[0].ID
+		assert.Equal(t, original, restored)
+		assert.Equal(t, original, getBackup(t, db, user.ID))
+	})
+
+	t.Run("merge into another account is idempotent", func(t *testing.T) {
+		other := testutil.CreateUserDBTestHelper(t, db, "mergeuser", "testpassword", false)
+		var first document
+		for i := range 2 {
+			rr := restoreBackup(t, db, other.ID, "?mode=merge", body)
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+			restored := getBackup(t, db, other.ID)
+			require.Len(t, restored.Sessions, 1)
+			assert.NotEqual(t, original.Sessions[0].ID, restored.Sessions[0].ID)
+			if i == 0 {
+				first = restored
+			}
+			assert.Equal(t, first, restored)
+		}
+		assert.Equal(t, original, getBackup(t, db, user.ID))
+	})
+
+	testCases := []struct {
+		name       string
+		query      string
+		body       string
+		statusCode int
+		errKeys    []string
+	}{
+		{
+			name:       "invalid mode",
+			query:      "?mode=append",
+			body:       string(body),
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"mode"},
+		},
+		{
+			name:       "unsupported version",
+			body:       strings.Replace(string(body), `"version":1`, `"version":99`, 1),
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"version"},
+		},
+		{
+			name:       "exercise not in the catalogue",
+			body:       strings.ReplaceAll(string(body), `"exercise":"squat"`, `"exercise":"zercher squat"`),
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"sessions[0].sets[0].exercise", "goals[0].exercise"},
+		},
+		{
+			name:       "value out of range",
+			body:       strings.Replace(string(body), `"waist":84`, `"waist":-84`, 1),
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"backup"},
+		},
+		{
+			name:       "invalid payload",
+			body:       `{"version": 1, "sessions": "all"}`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := restoreBackup(t, db, user.ID, tc.query, []byte(tc.body))
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			for _, key := range tc.errKeys {
+				assert.Contains(t, rr.Body.String(), key)
+			}
+			// failed restores change nothing
+			assert.Equal(t, original, getBackup(t, db, user.ID))
+		})
+	}
+
+	t.Run("exercise name shared by several exercises", func(t *testing.T) {
+		testutil.CreateExerciseDBTestHelper(t, db, "Squat")
+		rr := restoreBackup(t, db, user.ID, "", body)
+		require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+		assert.Contains(t, rr.Body.String(), "several exercises")
+		assert.Equal(t, original, getBackup(t, db, user.ID))
+	})
+}
@@ -0,0 +1,147 @@
+package calendar
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callTokenHandler(t *testing.T, handler http.Handler, method string, userID uuid.UUID) *httptest.ResponseRecorder {
+	t.Helper()
+	req, err := http.NewRequest(method, "/test", nil)
+	require.NoError(t, err)
+	req = req.WithContext(util.ContextWithUser(req.Context(), userID))
+	rr := httptest.NewRecorder()
+	middleware.RequestID(handler).ServeHTTP(rr, req)
+	return rr
+}
+
+func getFeed(t *testing.T, db *database.Queries, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	req, err := http.NewRequest("GET", path, nil)
+	require.NoError(t, err)
+	req.SetPathValue("file", strings.TrimPrefix(path, feedPath))
+	rr := httptest.NewRecorder()
+	middleware.RequestID(HandlerGetFeed(db, logger)).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestCalendarToken(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+
+	rr := callTokenHandler(t, HandlerGetToken(db, logger), "GET", user.ID)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = callTokenHandler(t, HandlerCreateToken(db, logger), "POST", user.ID)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var first tokenRes
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&first))
+	assert.True(t, strings.HasPrefix(first.Path, feedPath))
+	assert.True(t, strings.HasSuffix(first.Path, ".ics"))
+
+	rr = callTokenHandler(t, HandlerGetToken(db, logger), "GET", user.ID)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var current tokenRes
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&current))
+	assert.Equal(t, first.Path, current.Path)
+
+	// a new token revokes the previous url
+	rr = callTokenHandler(t, HandlerCreateToken(db, logger), "POST", user.ID)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var second tokenRes
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&second))
+	assert.NotEqual(t, first.Path, second.Path)
+	assert.Equal(t, http.StatusNotFound, getFeed(t, db, first.Path).Code)
+	assert.Equal(t, http.StatusOK, getFeed(t, db, second.Path).Code)
+
+	rr = callTokenHandler(t, HandlerDeleteToken(db, logger), "DELETE", user.ID)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, http.StatusNotFound, getFeed(t, db, second.Path).Code)
+	rr = callTokenHandler(t, HandlerDeleteToken(db, logger), "DELETE", user.ID)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestHandlerGetFeed(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	other := testutil.CreateUserDBTestHelper(t, db, "otheruser", "testpassword", false)
+	squatID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+	sessionID := testutil.CreateSessionDBTestHelper(t, db, "legs", user.ID)
+	testutil.CreateSetDBTestHelper(t, db, sessionID, squatID)
+	testutil.CreateSetDBTestHelper(t, db, sessionID, squatID)
+	testutil.CreateSessionDBTestHelper(t, db, "hidden", other.ID)
+
+	rr := callTokenHandler(t, HandlerCreateToken(db, logger), "POST", user.ID)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var token tokenRes
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&token))
+
+	t.Run("feed of the user", func(t *testing.T) {
+		rr := getFeed(t, db, token.Path)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, "text/calendar; charset=utf-8", rr.Header().Get("Content-Type"))
+		body := rr.Body.String()
+		assert.True(t, strings.HasPrefix(body, "BEGIN:VCALENDAR\r\n"))
+		assert.Equal(t, 1, strings.Count(body, "BEGIN:VEVENT"))
+		assert.Contains(t, body, "UID:"+sessionID.String()+"@gogym\r\n")
+		assert.Contains(t, body, "SUMMARY:legs\r\n")
+		assert.Contains(t, body, "DESCRIPTION:squat: 2 sets\r\n")
+		assert.NotContains(t, body, "hidden")
+	})
+
+	testCases := []struct {
+		name string
+		path string
+	}{
+		{name: "unknown token", path: feedPath + "unknown.ics"},
+		{name: "missing extension", path: strings.TrimSuffix(token.Path, ".ics")},
+		{name: "missing token", path: feedPath + ".ics"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := getFeed(t, db, tc.path)
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+		})
+	}
+}
+
+func TestHandlerGetFeedLogs(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	rr := callTokenHandler(t, HandlerCreateToken(db, logger), "POST", user.ID)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var token tokenRes
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&token))
+
+	// the token is a credential, it never reaches the logs
+	var logs bytes.Buffer
+	bufferLogger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	req, err := http.NewRequest("GET", token.Path, nil)
+	require.NoError(t, err)
+	req.SetPathValue("file", strings.TrimPrefix(token.Path, feedPath))
+	rr = httptest.NewRecorder()
+	middleware.RequestID(HandlerGetFeed(db, bufferLogger)).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	secret := strings.TrimSuffix(strings.TrimPrefix(token.Path, feedPath), ".ics")
+	assert.Contains(t, logs.String(), "get calendar feed success")
+	assert.NotContains(t, logs.String(), secret)
+}
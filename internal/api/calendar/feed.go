@@ -0,0 +1,75 @@
+package calendar
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// past sessions older than this are left out of the feed, planned sessions are always included
+const feedHistoryDays = 365
+
+// HandlerGetFeed serves the sessions of the owner of the token as an iCalendar feed.
+// The request is not authenticated, calendar applications only know the url.
+func HandlerGetFeed(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := feedReqLogger(logger, r)
+		// the token can not be a wildcard on its own since the file name carries the extension
+		token, ok := strings.CutSuffix(r.PathValue("file"), ".ics")
+		if !ok || token == "" {
+			util.RespondWithError(w, r, http.StatusNotFound, "calendar not found", nil)
+			return
+		}
+
+		userID, err := db.GetCalendarTokenUserID(r.Context(), token)
+		if err == pgx.ErrNoRows {
+			util.RespondWithError(w, r, http.StatusNotFound, "calendar not found", err)
+			return
+		} else if err != nil {
+			reqLogger.Error("get calendar feed failed - get token database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		now := time.Now().UTC()
+		rows, err := db.GetCalendarEvents(r.Context(), database.GetCalendarEventsParams{
+			UserID:   userID,
+			FromDate: pgtype.Date{Time: now.AddDate(0, 0, -feedHistoryDays), Valid: true},
+		})
+		if err != nil {
+			reqLogger.Error("get calendar feed failed - get events database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		events := eventsFromRows(rows)
+		var b strings.Builder
+		writeCalendar(&b, events, now)
+
+		reqLogger.Info("get calendar feed success", slog.Int("events", len(events)))
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Content-Disposition", `inline; filename="gogym.ics"`)
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write([]byte(b.String())); err != nil {
+			reqLogger.Debug("could not write the response", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// feedReqLogger is the request logger of the feed without its path, the token in it is a credential
+func feedReqLogger(logger *slog.Logger, r *http.Request) *slog.Logger {
+	requestID, _ := util.RequestIDFromContext(r.Context())
+	return logger.With(
+		slog.String("request_id", requestID),
+		slog.String("method", r.Method),
+		slog.String("path", feedPath+"{token}.ics"),
+		slog.String("remote_addr", r.RemoteAddr),
+	)
+}
@@ -0,0 +1,132 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	icsDateLayout     = "20060102"
+	icsDateTimeLayout = "20060102T150405Z"
+	// RFC 5545 lines are folded after 75 octets
+	icsMaxLineLength = 75
+	// sessions without a recorded duration are shown with this one
+	defaultDurationMinutes = 60
+)
+
+type event struct {
+	id              uuid.UUID
+	name            string
+	date            time.Time
+	start           time.Time // zero when the session has no start, it is shown as an all-day event
+	durationMinutes int
+	exercises       []string
+	notes           string
+	location        string
+}
+
+// eventsFromRows groups the exercise rows of each session into a single event,
+// the rows of a session are expected to be consecutive
+func eventsFromRows(rows []database.GetCalendarEventsRow) []event {
+	events := make([]event, 0)
+	for _, row := range rows {
+		if len(events) == 0 || events[len(events)-1].id != row.ID {
+			e := event{
+				id:              row.ID,
+				name:            row.Name,
+				date:            row.Date.Time,
+				durationMinutes: defaultDurationMinutes,
+				notes:           row.Notes.String,
+				location:        row.Location.String,
+			}
+			if row.StartTimestamp.Valid {
+				e.start = row.StartTimestamp.Time
+			}
+			if row.DurationMinutes.Valid && row.DurationMinutes.Int16 > 0 {
+				e.durationMinutes = int(row.DurationMinutes.Int16)
+			}
+			events = append(events, e)
+		}
+		if row.ExerciseName.Valid {
+			e := &events[len(events)-1]
+			sets := "sets"
+			if row.SetCount == 1 {
+				sets = "set"
+			}
+			e.exercises = append(e.exercises, fmt.Sprintf("%s: %d %s", row.ExerciseName.String, row.SetCount, sets))
+		}
+	}
+	return events
+}
+
+func (e event) description() string {
+	description := strings.Join(e.exercises, "\n")
+	if e.notes != "" {
+		if description != "" {
+			description += "\n\n"
+		}
+		description += e.notes
+	}
+	return description
+}
+
+// writeCalendar renders the events as an RFC 5545 calendar, stamp is the time the feed is generated at
+func writeCalendar(b *strings.Builder, events []event, stamp time.Time) {
+	writeLine(b, "BEGIN:VCALENDAR")
+	writeLine(b, "VERSION:2.0")
+	writeLine(b, "PRODID:-//gogym//calendar//EN")
+	writeLine(b, "CALSCALE:GREGORIAN")
+	writeLine(b, "METHOD:PUBLISH")
+	writeLine(b, "X-WR-CALNAME:gogym")
+	for _, e := range events {
+		writeLine(b, "BEGIN:VEVENT")
+		writeLine(b, "UID:"+e.id.String()+"@gogym")
+		writeLine(b, "DTSTAMP:"+stamp.UTC().Format(icsDateTimeLayout))
+		if e.start.IsZero() {
+			// all-day events end on the next day by default
+			writeLine(b, "DTSTART;VALUE=DATE:"+e.date.Format(icsDateLayout))
+		} else {
+			writeLine(b, "DTSTART:"+e.start.UTC().Format(icsDateTimeLayout))
+			writeLine(b, fmt.Sprintf("DURATION:PT%dM", e.durationMinutes))
+		}
+		writeLine(b, "SUMMARY:"+escapeText(e.name))
+		if description := e.description(); description != "" {
+			writeLine(b, "DESCRIPTION:"+escapeText(description))
+		}
+		if e.location != "" {
+			writeLine(b, "LOCATION:"+escapeText(e.location))
+		}
+		writeLine(b, "END:VEVENT")
+	}
+	writeLine(b, "END:VCALENDAR")
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// escapeText escapes the characters with a meaning in TEXT values
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// writeLine writes a content line ended by CRLF, folding it without splitting multi-byte characters
+func writeLine(b *strings.Builder, line string) {
+	limit := icsMaxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// the leading space of the continuation line counts towards its length
+		limit = icsMaxLineLength - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
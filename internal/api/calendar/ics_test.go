@@ -0,0 +1,142 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapeText(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "plain text", input: "legs day", expected: "legs day"},
+		{name: "separators", input: "squat, bench; deadlift", expected: `squat\, bench\; deadlift`},
+		{name: "backslash", input: `push\pull`, expected: `push\\pull`},
+		{name: "new lines", input: "first\nsecond\r\nthird", expected: `first\nsecond\nthird`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, escapeText(tc.input))
+		})
+	}
+}
+
+func TestWriteLine(t *testing.T) {
+	testCases := []struct {
+		name string
+		line string
+	}{
+		{name: "short line", line: "SUMMARY:legs"},
+		{name: "exactly the limit", line: strings.Repeat("a", icsMaxLineLength)},
+		{name: "long line", line: "DESCRIPTION:" + strings.Repeat("squat ", 40)},
+		{name: "multi-byte characters", line: "SUMMARY:" + strings.Repeat("día ", 40)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var b strings.Builder
+			writeLine(&b, tc.line)
+			out := b.String()
+			require.True(t, strings.HasSuffix(out, "\r\n"))
+
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			for i, line := range lines {
+				assert.LessOrEqual(t, len(line), icsMaxLineLength)
+				if i > 0 {
+					assert.True(t, strings.HasPrefix(line, " "))
+				}
+			}
+			// unfolding gives back the original line
+			assert.Equal(t, tc.line, strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""))
+		})
+	}
+}
+
+func TestEventsFromRows(t *testing.T) {
+	legsID, restID := uuid.New(), uuid.New()
+	date := pgtype.Date{Time: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+	start := pgtype.Timestamp{Time: time.Date(2025, time.March, 1, 9, 30, 0, 0, time.UTC), Valid: true}
+	rows := []database.GetCalendarEventsRow{
+		{
+			ID: legsID, Name: "legs", Date: date, StartTimestamp: start,
+			DurationMinutes: pgtype.Int2{Int16: 75, Valid: true},
+			Notes:           pgtype.Text{String: "felt strong", Valid: true},
+			ExerciseName:    pgtype.Text{String: "squat", Valid: true},
+			SetCount:        3,
+		},
+		{
+			ID: legsID, Name: "legs", Date: date, StartTimestamp: start,
+			ExerciseName: pgtype.Text{String: "leg press", Valid: true},
+			SetCount:     1,
+		},
+		{ID: restID, Name: "mobility", Date: date},
+	}
+
+	events := eventsFromRows(rows)
+	require.Len(t, events, 2)
+	assert.Equal(t, []string{"squat: 3 sets", "leg press: 1 set"}, events[0].exercises)
+	assert.Equal(t, 75, events[0].durationMinutes)
+	assert.Equal(t, "squat: 3 sets\nleg press: 1 set\n\nfelt strong", events[0].description())
+	assert.Empty(t, events[1].exercises)
+	assert.True(t, events[1].start.IsZero())
+	assert.Equal(t, defaultDurationMinutes, events[1].durationMinutes)
+	assert.Empty(t, events[1].description())
+}
+
+func TestWriteCalendar(t *testing.T) {
+	id := uuid.MustParse("6f1c9a4e-2b1d-4c55-9f0a-1c2b3d4e5f60")
+	events := []event{
+		{
+			id:              id,
+			name:            "legs, heavy",
+			date:            time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+			start:           time.Date(2025, time.March, 1, 9, 30, 0, 0, time.UTC),
+			durationMinutes: 60,
+			exercises:       []string{"squat: 3 sets"},
+			location:        "home",
+		},
+		{
+			id:              id,
+			name:            "mobility",
+			date:            time.Date(2025, time.March, 2, 0, 0, 0, 0, time.UTC),
+			durationMinutes: defaultDurationMinutes,
+		},
+	}
+	stamp := time.Date(2025, time.March, 3, 8, 0, 0, 0, time.UTC)
+
+	var b strings.Builder
+	writeCalendar(&b, events, stamp)
+	expected := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//gogym//calendar//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:gogym",
+		"BEGIN:VEVENT",
+		"UID:6f1c9a4e-2b1d-4c55-9f0a-1c2b3d4e5f60@gogym",
+		"DTSTAMP:20250303T080000Z",
+		"DTSTART:20250301T093000Z",
+		"DURATION:PT60M",
+		`SUMMARY:legs\, heavy`,
+		"DESCRIPTION:squat: 3 sets",
+		"LOCATION:home",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:6f1c9a4e-2b1d-4c55-9f0a-1c2b3d4e5f60@gogym",
+		"DTSTAMP:20250303T080000Z",
+		"DTSTART;VALUE=DATE:20250302",
+		"SUMMARY:mobility",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n") + "\r\n"
+	assert.Equal(t, expected, b.String())
+}
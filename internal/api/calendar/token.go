@@ -0,0 +1,114 @@
+package calendar
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/auth"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5"
+)
+
+// feedPath is the path of the calendar feed, the token is the only credential needed to read it
+const feedPath = "/api/v1/calendar/"
+
+type tokenRes struct {
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func tokenResFromDB(token database.CalendarToken) tokenRes {
+	return tokenRes{
+		Path:      feedPath + token.Token + ".ics",
+		CreatedAt: token.CreatedAt.Time,
+	}
+}
+
+// HandlerCreateToken enables the calendar feed of the user,
+// calling it again issues a new token and the previous feed url stops working
+func HandlerCreateToken(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("create calendar token failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		// the feed token is as hard to guess as a refresh token
+		randomToken, err := auth.MakeRefreshToken()
+		if err != nil {
+			reqLogger.Error("create calendar token failed - token generation error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		token, err := db.UpsertCalendarToken(r.Context(), database.UpsertCalendarTokenParams{
+			Token:  randomToken,
+			UserID: userID,
+		})
+		if err != nil {
+			reqLogger.Error("create calendar token failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		reqLogger.Info("create calendar token success")
+		util.RespondWithJSON(w, r, http.StatusCreated, tokenResFromDB(token))
+	}
+}
+
+func HandlerGetToken(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("get calendar token failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		token, err := db.GetCalendarToken(r.Context(), userID)
+		if err == pgx.ErrNoRows {
+			util.RespondWithError(w, r, http.StatusNotFound, "calendar feed not enabled", err)
+			return
+		} else if err != nil {
+			reqLogger.Error("get calendar token failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		reqLogger.Info("get calendar token success")
+		util.RespondWithJSON(w, r, http.StatusOK, tokenResFromDB(token))
+	}
+}
+
+// HandlerDeleteToken disables the calendar feed of the user
+func HandlerDeleteToken(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("delete calendar token failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		if _, err := db.DeleteCalendarToken(r.Context(), userID); err == pgx.ErrNoRows {
+			util.RespondWithError(w, r, http.StatusNotFound, "calendar feed not enabled", err)
+			return
+		} else if err != nil {
+			reqLogger.Error("delete calendar token failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		reqLogger.Info("delete calendar token success")
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/CTSDM/gogym/internal/api/middleware"
 	"github.com/CTSDM/gogym/internal/api/util"
@@ -16,9 +18,18 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// primary muscle groups an exercise can train
+var MuscleGroups = []string{
+	"chest", "back", "shoulders", "biceps", "triceps", "forearms",
+	"quads", "hamstrings", "glutes", "calves", "core", "full_body",
+}
+
 type createExerciseReq struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name            string  `json:"name"`
+	Description     string  `json:"description"`
+	MuscleGroup     string  `json:"muscle_group,omitempty"`
+	BodyweightRatio float64 `json:"bodyweight_ratio,omitempty"` // share of the bodyweight moved, 1 for pull ups
+	Leaderboard     bool    `json:"leaderboard"`                // whether users are ranked in the exercise
 }
 
 type createExerciseRes struct {
@@ -27,9 +38,12 @@ type createExerciseRes struct {
 }
 
 type exerciseItem struct {
-	ID          int32  `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	ID              int32   `json:"id"`
+	Name            string  `json:"name"`
+	Description     string  `json:"description"`
+	MuscleGroup     string  `json:"muscle_group,omitempty"`
+	BodyweightRatio float64 `json:"bodyweight_ratio,omitempty"`
+	Leaderboard     bool    `json:"leaderboard"`
 }
 
 type exercisesRes struct {
@@ -48,6 +62,17 @@ func (r createExerciseReq) Valid(ctx context.Context) map[string]string {
 		problems["description"] = fmt.Sprintf("invalid description: %s", err.Error())
 	}
 
+	// muscle group validation; it is an optional field
+	if r.MuscleGroup != "" && !slices.Contains(MuscleGroups, r.MuscleGroup) {
+		problems["muscle_group"] = fmt.Sprintf(
+			"invalid muscle_group: muscle_group must be one of %s", strings.Join(MuscleGroups, ", "))
+	}
+
+	// bodyweight ratio validation; it is an optional field
+	if r.BodyweightRatio < 0 || r.BodyweightRatio > 1 {
+		problems["bodyweight_ratio"] = "invalid bodyweight_ratio: bodyweight_ratio must be between 0 and 1"
+	}
+
 	return problems
 }
 
@@ -70,6 +95,12 @@ func HandlerCreateExercise(db *database.Queries, logger *slog.Logger) http.Handl
 		exercise, err := db.CreateExercise(r.Context(), database.CreateExerciseParams{
 			Name:        reqParams.Name,
 			Description: pgtype.Text{String: reqParams.Description, Valid: true},
+			MuscleGroup: pgtype.Text{String: reqParams.MuscleGroup, Valid: reqParams.MuscleGroup != ""},
+			BodyweightRatio: pgtype.Float8{
+				Float64: reqParams.BodyweightRatio,
+				Valid:   reqParams.BodyweightRatio != 0,
+			},
+			Leaderboard: reqParams.Leaderboard,
 		})
 		if err != nil {
 			reqLogger.Error("create exercise failed - database error", slog.String("error", err.Error()))
@@ -81,8 +112,11 @@ func HandlerCreateExercise(db *database.Queries, logger *slog.Logger) http.Handl
 		util.RespondWithJSON(w, r, http.StatusCreated, createExerciseRes{
 			ID: exercise.ID,
 			createExerciseReq: createExerciseReq{
-				Name:        exercise.Name,
-				Description: exercise.Description.String,
+				Name:            exercise.Name,
+				Description:     exercise.Description.String,
+				MuscleGroup:     exercise.MuscleGroup.String,
+				BodyweightRatio: exercise.BodyweightRatio.Float64,
+				Leaderboard:     exercise.Leaderboard,
 			},
 		})
 	}
@@ -103,6 +137,9 @@ func HandlerGetExercises(db *database.Queries, logger *slog.Logger) http.Handler
 			resParams.Exercises[i].Description = e.Description.String
 			resParams.Exercises[i].Name = e.Name
 			resParams.Exercises[i].ID = e.ID
+			resParams.Exercises[i].MuscleGroup = e.MuscleGroup.String
+			resParams.Exercises[i].BodyweightRatio = e.BodyweightRatio.Float64
+			resParams.Exercises[i].Leaderboard = e.Leaderboard
 		}
 		util.RespondWithJSON(w, r, http.StatusOK, resParams)
 	}
@@ -132,9 +169,12 @@ func HandlerGetExercise(db *database.Queries, logger *slog.Logger) http.HandlerF
 		}
 
 		util.RespondWithJSON(w, r, http.StatusOK, exerciseItem{
-			ID:          exerciseDB.ID,
-			Name:        exerciseDB.Name,
-			Description: exerciseDB.Description.String,
+			ID:              exerciseDB.ID,
+			Name:            exerciseDB.Name,
+			Description:     exerciseDB.Description.String,
+			MuscleGroup:     exerciseDB.MuscleGroup.String,
+			BodyweightRatio: exerciseDB.BodyweightRatio.Float64,
+			Leaderboard:     exerciseDB.Leaderboard,
 		})
 	}
 }
@@ -19,9 +19,11 @@ import (
 
 func TestValidateCreateExercise(t *testing.T) {
 	testCases := []struct {
-		exerciseName string
-		description  string
-		hasError     bool
+		exerciseName    string
+		description     string
+		muscleGroup     string
+		bodyweightRatio float64
+		hasError        bool
 	}{
 		{
 			exerciseName: "valid name",
@@ -44,13 +46,33 @@ func TestValidateCreateExercise(t *testing.T) {
 			description:  testutil.RandomString(apiconstants.MaxDescriptionLength + 1),
 			hasError:     true,
 		},
+		{
+			exerciseName: "squat",
+			muscleGroup:  "quads",
+		},
+		{
+			exerciseName: "squat",
+			muscleGroup:  "legs",
+			hasError:     true,
+		},
+		{
+			exerciseName:    "pull ups",
+			bodyweightRatio: 1,
+		},
+		{
+			exerciseName:    "pull ups",
+			bodyweightRatio: 1.5,
+			hasError:        true,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(fmt.Sprintf("name length %d, description length %d", len(tc.exerciseName), len(tc.description)), func(t *testing.T) {
 			req := createExerciseReq{
-				Name:        tc.exerciseName,
-				Description: tc.description,
+				Name:            tc.exerciseName,
+				Description:     tc.description,
+				MuscleGroup:     tc.muscleGroup,
+				BodyweightRatio: tc.bodyweightRatio,
 			}
 			problems := req.Valid(context.Background())
 			if tc.hasError {
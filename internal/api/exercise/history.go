@@ -0,0 +1,198 @@
+package exercise
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/CTSDM/gogym/internal/api/exlog"
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/set"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	MAX_HISTORY_LIMIT     int32 = 20
+	DEFAULT_HISTORY_LIMIT int32 = 10
+)
+
+type historySet struct {
+	set.SetRes
+	Logs []exlog.LogRes `json:"logs"`
+}
+
+type historySession struct {
+	ID   string       `json:"id"`
+	Name string       `json:"name"`
+	Date string       `json:"date"`
+	Sets []historySet `json:"sets"` // only the sets of the exercise
+}
+
+type historyRes struct {
+	ExerciseID int32            `json:"exercise_id"`
+	Sessions   []historySession `json:"sessions"`
+	Limit      int32            `json:"limit"`
+	Offset     int32            `json:"offset"`
+	Total      int              `json:"total"` // total number of sessions with the exercise
+}
+
+// HandlerGetExerciseHistory returns the sessions of the user with the exercise, newest first,
+// with the sets and logs of the exercise
+func HandlerGetExerciseHistory(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("get exercise history failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		exerciseIDString := r.PathValue("id")
+		exerciseID, err := strconv.ParseInt(exerciseIDString, 10, 32)
+		if err != nil {
+			reqLogger.Debug("invalid exercise id format", slog.String("exercise_id", exerciseIDString))
+			util.RespondWithError(w, r, http.StatusBadRequest, "invalid exercise id format", err)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()), slog.Int64("exercise_id", exerciseID))
+
+		problems := map[string]string{}
+		params := database.GetSessionsFilteredParams{
+			UserID:     userID,
+			ExerciseID: pgtype.Int4{Int32: int32(exerciseID), Valid: true},
+			Sort:       "date_desc",
+			PageLimit:  DEFAULT_HISTORY_LIMIT,
+		}
+		query := r.URL.Query()
+		if query.Has("offset") {
+			parsed, err := strconv.ParseInt(query.Get("offset"), 10, 32)
+			if err != nil {
+				problems["offset"] = "invalid offset format"
+			} else if parsed < 0 {
+				problems["offset"] = "invalid offset value, must be positive"
+			} else {
+				params.PageOffset = int32(parsed)
+			}
+		}
+		if query.Has("limit") {
+			parsed, err := strconv.ParseInt(query.Get("limit"), 10, 32)
+			if err != nil {
+				problems["limit"] = "invalid limit format"
+			} else if parsed < 0 {
+				problems["limit"] = "invalid limit value, must be positive"
+			} else if int32(parsed) > MAX_HISTORY_LIMIT {
+				problems["limit"] = fmt.Sprintf("invalid limit value, must be less than %d", MAX_HISTORY_LIMIT)
+			} else {
+				params.PageLimit = int32(parsed)
+			}
+		}
+		if len(problems) > 0 {
+			reqLogger.Debug("get exercise history failed - invalid query parameters", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		}
+
+		if _, err := db.GetExercise(r.Context(), int32(exerciseID)); err == pgx.ErrNoRows {
+			reqLogger.Debug("get exercise history failed - exercise not in database")
+			util.RespondWithError(w, r, http.StatusNotFound, "exercise id not found", err)
+			return
+		} else if err != nil {
+			reqLogger.Error("get exercise history failed - get exercise database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "get exercise history")
+		if !ok {
+			return
+		}
+
+		total, err := db.GetNumberSessionsFiltered(r.Context(), database.GetNumberSessionsFilteredParams{
+			UserID:     params.UserID,
+			ExerciseID: params.ExerciseID,
+		})
+		if err != nil {
+			reqLogger.Error("get exercise history failed - count sessions database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		sessions, err := db.GetSessionsFiltered(r.Context(), params)
+		if err != nil {
+			reqLogger.Error("get exercise history failed - get sessions database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		resParams := historyRes{
+			ExerciseID: int32(exerciseID),
+			Sessions:   make([]historySession, 0, len(sessions)),
+			Limit:      params.PageLimit,
+			Offset:     params.PageOffset,
+			Total:      int(total),
+		}
+		if len(sessions) == 0 {
+			util.RespondWithJSON(w, r, http.StatusOK, resParams)
+			return
+		}
+
+		sessionIDs := make([]uuid.UUID, len(sessions))
+		for i, s := range sessions {
+			sessionIDs[i] = s.ID
+		}
+		allSets, err := db.GetSetsBySessionIDs(r.Context(), sessionIDs)
+		if err != nil {
+			reqLogger.Error("get exercise history failed - get sets database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		// the sessions may contain other exercises
+		sets := make([]database.Set, 0, len(allSets))
+		setIDs := make([]int64, 0, len(allSets))
+		for _, s := range allSets {
+			if s.ExerciseID == int32(exerciseID) {
+				sets = append(sets, s)
+				setIDs = append(setIDs, s.ID)
+			}
+		}
+		logs, err := db.GetLogsBySetIDs(r.Context(), setIDs)
+		if err != nil {
+			reqLogger.Error("get exercise history failed - get logs database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		logsBySetID := make(map[int64][]exlog.LogRes)
+		for _, log := range logs {
+			logsBySetID[log.SetID] = append(logsBySetID[log.SetID], exlog.LogResFromDB(log, unit))
+		}
+		setsBySessionID := make(map[uuid.UUID][]historySet)
+		for _, s := range sets {
+			logs := logsBySetID[s.ID]
+			if logs == nil {
+				logs = []exlog.LogRes{}
+			}
+			setsBySessionID[s.SessionID] = append(setsBySessionID[s.SessionID], historySet{
+				SetRes: set.SetResFromDB(s),
+				Logs:   logs,
+			})
+		}
+		for _, s := range sessions {
+			resParams.Sessions = append(resParams.Sessions, historySession{
+				ID:   s.ID.String(),
+				Name: s.Name,
+				Date: s.Date.Time.Format(apiconstants.DATE_LAYOUT),
+				Sets: setsBySessionID[s.ID],
+			})
+		}
+
+		util.RespondWithJSON(w, r, http.StatusOK, resParams)
+	}
+}
@@ -0,0 +1,145 @@
+package exercise
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerGetExerciseHistory(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	otherUser := testutil.CreateUserDBTestHelper(t, db, "otheruser", "testpassword", false)
+	squatID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+	benchID := testutil.CreateExerciseDBTestHelper(t, db, "bench press")
+
+	// three sessions with squats, the newest also has bench press
+	for i, name := range []string{"legs I", "legs II", "full body"} {
+		session, err := db.CreateSession(context.Background(), database.CreateSessionParams{
+			Name:   name,
+			Date:   pgtype.Date{Time: time.Now().AddDate(0, 0, i-3), Valid: true},
+			UserID: user.ID,
+		})
+		require.NoError(t, err)
+		setID := testutil.CreateSetDBTestHelper(t, db, session.ID, squatID)
+		testutil.CreateLogExerciseDBTestHelper(t, db, 5, 1, squatID, setID, 100+float64(i)*5)
+		testutil.CreateLogExerciseDBTestHelper(t, db, 5, 2, squatID, setID, 100+float64(i)*5)
+		if name == "full body" {
+			benchSetID := testutil.CreateSetDBTestHelper(t, db, session.ID, benchID)
+			testutil.CreateLogExerciseDBTestHelper(t, db, 8, 1, benchID, benchSetID, 80)
+		}
+	}
+	otherSessionID := testutil.CreateSessionDBTestHelper(t, db, "legs", otherUser.ID)
+	testutil.CreateSetDBTestHelper(t, db, otherSessionID, squatID)
+
+	testCases := []struct {
+		name             string
+		exerciseID       string
+		query            string
+		statusCode       int
+		errMsg           []string
+		expectedSessions []string
+		expectedWeight   float64 // of the first log of the first session
+		expectedTotal    int
+	}{
+		{
+			name:             "happy path: newest first with the sets of the exercise only",
+			exerciseID:       strconv.Itoa(int(squatID)),
+			statusCode:       http.StatusOK,
+			expectedSessions: []string{"full body", "legs II", "legs I"},
+			expectedWeight:   110,
+			expectedTotal:    3,
+		},
+		{
+			name:             "happy path: pagination and units",
+			exerciseID:       strconv.Itoa(int(squatID)),
+			query:            "?limit=1&offset=1&units=lb",
+			statusCode:       http.StatusOK,
+			expectedSessions: []string{"legs II"},
+			expectedWeight:   231.485,
+			expectedTotal:    3,
+		},
+		{
+			name:             "happy path: exercise in a single session",
+			exerciseID:       strconv.Itoa(int(benchID)),
+			statusCode:       http.StatusOK,
+			expectedSessions: []string{"full body"},
+			expectedWeight:   80,
+			expectedTotal:    1,
+		},
+		{
+			name:       "invalid exercise id",
+			exerciseID: "abc",
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid exercise id format"},
+		},
+		{
+			name:       "exercise not found",
+			exerciseID: strconv.Itoa(int(squatID) + 1000),
+			statusCode: http.StatusNotFound,
+			errMsg:     []string{"exercise id not found"},
+		},
+		{
+			name:       "limit too large",
+			exerciseID: strconv.Itoa(int(squatID)),
+			query:      "?limit=100",
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid limit value"},
+		},
+		{
+			name:       "invalid units",
+			exerciseID: strconv.Itoa(int(squatID)),
+			query:      "?units=stone",
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid units"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/test"+tc.query, nil)
+			require.NoError(t, err, "unexpected error while creating the request")
+			req.SetPathValue("id", tc.exerciseID)
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			rr := httptest.NewRecorder()
+
+			handler := HandlerGetExerciseHistory(db, logger)
+			middleware.RequestID(handler).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				for _, msg := range tc.errMsg {
+					assert.Contains(t, rr.Body.String(), msg)
+				}
+				return
+			}
+
+			var resParams historyRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			assert.Equal(t, tc.expectedTotal, resParams.Total)
+			require.Len(t, resParams.Sessions, len(tc.expectedSessions))
+			for i, s := range resParams.Sessions {
+				assert.Equal(t, tc.expectedSessions[i], s.Name)
+				require.Len(t, s.Sets, 1)
+				assert.Equal(t, resParams.ExerciseID, s.Sets[0].ExerciseID)
+			}
+			require.NotEmpty(t, resParams.Sessions[0].Sets[0].Logs)
+			assert.Equal(t, tc.expectedWeight, resParams.Sessions[0].Sets[0].Logs[0].Weight)
+		})
+	}
+}
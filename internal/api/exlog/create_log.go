@@ -3,32 +3,55 @@ package exlog
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/record"
+	"github.com/CTSDM/gogym/internal/api/units"
 	"github.com/CTSDM/gogym/internal/api/util"
 	"github.com/CTSDM/gogym/internal/api/validation"
+	"github.com/CTSDM/gogym/internal/apiconstants"
 	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type LogReq struct {
-	ExerciseID int32   `json:"exercise_id"`
-	Weight     float64 `json:"weight"`
-	Reps       int32   `json:"reps"`
-	Order      int32   `json:"order"`
+	ExerciseID     int32   `json:"exercise_id"` // optional on creation, logs take the exercise of their set
+	Weight         float64 `json:"weight"`
+	Reps           int32   `json:"reps"`
+	Order          int32   `json:"order"`
+	RPE            float64 `json:"rpe,omitempty"` // 1 to 10 in steps of 0.5
+	RIR            *int32  `json:"rir,omitempty"` // reps in reserve, zero is a meaningful value
+	Tempo          string  `json:"tempo,omitempty"`
+	ReachedFailure bool    `json:"reached_failure"`
+	PartialReps    int32   `json:"partial_reps"`
+	Notes          string  `json:"notes,omitempty"`
+	Unit           string  `json:"unit"` // kg or lb, defaults to the preferred unit of the user
 }
 
 type LogRes struct {
-	ID    int64 `json:"id"`
-	SetID int64 `json:"set_id"`
+	ID          int64  `json:"id"`
+	SetID       int64  `json:"set_id"`
+	EnteredUnit string `json:"entered_unit"` // unit the weight was logged in
 	LogReq
 }
 
+// Responses of created and updated logs carry the personal records the log set
+type logRecordsRes struct {
+	LogRes
+	NewRecords []record.RecordRes `json:"new_records"`
+}
+
 func (r *LogReq) Valid(ctx context.Context) map[string]string {
 	problems := make(map[string]string)
 	// weight validation
@@ -37,6 +60,11 @@ func (r *LogReq) Valid(ctx context.Context) map[string]string {
 		r.Weight = 0
 	}
 
+	// unit validation, it is an optional parameter
+	if r.Unit != "" && !units.Valid(r.Unit) {
+		problems["unit"] = "invalid unit: " + units.ErrInvalidUnit.Error()
+	}
+
 	// order validation
 	if r.Order < 0 {
 		problems["order"] = "invalid order: log order must be positive"
@@ -47,19 +75,104 @@ func (r *LogReq) Valid(ctx context.Context) map[string]string {
 		problems["reps"] = "invalid reps: reps must be positive"
 	}
 
+	// rpe validation, zero means the value was not provided
+	if r.RPE != 0 {
+		if r.RPE < apiconstants.MinRPE || r.RPE > apiconstants.MaxRPE || math.Mod(r.RPE*2, 1) != 0 {
+			problems["rpe"] = fmt.Sprintf(
+				"invalid rpe: rpe must be between %d and %d in steps of 0.5",
+				apiconstants.MinRPE, apiconstants.MaxRPE)
+		}
+	}
+
+	// reps in reserve validation
+	if r.RIR != nil {
+		if *r.RIR < 0 || *r.RIR > apiconstants.MaxRIR {
+			problems["rir"] = fmt.Sprintf("invalid rir: rir must be between 0 and %d", apiconstants.MaxRIR)
+		} else if r.ReachedFailure && *r.RIR != 0 {
+			problems["rir"] = "invalid rir: a set taken to failure has no reps in reserve"
+		}
+	}
+
+	// tempo validation, stored uppercase so explosive phases are always "X"
+	if r.Tempo != "" {
+		r.Tempo = strings.ToUpper(r.Tempo)
+		if !tempoRegex.MatchString(r.Tempo) {
+			problems["tempo"] = `invalid tempo: tempo must have four phases separated by "-", e.g. "3-1-1-0" or "2-0-X-0"`
+		}
+	}
+
+	// partial reps validation
+	if r.PartialReps < 0 || r.PartialReps > math.MaxInt16 {
+		problems["partial_reps"] = "invalid partial_reps: partial_reps must be positive"
+	}
+
+	// notes validation
+	if err := validation.String(r.Notes, 0, apiconstants.MaxNotesLength); err != nil {
+		problems["notes"] = "invalid notes: " + err.Error()
+	}
+
 	return problems
 }
 
-func HandlerCreateLog(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+// eccentric, bottom pause, concentric and top pause in seconds, X stands for explosive
+var tempoRegex = regexp.MustCompile(`^([0-9]{1,2}|X)(-([0-9]{1,2}|X)){3}$`)
+
+// LogResFromDB builds the response of a log with its weight converted into unit
+func LogResFromDB(log database.Log, unit string) LogRes {
+	res := LogRes{
+		ID:          log.ID,
+		SetID:       log.SetID,
+		EnteredUnit: log.WeightUnit,
+		LogReq: LogReq{
+			ExerciseID:     log.ExerciseID,
+			Weight:         units.FromKG(log.Weight.Float64, unit),
+			Unit:           unit,
+			Reps:           log.Reps,
+			Order:          log.LogsOrder,
+			RPE:            log.Rpe.Float64,
+			Tempo:          log.Tempo.String,
+			ReachedFailure: log.ReachedFailure,
+			PartialReps:    int32(log.PartialReps),
+			Notes:          log.Notes.String,
+		},
+	}
+	if log.Rir.Valid {
+		rir := int32(log.Rir.Int16)
+		res.RIR = &rir
+	}
+	return res
+}
+
+func (r *LogReq) rir() pgtype.Int2 {
+	if r.RIR == nil {
+		return pgtype.Int2{}
+	}
+	return pgtype.Int2{Int16: int16(*r.RIR), Valid: true}
+}
+
+func HandlerCreateLog(pool *pgxpool.Pool, db *database.Queries, logger *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		reqLogger := middleware.BasicReqLogger(logger, r)
-		// set id must be a valid number
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("create log failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		// session id must be a valid uuid and set id a valid number
+		sessionID, err := uuid.Parse(r.PathValue("sessionID"))
+		if err != nil {
+			util.RespondWithError(w, r, http.StatusNotFound, "session ID not found", err)
+			return
+		}
 		setID, err := strconv.ParseInt(r.PathValue("setID"), 10, 64)
 		if err != nil {
 			util.RespondWithError(w, r, http.StatusNotFound, "set ID not found", err)
 			return
 		}
-		reqLogger = reqLogger.With(slog.Int64("set_id", setID))
+		reqLogger = reqLogger.With(slog.String("session_id", sessionID.String()), slog.Int64("set_id", setID))
 
 		reqParams, problems, err := validation.DecodeValid[*LogReq](r)
 		if len(problems) > 0 {
@@ -72,17 +185,77 @@ func HandlerCreateLog(db *database.Queries, logger *slog.Logger) http.HandlerFun
 			return
 		}
 
+		// The set must belong to the session in the path and the session to the user.
+		// Sets from other users are reported as not found to not disclose their existence.
+		set, err := db.GetSetBySessionAndUser(r.Context(), database.GetSetBySessionAndUserParams{
+			ID:        setID,
+			SessionID: sessionID,
+			UserID:    userID,
+		})
+		if err == pgx.ErrNoRows {
+			reqLogger.Debug("create log failed - set not found in the session")
+			util.RespondWithError(w, r, http.StatusNotFound, "set ID not found", err)
+			return
+		} else if err != nil {
+			reqLogger.Error("create log failed - get set database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		// The exercise is derived from the set, a different one is rejected
+		if reqParams.ExerciseID == 0 {
+			reqParams.ExerciseID = set.ExerciseID
+		} else if reqParams.ExerciseID != set.ExerciseID {
+			reqLogger.Debug("create log failed - exercise mismatch",
+				slog.Int64("exercise_id", int64(reqParams.ExerciseID)),
+				slog.Int64("set_exercise_id", int64(set.ExerciseID)))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, map[string]string{
+				"exercise_id": "invalid exercise_id: exercise_id must match the exercise of the set",
+			})
+			return
+		}
+
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "create log")
+		if !ok {
+			return
+		}
+		enteredUnit := reqParams.Unit
+		if enteredUnit == "" {
+			enteredUnit = unit
+		}
+
 		// Record the log into the database
 		dbParams := database.CreateLogParams{
-			Weight:     pgtype.Float8{Float64: reqParams.Weight, Valid: true},
-			Reps:       reqParams.Reps,
-			LogsOrder:  reqParams.Order,
-			SetID:      setID,
-			ExerciseID: reqParams.ExerciseID,
+			Weight:         pgtype.Float8{Float64: units.ToKG(reqParams.Weight, enteredUnit), Valid: true},
+			Reps:           reqParams.Reps,
+			LogsOrder:      reqParams.Order,
+			SetID:          setID,
+			ExerciseID:     reqParams.ExerciseID,
+			Rpe:            pgtype.Float8{Float64: reqParams.RPE, Valid: reqParams.RPE != 0},
+			Rir:            reqParams.rir(),
+			Tempo:          pgtype.Text{String: reqParams.Tempo, Valid: reqParams.Tempo != ""},
+			ReachedFailure: reqParams.ReachedFailure,
+			PartialReps:    int16(reqParams.PartialReps),
+			Notes:          pgtype.Text{String: reqParams.Notes, Valid: reqParams.Notes != ""},
+			WeightUnit:     enteredUnit,
+		}
+		tx, err := pool.Begin(r.Context())
+		if err != nil {
+			reqLogger.Error("create log failed - transaction start error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
 		}
-		newLog, err := db.CreateLog(r.Context(), dbParams)
+		defer tx.Rollback(r.Context())
+		txQueries := db.WithTx(tx)
+
+		newLog, err := txQueries.CreateLog(r.Context(), dbParams)
 		if err != nil {
 			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				reqLogger.Debug("create log failed - log order already in use", slog.Int64("logs_order", int64(reqParams.Order)))
+				util.RespondWithError(w, r, http.StatusConflict, "order already in use in the set", err)
+				return
+			}
 			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
 				if strings.Contains(err.Error(), "set") {
 					reqLogger.Warn("create log failed - set not found")
@@ -103,16 +276,32 @@ func HandlerCreateLog(db *database.Queries, logger *slog.Logger) http.HandlerFun
 			return
 		}
 
-		reqLogger.Info("create log success", slog.Int64("log_id", newLog.ID))
-		util.RespondWithJSON(w, r, http.StatusCreated, LogRes{
-			ID:    newLog.ID,
-			SetID: setID,
-			LogReq: LogReq{
-				ExerciseID: newLog.ExerciseID,
-				Weight:     newLog.Weight.Float64,
-				Reps:       newLog.Reps,
-				Order:      newLog.LogsOrder,
-			},
-		})
+		records, err := record.Detect(r.Context(), txQueries, userID, newLog)
+		if err != nil {
+			reqLogger.Error("create log failed - detect records database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		if err := tx.Commit(r.Context()); err != nil {
+			reqLogger.Error("create log failed - transaction commit error", slog.String("error", err.Error()))
+			err = fmt.Errorf("could not commit the transaction: %w", err)
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		reqLogger.Info("create log success", slog.Int64("log_id", newLog.ID), slog.Int("new_records", len(records)))
+		util.RespondWithJSON(w, r, http.StatusCreated, logRecordsResFromDB(newLog, records, unit))
+	}
+}
+
+func logRecordsResFromDB(log database.Log, records []database.PersonalRecord, unit string) logRecordsRes {
+	res := logRecordsRes{
+		LogRes:     LogResFromDB(log, unit),
+		NewRecords: make([]record.RecordRes, len(records)),
+	}
+	for i, rec := range records {
+		res.NewRecords[i] = record.RecordResFromDB(rec, pgtype.Date{}, unit)
 	}
+	return res
 }
@@ -10,7 +10,10 @@ import (
 	"testing"
 
 	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/record"
 	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
 	"github.com/CTSDM/gogym/internal/database"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -29,6 +32,9 @@ func TestHandlerCreateLog(t *testing.T) {
 		reps         int32
 		order        int32
 		invalidSetID bool
+		omitExercise bool
+		otherSession bool
+		otherUser    bool
 	}{
 		{
 			name:       "happy path",
@@ -107,14 +113,43 @@ func TestHandlerCreateLog(t *testing.T) {
 			invalidSetID: true,
 		},
 		{
-			name:       "exercise id not found",
-			statusCode: http.StatusNotFound,
+			name:       "exercise id does not match the set",
+			statusCode: http.StatusBadRequest,
 			hasJSON:    true,
 			weight:     100,
 			reps:       10,
 			order:      1,
 			exerciseID: 99999,
-			errMsg:     []string{"not found"},
+			errMsg:     []string{"invalid exercise_id"},
+		},
+		{
+			name:         "exercise id is derived from the set",
+			statusCode:   http.StatusCreated,
+			hasJSON:      true,
+			weight:       100,
+			reps:         10,
+			order:        1,
+			omitExercise: true,
+		},
+		{
+			name:         "set does not belong to the session",
+			statusCode:   http.StatusNotFound,
+			hasJSON:      true,
+			weight:       100,
+			reps:         10,
+			order:        1,
+			otherSession: true,
+			errMsg:       []string{"set ID not found"},
+		},
+		{
+			name:       "set belongs to another user",
+			statusCode: http.StatusNotFound,
+			hasJSON:    true,
+			weight:     100,
+			reps:       10,
+			order:      1,
+			otherUser:  true,
+			errMsg:     []string{"set ID not found"},
 		},
 	}
 
@@ -126,9 +161,12 @@ func TestHandlerCreateLog(t *testing.T) {
 	sessionID := testutil.CreateSessionDBTestHelper(t, db, "test session", user.ID)
 	exerciseID := testutil.CreateExerciseDBTestHelper(t, db, "pull ups")
 	setID := testutil.CreateSetDBTestHelper(t, db, sessionID, exerciseID)
+	otherSessionID := testutil.CreateSessionDBTestHelper(t, db, "other session", user.ID)
+	otherUser := testutil.CreateUserDBTestHelper(t, db, "otheruser", "passwordtest", false)
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, testutil.Cleanup(dbPool, "logs"))
 			reader := &bytes.Reader{}
 			if tc.hasEmptyJSON {
 				reader = bytes.NewReader([]byte("{}"))
@@ -141,6 +179,8 @@ func TestHandlerCreateLog(t *testing.T) {
 				}
 				if tc.exerciseID != 0 {
 					reqParams.ExerciseID = tc.exerciseID
+				} else if tc.omitExercise {
+					reqParams.ExerciseID = 0
 				}
 				body, err := json.Marshal(reqParams)
 				require.NoError(t, err, "unexpected JSON marshal error")
@@ -150,7 +190,16 @@ func TestHandlerCreateLog(t *testing.T) {
 			req, err := http.NewRequest("POST", "/test", reader)
 			require.NoError(t, err, "unexpected error while creating the request")
 
-			// set up the path value
+			// set up the user and the path values
+			if tc.otherUser {
+				req = req.WithContext(util.ContextWithUser(req.Context(), otherUser.ID))
+			} else {
+				req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			}
+			req.SetPathValue("sessionID", sessionID.String())
+			if tc.otherSession {
+				req.SetPathValue("sessionID", otherSessionID.String())
+			}
 			req.SetPathValue("setID", strconv.FormatInt(setID, 10))
 			if tc.invalidSetID {
 				req.SetPathValue("setID", "not an int")
@@ -160,7 +209,7 @@ func TestHandlerCreateLog(t *testing.T) {
 
 			rr := httptest.NewRecorder()
 
-			handler := HandlerCreateLog(db, logger)
+			handler := HandlerCreateLog(dbPool, db, logger)
 			middleware.RequestID(handler).ServeHTTP(rr, req)
 			if tc.statusCode != rr.Code {
 				t.Logf("Status code do not match, want %d, got %d", tc.statusCode, rr.Code)
@@ -191,6 +240,169 @@ func TestHandlerCreateLog(t *testing.T) {
 	}
 }
 
+func TestHandlerCreateLogUnits(t *testing.T) {
+	testCases := []struct {
+		name          string
+		unit          string
+		query         string
+		weight        float64
+		statusCode    int
+		storedKG      float64
+		expected      float64
+		expectedUnit  string
+		expectedEntry string
+	}{
+		{
+			name:          "kilograms by default",
+			weight:        100,
+			statusCode:    http.StatusCreated,
+			storedKG:      100,
+			expected:      100,
+			expectedUnit:  units.KG,
+			expectedEntry: units.KG,
+		},
+		{
+			name:          "pounds are stored as kilograms",
+			unit:          units.LB,
+			weight:        225,
+			statusCode:    http.StatusCreated,
+			storedKG:      225 * 0.45359237,
+			expected:      102.058,
+			expectedUnit:  units.KG,
+			expectedEntry: units.LB,
+		},
+		{
+			name:          "pounds are returned unchanged with the units override",
+			unit:          units.LB,
+			query:         "?units=lb",
+			weight:        137.5,
+			statusCode:    http.StatusCreated,
+			storedKG:      137.5 * 0.45359237,
+			expected:      137.5,
+			expectedUnit:  units.LB,
+			expectedEntry: units.LB,
+		},
+		{
+			name:       "invalid units override",
+			query:      "?units=stone",
+			weight:     100,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid unit",
+			unit:       "stone",
+			weight:     100,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "usertest", "passwordtest", false)
+	sessionID := testutil.CreateSessionDBTestHelper(t, db, "test session", user.ID)
+	exerciseID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+	setID := testutil.CreateSetDBTestHelper(t, db, sessionID, exerciseID)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, testutil.Cleanup(dbPool, "logs"))
+			body, err := json.Marshal(LogReq{Weight: tc.weight, Reps: 5, Order: 1, Unit: tc.unit})
+			require.NoError(t, err)
+			req, err := http.NewRequest("POST", "/test"+tc.query, bytes.NewReader(body))
+			require.NoError(t, err)
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			req.SetPathValue("sessionID", sessionID.String())
+			req.SetPathValue("setID", strconv.FormatInt(setID, 10))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerCreateLog(dbPool, db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				assert.Contains(t, rr.Body.String(), "invalid unit")
+				return
+			}
+
+			var resParams LogRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			assert.Equal(t, tc.expected, resParams.Weight)
+			assert.Equal(t, tc.expectedUnit, resParams.Unit)
+			assert.Equal(t, tc.expectedEntry, resParams.EnteredUnit)
+
+			logDB, err := db.GetLog(context.Background(), resParams.ID)
+			require.NoError(t, err)
+			assert.InDelta(t, tc.storedKG, logDB.Weight.Float64, 1e-9)
+			assert.Equal(t, tc.expectedEntry, logDB.WeightUnit)
+		})
+	}
+}
+
+func TestHandlerCreateLogRecords(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "usertest", "passwordtest", false)
+	sessionID := testutil.CreateSessionDBTestHelper(t, db, "test session", user.ID)
+	exerciseID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+	setID := testutil.CreateSetDBTestHelper(t, db, sessionID, exerciseID)
+
+	// the logs are created in order, each one is compared against the previous ones
+	testCases := []struct {
+		name            string
+		weight          float64
+		reps            int32
+		expectedRecords []string
+	}{
+		{
+			name:   "first log sets every record",
+			weight: 100,
+			reps:   5,
+			expectedRecords: []string{
+				record.TypeMaxWeight, record.TypeMaxReps, record.TypeE1RM, record.TypeSessionVolume,
+			},
+		},
+		{
+			name:            "fewer reps only increase the session volume",
+			weight:          100,
+			reps:            3,
+			expectedRecords: []string{record.TypeSessionVolume},
+		},
+		{
+			name:            "heavier single sets the weight and its reps",
+			weight:          110,
+			reps:            1,
+			expectedRecords: []string{record.TypeMaxWeight, record.TypeMaxReps, record.TypeSessionVolume},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := json.Marshal(LogReq{Weight: tc.weight, Reps: tc.reps, Order: int32(i + 1)})
+			require.NoError(t, err)
+			req, err := http.NewRequest("POST", "/test", bytes.NewReader(body))
+			require.NoError(t, err)
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			req.SetPathValue("sessionID", sessionID.String())
+			req.SetPathValue("setID", strconv.FormatInt(setID, 10))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerCreateLog(dbPool, db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+			var resParams logRecordsRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			types := make([]string, len(resParams.NewRecords))
+			for j, r := range resParams.NewRecords {
+				types[j] = r.Type
+				assert.Equal(t, resParams.ID, r.LogID)
+			}
+			assert.Equal(t, tc.expectedRecords, types)
+		})
+	}
+}
+
 func TestValidateCreateLog(t *testing.T) {
 	testCases := []struct {
 		name      string
@@ -281,3 +493,89 @@ func TestValidateCreateLog(t *testing.T) {
 		})
 	}
 }
+
+func TestLogReqValid(t *testing.T) {
+	zero := int32(0)
+	two := int32(2)
+	tooMany := int32(11)
+	testCases := []struct {
+		name          string
+		reqParams     LogReq
+		expectedTempo string
+		errKeys       []string
+	}{
+		{
+			name:      "only required fields",
+			reqParams: LogReq{Reps: 5},
+		},
+		{
+			name: "all details",
+			reqParams: LogReq{
+				Reps:        5,
+				RPE:         8.5,
+				RIR:         &two,
+				Tempo:       "3-1-x-0",
+				PartialReps: 2,
+				Notes:       "belt on",
+			},
+			expectedTempo: "3-1-X-0",
+		},
+		{
+			name:      "failure with zero reps in reserve",
+			reqParams: LogReq{Reps: 5, RIR: &zero, ReachedFailure: true},
+		},
+		{
+			name:      "rpe below range",
+			reqParams: LogReq{Reps: 5, RPE: 0.5},
+			errKeys:   []string{"rpe"},
+		},
+		{
+			name:      "rpe above range",
+			reqParams: LogReq{Reps: 5, RPE: 10.5},
+			errKeys:   []string{"rpe"},
+		},
+		{
+			name:      "rpe not in half steps",
+			reqParams: LogReq{Reps: 5, RPE: 7.3},
+			errKeys:   []string{"rpe"},
+		},
+		{
+			name:      "rir out of range",
+			reqParams: LogReq{Reps: 5, RIR: &tooMany},
+			errKeys:   []string{"rir"},
+		},
+		{
+			name:      "failure with reps in reserve",
+			reqParams: LogReq{Reps: 5, RIR: &two, ReachedFailure: true},
+			errKeys:   []string{"rir"},
+		},
+		{
+			name:      "tempo with three phases",
+			reqParams: LogReq{Reps: 5, Tempo: "3-1-1"},
+			errKeys:   []string{"tempo"},
+		},
+		{
+			name:      "tempo with letters",
+			reqParams: LogReq{Reps: 5, Tempo: "a-b-c-d"},
+			errKeys:   []string{"tempo"},
+		},
+		{
+			name:      "negative partial reps",
+			reqParams: LogReq{Reps: 5, PartialReps: -1},
+			errKeys:   []string{"partial_reps"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			problems := tc.reqParams.Valid(context.Background())
+			assert.Len(t, problems, len(tc.errKeys), "problems: %v", problems)
+			for _, key := range tc.errKeys {
+				assert.Contains(t, problems, key)
+			}
+			if tc.expectedTempo != "" {
+				assert.Equal(t, tc.expectedTempo, tc.reqParams.Tempo)
+			}
+		})
+	}
+}
@@ -8,9 +8,13 @@ import (
 	"strconv"
 
 	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/pagination"
+	"github.com/CTSDM/gogym/internal/api/units"
 	"github.com/CTSDM/gogym/internal/api/util"
 	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/auth"
 	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const (
@@ -19,19 +23,21 @@ const (
 	MAX_LIMIT      int32 = 200
 )
 
-func HandlerGetLogs(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+func HandlerGetLogs(db *database.Queries, authConfig *auth.Config, logger *slog.Logger) http.HandlerFunc {
 	type logItem struct {
-		Log  LogRes
+		Log  LogRes `json:"log"`
 		Date string `json:"date"`
 	}
 	type res struct {
-		Logs []logItem
+		Logs       []logItem `json:"logs"`
+		NextCursor string    `json:"next_cursor,omitempty"`
 	}
 
-	validateQueryParams := func(r *http.Request) (int32, int32, map[string]string) {
+	validateQueryParams := func(r *http.Request) (database.GetLogsByUserIDParams, map[string]string) {
 		problems := map[string]string{}
 		var offset int32
 		var limit int32
+		params := database.GetLogsByUserIDParams{}
 
 		// validate and offset
 		if r.URL.Query().Has("offset") {
@@ -63,7 +69,23 @@ func HandlerGetLogs(db *database.Queries, logger *slog.Logger) http.HandlerFunc
 			limit = DEFAULT_LIMIT
 		}
 
-		return offset, limit, problems
+		// validate the cursor
+		if r.URL.Query().Has("cursor") {
+			cursor, err := pagination.Decode(r.URL.Query().Get("cursor"), authConfig.JWTsecret)
+			cursorID, errID := strconv.ParseInt(cursor.ID, 10, 64)
+			if err != nil || errID != nil {
+				problems["cursor"] = "invalid cursor"
+			} else if r.URL.Query().Has("offset") {
+				problems["cursor"] = "invalid cursor: cursor and offset cannot be used together"
+			} else {
+				params.CursorDate = pgtype.Date{Time: cursor.Date, Valid: true}
+				params.CursorID = pgtype.Int8{Int64: cursorID, Valid: true}
+			}
+		}
+
+		params.PageOffset = offset
+		params.PageLimit = limit
+		return params, problems
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -78,40 +100,71 @@ func HandlerGetLogs(db *database.Queries, logger *slog.Logger) http.HandlerFunc
 		}
 		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
 
-		offset, limit, problems := validateQueryParams(r)
+		dbParams, problems := validateQueryParams(r)
 		if len(problems) > 0 {
 			reqLogger.Debug("get logs failed - validation failed", slog.Any("problems", problems))
 			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
 			return
 		}
 
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "get logs")
+		if !ok {
+			return
+		}
+
 		// Get logs from the database
-		rows, err := db.GetLogsByUserID(r.Context(), database.GetLogsByUserIDParams{
-			UserID: userID,
-			Offset: offset,
-			Limit:  limit,
-		})
+		// one extra row is requested to know whether there is a next page
+		limit := dbParams.PageLimit
+		dbParams.UserID = userID
+		dbParams.PageLimit = limit + 1
+		rows, err := db.GetLogsByUserID(r.Context(), dbParams)
 		if err != nil {
 			reqLogger.Error("get logs failed - database error", slog.String("error", err.Error()))
 			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
 			return
 		}
 
+		// generate the cursor pointing after the last log of the page
+		var nextCursor string
+		if len(rows) > int(limit) {
+			rows = rows[:limit]
+			if limit > 0 {
+				last := rows[len(rows)-1]
+				nextCursor, err = pagination.Encode(pagination.Cursor{
+					Date: last.Date.Time,
+					ID:   strconv.FormatInt(last.ID, 10),
+				}, authConfig.JWTsecret)
+				if err != nil {
+					reqLogger.Error("get logs failed - cursor encoding error", slog.String("error", err.Error()))
+					util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+					return
+				}
+				w.Header().Set("Link", pagination.NextLink(r, nextCursor))
+			}
+		}
+
 		// build the response
-		resParams := res{Logs: make([]logItem, len(rows))}
+		resParams := res{Logs: make([]logItem, len(rows)), NextCursor: nextCursor}
 		for i, row := range rows {
 			resParams.Logs[i] = logItem{
 				Date: row.Date.Time.Format(apiconstants.DATE_LAYOUT),
-				Log: LogRes{
-					ID:    row.ID,
-					SetID: row.SetID,
-					LogReq: LogReq{
-						ExerciseID: row.ExerciseID,
-						Weight:     row.Weight.Float64,
-						Reps:       row.Reps,
-						Order:      row.LogsOrder,
-					},
-				},
+				Log: LogResFromDB(database.Log{
+					ID:             row.ID,
+					CreatedAt:      row.CreatedAt,
+					LastModifiedAt: row.LastModifiedAt,
+					Weight:         row.Weight,
+					Reps:           row.Reps,
+					LogsOrder:      row.LogsOrder,
+					ExerciseID:     row.ExerciseID,
+					SetID:          row.SetID,
+					Rpe:            row.Rpe,
+					Rir:            row.Rir,
+					Tempo:          row.Tempo,
+					ReachedFailure: row.ReachedFailure,
+					PartialReps:    row.PartialReps,
+					Notes:          row.Notes,
+					WeightUnit:     row.WeightUnit,
+				}, unit),
 			}
 		}
 
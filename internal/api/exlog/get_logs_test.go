@@ -0,0 +1,106 @@
+package exlog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/auth"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerGetLogs(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	db := database.New(dbPool)
+	authConfig := &auth.Config{JWTsecret: "testSecret"}
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+
+	const numLogs = 5
+	sessionID := testutil.CreateSessionDBTestHelper(t, db, "test session", user.ID)
+	exerciseID := testutil.CreateExerciseDBTestHelper(t, db, "pull ups")
+	setID := testutil.CreateSetDBTestHelper(t, db, sessionID, exerciseID)
+	for i := range numLogs {
+		testutil.CreateLogExerciseDBTestHelper(t, db, 10, int32(i), exerciseID, setID, 100)
+	}
+
+	type res struct {
+		Logs []struct {
+			Log struct {
+				ID int64 `json:"id"`
+			} `json:"log"`
+		} `json:"logs"`
+		NextCursor string `json:"next_cursor"`
+	}
+
+	doRequest := func(t *testing.T, query string) (*httptest.ResponseRecorder, res) {
+		req, err := http.NewRequest("GET", "/api/v1/logs/?"+query, nil)
+		require.NoError(t, err, "unexpected error while creating the request")
+		req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+		rr := httptest.NewRecorder()
+		middleware.RequestID(HandlerGetLogs(db, authConfig, logger)).ServeHTTP(rr, req)
+
+		var resParams res
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resParams))
+		}
+		return rr, resParams
+	}
+
+	t.Run("walk all pages with the cursor", func(t *testing.T) {
+		ids := []int64{}
+		query := "limit=2"
+		for {
+			rr, resParams := doRequest(t, query)
+			require.Equal(t, http.StatusOK, rr.Code)
+			for _, l := range resParams.Logs {
+				ids = append(ids, l.Log.ID)
+			}
+			if resParams.NextCursor == "" {
+				assert.Empty(t, rr.Header().Get("Link"))
+				break
+			}
+			assert.Contains(t, rr.Header().Get("Link"), `rel="next"`)
+			query = "limit=2&cursor=" + url.QueryEscape(resParams.NextCursor)
+		}
+		require.Len(t, ids, numLogs)
+		// logs of the same date are ordered by id descending
+		for i := 1; i < len(ids); i++ {
+			assert.Greater(t, ids[i-1], ids[i])
+		}
+	})
+
+	t.Run("offset pagination still works", func(t *testing.T) {
+		rr, resParams := doRequest(t, "offset=3&limit=10")
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Len(t, resParams.Logs, numLogs-3)
+		assert.Empty(t, resParams.NextCursor)
+		// keys are snake case like every other response
+		assert.Contains(t, rr.Body.String(), `"logs":[{"log":{`)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		rr, _ := doRequest(t, "cursor=abc.def")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "invalid cursor")
+	})
+
+	t.Run("cursor signed with another secret", func(t *testing.T) {
+		_, first := doRequest(t, "limit=1")
+		require.NotEmpty(t, first.NextCursor)
+		otherConfig := &auth.Config{JWTsecret: "anotherSecret"}
+		req, err := http.NewRequest("GET", "/api/v1/logs/?cursor="+url.QueryEscape(first.NextCursor), nil)
+		require.NoError(t, err)
+		req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+		rr := httptest.NewRecorder()
+		middleware.RequestID(HandlerGetLogs(db, otherConfig, logger)).ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
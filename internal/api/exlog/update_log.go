@@ -1,22 +1,34 @@
 package exlog
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 
 	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/record"
+	"github.com/CTSDM/gogym/internal/api/units"
 	"github.com/CTSDM/gogym/internal/api/util"
 	"github.com/CTSDM/gogym/internal/api/validation"
 	"github.com/CTSDM/gogym/internal/database"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func HandlerUpdateLog(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+func HandlerUpdateLog(pool *pgxpool.Pool, db *database.Queries, logger *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("update log failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
 		logID, _ := retrieveParseIDFromContext(r.Context())
-		reqLogger = reqLogger.With(slog.Int64("log_id", logID))
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()), slog.Int64("log_id", logID))
 		// decode and validate
 		reqParams, problems, err := validation.DecodeValid[*LogReq](r)
 		if len(problems) > 0 {
@@ -29,19 +41,69 @@ func HandlerUpdateLog(db *database.Queries, logger *slog.Logger) http.HandlerFun
 			return
 		}
 
+		// Logs keep the exercise of their set, a different one is rejected
+		if reqParams.ExerciseID != 0 {
+			currentLog, err := db.GetLog(r.Context(), logID)
+			if err != nil {
+				reqLogger.Error("update log failed - get log database error", slog.String("error", err.Error()))
+				util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+				return
+			}
+			if reqParams.ExerciseID != currentLog.ExerciseID {
+				reqLogger.Debug("update log failed - exercise mismatch",
+					slog.Int64("exercise_id", int64(reqParams.ExerciseID)),
+					slog.Int64("set_exercise_id", int64(currentLog.ExerciseID)))
+				util.RespondWithJSON(w, r, http.StatusBadRequest, map[string]string{
+					"exercise_id": "invalid exercise_id: exercise_id must match the exercise of the set",
+				})
+				return
+			}
+		}
+
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "update log")
+		if !ok {
+			return
+		}
+		enteredUnit := reqParams.Unit
+		if enteredUnit == "" {
+			enteredUnit = unit
+		}
+
 		// Update the entry
 		dbParams := database.UpdateLogParams{
-			Weight:    pgtype.Float8{Float64: reqParams.Weight, Valid: true},
-			Reps:      reqParams.Reps,
-			LogsOrder: reqParams.Order,
-			ID:        logID,
+			Weight:         pgtype.Float8{Float64: units.ToKG(reqParams.Weight, enteredUnit), Valid: true},
+			Reps:           reqParams.Reps,
+			LogsOrder:      reqParams.Order,
+			Rpe:            pgtype.Float8{Float64: reqParams.RPE, Valid: reqParams.RPE != 0},
+			Rir:            reqParams.rir(),
+			Tempo:          pgtype.Text{String: reqParams.Tempo, Valid: reqParams.Tempo != ""},
+			ReachedFailure: reqParams.ReachedFailure,
+			PartialReps:    int16(reqParams.PartialReps),
+			Notes:          pgtype.Text{String: reqParams.Notes, Valid: reqParams.Notes != ""},
+			WeightUnit:     enteredUnit,
+			ID:             logID,
+		}
+		tx, err := pool.Begin(r.Context())
+		if err != nil {
+			reqLogger.Error("update log failed - transaction start error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
 		}
-		updatedLog, err := db.UpdateLog(r.Context(), dbParams)
+		defer tx.Rollback(r.Context())
+		txQueries := db.WithTx(tx)
+
+		updatedLog, err := txQueries.UpdateLog(r.Context(), dbParams)
 		if err == pgx.ErrNoRows {
 			reqLogger.Error("update log failed - log not found", slog.String("error", err.Error()))
 			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
 			return
 		} else if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				reqLogger.Debug("update log failed - log order already in use", slog.Int64("logs_order", int64(reqParams.Order)))
+				util.RespondWithError(w, r, http.StatusConflict, "order already in use in the set", err)
+				return
+			}
 			reqLogger.Error(
 				"update log failed - database error",
 				slog.String("error", err.Error()),
@@ -51,16 +113,27 @@ func HandlerUpdateLog(db *database.Queries, logger *slog.Logger) http.HandlerFun
 			return
 		}
 
-		reqLogger.Info("update log success")
-		util.RespondWithJSON(w, r, http.StatusOK, LogRes{
-			ID:    updatedLog.ID,
-			SetID: updatedLog.SetID,
-			LogReq: LogReq{
-				ExerciseID: updatedLog.ExerciseID,
-				Weight:     updatedLog.Weight.Float64,
-				Reps:       updatedLog.Reps,
-				Order:      updatedLog.LogsOrder,
-			},
-		})
+		// the records the log held are detected again with its new values
+		if err := txQueries.DeletePersonalRecordsByLogID(r.Context(), logID); err != nil {
+			reqLogger.Error("update log failed - delete records database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		records, err := record.Detect(r.Context(), txQueries, userID, updatedLog)
+		if err != nil {
+			reqLogger.Error("update log failed - detect records database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		if err := tx.Commit(r.Context()); err != nil {
+			reqLogger.Error("update log failed - transaction commit error", slog.String("error", err.Error()))
+			err = fmt.Errorf("could not commit the transaction: %w", err)
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		reqLogger.Info("update log success", slog.Int("new_records", len(records)))
+		util.RespondWithJSON(w, r, http.StatusOK, logRecordsResFromDB(updatedLog, records, unit))
 	}
 }
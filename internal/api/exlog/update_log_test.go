@@ -18,30 +18,40 @@ import (
 
 func TestHandlerUpdateLog(t *testing.T) {
 	testCases := []struct {
-		name         string
-		statusCode   int
-		errMsg       []string
-		hasJSON      bool
-		hasEmptyJSON bool
-		setID        int64
-		exerciseID   int32
-		weight       float64
-		reps         int32
-		order        int32
-		logID        int64
-		userID       uuid.UUID
-		hasLogID     bool
+		name          string
+		statusCode    int
+		errMsg        []string
+		hasJSON       bool
+		hasEmptyJSON  bool
+		setID         int64
+		weight        float64
+		reps          int32
+		order         int32
+		logID         int64
+		userID        uuid.UUID
+		hasLogID      bool
+		otherExercise bool
 	}{
 		{
 			name:       "happy path",
 			statusCode: http.StatusOK,
 			hasJSON:    true,
-			exerciseID: 1,
 			weight:     100.5,
 			reps:       10,
 			order:      1,
 			hasLogID:   true,
 		},
+		{
+			name:          "exercise of another set",
+			statusCode:    http.StatusBadRequest,
+			hasJSON:       true,
+			weight:        100,
+			reps:          10,
+			order:         1,
+			hasLogID:      true,
+			otherExercise: true,
+			errMsg:        []string{"exercise_id must match the exercise of the set"},
+		},
 		{
 			name:       "log id does not exist",
 			statusCode: http.StatusInternalServerError,
@@ -74,6 +84,7 @@ func TestHandlerUpdateLog(t *testing.T) {
 	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
 	sessionID := testutil.CreateSessionDBTestHelper(t, db, "test session", user.ID)
 	exerciseID := testutil.CreateExerciseDBTestHelper(t, db, "pull ups")
+	otherExerciseID := testutil.CreateExerciseDBTestHelper(t, db, "chin ups")
 	setID := testutil.CreateSetDBTestHelper(t, db, sessionID, exerciseID)
 	// a log is created with negative values
 	logID := testutil.CreateLogExerciseDBTestHelper(t, db, -10, -5, exerciseID, setID, -100)
@@ -90,8 +101,8 @@ func TestHandlerUpdateLog(t *testing.T) {
 					Reps:       tc.reps,
 					Order:      tc.order,
 				}
-				if tc.exerciseID != 0 {
-					reqParams.ExerciseID = tc.exerciseID
+				if tc.otherExercise {
+					reqParams.ExerciseID = otherExerciseID
 				}
 				body, err := json.Marshal(reqParams)
 				require.NoError(t, err, "unexpected JSON marshal error")
@@ -120,7 +131,7 @@ func TestHandlerUpdateLog(t *testing.T) {
 			req = req.WithContext(ctx)
 			rr := httptest.NewRecorder()
 
-			handler := HandlerUpdateLog(db, logger)
+			handler := HandlerUpdateLog(dbPool, db, logger)
 			middleware.RequestID(handler).ServeHTTP(rr, req)
 			if tc.statusCode != rr.Code {
 				t.Fatalf("Body response: %s", rr.Body.String())
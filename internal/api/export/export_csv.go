@@ -0,0 +1,170 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/api/validation"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// logs read from the database at once, only a batch is held in memory while streaming
+var exportBatchSize int32 = 500
+
+var csvHeader = []string{
+	"date", "session", "set", "set_type", "exercise", "log", "weight", "unit", "reps", "rpe", "rir", "rest_seconds",
+}
+
+// HandlerExportCSV streams every log of the user as a CSV row, optionally within the from and to dates.
+// Logs are read in batches and flushed to the client as they are written.
+func HandlerExportCSV(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("export csv failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		// validate the query parameters, the export covers the whole history by default
+		problems := map[string]string{}
+		query := r.URL.Query()
+		params := database.GetExportRowsParams{UserID: userID, RowLimit: exportBatchSize}
+		for _, key := range []string{"from", "to"} {
+			if !query.Has(key) {
+				continue
+			}
+			date, err := validation.Date(query.Get(key), apiconstants.DATE_LAYOUT, nil, nil)
+			if err != nil {
+				problems[key] = fmt.Sprintf("invalid %s date: %s", key, err.Error())
+				continue
+			}
+			if key == "from" {
+				params.FromDate = pgtype.Date{Time: date, Valid: true}
+			} else {
+				params.ToDate = pgtype.Date{Time: date, Valid: true}
+			}
+		}
+		if params.FromDate.Valid && params.ToDate.Valid && params.FromDate.Time.After(params.ToDate.Time) {
+			problems["from"] = "invalid from date: must be before the to date"
+		}
+		if len(problems) > 0 {
+			reqLogger.Debug("export csv failed - validation failed", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		}
+
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "export csv")
+		if !ok {
+			return
+		}
+
+		// the first batch is read before answering so database errors can still be reported
+		rows, err := db.GetExportRows(r.Context(), params)
+		if err != nil {
+			reqLogger.Error("export csv failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		filename := "gogym-export-" + time.Now().UTC().Format(apiconstants.DATE_LAYOUT) + ".csv"
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		w.WriteHeader(http.StatusOK)
+		flusher, _ := w.(http.Flusher)
+		writer := csv.NewWriter(w)
+		if err := writer.Write(csvHeader); err != nil {
+			reqLogger.Debug("could not write the response", slog.String("error", err.Error()))
+			return
+		}
+
+		exported := 0
+		for len(rows) > 0 {
+			for _, row := range rows {
+				if err := writer.Write(exportRecord(row, unit)); err != nil {
+					reqLogger.Debug("could not write the response", slog.String("error", err.Error()))
+					return
+				}
+			}
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				reqLogger.Debug("could not write the response", slog.String("error", err.Error()))
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			exported += len(rows)
+			if int32(len(rows)) < exportBatchSize {
+				break
+			}
+
+			// resume after the last exported log
+			last := rows[len(rows)-1]
+			params.AfterDate = last.Date
+			params.AfterSessionID = last.SessionID
+			params.AfterSetOrder = last.SetOrder
+			params.AfterLogsOrder = last.LogsOrder
+			rows, err = db.GetExportRows(r.Context(), params)
+			if err != nil {
+				// the status is already sent, the client gets a truncated file
+				reqLogger.Error("export csv failed - database error while streaming", slog.String("error", err.Error()))
+				return
+			}
+		}
+		writer.Flush()
+
+		reqLogger.Info("export csv success", slog.Int("logs", exported))
+	}
+}
+
+// exportRecord returns the CSV fields of a log, the weight in unit and empty fields for missing values
+func exportRecord(row database.GetExportRowsRow, unit string) []string {
+	record := []string{
+		row.Date.Time.Format(apiconstants.DATE_LAYOUT),
+		escapeCell(row.SessionName),
+		strconv.Itoa(int(row.SetOrder)),
+		row.SetType,
+		escapeCell(row.ExerciseName),
+		strconv.Itoa(int(row.LogsOrder)),
+		"",
+		unit,
+		strconv.Itoa(int(row.Reps)),
+		"",
+		"",
+		"",
+	}
+	if row.Weight.Valid {
+		record[6] = strconv.FormatFloat(units.FromKG(row.Weight.Float64, unit), 'f', -1, 64)
+	}
+	if row.Rpe.Valid {
+		record[9] = strconv.FormatFloat(row.Rpe.Float64, 'f', -1, 64)
+	}
+	if row.Rir.Valid {
+		record[10] = strconv.Itoa(int(row.Rir.Int16))
+	}
+	if row.RestTime.Valid {
+		record[11] = strconv.Itoa(int(row.RestTime.Int32))
+	}
+	return record
+}
+
+// escapeCell prefixes the text cells spreadsheets would run as formulas with a quote, the names are user input
+func escapeCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
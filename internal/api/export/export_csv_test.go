@@ -0,0 +1,187 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerExportCSV(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	squatID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+	benchID := testutil.CreateExerciseDBTestHelper(t, db, "bench press")
+
+	// the later session is created first, the export is sorted by date
+	for _, s := range []struct {
+		date   time.Time
+		weight float64
+	}{
+		{date: time.Date(2025, time.March, 8, 0, 0, 0, 0, time.UTC), weight: 105},
+		{date: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), weight: 100},
+	} {
+		session, err := db.CreateSession(context.Background(), database.CreateSessionParams{
+			Name:   "legs, then push",
+			Date:   pgtype.Date{Time: s.date, Valid: true},
+			UserID: user.ID,
+		})
+		require.NoError(t, err)
+		setID := testutil.CreateSetDBTestHelper(t, db, session.ID, squatID)
+		testutil.CreateLogExerciseDBTestHelper(t, db, 5, 1, squatID, setID, s.weight)
+		testutil.CreateLogExerciseDBTestHelper(t, db, 3, 2, squatID, setID, s.weight+10)
+		setID = testutil.CreateSetDBTestHelper(t, db, session.ID, benchID)
+		testutil.CreateLogExerciseDBTestHelper(t, db, 8, 1, benchID, setID, 60)
+	}
+	// logs of other users are never exported
+	other := testutil.CreateUserDBTestHelper(t, db, "otheruser", "testpassword", false)
+	otherSession := testutil.CreateSessionDBTestHelper(t, db, "other", other.ID)
+	setID := testutil.CreateSetDBTestHelper(t, db, otherSession, squatID)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 1, 1, squatID, setID, 200)
+
+	// batches smaller than the export so it is resumed several times
+	exportBatchSize = 2
+	t.Cleanup(func() { exportBatchSize = 500 })
+
+	testCases := []struct {
+		name       string
+		query      string
+		statusCode int
+		errKeys    []string
+		expected   [][]string
+	}{
+		{
+			name:       "happy path",
+			statusCode: http.StatusOK,
+			expected: [][]string{
+				{"2025-03-01", "legs, then push", "1", "working", "squat", "1", "100", "kg", "5", "", "", "90"},
+				{"2025-03-01", "legs, then push", "1", "working", "squat", "2", "110", "kg", "3", "", "", "90"},
+				{"2025-03-01", "legs, then push", "2", "working", "bench press", "1", "60", "kg", "8", "", "", "90"},
+				{"2025-03-08", "legs, then push", "1", "working", "squat", "1", "105", "kg", "5", "", "", "90"},
+				{"2025-03-08", "legs, then push", "1", "working", "squat", "2", "115", "kg", "3", "", "", "90"},
+				{"2025-03-08", "legs, then push", "2", "working", "bench press", "1", "60", "kg", "8", "", "", "90"},
+			},
+		},
+		{
+			name:       "happy path: date range in pounds",
+			query:      "?from=2025-03-05&to=2025-03-31&units=lb",
+			statusCode: http.StatusOK,
+			expected: [][]string{
+				{"2025-03-08", "legs, then push", "1", "working", "squat", "1", "231.485", "lb", "5", "", "", "90"},
+				{"2025-03-08", "legs, then push", "1", "working", "squat", "2", "253.532", "lb", "3", "", "", "90"},
+				{"2025-03-08", "legs, then push", "2", "working", "bench press", "1", "132.277", "lb", "8", "", "", "90"},
+			},
+		},
+		{
+			name:       "happy path: nothing in the range",
+			query:      "?from=2024-01-01&to=2024-12-31",
+			statusCode: http.StatusOK,
+			expected:   [][]string{},
+		},
+		{
+			name:       "invalid dates",
+			query:      "?from=2025-04-01&to=2025-03-01",
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"from"},
+		},
+		{
+			name:       "invalid units",
+			query:      "?units=stone",
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"units"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/test"+tc.query, nil)
+			require.NoError(t, err)
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerExportCSV(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				for _, key := range tc.errKeys {
+					assert.Contains(t, rr.Body.String(), key)
+				}
+				return
+			}
+
+			assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+			assert.Contains(t, rr.Header().Get("Content-Disposition"), "attachment")
+			records, err := csv.NewReader(rr.Body).ReadAll()
+			require.NoError(t, err)
+			require.NotEmpty(t, records)
+			assert.Equal(t, csvHeader, records[0])
+			assert.Equal(t, tc.expected, records[1:])
+		})
+	}
+}
+
+func TestExportRecord(t *testing.T) {
+	row := database.GetExportRowsRow{
+		Date:         pgtype.Date{Time: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		SessionName:  "push",
+		SetOrder:     3,
+		SetType:      "drop",
+		ExerciseName: "bench press",
+		LogsOrder:    2,
+		Weight:       pgtype.Float8{Float64: 82.5, Valid: true},
+		Reps:         10,
+		Rpe:          pgtype.Float8{Float64: 8.5, Valid: true},
+		Rir:          pgtype.Int2{Int16: 2, Valid: true},
+		RestTime:     pgtype.Int4{Int32: 120, Valid: true},
+	}
+	assert.Equal(t,
+		[]string{"2025-03-01", "push", "3", "drop", "bench press", "2", "82.5", "kg", "10", "8.5", "2", "120"},
+		exportRecord(row, units.KG))
+
+	// bodyweight logs have no weight
+	row.Weight = pgtype.Float8{}
+	row.Rpe, row.Rir, row.RestTime = pgtype.Float8{}, pgtype.Int2{}, pgtype.Int4{}
+	assert.Equal(t,
+		[]string{"2025-03-01", "push", "3", "drop", "bench press", "2", "", "lb", "10", "", "", ""},
+		exportRecord(row, units.LB))
+
+	// names starting like a formula are not run by spreadsheets
+	row.SessionName, row.ExerciseName = "=HYPERLINK(\"http://example.com\")", "@sum"
+	record := exportRecord(row, units.KG)
+	assert.Equal(t, "'=HYPERLINK(\"http://example.com\")", record[1])
+	assert.Equal(t, "'@sum", record[4])
+}
+
+func TestEscapeCell(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{value: "bench press", expected: "bench press"},
+		{value: "", expected: ""},
+		{value: "=1+1", expected: "'=1+1"},
+		{value: "+cmd", expected: "'+cmd"},
+		{value: "-2+3", expected: "'-2+3"},
+		{value: "@SUM(A1)", expected: "'@SUM(A1)"},
+		{value: "\t=1", expected: "'\t=1"},
+		{value: "push-ups", expected: "push-ups"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			assert.Equal(t, tc.expected, escapeCell(tc.value))
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package goal
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/api/validation"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func HandlerCreateGoal(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("create goal failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		reqParams, problems, err := validation.DecodeValid[*GoalReq](r)
+		if len(problems) > 0 {
+			reqLogger.Debug("create goal failed - validation failed", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		} else if err != nil {
+			reqLogger.Debug("create goal failed - invalid payload", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusBadRequest, "invalid payload", err)
+			return
+		}
+
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "create goal")
+		if !ok {
+			return
+		}
+		enteredUnit := reqParams.Unit
+		if enteredUnit == "" {
+			enteredUnit = unit
+		}
+
+		base, err := baseline(r.Context(), db, userID, reqParams.Type, reqParams.startDate)
+		if err != nil {
+			reqLogger.Error("create goal failed - baseline database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		goal, err := db.CreateGoal(r.Context(), database.CreateGoalParams{
+			UserID:     userID,
+			GoalType:   reqParams.Type,
+			ExerciseID: reqParams.exerciseID(),
+			Target:     reqParams.target(enteredUnit),
+			Baseline:   base,
+			StartDate:  pgtype.Date{Time: reqParams.startDate, Valid: true},
+			TargetDate: pgtype.Date{Time: reqParams.targetDate, Valid: true},
+			Notes:      pgtype.Text{String: reqParams.Notes, Valid: reqParams.Notes != ""},
+		})
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				reqLogger.Debug("create goal failed - exercise not found", slog.Int64("exercise_id", int64(reqParams.ExerciseID)))
+				util.RespondWithError(w, r, http.StatusNotFound, "exercise ID not found", err)
+				return
+			}
+			reqLogger.Error("create goal failed - create goal database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		resParams, err := Evaluate(r.Context(), db, goal, unit, currentDay())
+		if err != nil {
+			reqLogger.Error("create goal failed - progress database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		reqLogger.Info("create goal success", slog.Int64("goal_id", goal.ID))
+		util.RespondWithJSON(w, r, http.StatusCreated, resParams)
+	}
+}
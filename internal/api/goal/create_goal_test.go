@@ -0,0 +1,194 @@
+package goal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerCreateGoal(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	squatID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+	today := time.Now().UTC().Format(apiconstants.DATE_LAYOUT)
+	nextMonth := time.Now().UTC().AddDate(0, 1, 0).Format(apiconstants.DATE_LAYOUT)
+
+	// a squat of 100 kg today
+	sessionID := testutil.CreateSessionDBTestHelper(t, db, "legs", user.ID)
+	setID := testutil.CreateSetDBTestHelper(t, db, sessionID, squatID)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 5, 1, squatID, setID, 100)
+
+	testCases := []struct {
+		name            string
+		body            string
+		query           string
+		missingContext  bool
+		statusCode      int
+		errMsg          []string
+		expectedTarget  float64
+		expectedPercent float64
+		expectedUnit    string
+	}{
+		{
+			name:            "happy path: strength goal",
+			body:            `{"type": "strength", "exercise_id": ` + strconv.Itoa(int(squatID)) + `, "target": 125, "target_date": "` + nextMonth + `"}`,
+			statusCode:      http.StatusCreated,
+			expectedTarget:  125,
+			expectedPercent: 80,
+			expectedUnit:    "kg",
+		},
+		{
+			name:            "happy path: target entered in pounds, returned in kilograms",
+			body:            `{"type": "strength", "exercise_id": ` + strconv.Itoa(int(squatID)) + `, "target": 440.925, "unit": "lb", "target_date": "` + nextMonth + `"}`,
+			statusCode:      http.StatusCreated,
+			expectedTarget:  200,
+			expectedPercent: 50,
+			expectedUnit:    "kg",
+		},
+		{
+			name:            "happy path: frequency goal",
+			body:            `{"type": "frequency", "target": 4, "start_date": "` + today + `", "target_date": "` + nextMonth + `"}`,
+			statusCode:      http.StatusCreated,
+			expectedTarget:  4,
+			expectedPercent: 25,
+		},
+		{
+			name:       "exercise does not exist",
+			body:       `{"type": "strength", "exercise_id": 999999, "target": 125, "target_date": "` + nextMonth + `"}`,
+			statusCode: http.StatusNotFound,
+			errMsg:     []string{"exercise ID not found"},
+		},
+		{
+			name:       "no JSON sent",
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid payload"},
+		},
+		{
+			name:       "invalid payload",
+			body:       `{"type": "strength", "target": 125, "target_date": "2025-01-01"}`,
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"exercise_id", "target_date"},
+		},
+		{
+			name:       "invalid units",
+			body:       `{"type": "frequency", "target": 4, "target_date": "` + nextMonth + `"}`,
+			query:      "?units=stone",
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid units"},
+		},
+		{
+			name:           "user not found in context",
+			body:           `{"type": "frequency", "target": 4, "target_date": "` + nextMonth + `"}`,
+			missingContext: true,
+			statusCode:     http.StatusInternalServerError,
+			errMsg:         []string{"something went wrong"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := util.ContextWithUser(context.Background(), user.ID)
+			if tc.missingContext {
+				ctx = context.Background()
+			}
+			req, err := http.NewRequestWithContext(ctx, "POST", "/test"+tc.query, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerCreateGoal(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				for _, message := range tc.errMsg {
+					assert.Contains(t, rr.Body.String(), message)
+				}
+				return
+			}
+
+			var resParams GoalRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			assert.NotZero(t, resParams.ID)
+			assert.Equal(t, StatusActive, resParams.Status)
+			assert.InDelta(t, tc.expectedTarget, resParams.Target, 0.01)
+			assert.InDelta(t, tc.expectedPercent, resParams.Progress.Percent, 0.01)
+			assert.Equal(t, tc.expectedUnit, resParams.Unit)
+			assert.Equal(t, today, resParams.StartDate)
+		})
+	}
+}
+
+func TestValidateGoal(t *testing.T) {
+	testCases := []struct {
+		name    string
+		req     GoalReq
+		errKeys []string
+	}{
+		{
+			name: "valid strength goal",
+			req:  GoalReq{Type: "Strength", ExerciseID: 1, Target: 100, TargetDate: "2099-12-31"},
+		},
+		{
+			name: "valid volume goal without exercise",
+			req:  GoalReq{Type: TypeVolume, Target: 50000, Unit: "lb", StartDate: "2025-01-01", TargetDate: "2099-12-31"},
+		},
+		{
+			name:    "strength goal without exercise",
+			req:     GoalReq{Type: TypeStrength, Target: 100, TargetDate: "2099-12-31"},
+			errKeys: []string{"exercise_id"},
+		},
+		{
+			name:    "bodyweight goal with exercise",
+			req:     GoalReq{Type: TypeBodyweight, ExerciseID: 1, Target: 75, TargetDate: "2099-12-31"},
+			errKeys: []string{"exercise_id"},
+		},
+		{
+			name:    "invalid type, target and unit",
+			req:     GoalReq{Type: "speed", Target: -1, Unit: "stone", TargetDate: "2099-12-31"},
+			errKeys: []string{"type", "target", "unit"},
+		},
+		{
+			name:    "too many sessions per week",
+			req:     GoalReq{Type: TypeFrequency, Target: maxSessionsPerWeek + 1, TargetDate: "2099-12-31"},
+			errKeys: []string{"target"},
+		},
+		{
+			name:    "target date before start date",
+			req:     GoalReq{Type: TypeFrequency, Target: 3, StartDate: "2025-06-01", TargetDate: "2025-05-31"},
+			errKeys: []string{"target_date"},
+		},
+		{
+			name:    "invalid dates",
+			req:     GoalReq{Type: TypeFrequency, Target: 3, StartDate: "01-06-2025"},
+			errKeys: []string{"start_date", "target_date"},
+		},
+		{
+			name:    "notes too long",
+			req:     GoalReq{Type: TypeFrequency, Target: 3, TargetDate: "2099-12-31", Notes: strings.Repeat("a", apiconstants.MaxNotesLength+1)},
+			errKeys: []string{"notes"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			problems := tc.req.Valid(context.Background())
+			require.Len(t, problems, len(tc.errKeys), problems)
+			for _, key := range tc.errKeys {
+				assert.Contains(t, problems, key)
+			}
+		})
+	}
+}
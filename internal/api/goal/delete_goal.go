@@ -0,0 +1,31 @@
+package goal
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5"
+)
+
+func HandlerDeleteGoal(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		// goal id is stored in the context with a generic key
+		goalID, _ := retrieveParseIDFromContext(r.Context())
+		reqLogger = reqLogger.With(slog.Int64("goal_id", goalID))
+
+		if _, err := db.DeleteGoal(r.Context(), goalID); err == pgx.ErrNoRows {
+			util.RespondWithError(w, r, http.StatusNotFound, "goal not found", err)
+			return
+		} else if err != nil {
+			reqLogger.Error("delete goal failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		reqLogger.Info("delete goal success")
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
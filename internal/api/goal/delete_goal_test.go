@@ -0,0 +1,62 @@
+package goal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerDeleteGoal(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	today := currentDay()
+
+	testCases := []struct {
+		name       string
+		goalID     int64
+		statusCode int
+	}{
+		{
+			name:       "happy path",
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name:       "goal does not exist",
+			goalID:     -1,
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			goal := createGoalDBTestHelper(t, db, user.ID, 3, today, today.AddDate(0, 1, 0))
+			goalID := goal.ID
+			if tc.goalID != 0 {
+				goalID = tc.goalID
+			}
+
+			req, err := http.NewRequest("DELETE", "/test", nil)
+			require.NoError(t, err)
+			ctx := util.ContextWithUser(req.Context(), user.ID)
+			req = req.WithContext(util.ContextWithResourceID(ctx, goalID))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerDeleteGoal(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode == http.StatusNoContent {
+				_, err := db.GetGoal(context.Background(), goal.ID)
+				assert.ErrorIs(t, err, pgx.ErrNoRows)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package goal
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5"
+)
+
+type goalsRes struct {
+	Goals []GoalRes `json:"goals"`
+}
+
+// HandlerGetGoals returns the goals of the user with their progress, optionally filtered by status
+func HandlerGetGoals(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("get goals failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		status := strings.ToLower(r.URL.Query().Get("status"))
+		if status != "" && !slices.Contains(Statuses, status) {
+			reqLogger.Debug("get goals failed - invalid status", slog.String("status", status))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, map[string]string{
+				"status": "invalid status: status must be one of " + strings.Join(Statuses, ", "),
+			})
+			return
+		}
+
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "get goals")
+		if !ok {
+			return
+		}
+
+		goals, err := db.GetGoalsByUserID(r.Context(), userID)
+		if err != nil {
+			reqLogger.Error("get goals failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		// the status is filtered once the goals are evaluated, as it may have changed
+		today := currentDay()
+		resParams := goalsRes{Goals: []GoalRes{}}
+		for _, goal := range goals {
+			res, err := Evaluate(r.Context(), db, goal, unit, today)
+			if err != nil {
+				reqLogger.Error("get goals failed - progress database error", slog.String("error", err.Error()))
+				util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+				return
+			}
+			if status == "" || res.Status == status {
+				resParams.Goals = append(resParams.Goals, res)
+			}
+		}
+		util.RespondWithJSON(w, r, http.StatusOK, resParams)
+	}
+}
+
+func HandlerGetGoal(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		// goal id is stored in the context with a generic key
+		goalID, _ := retrieveParseIDFromContext(r.Context())
+		reqLogger = reqLogger.With(slog.Int64("goal_id", goalID))
+
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "get goal")
+		if !ok {
+			return
+		}
+
+		goal, err := db.GetGoal(r.Context(), goalID)
+		if err == pgx.ErrNoRows {
+			util.RespondWithError(w, r, http.StatusNotFound, "goal not found", err)
+			return
+		} else if err != nil {
+			reqLogger.Error("get goal failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		resParams, err := Evaluate(r.Context(), db, goal, unit, currentDay())
+		if err != nil {
+			reqLogger.Error("get goal failed - progress database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		util.RespondWithJSON(w, r, http.StatusOK, resParams)
+	}
+}
@@ -0,0 +1,162 @@
+package goal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createGoalDBTestHelper creates a frequency goal of the user between start and target
+func createGoalDBTestHelper(t *testing.T, db *database.Queries, userID uuid.UUID, target float64, start, end time.Time) database.Goal {
+	t.Helper()
+	goal, err := db.CreateGoal(context.Background(), database.CreateGoalParams{
+		UserID:     userID,
+		GoalType:   TypeFrequency,
+		Target:     target,
+		StartDate:  pgtype.Date{Time: start, Valid: true},
+		TargetDate: pgtype.Date{Time: end, Valid: true},
+	})
+	require.NoError(t, err)
+	return goal
+}
+
+func TestHandlerGetGoals(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	today := currentDay()
+
+	// one session today, the past goal is missed and the current one is active
+	testutil.CreateSessionDBTestHelper(t, db, "full body", user.ID)
+	missed := createGoalDBTestHelper(t, db, user.ID, 3, today.AddDate(0, -2, 0), today.AddDate(0, -1, 0))
+	active := createGoalDBTestHelper(t, db, user.ID, 3, today, today.AddDate(0, 1, 0))
+
+	testCases := []struct {
+		name        string
+		query       string
+		statusCode  int
+		errKeys     []string
+		expectedIDs []int64
+	}{
+		{
+			name:        "happy path: all goals",
+			statusCode:  http.StatusOK,
+			expectedIDs: []int64{missed.ID, active.ID},
+		},
+		{
+			name:        "happy path: missed goals",
+			query:       "?status=missed",
+			statusCode:  http.StatusOK,
+			expectedIDs: []int64{missed.ID},
+		},
+		{
+			name:        "happy path: no achieved goals",
+			query:       "?status=achieved",
+			statusCode:  http.StatusOK,
+			expectedIDs: []int64{},
+		},
+		{
+			name:       "invalid status",
+			query:      "?status=abandoned",
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"status"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/test"+tc.query, nil)
+			require.NoError(t, err)
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerGetGoals(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				var problems map[string]string
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&problems))
+				for _, key := range tc.errKeys {
+					assert.Contains(t, problems, key)
+				}
+				return
+			}
+
+			var resParams goalsRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			ids := []int64{}
+			for _, g := range resParams.Goals {
+				ids = append(ids, g.ID)
+			}
+			assert.Equal(t, tc.expectedIDs, ids)
+		})
+	}
+
+	// the transition is persisted
+	stored, err := db.GetGoal(context.Background(), missed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusMissed, stored.Status)
+	assert.True(t, stored.StatusDate.Time.Equal(missed.TargetDate.Time))
+}
+
+func TestHandlerGetGoal(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	today := currentDay()
+	goal := createGoalDBTestHelper(t, db, user.ID, 2, today, today.AddDate(0, 0, 27))
+	testutil.CreateSessionDBTestHelper(t, db, "full body", user.ID)
+
+	testCases := []struct {
+		name       string
+		goalID     int64
+		statusCode int
+	}{
+		{
+			name:       "happy path",
+			goalID:     goal.ID,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "goal does not exist",
+			goalID:     -1,
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			ctx := util.ContextWithUser(req.Context(), user.ID)
+			req = req.WithContext(util.ContextWithResourceID(ctx, tc.goalID))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerGetGoal(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode != http.StatusOK {
+				return
+			}
+
+			var resParams GoalRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			assert.Equal(t, goal.ID, resParams.ID)
+			assert.Equal(t, StatusActive, resParams.Status)
+			require.NotNil(t, resParams.Progress.Current)
+			assert.InDelta(t, 1, *resParams.Progress.Current, 0.01)
+			assert.InDelta(t, 50, resParams.Progress.Percent, 0.01)
+			assert.Empty(t, resParams.Unit)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package goal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/api/validation"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	TypeStrength   = "strength"   // lift the target weight in the exercise
+	TypeVolume     = "volume"     // lift the target tonnage, in total or in the exercise
+	TypeFrequency  = "frequency"  // train the target sessions per week on average
+	TypeBodyweight = "bodyweight" // reach the target bodyweight, losing or gaining weight
+)
+
+var Types = []string{TypeStrength, TypeVolume, TypeFrequency, TypeBodyweight}
+
+const (
+	StatusActive   = "active"
+	StatusAchieved = "achieved"
+	StatusMissed   = "missed"
+)
+
+var Statuses = []string{StatusActive, StatusAchieved, StatusMissed}
+
+const maxSessionsPerWeek = 14
+
+type GoalReq struct {
+	Type       string  `json:"type"`
+	ExerciseID int32   `json:"exercise_id,omitempty"` // required by strength goals, optional for volume goals
+	Target     float64 `json:"target"`
+	Unit       string  `json:"unit,omitempty"`       // unit of weight targets, defaults to the preferred unit
+	StartDate  string  `json:"start_date,omitempty"` // defaults to today
+	TargetDate string  `json:"target_date"`
+	Notes      string  `json:"notes,omitempty"`
+
+	startDate  time.Time
+	targetDate time.Time
+}
+
+type progressRes struct {
+	Current *float64 `json:"current"` // null until there is something to measure
+	Percent float64  `json:"percent"`
+}
+
+type GoalRes struct {
+	ID         int64       `json:"id"`
+	Status     string      `json:"status"`
+	StatusDate string      `json:"status_date,omitempty"` // day the goal was achieved or missed
+	Progress   progressRes `json:"progress"`
+	GoalReq
+}
+
+func (r *GoalReq) Valid(ctx context.Context) map[string]string {
+	problems := make(map[string]string)
+
+	// type validation
+	r.Type = strings.ToLower(r.Type)
+	if !slices.Contains(Types, r.Type) {
+		problems["type"] = "invalid type: type must be one of " + strings.Join(Types, ", ")
+	}
+
+	// exercise validation, only strength and volume goals are about exercises
+	if r.Type == TypeStrength && r.ExerciseID == 0 {
+		problems["exercise_id"] = "invalid exercise_id: strength goals require an exercise"
+	} else if (r.Type == TypeFrequency || r.Type == TypeBodyweight) && r.ExerciseID != 0 {
+		problems["exercise_id"] = fmt.Sprintf("invalid exercise_id: %s goals are not about an exercise", r.Type)
+	}
+
+	// target validation
+	if r.Target <= 0 {
+		problems["target"] = "invalid target: target must be positive"
+	} else if r.Type == TypeFrequency && r.Target > maxSessionsPerWeek {
+		problems["target"] = fmt.Sprintf("invalid target: frequency targets must be at most %d sessions per week", maxSessionsPerWeek)
+	}
+
+	// unit validation, it is an optional parameter
+	if r.Unit != "" && !units.Valid(r.Unit) {
+		problems["unit"] = "invalid unit: " + units.ErrInvalidUnit.Error()
+	}
+
+	// dates validation
+	if r.StartDate == "" {
+		r.StartDate = time.Now().UTC().Format(apiconstants.DATE_LAYOUT)
+	}
+	startDate, err := validation.Date(r.StartDate, apiconstants.DATE_LAYOUT, nil, nil)
+	if err != nil {
+		problems["start_date"] = "invalid start_date: " + err.Error()
+	}
+	r.startDate = startDate
+	targetDate, err := validation.Date(r.TargetDate, apiconstants.DATE_LAYOUT, nil, nil)
+	if err != nil {
+		problems["target_date"] = "invalid target_date: " + err.Error()
+	} else if problems["start_date"] == "" && targetDate.Before(startDate) {
+		problems["target_date"] = "invalid target_date: target_date must not be before start_date"
+	}
+	r.targetDate = targetDate
+
+	// notes validation, it is an optional parameter
+	r.Notes = strings.TrimSpace(r.Notes)
+	if err := validation.String(r.Notes, 0, apiconstants.MaxNotesLength); err != nil {
+		problems["notes"] = "invalid notes: " + err.Error()
+	}
+
+	return problems
+}
+
+func (r *GoalReq) exerciseID() pgtype.Int4 {
+	return pgtype.Int4{Int32: r.ExerciseID, Valid: r.ExerciseID != 0}
+}
+
+// target returns the target in kilograms for weight goals
+func (r *GoalReq) target(unit string) float64 {
+	if r.Type == TypeFrequency {
+		return r.Target
+	}
+	return units.ToKG(r.Target, unit)
+}
+
+// goalResFromDB builds the response of the goal with its weights converted into unit
+func goalResFromDB(goal database.Goal, e evaluation, unit string) GoalRes {
+	res := GoalRes{
+		ID:       goal.ID,
+		Status:   goal.Status,
+		Progress: progressRes{Percent: e.percent},
+		GoalReq: GoalReq{
+			Type:       goal.GoalType,
+			ExerciseID: goal.ExerciseID.Int32,
+			Target:     goal.Target,
+			StartDate:  goal.StartDate.Time.Format(apiconstants.DATE_LAYOUT),
+			TargetDate: goal.TargetDate.Time.Format(apiconstants.DATE_LAYOUT),
+			Notes:      goal.Notes.String,
+		},
+	}
+	if goal.StatusDate.Valid {
+		res.StatusDate = goal.StatusDate.Time.Format(apiconstants.DATE_LAYOUT)
+	}
+	if e.current != nil {
+		current := *e.current
+		res.Progress.Current = &current
+	}
+	// frequency goals are not about weights
+	if goal.GoalType != TypeFrequency {
+		res.Unit = unit
+		res.Target = units.FromKG(goal.Target, unit)
+		if res.Progress.Current != nil {
+			*res.Progress.Current = units.FromKG(*res.Progress.Current, unit)
+		}
+	}
+	return res
+}
+
+func retrieveParseIDFromContext(ctx context.Context) (int64, error) {
+	// pull the resource from the context
+	resourceID, ok := util.ResourceIDFromContext(ctx)
+	if !ok {
+		return 0, errors.New("could not find the goal id")
+	}
+	// coerce the resource id into int
+	goalID, ok := resourceID.(int64)
+	if !ok {
+		return 0, errors.New("could not type coerce the goal id into int64")
+	}
+	return goalID, nil
+}
@@ -0,0 +1,225 @@
+package goal
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type dailyValue struct {
+	date  time.Time
+	value float64 // kilograms, except for frequency goals where it is the number of sessions
+}
+
+type evaluation struct {
+	current    *float64
+	percent    float64
+	status     string
+	statusDate time.Time
+}
+
+// evaluate measures the progress of the goal from its daily values between the start date and today,
+// the target date at the latest. Goals are achieved the day the target is reached, and missed when the
+// target date passes without reaching it. Frequency goals are an average and are only achieved at the end.
+func evaluate(goal database.Goal, days []dailyValue, today time.Time) evaluation {
+	e := evaluation{status: StatusActive}
+	startDate, targetDate := goal.StartDate.Time, goal.TargetDate.Time
+	var reached time.Time
+
+	switch goal.GoalType {
+	case TypeStrength:
+		best := 0.0
+		for _, d := range days {
+			best = max(best, d.value)
+			if reached.IsZero() && d.value >= goal.Target {
+				reached = d.date
+			}
+		}
+		if len(days) > 0 {
+			e.current = &best
+		}
+		e.percent = best / goal.Target * 100
+	case TypeVolume:
+		total := 0.0
+		for _, d := range days {
+			total += d.value
+			if reached.IsZero() && total >= goal.Target {
+				reached = d.date
+			}
+		}
+		e.current = &total
+		e.percent = total / goal.Target * 100
+	case TypeFrequency:
+		end := today
+		if end.After(targetDate) {
+			end = targetDate
+		}
+		if end.Before(startDate) {
+			break
+		}
+		sessions := 0.0
+		for _, d := range days {
+			sessions += d.value
+		}
+		// the first days of the goal count as a whole week
+		weeks := max(1, (end.Sub(startDate).Hours()/24+1)/7)
+		average := round(sessions / weeks)
+		e.current = &average
+		e.percent = average / goal.Target * 100
+		if today.After(targetDate) && average >= goal.Target {
+			reached = targetDate
+		}
+	case TypeBodyweight:
+		if len(days) == 0 {
+			break
+		}
+		// without a reading before the goal, the first one tells the direction
+		start := days[0].value
+		if goal.Baseline.Valid {
+			start = goal.Baseline.Float64
+		}
+		losing := goal.Target < start
+		for _, d := range days {
+			if reached.IsZero() && ((losing && d.value <= goal.Target) || (!losing && d.value >= goal.Target)) {
+				reached = d.date
+			}
+		}
+		current := days[len(days)-1].value
+		e.current = &current
+		if start == goal.Target {
+			e.percent = 100
+		} else {
+			e.percent = (start - current) / (start - goal.Target) * 100
+		}
+	}
+
+	e.percent = round(min(100, max(0, e.percent)))
+	if !reached.IsZero() {
+		e.status, e.statusDate = StatusAchieved, reached
+	} else if today.After(targetDate) {
+		e.status, e.statusDate = StatusMissed, targetDate
+	}
+	return e
+}
+
+// dailyValues fetches the values measured by the goal every day from its start date
+// up to today, the target date at the latest
+func dailyValues(ctx context.Context, db *database.Queries, goal database.Goal, today time.Time) ([]dailyValue, error) {
+	from, to := goal.StartDate, goal.TargetDate
+	if today.Before(to.Time) {
+		to = pgtype.Date{Time: today, Valid: true}
+	}
+	if to.Time.Before(from.Time) {
+		return nil, nil
+	}
+
+	days := []dailyValue{}
+	switch goal.GoalType {
+	case TypeStrength:
+		rows, err := db.GetDailyMaxWeight(ctx, database.GetDailyMaxWeightParams{
+			UserID:     goal.UserID,
+			ExerciseID: goal.ExerciseID.Int32,
+			FromDate:   from,
+			ToDate:     to,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("get daily max weight: %w", err)
+		}
+		for _, row := range rows {
+			days = append(days, dailyValue{date: row.Date.Time, value: row.MaxWeight})
+		}
+	case TypeVolume:
+		rows, err := db.GetDailyVolume(ctx, database.GetDailyVolumeParams{
+			UserID:     goal.UserID,
+			ExerciseID: goal.ExerciseID,
+			FromDate:   from,
+			ToDate:     to,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("get daily volume: %w", err)
+		}
+		for _, row := range rows {
+			days = append(days, dailyValue{date: row.Date.Time, value: row.Volume})
+		}
+	case TypeFrequency:
+		rows, err := db.GetSessionCountsByDate(ctx, goal.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("get session counts: %w", err)
+		}
+		for _, row := range rows {
+			if !row.Date.Time.Before(from.Time) && !row.Date.Time.After(to.Time) {
+				days = append(days, dailyValue{date: row.Date.Time, value: float64(row.Sessions)})
+			}
+		}
+	case TypeBodyweight:
+		rows, err := db.GetDailyBodyweight(ctx, database.GetDailyBodyweightParams{
+			UserID:   goal.UserID,
+			FromDate: from,
+			ToDate:   to,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("get daily bodyweight: %w", err)
+		}
+		for _, row := range rows {
+			days = append(days, dailyValue{date: row.Date.Time, value: row.Bodyweight})
+		}
+	}
+	return days, nil
+}
+
+// Evaluate measures the progress of the goal and returns it with its weights converted into unit.
+// Active goals that got achieved or missed are updated, achieved and missed goals keep their status.
+func Evaluate(ctx context.Context, db *database.Queries, goal database.Goal, unit string, today time.Time) (GoalRes, error) {
+	days, err := dailyValues(ctx, db, goal, today)
+	if err != nil {
+		return GoalRes{}, err
+	}
+	e := evaluate(goal, days, today)
+
+	if goal.Status == StatusActive && e.status != StatusActive {
+		goal.Status = e.status
+		goal.StatusDate = pgtype.Date{Time: e.statusDate, Valid: true}
+		if err := db.UpdateGoalStatus(ctx, database.UpdateGoalStatusParams{
+			Status:     goal.Status,
+			StatusDate: goal.StatusDate,
+			ID:         goal.ID,
+		}); err != nil {
+			return GoalRes{}, fmt.Errorf("update goal status: %w", err)
+		}
+	}
+
+	return goalResFromDB(goal, e, unit), nil
+}
+
+// baseline returns the bodyweight of the user at the start of bodyweight goals
+func baseline(ctx context.Context, db *database.Queries, userID uuid.UUID, goalType string, startDate time.Time) (pgtype.Float8, error) {
+	if goalType != TypeBodyweight {
+		return pgtype.Float8{}, nil
+	}
+	bodyweight, err := db.GetLatestBodyweight(ctx, database.GetLatestBodyweightParams{
+		UserID: userID,
+		Date:   pgtype.Date{Time: startDate, Valid: true},
+	})
+	if err == pgx.ErrNoRows {
+		return pgtype.Float8{}, nil
+	} else if err != nil {
+		return pgtype.Float8{}, fmt.Errorf("get latest bodyweight: %w", err)
+	}
+	return pgtype.Float8{Float64: bodyweight, Valid: true}, nil
+}
+
+// currentDay returns the current day in UTC
+func currentDay() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func round(x float64) float64 {
+	return math.Round(x*100) / 100
+}
@@ -0,0 +1,173 @@
+package goal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// series builds the daily values of a goal starting on from, one value every step days
+func series(from time.Time, step int, values ...float64) []dailyValue {
+	days := make([]dailyValue, len(values))
+	for i, v := range values {
+		days[i] = dailyValue{date: from.AddDate(0, 0, step*i), value: v}
+	}
+	return days
+}
+
+func TestEvaluate(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, time.January, 28, 0, 0, 0, 0, time.UTC)
+	goal := func(goalType string, target float64, baseline *float64) database.Goal {
+		g := database.Goal{
+			GoalType:   goalType,
+			Target:     target,
+			StartDate:  pgtype.Date{Time: start, Valid: true},
+			TargetDate: pgtype.Date{Time: end, Valid: true},
+			Status:     StatusActive,
+		}
+		if baseline != nil {
+			g.Baseline = pgtype.Float8{Float64: *baseline, Valid: true}
+		}
+		return g
+	}
+	baseline := 80.0
+
+	testCases := []struct {
+		name               string
+		goal               database.Goal
+		days               []dailyValue
+		today              time.Time
+		expectedCurrent    *float64
+		expectedPercent    float64
+		expectedStatus     string
+		expectedStatusDate time.Time
+	}{
+		{
+			name:            "strength in progress",
+			goal:            goal(TypeStrength, 120, nil),
+			days:            series(start, 7, 100, 105),
+			today:           start.AddDate(0, 0, 10),
+			expectedCurrent: ptr(105),
+			expectedPercent: 87.5,
+			expectedStatus:  StatusActive,
+		},
+		{
+			name:               "strength achieved the first day it is reached",
+			goal:               goal(TypeStrength, 110, nil),
+			days:               series(start, 7, 100, 110, 112.5),
+			today:              start.AddDate(0, 0, 20),
+			expectedCurrent:    ptr(112.5),
+			expectedPercent:    100,
+			expectedStatus:     StatusAchieved,
+			expectedStatusDate: start.AddDate(0, 0, 7),
+		},
+		{
+			name:            "strength without training",
+			goal:            goal(TypeStrength, 110, nil),
+			today:           start.AddDate(0, 0, 3),
+			expectedPercent: 0,
+			expectedStatus:  StatusActive,
+		},
+		{
+			name:               "strength missed after the target date",
+			goal:               goal(TypeStrength, 120, nil),
+			days:               series(start, 7, 100, 105),
+			today:              end.AddDate(0, 0, 1),
+			expectedCurrent:    ptr(105),
+			expectedPercent:    87.5,
+			expectedStatus:     StatusMissed,
+			expectedStatusDate: end,
+		},
+		{
+			name:               "volume accumulates",
+			goal:               goal(TypeVolume, 10000, nil),
+			days:               series(start, 2, 4000, 4000, 4000),
+			today:              start.AddDate(0, 0, 10),
+			expectedCurrent:    ptr(12000),
+			expectedPercent:    100,
+			expectedStatus:     StatusAchieved,
+			expectedStatusDate: start.AddDate(0, 0, 4),
+		},
+		{
+			name:            "frequency is only achieved at the end",
+			goal:            goal(TypeFrequency, 2, nil),
+			days:            series(start, 3, 1, 1, 1, 1, 1),
+			today:           start.AddDate(0, 0, 13),
+			expectedCurrent: ptr(2.5),
+			expectedPercent: 100,
+			expectedStatus:  StatusActive,
+		},
+		{
+			name:               "frequency achieved",
+			goal:               goal(TypeFrequency, 2, nil),
+			days:               series(start, 3, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1),
+			today:              end.AddDate(0, 0, 1),
+			expectedCurrent:    ptr(2.5),
+			expectedPercent:    100,
+			expectedStatus:     StatusAchieved,
+			expectedStatusDate: end,
+		},
+		{
+			name:               "frequency missed",
+			goal:               goal(TypeFrequency, 3, nil),
+			days:               series(start, 7, 1, 1, 1, 1),
+			today:              end.AddDate(0, 0, 1),
+			expectedCurrent:    ptr(1),
+			expectedPercent:    33.33,
+			expectedStatus:     StatusMissed,
+			expectedStatusDate: end,
+		},
+		{
+			name:            "bodyweight loss from the baseline",
+			goal:            goal(TypeBodyweight, 75, &baseline),
+			days:            series(start, 7, 79, 78),
+			today:           start.AddDate(0, 0, 10),
+			expectedCurrent: ptr(78),
+			expectedPercent: 40,
+			expectedStatus:  StatusActive,
+		},
+		{
+			name:               "bodyweight gain without baseline",
+			goal:               goal(TypeBodyweight, 82, nil),
+			days:               series(start, 7, 78, 80, 82.5),
+			today:              start.AddDate(0, 0, 20),
+			expectedCurrent:    ptr(82.5),
+			expectedPercent:    100,
+			expectedStatus:     StatusAchieved,
+			expectedStatusDate: start.AddDate(0, 0, 14),
+		},
+		{
+			name:            "bodyweight moving away from the target",
+			goal:            goal(TypeBodyweight, 75, &baseline),
+			days:            series(start, 7, 81),
+			today:           start.AddDate(0, 0, 10),
+			expectedCurrent: ptr(81),
+			expectedPercent: 0,
+			expectedStatus:  StatusActive,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := evaluate(tc.goal, tc.days, tc.today)
+			if tc.expectedCurrent == nil {
+				assert.Nil(t, e.current)
+			} else {
+				require.NotNil(t, e.current)
+				assert.InDelta(t, *tc.expectedCurrent, *e.current, 0.01)
+			}
+			assert.InDelta(t, tc.expectedPercent, e.percent, 0.01)
+			assert.Equal(t, tc.expectedStatus, e.status)
+			assert.Equal(t, tc.expectedStatusDate, e.statusDate)
+		})
+	}
+}
+
+func ptr(x float64) *float64 {
+	return &x
+}
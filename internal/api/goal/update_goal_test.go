@@ -0,0 +1,103 @@
+package goal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerUpdateGoal(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	today := currentDay()
+	start := today.AddDate(0, -2, 0).Format(apiconstants.DATE_LAYOUT)
+	nextMonth := today.AddDate(0, 1, 0).Format(apiconstants.DATE_LAYOUT)
+
+	testCases := []struct {
+		name           string
+		body           string
+		goalID         int64
+		statusCode     int
+		errMsg         []string
+		expectedTarget float64
+		expectedNotes  string
+	}{
+		{
+			name:           "happy path: extending a missed goal reopens it",
+			body:           `{"type": "frequency", "target": 2, "start_date": "` + start + `", "target_date": "` + nextMonth + `", "notes": "one more month"}`,
+			statusCode:     http.StatusOK,
+			expectedTarget: 2,
+			expectedNotes:  "one more month",
+		},
+		{
+			name:       "invalid payload",
+			body:       `{"type": "frequency", "target": 0, "target_date": "` + nextMonth + `"}`,
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"target"},
+		},
+		{
+			name:       "exercise does not exist",
+			body:       `{"type": "volume", "exercise_id": 999999, "target": 1000, "target_date": "` + nextMonth + `"}`,
+			statusCode: http.StatusNotFound,
+			errMsg:     []string{"exercise ID not found"},
+		},
+		{
+			name:       "goal does not exist",
+			body:       `{"type": "frequency", "target": 2, "target_date": "` + nextMonth + `"}`,
+			goalID:     -1,
+			statusCode: http.StatusNotFound,
+			errMsg:     []string{"goal not found"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			goal := createGoalDBTestHelper(t, db, user.ID, 3, today.AddDate(0, -2, 0), today.AddDate(0, -1, 0))
+			require.NoError(t, db.UpdateGoalStatus(context.Background(), database.UpdateGoalStatusParams{
+				Status:     StatusMissed,
+				StatusDate: goal.TargetDate,
+				ID:         goal.ID,
+			}))
+			goalID := goal.ID
+			if tc.goalID != 0 {
+				goalID = tc.goalID
+			}
+
+			req, err := http.NewRequest("PUT", "/test", strings.NewReader(tc.body))
+			require.NoError(t, err)
+			ctx := util.ContextWithUser(req.Context(), user.ID)
+			req = req.WithContext(util.ContextWithResourceID(ctx, goalID))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerUpdateGoal(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				for _, message := range tc.errMsg {
+					assert.Contains(t, rr.Body.String(), message)
+				}
+				return
+			}
+
+			var resParams GoalRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			assert.Equal(t, goal.ID, resParams.ID)
+			assert.Equal(t, StatusActive, resParams.Status)
+			assert.Empty(t, resParams.StatusDate)
+			assert.Equal(t, tc.expectedTarget, resParams.Target)
+			assert.Equal(t, tc.expectedNotes, resParams.Notes)
+			assert.Equal(t, nextMonth, resParams.TargetDate)
+		})
+	}
+}
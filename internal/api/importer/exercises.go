@@ -0,0 +1,107 @@
+package importer
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/CTSDM/gogym/internal/database"
+)
+
+// exercises of the catalogue suggested for every name that could not be matched
+const maxSuggestions = 5
+
+type exerciseSuggestion struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+}
+
+// catalogue finds the exercises of the catalogue by their normalized names
+type catalogue struct {
+	byName    map[string]database.Exercise
+	byID      map[int32]database.Exercise
+	exercises []database.Exercise
+}
+
+func newCatalogue(exercises []database.Exercise) catalogue {
+	c := catalogue{
+		byName:    make(map[string]database.Exercise, len(exercises)),
+		byID:      make(map[int32]database.Exercise, len(exercises)),
+		exercises: exercises,
+	}
+	for _, exercise := range exercises {
+		c.byName[normalize(exercise.Name)] = exercise
+		c.byID[exercise.ID] = exercise
+	}
+	return c
+}
+
+// match returns the exercise of the catalogue the name of the file refers to
+func (c catalogue) match(name string) (database.Exercise, bool) {
+	for _, candidate := range candidates(name) {
+		if exercise, ok := c.byName[candidate]; ok {
+			return exercise, true
+		}
+	}
+	return database.Exercise{}, false
+}
+
+// suggest returns the exercises sharing the most words with the name, the closest first
+func (c catalogue) suggest(name string) []exerciseSuggestion {
+	words := strings.Fields(normalize(name))
+	type scored struct {
+		exercise database.Exercise
+		score    int
+	}
+	matches := []scored{}
+	for _, exercise := range c.exercises {
+		score := 0
+		for _, word := range strings.Fields(normalize(exercise.Name)) {
+			if slices.Contains(words, word) {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, scored{exercise: exercise, score: score})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b scored) int {
+		if a.score != b.score {
+			return b.score - a.score
+		}
+		return strings.Compare(a.exercise.Name, b.exercise.Name)
+	})
+
+	suggestions := make([]exerciseSuggestion, 0, min(len(matches), maxSuggestions))
+	for _, m := range matches[:min(len(matches), maxSuggestions)] {
+		suggestions = append(suggestions, exerciseSuggestion{ID: m.exercise.ID, Name: m.exercise.Name})
+	}
+	return suggestions
+}
+
+// normalize lowercases the name and keeps its words only, e.g. "Bench Press (Barbell)" is "bench press barbell"
+func normalize(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// the apps write the equipment in parentheses after the name, e.g. "Squat (Barbell)"
+var equipmentPattern = regexp.MustCompile(`^(.*?)\s*\(([^)]*)\)\s*$`)
+
+// candidates returns the names the exercise may have in the catalogue, the most precise first:
+// the name itself, the name with the equipment first and the name without the equipment,
+// "Squat (Barbell)" may be "squat barbell", "barbell squat" or "squat"
+func candidates(name string) []string {
+	names := []string{normalize(name)}
+	if match := equipmentPattern.FindStringSubmatch(name); match != nil {
+		base, equipment := normalize(match[1]), normalize(match[2])
+		if equipment != "" {
+			names = append(names, equipment+" "+base)
+		}
+		names = append(names, base)
+	}
+	return names
+}
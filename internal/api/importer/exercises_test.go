@@ -0,0 +1,54 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogueMatch(t *testing.T) {
+	c := newCatalogue([]database.Exercise{
+		{ID: 1, Name: "squat"},
+		{ID: 2, Name: "bench press"},
+		{ID: 3, Name: "dumbbell bench press"},
+		{ID: 4, Name: "pull-up"},
+		{ID: 5, Name: "leg press"},
+	})
+
+	testCases := []struct {
+		name     string
+		expected int32 // zero when not matched
+	}{
+		{name: "Squat", expected: 1},
+		{name: "Squat (Barbell)", expected: 1},
+		{name: "Bench Press (Dumbbell)", expected: 3},
+		{name: "Bench Press (Barbell)", expected: 2},
+		{name: "Pull Up", expected: 4},
+		{name: "  LEG   press ", expected: 5},
+		{name: "Romanian Deadlift (Barbell)"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exercise, ok := c.match(tc.name)
+			assert.Equal(t, tc.expected != 0, ok)
+			assert.Equal(t, tc.expected, exercise.ID)
+		})
+	}
+}
+
+func TestCatalogueSuggest(t *testing.T) {
+	c := newCatalogue([]database.Exercise{
+		{ID: 1, Name: "squat"},
+		{ID: 2, Name: "bench press"},
+		{ID: 3, Name: "dumbbell bench press"},
+		{ID: 4, Name: "leg press"},
+	})
+
+	assert.Equal(t, []exerciseSuggestion{
+		{ID: 3, Name: "dumbbell bench press"},
+		{ID: 2, Name: "bench press"},
+		{ID: 4, Name: "leg press"},
+	}, c.suggest("Incline Bench Press (Dumbbell)"))
+	assert.Empty(t, c.suggest("Running"))
+}
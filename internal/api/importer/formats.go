@@ -0,0 +1,406 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+)
+
+// apps whose CSV exports can be imported, the format is detected from the header
+const (
+	FormatStrong   = "strong"
+	FormatHevy     = "hevy"
+	FormatFitNotes = "fitnotes"
+)
+
+var ErrUnknownFormat = errors.New("unknown format: the file must be a Strong, Hevy or FitNotes CSV export")
+
+// name of the sessions of the formats without one
+const defaultSessionName = "Imported workout"
+
+// rows beyond these bounds are mistakes of the file, the weight is in the unit of the file
+const (
+	maxReps   = 1000
+	maxWeight = 2000
+)
+
+type parsedLog struct {
+	weight  float64
+	unit    string // empty when the file does not tell it, the weight is in the unit of the request
+	reps    int32
+	rpe     float64 // zero when unknown
+	failure bool
+	notes   string
+}
+
+// consecutive rows of the same exercise and set type of a session are the logs of a set
+type parsedSet struct {
+	exercise string // name of the exercise in the file
+	setType  string
+	logs     []parsedLog
+}
+
+type parsedSession struct {
+	name     string
+	date     time.Time
+	start    time.Time // zero when unknown
+	duration int       // minutes, zero when unknown
+	notes    string
+	sets     []parsedSet
+}
+
+type parsedFile struct {
+	format   string
+	sessions []parsedSession
+	skipped  int // rows without reps, e.g. cardio or rest timers
+}
+
+// row is a line of the file, the apps export one per performed set
+type row struct {
+	session  string // rows of the same session share the key
+	name     string
+	date     time.Time
+	start    time.Time
+	duration int
+	notes    string
+	exercise string
+	setType  string
+	log      parsedLog
+}
+
+// errSkipRow is returned by the parsers for the rows that are not weight training sets
+var errSkipRow = errors.New("skip row")
+
+// columns gets the value of a column of the current record by its lowercased name
+type columns func(name string) string
+
+type rowParser func(get columns) (row, error)
+
+// parse detects the format of the CSV export and groups its rows into sessions
+func parse(r io.Reader) (parsedFile, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return parsedFile{}, fmt.Errorf("could not read the file: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	// exports of some locales are separated by semicolons
+	reader := csv.NewReader(bytes.NewReader(data))
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return parsedFile{}, errors.New("the file is empty")
+	} else if err != nil {
+		return parsedFile{}, fmt.Errorf("could not read the header: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	format, parser, err := detect(index)
+	if err != nil {
+		return parsedFile{}, err
+	}
+	file := parsedFile{format: format}
+	rows := []row{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return parsedFile{}, err
+		}
+		line, _ := reader.FieldPos(0)
+		get := func(name string) string {
+			i, ok := index[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		parsed, err := parser(get)
+		if errors.Is(err, errSkipRow) {
+			file.skipped++
+			continue
+		} else if err != nil {
+			return parsedFile{}, fmt.Errorf("line %d: %w", line, err)
+		}
+		rows = append(rows, parsed)
+	}
+
+	file.sessions = group(rows)
+	return file, nil
+}
+
+func detect(index map[string]int) (string, rowParser, error) {
+	has := func(names ...string) bool {
+		for _, name := range names {
+			if _, ok := index[name]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch {
+	case has("title", "start_time", "exercise_title", "reps"):
+		return FormatHevy, parseHevyRow, nil
+	case has("date", "workout name", "exercise name", "set order", "reps"):
+		return FormatStrong, parseStrongRow, nil
+	case has("date", "exercise", "category", "reps"):
+		return FormatFitNotes, parseFitNotesRow, nil
+	}
+	return "", nil, ErrUnknownFormat
+}
+
+// group builds the sessions in the order they appear in the file
+func group(rows []row) []parsedSession {
+	sessions := []parsedSession{}
+	index := map[string]int{}
+	for _, r := range rows {
+		i, ok := index[r.session]
+		if !ok {
+			i = len(sessions)
+			index[r.session] = i
+			sessions = append(sessions, parsedSession{
+				name:     r.name,
+				date:     r.date,
+				start:    r.start,
+				duration: r.duration,
+				notes:    r.notes,
+			})
+		}
+
+		session := &sessions[i]
+		if last := len(session.sets) - 1; last >= 0 &&
+			session.sets[last].exercise == r.exercise && session.sets[last].setType == r.setType {
+			session.sets[last].logs = append(session.sets[last].logs, r.log)
+			continue
+		}
+		session.sets = append(session.sets, parsedSet{
+			exercise: r.exercise,
+			setType:  r.setType,
+			logs:     []parsedLog{r.log},
+		})
+	}
+	return sessions
+}
+
+// Strong: one row per set with the date and time the workout started,
+// the set order is a number or a letter for warm-up (W), drop (D) and failure (F) sets
+func parseStrongRow(get columns) (row, error) {
+	setType, failure := "working", false
+	switch order := strings.ToUpper(get("set order")); order {
+	case "W":
+		setType = "warm_up"
+	case "D":
+		setType = "drop"
+	case "F":
+		failure = true
+	default:
+		if _, err := strconv.Atoi(order); err != nil {
+			return row{}, errSkipRow
+		}
+	}
+	log, err := parseLog(get("weight"), get("reps"), get("rpe"))
+	if err != nil {
+		return row{}, err
+	}
+	log.failure = failure
+	log.notes = get("notes")
+	switch strings.ToLower(get("weight unit")) {
+	case "kg", "kgs":
+		log.unit = units.KG
+	case "lb", "lbs":
+		log.unit = units.LB
+	}
+
+	start, err := time.Parse("2006-01-02 15:04:05", get("date"))
+	if err != nil {
+		return row{}, fmt.Errorf("invalid date %q", get("date"))
+	}
+	duration := get("duration")
+	if duration == "" {
+		duration = get("workout duration")
+	}
+	return row{
+		session:  get("date") + get("workout name"),
+		name:     get("workout name"),
+		date:     day(start),
+		start:    start,
+		duration: parseStrongDuration(duration),
+		notes:    get("workout notes"),
+		exercise: get("exercise name"),
+		setType:  setType,
+		log:      log,
+	}, nil
+}
+
+var strongDurationPattern = regexp.MustCompile(`^(?:(\d+)h)?\s*(?:(\d+)m)?\s*(?:(\d+)s)?$`)
+
+// parseStrongDuration returns the minutes of durations like "1h 5m", zero when unknown
+func parseStrongDuration(value string) int {
+	match := strongDurationPattern.FindStringSubmatch(value)
+	if value == "" || match == nil {
+		return 0
+	}
+	hours, _ := strconv.Atoi(match[1])
+	minutes, _ := strconv.Atoi(match[2])
+	return hours*60 + minutes
+}
+
+// layouts of the start and end times of the Hevy exports
+var hevyLayouts = []string{"2 Jan 2006, 15:04", "2006-01-02 15:04:05", time.RFC3339}
+
+// Hevy: one row per set with the start and end times of the workout and the weight in the column name
+func parseHevyRow(get columns) (row, error) {
+	setType, failure := "working", false
+	switch get("set_type") {
+	case "warmup":
+		setType = "warm_up"
+	case "dropset":
+		setType = "drop"
+	case "failure":
+		failure = true
+	}
+
+	weight, unit := get("weight_kg"), units.KG
+	if weight == "" && get("weight_lbs") != "" {
+		weight, unit = get("weight_lbs"), units.LB
+	}
+	log, err := parseLog(weight, get("reps"), get("rpe"))
+	if err != nil {
+		return row{}, err
+	}
+	log.unit = unit
+	log.failure = failure
+
+	start, ok := parseTime(get("start_time"), hevyLayouts)
+	if !ok {
+		return row{}, fmt.Errorf("invalid start_time %q", get("start_time"))
+	}
+	duration := 0
+	if end, ok := parseTime(get("end_time"), hevyLayouts); ok && end.After(start) {
+		duration = int(end.Sub(start).Minutes())
+	}
+	return row{
+		session:  get("start_time") + get("title"),
+		name:     get("title"),
+		date:     day(start),
+		start:    start,
+		duration: duration,
+		notes:    get("description"),
+		exercise: get("exercise_title"),
+		setType:  setType,
+		log:      log,
+	}, nil
+}
+
+// FitNotes: one row per set with the day of the workout, which has no name, and the weight in the column name
+func parseFitNotesRow(get columns) (row, error) {
+	weight, unit := get("weight (kgs)"), units.KG
+	if weight == "" && get("weight (lbs)") != "" {
+		weight, unit = get("weight (lbs)"), units.LB
+	}
+	log, err := parseLog(weight, get("reps"), "")
+	if err != nil {
+		return row{}, err
+	}
+	log.unit = unit
+	log.notes = get("comment")
+
+	date, err := time.Parse(apiconstants.DATE_LAYOUT, get("date"))
+	if err != nil {
+		return row{}, fmt.Errorf("invalid date %q", get("date"))
+	}
+	return row{
+		session:  get("date"),
+		name:     defaultSessionName,
+		date:     date,
+		exercise: get("exercise"),
+		setType:  "working",
+		log:      log,
+	}, nil
+}
+
+// parseLog parses the weight, reps and RPE of a set. Sets without reps (cardio, timed holds)
+// and assisted sets, with negative weights, are skipped.
+func parseLog(weight, reps, rpe string) (parsedLog, error) {
+	log := parsedLog{}
+	if reps == "" {
+		return log, errSkipRow
+	}
+	parsedReps, err := parseNumber(reps)
+	if err != nil || parsedReps != math.Trunc(parsedReps) || parsedReps > maxReps {
+		return log, fmt.Errorf("invalid reps %q", reps)
+	}
+	if parsedReps < 1 {
+		return log, errSkipRow
+	}
+	log.reps = int32(parsedReps)
+
+	// bodyweight sets have no weight
+	if weight != "" {
+		log.weight, err = parseNumber(weight)
+		if err != nil || log.weight > maxWeight {
+			return log, fmt.Errorf("invalid weight %q", weight)
+		}
+		if log.weight < 0 {
+			return log, errSkipRow
+		}
+	}
+
+	// RPEs out of range are dropped instead of failing the whole import
+	if rpe != "" {
+		parsed, err := parseNumber(rpe)
+		if err == nil && parsed >= apiconstants.MinRPE && parsed <= apiconstants.MaxRPE {
+			log.rpe = parsed
+		}
+	}
+	return log, nil
+}
+
+// parseNumber accepts decimal commas, used by the exports of some locales. NaN and infinities are not numbers of a workout
+func parseNumber(value string) (float64, error) {
+	if !strings.Contains(value, ".") {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+		return 0, fmt.Errorf("%q is not a finite number", value)
+	}
+	return parsed, nil
+}
+
+func parseTime(value string, layouts []string) (time.Time, bool) {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
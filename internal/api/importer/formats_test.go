@@ -0,0 +1,206 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const strongCSV = `Date,Workout Name,Duration,Exercise Name,Set Order,Weight,Reps,Distance,Seconds,Notes,Workout Notes,RPE
+2025-03-01 09:30:00,Legs,1h 5m,Squat (Barbell),W,60,5,0,0,,felt strong,
+2025-03-01 09:30:00,Legs,1h 5m,Squat (Barbell),1,100,5,0,0,,felt strong,8
+2025-03-01 09:30:00,Legs,1h 5m,Squat (Barbell),2,100,5,0,0,,felt strong,12
+2025-03-01 09:30:00,Legs,1h 5m,Squat (Barbell),Rest Timer,0,0,0,90,,,
+2025-03-01 09:30:00,Legs,1h 5m,Running,1,0,0,5,1800,,,
+2025-03-01 09:30:00,Legs,1h 5m,Leg Press,F,200,12,0,0,last one,,
+2025-03-03 18:00:00,Push,45m,Bench Press (Barbell),1,"80,5",8,0,0,,,
+`
+
+const hevyCSV = `"title","start_time","end_time","description","exercise_title","superset_id","exercise_notes","set_index","set_type","weight_kg","reps","distance_km","duration_seconds","rpe"
+"Pull","5 Mar 2025, 07:00","5 Mar 2025, 08:10","back day","Pull Up","","",0,"warmup",,5,,,
+"Pull","5 Mar 2025, 07:00","5 Mar 2025, 08:10","back day","Pull Up","","",1,"normal",10,8,,,9
+"Pull","5 Mar 2025, 07:00","5 Mar 2025, 08:10","back day","Pull Up","","",2,"failure",10,6,,,
+"Pull","5 Mar 2025, 07:00","5 Mar 2025, 08:10","back day","Pull Up","","",3,"dropset",0,4,,,
+`
+
+const fitNotesCSV = `Date;Exercise;Category;Weight (lbs);Reps;Distance;Distance Unit;Time;Comment
+2025-03-07;Deadlift;Back;315;5;;;;
+2025-03-07;Deadlift;Back;315;5;;;;grip failed
+2025-03-07;Treadmill;Cardio;;;2;mi;00:20:00;
+2025-03-08;Deadlift;Back;225;8;;;;
+`
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name     string
+		file     string
+		format   string
+		skipped  int
+		expected []parsedSession
+		errMsg   string
+	}{
+		{
+			name:    "strong",
+			file:    "\xef\xbb\xbf" + strongCSV,
+			format:  FormatStrong,
+			skipped: 2,
+			expected: []parsedSession{
+				{
+					name:     "Legs",
+					date:     time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+					start:    time.Date(2025, time.March, 1, 9, 30, 0, 0, time.UTC),
+					duration: 65,
+					notes:    "felt strong",
+					sets: []parsedSet{
+						{exercise: "Squat (Barbell)", setType: "warm_up", logs: []parsedLog{{weight: 60, reps: 5}}},
+						{exercise: "Squat (Barbell)", setType: "working", logs: []parsedLog{
+							{weight: 100, reps: 5, rpe: 8},
+							{weight: 100, reps: 5},
+						}},
+						{exercise: "Leg Press", setType: "working", logs: []parsedLog{
+							{weight: 200, reps: 12, failure: true, notes: "last one"},
+						}},
+					},
+				},
+				{
+					name:     "Push",
+					date:     time.Date(2025, time.March, 3, 0, 0, 0, 0, time.UTC),
+					start:    time.Date(2025, time.March, 3, 18, 0, 0, 0, time.UTC),
+					duration: 45,
+					sets: []parsedSet{
+						{exercise: "Bench Press (Barbell)", setType: "working", logs: []parsedLog{{weight: 80.5, reps: 8}}},
+					},
+				},
+			},
+		},
+		{
+			name:   "hevy",
+			file:   hevyCSV,
+			format: FormatHevy,
+			expected: []parsedSession{
+				{
+					name:     "Pull",
+					date:     time.Date(2025, time.March, 5, 0, 0, 0, 0, time.UTC),
+					start:    time.Date(2025, time.March, 5, 7, 0, 0, 0, time.UTC),
+					duration: 70,
+					notes:    "back day",
+					sets: []parsedSet{
+						{exercise: "Pull Up", setType: "warm_up", logs: []parsedLog{{unit: units.KG, reps: 5}}},
+						{exercise: "Pull Up", setType: "working", logs: []parsedLog{
+							{weight: 10, unit: units.KG, reps: 8, rpe: 9},
+							{weight: 10, unit: units.KG, reps: 6, failure: true},
+						}},
+						{exercise: "Pull Up", setType: "drop", logs: []parsedLog{{unit: units.KG, reps: 4}}},
+					},
+				},
+			},
+		},
+		{
+			name:    "fitnotes separated by semicolons",
+			file:    fitNotesCSV,
+			format:  FormatFitNotes,
+			skipped: 1,
+			expected: []parsedSession{
+				{
+					name: defaultSessionName,
+					date: time.Date(2025, time.March, 7, 0, 0, 0, 0, time.UTC),
+					sets: []parsedSet{
+						{exercise: "Deadlift", setType: "working", logs: []parsedLog{
+							{weight: 315, unit: units.LB, reps: 5},
+							{weight: 315, unit: units.LB, reps: 5, notes: "grip failed"},
+						}},
+					},
+				},
+				{
+					name: defaultSessionName,
+					date: time.Date(2025, time.March, 8, 0, 0, 0, 0, time.UTC),
+					sets: []parsedSet{
+						{exercise: "Deadlift", setType: "working", logs: []parsedLog{{weight: 225, unit: units.LB, reps: 8}}},
+					},
+				},
+			},
+		},
+		{
+			name:   "unknown format",
+			file:   "date,lift,kg\n2025-03-01,squat,100\n",
+			errMsg: "unknown format",
+		},
+		{
+			name:   "empty file",
+			file:   "",
+			errMsg: "empty",
+		},
+		{
+			name:   "invalid date",
+			file:   "Date,Exercise,Category,Weight (kgs),Reps\n01/03/2025,Squat,Legs,100,5\n",
+			errMsg: "line 2: invalid date",
+		},
+		{
+			name:   "invalid reps",
+			file:   "Date,Exercise,Category,Weight (kgs),Reps\n2025-03-01,Squat,Legs,100,five\n",
+			errMsg: "invalid reps",
+		},
+		{
+			name:   "infinite weight",
+			file:   "Date,Exercise,Category,Weight (kgs),Reps\n2025-03-01,Squat,Legs,inf,5\n",
+			errMsg: "invalid weight",
+		},
+		{
+			name:   "not a number weight",
+			file:   "Date,Exercise,Category,Weight (kgs),Reps\n2025-03-01,Squat,Legs,NaN,5\n",
+			errMsg: "invalid weight",
+		},
+		{
+			name:   "weight out of range",
+			file:   "Date,Exercise,Category,Weight (kgs),Reps\n2025-03-01,Squat,Legs,1e10,5\n",
+			errMsg: "invalid weight",
+		},
+		{
+			name:   "infinite reps",
+			file:   "Date,Exercise,Category,Weight (kgs),Reps\n2025-03-01,Squat,Legs,100,Inf\n",
+			errMsg: "invalid reps",
+		},
+		{
+			name:   "reps out of range",
+			file:   "Date,Exercise,Category,Weight (kgs),Reps\n2025-03-01,Squat,Legs,100,1e10\n",
+			errMsg: "invalid reps",
+		},
+		{
+			name:   "fractional reps",
+			file:   "Date,Exercise,Category,Weight (kgs),Reps\n2025-03-01,Squat,Legs,100,5.7\n",
+			errMsg: "invalid reps",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			file, err := parse(strings.NewReader(tc.file))
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.format, file.format)
+			assert.Equal(t, tc.skipped, file.skipped)
+			assert.Equal(t, tc.expected, file.sessions)
+		})
+	}
+}
+
+func TestParseStrongDuration(t *testing.T) {
+	for value, expected := range map[string]int{
+		"1h 5m":    65,
+		"45m":      45,
+		"2h":       120,
+		"1h 2m 3s": 62,
+		"":         0,
+		"unknown":  0,
+	} {
+		assert.Equal(t, expected, parseStrongDuration(value), value)
+	}
+}
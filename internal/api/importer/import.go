@@ -0,0 +1,373 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/record"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// size limit of the uploaded file, years of history of the apps take a few megabytes
+const maxImportSize = 10 << 20
+
+type unmatchedExercise struct {
+	Name        string               `json:"name"`
+	Sets        int                  `json:"sets"`
+	Suggestions []exerciseSuggestion `json:"suggestions"`
+}
+
+type duplicateSession struct {
+	Date string `json:"date"`
+	Name string `json:"name"`
+}
+
+// On dry runs and when exercises are unmatched the counts are what the import would create.
+// Unmatched exercises are mapped with the exercise_map form value, e.g. {"Squat (Smith)": 12}.
+type importRes struct {
+	Format      string              `json:"format"`
+	DryRun      bool                `json:"dry_run"`
+	Sessions    int                 `json:"sessions"`
+	Sets        int                 `json:"sets"`
+	Logs        int                 `json:"logs"`
+	SkippedRows int                 `json:"skipped_rows"`
+	Exercises   map[string]int32    `json:"exercises"` // exercise of the catalogue of every matched name of the file
+	Unmatched   []unmatchedExercise `json:"unmatched"`
+	Duplicates  []duplicateSession  `json:"duplicates"` // sessions already recorded, they are not imported again
+	SessionIDs  []uuid.UUID         `json:"session_ids,omitempty"`
+}
+
+// HandlerImport imports the workout history exported by Strong, Hevy or FitNotes as CSV.
+// The file is uploaded in the file field of a multipart form, the format is detected from its header.
+// Nothing is created when some exercises can not be matched to the catalogue, the response lists them
+// with suggestions so they can be mapped with exercise_map. With dry_run=true it only reports the import.
+func HandlerImport(pool *pgxpool.Pool, db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("import failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		problems := map[string]string{}
+		dryRun := false
+		if value := r.URL.Query().Get("dry_run"); value != "" {
+			var err error
+			if dryRun, err = strconv.ParseBool(value); err != nil {
+				problems["dry_run"] = "invalid dry_run: dry_run must be true or false"
+			}
+		}
+
+		// weights of the files without unit are read in the unit of the request
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "import")
+		if !ok {
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+		if err := r.ParseMultipartForm(maxImportSize); err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				reqLogger.Debug("import failed - file too large")
+				util.RespondWithError(w, r, http.StatusRequestEntityTooLarge,
+					fmt.Sprintf("file too large: the limit is %d MB", maxImportSize>>20), err)
+				return
+			}
+			reqLogger.Debug("import failed - invalid payload", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusBadRequest, "invalid payload: a multipart form is expected", err)
+			return
+		}
+
+		exerciseMap := map[string]int32{}
+		if value := r.FormValue("exercise_map"); value != "" {
+			if err := json.Unmarshal([]byte(value), &exerciseMap); err != nil {
+				problems["exercise_map"] = "invalid exercise_map: exercise_map must map exercise names to exercise ids"
+			}
+		}
+
+		var file parsedFile
+		upload, _, err := r.FormFile("file")
+		if err != nil {
+			problems["file"] = "invalid file: file is required"
+		} else {
+			defer upload.Close()
+			file, err = parse(upload)
+			if err != nil {
+				problems["file"] = "invalid file: " + err.Error()
+			} else if len(file.sessions) == 0 {
+				problems["file"] = "invalid file: the file has no sets"
+			}
+		}
+
+		if len(problems) > 0 {
+			reqLogger.Debug("import failed - validation failed", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("format", file.format))
+
+		exercises, err := db.GetExercises(r.Context())
+		if err != nil {
+			reqLogger.Error("import failed - get exercises database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		catalogue := newCatalogue(exercises)
+		for name, id := range exerciseMap {
+			if _, ok := catalogue.byID[id]; !ok {
+				problems["exercise_map"] = fmt.Sprintf("invalid exercise_map: exercise %d of %q not found", id, name)
+			}
+		}
+		if len(problems) > 0 {
+			reqLogger.Debug("import failed - validation failed", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		}
+
+		// sessions already recorded on the same day with the same name are not imported again
+		duplicates, err := findDuplicates(r.Context(), db, userID, file.sessions)
+		if err != nil {
+			reqLogger.Error("import failed - get sessions database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		res := importRes{
+			Format:      file.format,
+			DryRun:      dryRun,
+			SkippedRows: file.skipped,
+			Exercises:   map[string]int32{},
+			Unmatched:   []unmatchedExercise{},
+			Duplicates:  []duplicateSession{},
+		}
+		sessions := []parsedSession{}
+		for _, session := range file.sessions {
+			if duplicates[duplicateKey(session.date, session.name)] {
+				res.Duplicates = append(res.Duplicates, duplicateSession{
+					Date: session.date.Format(apiconstants.DATE_LAYOUT),
+					Name: session.name,
+				})
+				continue
+			}
+			sessions = append(sessions, session)
+			res.Sessions++
+			for _, set := range session.sets {
+				res.Sets++
+				res.Logs += len(set.logs)
+			}
+		}
+		matchExercises(&res, sessions, catalogue, exerciseMap)
+
+		if len(res.Unmatched) > 0 && !dryRun {
+			reqLogger.Debug("import failed - unmatched exercises", slog.Int("unmatched", len(res.Unmatched)))
+			util.RespondWithJSON(w, r, http.StatusUnprocessableEntity, res)
+			return
+		}
+		if dryRun {
+			reqLogger.Info("import dry run success", slog.Int("sessions", res.Sessions), slog.Int("logs", res.Logs))
+			util.RespondWithJSON(w, r, http.StatusOK, res)
+			return
+		}
+
+		tx, err := pool.Begin(r.Context())
+		if err != nil {
+			reqLogger.Error("import failed - transaction start error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		defer tx.Rollback(r.Context())
+
+		res.SessionIDs, err = importSessions(r.Context(), db.WithTx(tx), userID, sessions, res.Exercises, unit)
+		if err != nil {
+			reqLogger.Error("import failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		if err := tx.Commit(r.Context()); err != nil {
+			reqLogger.Error("import failed - transaction commit error", slog.String("error", err.Error()))
+			err = fmt.Errorf("could not commit the transaction: %w", err)
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		reqLogger.Info("import success",
+			slog.Int("sessions", res.Sessions),
+			slog.Int("logs", res.Logs),
+			slog.Int("duplicates", len(res.Duplicates)),
+		)
+		util.RespondWithJSON(w, r, http.StatusCreated, res)
+	}
+}
+
+// matchExercises maps every exercise of the sessions to the catalogue, the exercise map takes precedence
+func matchExercises(res *importRes, sessions []parsedSession, catalogue catalogue, exerciseMap map[string]int32) {
+	unmatched := map[string]int{}
+	for _, session := range sessions {
+		for _, set := range session.sets {
+			if _, ok := res.Exercises[set.exercise]; ok {
+				continue
+			}
+			if id, ok := exerciseMap[set.exercise]; ok {
+				res.Exercises[set.exercise] = id
+				continue
+			}
+			if exercise, ok := catalogue.match(set.exercise); ok {
+				res.Exercises[set.exercise] = exercise.ID
+				continue
+			}
+			if _, ok := unmatched[set.exercise]; !ok {
+				unmatched[set.exercise] = len(res.Unmatched)
+				res.Unmatched = append(res.Unmatched, unmatchedExercise{
+					Name:        set.exercise,
+					Suggestions: catalogue.suggest(set.exercise),
+				})
+			}
+			res.Unmatched[unmatched[set.exercise]].Sets++
+		}
+	}
+}
+
+func duplicateKey(date time.Time, name string) string {
+	return date.Format(apiconstants.DATE_LAYOUT) + strings.ToLower(strings.TrimSpace(name))
+}
+
+// findDuplicates returns the keys of the sessions the user recorded between the first and last days of the file
+func findDuplicates(ctx context.Context, db *database.Queries, userID uuid.UUID, sessions []parsedSession) (map[string]bool, error) {
+	from, to := sessions[0].date, sessions[0].date
+	for _, session := range sessions[1:] {
+		if session.date.Before(from) {
+			from = session.date
+		}
+		if session.date.After(to) {
+			to = session.date
+		}
+	}
+
+	recorded, err := db.GetSessionNamesByDates(ctx, database.GetSessionNamesByDatesParams{
+		UserID:   userID,
+		FromDate: pgtype.Date{Time: from, Valid: true},
+		ToDate:   pgtype.Date{Time: to, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	duplicates := make(map[string]bool, len(recorded))
+	for _, session := range recorded {
+		duplicates[duplicateKey(session.Date.Time, session.Name)] = true
+	}
+	return duplicates, nil
+}
+
+// importSessions creates the sessions with their sets and logs in chronological order,
+// then detects the personal records of the logs in that order, as they were set
+func importSessions(
+	ctx context.Context,
+	q *database.Queries,
+	userID uuid.UUID,
+	sessions []parsedSession,
+	exerciseIDs map[string]int32,
+	unit string,
+) ([]uuid.UUID, error) {
+	sessions = slices.Clone(sessions)
+	slices.SortStableFunc(sessions, func(a, b parsedSession) int {
+		if c := a.date.Compare(b.date); c != 0 {
+			return c
+		}
+		return a.start.Compare(b.start)
+	})
+
+	ids := make([]uuid.UUID, 0, len(sessions))
+	logs := []record.BatchLog{}
+	for _, s := range sessions {
+		name := truncate(strings.TrimSpace(s.name), apiconstants.MaxSessionNameLength)
+		if name == "" {
+			name = defaultSessionName
+		}
+		session, err := q.CreateSession(ctx, database.CreateSessionParams{
+			Name:            name,
+			Date:            pgtype.Date{Time: s.date, Valid: true},
+			StartTimestamp:  pgtype.Timestamp{Time: s.start, Valid: !s.start.IsZero()},
+			DurationMinutes: pgtype.Int2{Int16: int16(s.duration), Valid: s.duration > 0 && s.duration <= math.MaxInt16},
+			UserID:          userID,
+			Tags:            []string{},
+			Notes:           notes(s.notes),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create session: %w", err)
+		}
+		ids = append(ids, session.ID)
+
+		for i, s := range s.sets {
+			set, err := q.CreateSet(ctx, database.CreateSetParams{
+				SetOrder:   int32(i + 1),
+				SessionID:  session.ID,
+				ExerciseID: exerciseIDs[s.exercise],
+				SetType:    s.setType,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("create set: %w", err)
+			}
+
+			for j, l := range s.logs {
+				enteredUnit := l.unit
+				if enteredUnit == "" {
+					enteredUnit = unit
+				}
+				log, err := q.CreateLog(ctx, database.CreateLogParams{
+					Weight:         pgtype.Float8{Float64: units.ToKG(l.weight, enteredUnit), Valid: true},
+					Reps:           l.reps,
+					LogsOrder:      int32(j + 1),
+					ExerciseID:     set.ExerciseID,
+					SetID:          set.ID,
+					Rpe:            pgtype.Float8{Float64: l.rpe, Valid: l.rpe != 0},
+					ReachedFailure: l.failure,
+					Notes:          notes(l.notes),
+					WeightUnit:     enteredUnit,
+				})
+				if err != nil {
+					return nil, fmt.Errorf("create log: %w", err)
+				}
+				logs = append(logs, record.BatchLog{Log: log, SessionID: session.ID, SetType: set.SetType})
+			}
+		}
+	}
+
+	if _, err := record.DetectBatch(ctx, q, userID, logs); err != nil {
+		return nil, fmt.Errorf("detect records: %w", err)
+	}
+	return ids, nil
+}
+
+func notes(value string) pgtype.Text {
+	value = truncate(strings.TrimSpace(value), apiconstants.MaxNotesLength)
+	return pgtype.Text{String: value, Valid: value != ""}
+}
+
+// truncate cuts the value to max characters, the apps have no limits on names and notes
+func truncate(value string, max int) string {
+	if utf8.RuneCountInString(value) <= max {
+		return value
+	}
+	return string([]rune(value)[:max])
+}
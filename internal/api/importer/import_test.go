@@ -0,0 +1,209 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/record"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const importCSV = `Date,Workout Name,Duration,Exercise Name,Set Order,Weight,Reps,Notes,Workout Notes,RPE
+2025-03-01 09:30:00,Legs,1h,Squat (Barbell),W,60,5,,,
+2025-03-01 09:30:00,Legs,1h,Squat (Barbell),1,100,5,,,8
+2025-03-01 09:30:00,Legs,1h,Squat (Barbell),2,100,5,,,
+2025-03-03 18:00:00,Push,45m,Bench Press (Barbell),1,80,8,,,
+2025-03-03 18:00:00,Push,45m,Cable Fly,1,20,12,,,
+`
+
+func TestHandlerImport(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	squatID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+	benchID := testutil.CreateExerciseDBTestHelper(t, db, "bench press")
+	flyID := testutil.CreateExerciseDBTestHelper(t, db, "dumbbell fly")
+
+	testCases := []struct {
+		name        string
+		query       string
+		file        string
+		exerciseMap string
+		statusCode  int
+		errKeys     []string
+		expected    importRes
+	}{
+		{
+			name:       "unmatched exercises",
+			file:       importCSV,
+			statusCode: http.StatusUnprocessableEntity,
+			expected: importRes{
+				Format:     FormatStrong,
+				Sessions:   2,
+				Sets:       4,
+				Logs:       5,
+				Exercises:  map[string]int32{"Squat (Barbell)": squatID, "Bench Press (Barbell)": benchID},
+				Unmatched:  []unmatchedExercise{{Name: "Cable Fly", Sets: 1, Suggestions: []exerciseSuggestion{{ID: flyID, Name: "dumbbell fly"}}}},
+				Duplicates: []duplicateSession{},
+			},
+		},
+		{
+			name:        "happy path: dry run",
+			query:       "?dry_run=true",
+			file:        importCSV,
+			exerciseMap: fmt.Sprintf(`{"Cable Fly": %d}`, flyID),
+			statusCode:  http.StatusOK,
+			expected: importRes{
+				Format:     FormatStrong,
+				DryRun:     true,
+				Sessions:   2,
+				Sets:       4,
+				Logs:       5,
+				Exercises:  map[string]int32{"Squat (Barbell)": squatID, "Bench Press (Barbell)": benchID, "Cable Fly": flyID},
+				Unmatched:  []unmatchedExercise{},
+				Duplicates: []duplicateSession{},
+			},
+		},
+		{
+			name:        "happy path",
+			file:        importCSV,
+			exerciseMap: fmt.Sprintf(`{"Cable Fly": %d}`, flyID),
+			statusCode:  http.StatusCreated,
+			expected: importRes{
+				Format:     FormatStrong,
+				Sessions:   2,
+				Sets:       4,
+				Logs:       5,
+				Exercises:  map[string]int32{"Squat (Barbell)": squatID, "Bench Press (Barbell)": benchID, "Cable Fly": flyID},
+				Unmatched:  []unmatchedExercise{},
+				Duplicates: []duplicateSession{},
+			},
+		},
+		{
+			name:        "happy path: imported sessions are duplicates",
+			file:        importCSV,
+			exerciseMap: fmt.Sprintf(`{"Cable Fly": %d}`, flyID),
+			statusCode:  http.StatusCreated,
+			expected: importRes{
+				Format:     FormatStrong,
+				Exercises:  map[string]int32{},
+				Unmatched:  []unmatchedExercise{},
+				Duplicates: []duplicateSession{{Date: "2025-03-01", Name: "Legs"}, {Date: "2025-03-03", Name: "Push"}},
+			},
+		},
+		{
+			name:       "unknown format",
+			file:       "date,lift,kg\n2025-03-01,squat,100\n",
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"file"},
+		},
+		{
+			name:        "invalid exercise map",
+			file:        importCSV,
+			exerciseMap: `{"Cable Fly": 999999}`,
+			statusCode:  http.StatusBadRequest,
+			errKeys:     []string{"exercise_map"},
+		},
+		{
+			name:       "invalid dry run",
+			query:      "?dry_run=maybe",
+			file:       importCSV,
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"dry_run"},
+		},
+	}
+
+	importedIDs := []uuid.UUID{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			part, err := writer.CreateFormFile("file", "export.csv")
+			require.NoError(t, err)
+			_, err = part.Write([]byte(tc.file))
+			require.NoError(t, err)
+			if tc.exerciseMap != "" {
+				require.NoError(t, writer.WriteField("exercise_map", tc.exerciseMap))
+			}
+			require.NoError(t, writer.Close())
+
+			req, err := http.NewRequest("POST", "/test"+tc.query, body)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerImport(dbPool, db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode == http.StatusBadRequest {
+				for _, key := range tc.errKeys {
+					assert.Contains(t, rr.Body.String(), key)
+				}
+				return
+			}
+
+			var res importRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+			if tc.statusCode == http.StatusCreated {
+				require.Len(t, res.SessionIDs, tc.expected.Sessions)
+				importedIDs = append(importedIDs, res.SessionIDs...)
+			} else {
+				assert.Empty(t, res.SessionIDs)
+			}
+			res.SessionIDs = nil
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+
+	// the sets and logs keep the order, types and weights of the file
+	require.Len(t, importedIDs, 2)
+	sets, err := db.GetSetsBySessionIDs(context.Background(), importedIDs)
+	require.NoError(t, err)
+	require.Len(t, sets, 4)
+	setIDs := make([]int64, len(sets))
+	setTypes := make([]string, len(sets))
+	for i, s := range sets {
+		setIDs[i] = s.ID
+		setTypes[i] = s.SetType
+	}
+	assert.ElementsMatch(t, []string{"warm_up", "working", "working", "working"}, setTypes)
+	logs, err := db.GetLogsBySetIDs(context.Background(), setIDs)
+	require.NoError(t, err)
+	require.Len(t, logs, 5)
+	weights := make([]float64, len(logs))
+	for i, l := range logs {
+		weights[i] = l.Weight.Float64
+	}
+	assert.ElementsMatch(t, []float64{60, 100, 100, 80, 20}, weights)
+
+	// the records are detected once the logs are imported, the warm-up and the repeated set set none
+	records, err := db.GetCurrentPersonalRecords(context.Background(), database.GetCurrentPersonalRecordsParams{UserID: user.ID})
+	require.NoError(t, err)
+	squatRecords := map[string]float64{}
+	for _, r := range records {
+		if r.ExerciseID == squatID {
+			squatRecords[r.RecordType] = r.Value
+		}
+	}
+	assert.Len(t, records, 12)
+	assert.Equal(t, map[string]float64{
+		record.TypeMaxWeight:     100,
+		record.TypeMaxReps:       5,
+		record.TypeE1RM:          record.EstimateOneRepMax(100, 5),
+		record.TypeSessionVolume: 1000,
+	}, squatRecords)
+}
@@ -0,0 +1,133 @@
+package insight
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+const (
+	MetricE1RM   = "e1rm"    // best estimated one rep max of the day
+	MetricTopSet = "top_set" // weight of the best set of the day
+)
+
+var Metrics = []string{MetricE1RM, MetricTopSet}
+
+const (
+	StatusPlateau    = "plateau"
+	StatusRegression = "regression"
+)
+
+const (
+	SuggestionDeload         = "deload"
+	SuggestionChangeRepRange = "change_rep_range"
+	SuggestionSwapVariation  = "swap_variation"
+)
+
+const (
+	// training days of the exercise needed in each half of the window
+	minDaysPerHalf = 2
+	// the recent best must beat the previous one by this fraction to count as progress
+	minProgress = 0.01
+	// a recent best below the previous one by this fraction is a regression
+	regression = 0.05
+	// top sets within this many reps are considered the same rep range
+	narrowRepRange = 2
+)
+
+// point is the top set of an exercise in a training day
+type point struct {
+	Date   time.Time
+	Weight float64
+	Reps   int32
+	E1RM   float64
+}
+
+type analysis struct {
+	Status      string
+	Change      float64 // relative change of the recent best over the previous one
+	Baseline    float64 // best of the first half of the window
+	Recent      float64 // best of the second half of the window
+	Days        int
+	Suggestions []string
+}
+
+// analyze compares the best value of metric in the first and second halves of the window starting at from.
+// It returns false when there is not enough data or the exercise is progressing.
+func analyze(points []point, metric string, from time.Time, weeks int) (analysis, bool) {
+	middle := from.AddDate(0, 0, weeks*7/2)
+	first, second := []point{}, []point{}
+	for _, p := range points {
+		if p.Date.Before(middle) {
+			first = append(first, p)
+		} else {
+			second = append(second, p)
+		}
+	}
+	if len(first) < minDaysPerHalf || len(second) < minDaysPerHalf {
+		return analysis{}, false
+	}
+
+	res := analysis{
+		Baseline: best(first, metric),
+		Recent:   best(second, metric),
+		Days:     len(points),
+	}
+	if res.Baseline <= 0 {
+		return analysis{}, false
+	}
+	res.Change = (res.Recent - res.Baseline) / res.Baseline
+
+	switch {
+	case res.Change <= -regression:
+		// performance dropping is a sign of accumulated fatigue
+		res.Status = StatusRegression
+		res.Suggestions = []string{SuggestionDeload}
+	case res.Change < minProgress:
+		res.Status = StatusPlateau
+		// the same stimulus stopped working, vary the reps first and the exercise otherwise
+		if repRange(second) <= narrowRepRange {
+			res.Suggestions = []string{SuggestionChangeRepRange}
+		} else {
+			res.Suggestions = []string{SuggestionSwapVariation}
+		}
+	default:
+		return analysis{}, false
+	}
+	return res, true
+}
+
+func best(points []point, metric string) float64 {
+	value := 0.0
+	for _, p := range points {
+		if metric == MetricTopSet {
+			value = math.Max(value, p.Weight)
+		} else {
+			value = math.Max(value, p.E1RM)
+		}
+	}
+	return value
+}
+
+func repRange(points []point) int32 {
+	reps := make([]int32, len(points))
+	for i, p := range points {
+		reps[i] = p.Reps
+	}
+	return slices.Max(reps) - slices.Min(reps)
+}
+
+// message describes the analysis in a sentence for the user
+func message(name string, a analysis, weeks int) string {
+	trend := "stalled"
+	if a.Status == StatusRegression {
+		trend = "dropped"
+	}
+	advice := map[string]string{
+		SuggestionDeload:         "take a lighter week to recover",
+		SuggestionChangeRepRange: "try a different rep range",
+		SuggestionSwapVariation:  "try a variation of the exercise",
+	}[a.Suggestions[0]]
+	return fmt.Sprintf("%s has %s over the last %d weeks (%+.1f%%), %s", name, trend, weeks, a.Change*100, advice)
+}
@@ -0,0 +1,101 @@
+package insight
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// history builds a synthetic history with a top set every week from the start of the window
+func history(from time.Time, sets ...[2]float64) []point {
+	points := make([]point, len(sets))
+	for i, s := range sets {
+		e1rm, _ := stats.OneRepMax(stats.FormulaEpley, s[0], int32(s[1]), 0)
+		points[i] = point{
+			Date:   from.AddDate(0, 0, 7*i),
+			Weight: s[0],
+			Reps:   int32(s[1]),
+			E1RM:   e1rm,
+		}
+	}
+	return points
+}
+
+func TestAnalyze(t *testing.T) {
+	from := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	weeks := 6
+
+	testCases := []struct {
+		name                string
+		points              []point
+		metric              string
+		ok                  bool
+		expectedStatus      string
+		expectedSuggestions []string
+	}{
+		{
+			name:   "progressing",
+			points: history(from, [2]float64{100, 5}, [2]float64{102.5, 5}, [2]float64{105, 5}, [2]float64{107.5, 5}, [2]float64{110, 5}, [2]float64{112.5, 5}),
+			metric: MetricE1RM,
+		},
+		{
+			name:                "plateau in the same rep range",
+			points:              history(from, [2]float64{100, 5}, [2]float64{100, 5}, [2]float64{100, 5}, [2]float64{100, 5}, [2]float64{100, 4}, [2]float64{100, 5}),
+			metric:              MetricE1RM,
+			ok:                  true,
+			expectedStatus:      StatusPlateau,
+			expectedSuggestions: []string{SuggestionChangeRepRange},
+		},
+		{
+			name:                "plateau across rep ranges",
+			points:              history(from, [2]float64{100, 5}, [2]float64{100, 5}, [2]float64{100, 5}, [2]float64{90, 8}, [2]float64{80, 12}, [2]float64{95, 6}),
+			metric:              MetricE1RM,
+			ok:                  true,
+			expectedStatus:      StatusPlateau,
+			expectedSuggestions: []string{SuggestionSwapVariation},
+		},
+		{
+			name:                "regression",
+			points:              history(from, [2]float64{100, 5}, [2]float64{100, 5}, [2]float64{100, 5}, [2]float64{92.5, 5}, [2]float64{90, 5}, [2]float64{90, 5}),
+			metric:              MetricE1RM,
+			ok:                  true,
+			expectedStatus:      StatusRegression,
+			expectedSuggestions: []string{SuggestionDeload},
+		},
+		{
+			name:   "not enough training days",
+			points: history(from, [2]float64{100, 5}, [2]float64{0, 0}, [2]float64{0, 0}, [2]float64{90, 5}, [2]float64{90, 5})[3:],
+			metric: MetricE1RM,
+		},
+		{
+			name:   "more reps with the same weight is progress of the e1rm",
+			points: history(from, [2]float64{100, 5}, [2]float64{100, 5}, [2]float64{100, 5}, [2]float64{100, 6}, [2]float64{100, 7}, [2]float64{100, 8}),
+			metric: MetricE1RM,
+		},
+		{
+			name:                "more reps with the same weight is a plateau of the top set",
+			points:              history(from, [2]float64{100, 5}, [2]float64{100, 5}, [2]float64{100, 5}, [2]float64{100, 6}, [2]float64{100, 7}, [2]float64{100, 8}),
+			metric:              MetricTopSet,
+			ok:                  true,
+			expectedStatus:      StatusPlateau,
+			expectedSuggestions: []string{SuggestionChangeRepRange},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a, ok := analyze(tc.points, tc.metric, from, weeks)
+			require.Equal(t, tc.ok, ok)
+			if !tc.ok {
+				return
+			}
+			assert.Equal(t, tc.expectedStatus, a.Status)
+			assert.Equal(t, tc.expectedSuggestions, a.Suggestions)
+			assert.Equal(t, len(tc.points), a.Days)
+			assert.NotEmpty(t, message("squat", a, weeks))
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package insight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	defaultWeeks = 6
+	minWeeks     = 3
+	maxWeeks     = 52
+)
+
+type insightItem struct {
+	ExerciseID    int32    `json:"exercise_id"`
+	Name          string   `json:"name"`
+	Status        string   `json:"status"`
+	ChangePercent float64  `json:"change_percent"`
+	Baseline      float64  `json:"baseline"` // best of the first half of the window
+	Recent        float64  `json:"recent"`   // best of the second half of the window
+	Days          int      `json:"days"`     // training days of the exercise in the window
+	Suggestions   []string `json:"suggestions"`
+	Message       string   `json:"message"`
+}
+
+type insightsRes struct {
+	Metric     string        `json:"metric"`
+	Weeks      int           `json:"weeks"`
+	From       string        `json:"from"`
+	To         string        `json:"to"`
+	AnalyzedAt time.Time     `json:"analyzed_at"`
+	Unit       string        `json:"unit"`
+	Insights   []insightItem `json:"insights"`
+}
+
+// HandlerGetInsights returns the exercises that stalled or regressed in the window with suggestions to get them moving again.
+// The results of the analysis job are served when it analyzed the requested window, other windows are analyzed on demand.
+func HandlerGetInsights(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("get insights failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		// validate the query parameters
+		problems := map[string]string{}
+		query := r.URL.Query()
+		weeks := defaultWeeks
+		if query.Has("weeks") {
+			parsed, err := strconv.Atoi(query.Get("weeks"))
+			if err != nil || parsed < minWeeks || parsed > maxWeeks {
+				problems["weeks"] = fmt.Sprintf("invalid weeks: weeks must be between %d and %d", minWeeks, maxWeeks)
+			} else {
+				weeks = parsed
+			}
+		}
+		metric := MetricE1RM
+		if query.Has("metric") {
+			metric = strings.ToLower(query.Get("metric"))
+			if !slices.Contains(Metrics, metric) {
+				problems["metric"] = "invalid metric: metric must be one of " + strings.Join(Metrics, ", ")
+			}
+		}
+		if len(problems) > 0 {
+			reqLogger.Debug("get insights failed - validation failed", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		}
+
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "get insights")
+		if !ok {
+			return
+		}
+
+		// serve the results of the analysis job when it analyzed the requested window
+		run, err := db.GetInsightRun(r.Context(), userID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			reqLogger.Error("get insights failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		stored := err == nil && (!query.Has("weeks") || weeks == int(run.Weeks))
+
+		var resParams insightsRes
+		if stored {
+			resParams, err = storedInsights(r.Context(), db, userID, run, metric, unit)
+		} else {
+			resParams, err = analyzeInsights(r.Context(), db, userID, time.Now().UTC(), weeks, metric, unit)
+		}
+		if err != nil {
+			reqLogger.Error("get insights failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		reqLogger.Debug("get insights success", slog.Int("insights", len(resParams.Insights)), slog.Bool("stored", stored))
+		util.RespondWithJSON(w, r, http.StatusOK, resParams)
+	}
+}
+
+// storedInsights returns the insights of the metric found by the last run of the analysis job
+func storedInsights(ctx context.Context, db *database.Queries, userID uuid.UUID, run database.InsightRun, metric, unit string) (insightsRes, error) {
+	rows, err := db.GetInsights(ctx, database.GetInsightsParams{UserID: userID, Metric: metric})
+	if err != nil {
+		return insightsRes{}, err
+	}
+
+	weeks := int(run.Weeks)
+	res := newInsightsRes(metric, weeks, run.FromDate.Time, run.ToDate.Time, run.AnalyzedAt.Time, unit)
+	for _, row := range rows {
+		res.Insights = append(res.Insights, newInsightItem(exerciseAnalysis{
+			ExerciseID: row.ExerciseID,
+			Name:       row.Name,
+			analysis: analysis{
+				Status:      row.Status,
+				Change:      row.Change,
+				Baseline:    row.Baseline,
+				Recent:      row.Recent,
+				Days:        int(row.Days),
+				Suggestions: row.Suggestions,
+			},
+		}, weeks, unit))
+	}
+	return res, nil
+}
+
+// analyzeInsights analyzes the window of weeks ending on the day of now
+func analyzeInsights(ctx context.Context, db *database.Queries, userID uuid.UUID, now time.Time, weeks int, metric, unit string) (insightsRes, error) {
+	from, to := window(now, weeks)
+	topSets, err := db.GetDailyTopSets(ctx, database.GetDailyTopSetsParams{
+		UserID:   userID,
+		FromDate: pgtype.Date{Time: from, Valid: true},
+		ToDate:   pgtype.Date{Time: to, Valid: true},
+	})
+	if err != nil {
+		return insightsRes{}, err
+	}
+
+	res := newInsightsRes(metric, weeks, from, to, now, unit)
+	for _, a := range analyzeTopSets(topSets, metric, from, weeks) {
+		res.Insights = append(res.Insights, newInsightItem(a, weeks, unit))
+	}
+	return res, nil
+}
+
+func newInsightsRes(metric string, weeks int, from, to, analyzedAt time.Time, unit string) insightsRes {
+	return insightsRes{
+		Metric:     metric,
+		Weeks:      weeks,
+		From:       from.Format(apiconstants.DATE_LAYOUT),
+		To:         to.Format(apiconstants.DATE_LAYOUT),
+		AnalyzedAt: analyzedAt,
+		Unit:       unit,
+		Insights:   []insightItem{},
+	}
+}
+
+func newInsightItem(a exerciseAnalysis, weeks int, unit string) insightItem {
+	return insightItem{
+		ExerciseID:    a.ExerciseID,
+		Name:          a.Name,
+		Status:        a.Status,
+		ChangePercent: math.Round(a.Change*1000) / 10,
+		Baseline:      units.FromKG(a.Baseline, unit),
+		Recent:        units.FromKG(a.Recent, unit),
+		Days:          a.Days,
+		Suggestions:   a.Suggestions,
+		Message:       message(a.Name, a.analysis, weeks),
+	}
+}
@@ -0,0 +1,101 @@
+package insight
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerGetInsights(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	squatID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+	benchID := testutil.CreateExerciseDBTestHelper(t, db, "bench press")
+
+	// a session every week of the default window, the squat regresses while the bench press progresses
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1-defaultWeeks*7)
+	squats := []float64{100, 100, 100, 90, 90, 90}
+	benches := []float64{60, 62.5, 65, 67.5, 70, 72.5}
+	for i := range squats {
+		session, err := db.CreateSession(context.Background(), database.CreateSessionParams{
+			Name:   "full body",
+			Date:   pgtype.Date{Time: from.AddDate(0, 0, 7*i), Valid: true},
+			UserID: user.ID,
+		})
+		require.NoError(t, err)
+		setID := testutil.CreateSetDBTestHelper(t, db, session.ID, squatID)
+		testutil.CreateLogExerciseDBTestHelper(t, db, 5, 1, squatID, setID, squats[i])
+		setID = testutil.CreateSetDBTestHelper(t, db, session.ID, benchID)
+		testutil.CreateLogExerciseDBTestHelper(t, db, 5, 1, benchID, setID, benches[i])
+	}
+
+	testCases := []struct {
+		name       string
+		query      string
+		statusCode int
+		expected   []string // names of the exercises with insights
+		errKeys    []string
+	}{
+		{
+			name:       "happy path",
+			statusCode: http.StatusOK,
+			expected:   []string{"squat"},
+		},
+		{
+			name:       "happy path: not enough training days in a shorter window",
+			query:      "?weeks=3&metric=top_set",
+			statusCode: http.StatusOK,
+			expected:   []string{},
+		},
+		{
+			name:       "invalid parameters",
+			query:      "?weeks=1&metric=volume",
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"weeks", "metric"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/test"+tc.query, bytes.NewReader(nil))
+			require.NoError(t, err)
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerGetInsights(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				for _, key := range tc.errKeys {
+					assert.Contains(t, rr.Body.String(), key)
+				}
+				return
+			}
+
+			var resParams insightsRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			names := []string{}
+			for _, insight := range resParams.Insights {
+				names = append(names, insight.Name)
+				assert.Equal(t, StatusRegression, insight.Status)
+				assert.Equal(t, []string{SuggestionDeload}, insight.Suggestions)
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package insight
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// JobConfig sets the window of training the analysis job inspects and how often it runs
+type JobConfig struct {
+	Weeks    int
+	Interval time.Duration
+}
+
+// exerciseAnalysis is the analysis of the trend of an exercise
+type exerciseAnalysis struct {
+	ExerciseID int32
+	Name       string
+	analysis
+}
+
+// RunJob analyzes the trends of the users when it starts and then on every interval until the context is done.
+// The insights found replace the previous ones of the user and are served by HandlerGetInsights.
+func RunJob(ctx context.Context, pool *pgxpool.Pool, db *database.Queries, config JobConfig, logger *slog.Logger) {
+	logger = logger.With(slog.String("job", "insights"), slog.Int("weeks", config.Weeks))
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+	for {
+		start := time.Now()
+		analyzed, err := analyzeUsers(ctx, pool, db, config.Weeks, start.UTC(), logger)
+		if err != nil {
+			logger.Error("insights job failed", slog.String("error", err.Error()))
+		} else {
+			logger.Info("insights job success", slog.Int("users", analyzed), slog.Duration("duration", time.Since(start)))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// analyzeUsers analyzes the users who trained in the window ending today and the ones analyzed before.
+// A user that can not be analyzed is logged and skipped, it returns the number of users analyzed.
+func analyzeUsers(ctx context.Context, pool *pgxpool.Pool, db *database.Queries, weeks int, now time.Time, logger *slog.Logger) (int, error) {
+	from, to := window(now, weeks)
+	userIDs, err := db.GetInsightUserIDs(ctx, pgtype.Date{Time: from, Valid: true})
+	if err != nil {
+		return 0, fmt.Errorf("get users: %w", err)
+	}
+
+	analyzed := 0
+	for _, userID := range userIDs {
+		if err := analyzeUser(ctx, pool, db, userID, weeks, from, to); err != nil {
+			if ctx.Err() != nil {
+				return analyzed, ctx.Err()
+			}
+			logger.Error("insights job failed - analyze user",
+				slog.String("user_id", userID.String()),
+				slog.String("error", err.Error()))
+			continue
+		}
+		analyzed++
+	}
+	return analyzed, nil
+}
+
+// analyzeUser replaces the insights of the user with the ones of every metric in the window
+func analyzeUser(ctx context.Context, pool *pgxpool.Pool, db *database.Queries, userID uuid.UUID, weeks int, from, to time.Time) error {
+	topSets, err := db.GetDailyTopSets(ctx, database.GetDailyTopSetsParams{
+		UserID:   userID,
+		FromDate: pgtype.Date{Time: from, Valid: true},
+		ToDate:   pgtype.Date{Time: to, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("get top sets: %w", err)
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	txQueries := db.WithTx(tx)
+
+	if err := txQueries.DeleteInsightsByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("delete insights: %w", err)
+	}
+	for _, metric := range Metrics {
+		for _, a := range analyzeTopSets(topSets, metric, from, weeks) {
+			if err := txQueries.CreateInsight(ctx, database.CreateInsightParams{
+				UserID:      userID,
+				ExerciseID:  a.ExerciseID,
+				Metric:      metric,
+				Status:      a.Status,
+				Change:      a.Change,
+				Baseline:    a.Baseline,
+				Recent:      a.Recent,
+				Days:        int32(a.Days),
+				Suggestions: a.Suggestions,
+			}); err != nil {
+				return fmt.Errorf("create insight: %w", err)
+			}
+		}
+	}
+	if err := txQueries.UpsertInsightRun(ctx, database.UpsertInsightRunParams{
+		UserID:   userID,
+		Weeks:    int32(weeks),
+		FromDate: pgtype.Date{Time: from, Valid: true},
+		ToDate:   pgtype.Date{Time: to, Valid: true},
+	}); err != nil {
+		return fmt.Errorf("upsert insight run: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// analyzeTopSets analyzes the trend of every exercise, the top sets are sorted by exercise and date.
+// Only the exercises that stalled or regressed are returned.
+func analyzeTopSets(topSets []database.GetDailyTopSetsRow, metric string, from time.Time, weeks int) []exerciseAnalysis {
+	res := []exerciseAnalysis{}
+	for start := 0; start < len(topSets); {
+		end := start
+		points := []point{}
+		for ; end < len(topSets) && topSets[end].ExerciseID == topSets[start].ExerciseID; end++ {
+			points = append(points, point{
+				Date:   topSets[end].Date.Time,
+				Weight: topSets[end].Weight,
+				Reps:   topSets[end].Reps,
+				E1RM:   topSets[end].E1rm,
+			})
+		}
+
+		if a, ok := analyze(points, metric, from, weeks); ok {
+			res = append(res, exerciseAnalysis{
+				ExerciseID: topSets[start].ExerciseID,
+				Name:       topSets[start].Name,
+				analysis:   a,
+			})
+		}
+		start = end
+	}
+	return res
+}
+
+// window returns the first and last day of the window of weeks ending on the day of now
+func window(now time.Time, weeks int) (time.Time, time.Time) {
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return to.AddDate(0, 0, 1-weeks*7), to
+}
@@ -0,0 +1,91 @@
+package insight
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeUsers(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	idleUser := testutil.CreateUserDBTestHelper(t, db, "idleuser", "testpassword", false)
+	squatID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+
+	now := time.Now().UTC()
+	from, to := window(now, defaultWeeks)
+	createSquat := func(date time.Time, weight float64) {
+		session, err := db.CreateSession(context.Background(), database.CreateSessionParams{
+			Name:   "legs",
+			Date:   pgtype.Date{Time: date, Valid: true},
+			UserID: user.ID,
+		})
+		require.NoError(t, err)
+		setID := testutil.CreateSetDBTestHelper(t, db, session.ID, squatID)
+		testutil.CreateLogExerciseDBTestHelper(t, db, 5, 1, squatID, setID, weight)
+	}
+	// a session every week of the window, the squat regresses
+	for i, weight := range []float64{100, 100, 100, 90, 90, 90} {
+		createSquat(from.AddDate(0, 0, 7*i), weight)
+	}
+
+	getInsights := func(query string) insightsRes {
+		req, err := http.NewRequest("GET", "/test"+query, bytes.NewReader(nil))
+		require.NoError(t, err)
+		req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+		rr := httptest.NewRecorder()
+		middleware.RequestID(HandlerGetInsights(db, logger)).ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var resParams insightsRes
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resParams))
+		return resParams
+	}
+
+	analyzed, err := analyzeUsers(context.Background(), dbPool, db, defaultWeeks, now, logger)
+	require.NoError(t, err)
+	assert.Equal(t, 1, analyzed)
+
+	_, err = db.GetInsightRun(context.Background(), idleUser.ID)
+	assert.Error(t, err, "users without sessions in the window are not analyzed")
+	run, err := db.GetInsightRun(context.Background(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int32(defaultWeeks), run.Weeks)
+	assert.Equal(t, from, run.FromDate.Time)
+	assert.Equal(t, to, run.ToDate.Time)
+	for _, metric := range Metrics {
+		insights, err := db.GetInsights(context.Background(), database.GetInsightsParams{UserID: user.ID, Metric: metric})
+		require.NoError(t, err)
+		require.Len(t, insights, 1, metric)
+		assert.Equal(t, squatID, insights[0].ExerciseID)
+		assert.Equal(t, StatusRegression, insights[0].Status)
+		assert.Equal(t, []string{SuggestionDeload}, insights[0].Suggestions)
+	}
+
+	// the squat recovers, the stored insights are served until the job runs again
+	createSquat(to, 110)
+	resParams := getInsights("")
+	assert.True(t, run.AnalyzedAt.Time.Equal(resParams.AnalyzedAt))
+	require.Len(t, resParams.Insights, 1)
+	assert.Equal(t, "squat", resParams.Insights[0].Name)
+	assert.Empty(t, getInsights("?weeks=4").Insights, "other windows are analyzed on demand")
+
+	analyzed, err = analyzeUsers(context.Background(), dbPool, db, defaultWeeks, now, logger)
+	require.NoError(t, err)
+	assert.Equal(t, 1, analyzed)
+	assert.Empty(t, getInsights("").Insights)
+}
@@ -0,0 +1,198 @@
+package leaderboard
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type leaderboardExercise struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+}
+
+type leaderboardsRes struct {
+	Exercises   []leaderboardExercise `json:"exercises"`
+	Metrics     []string              `json:"metrics"`
+	Windows     []string              `json:"windows"`
+	AgeBrackets []string              `json:"age_brackets"`
+}
+
+// Only the display name is shown. The bodyweight is the e1RM divided by the relative strength,
+// so only users sharing their bodyweight are ranked on the relative metric.
+type entry struct {
+	Rank         int      `json:"rank"`
+	DisplayName  string   `json:"display_name"`
+	Date         string   `json:"date"`
+	E1RM         *float64 `json:"e1rm,omitempty"`
+	RelativeE1RM *float64 `json:"relative_e1rm,omitempty"`
+}
+
+type leaderboardRes struct {
+	ExerciseID int32   `json:"exercise_id"`
+	Name       string  `json:"name"`
+	Metric     string  `json:"metric"`
+	Window     string  `json:"window"`
+	Country    string  `json:"country,omitempty"`
+	Age        string  `json:"age,omitempty"`
+	Unit       string  `json:"unit"`
+	Entries    []entry `json:"entries"`
+}
+
+// HandlerGetLeaderboards returns the exercises with leaderboards and the ways to filter them
+func HandlerGetLeaderboards(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+
+		exercises, err := db.GetLeaderboardExercises(r.Context())
+		if err != nil {
+			reqLogger.Error("get leaderboards failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		resParams := leaderboardsRes{
+			Exercises:   make([]leaderboardExercise, len(exercises)),
+			Metrics:     Metrics,
+			Windows:     Windows,
+			AgeBrackets: AgeBrackets(),
+		}
+		for i, e := range exercises {
+			resParams.Exercises[i] = leaderboardExercise{ID: e.ID, Name: e.Name}
+		}
+		util.RespondWithJSON(w, r, http.StatusOK, resParams)
+	}
+}
+
+// HandlerGetLeaderboard ranks the best lift of every user who opted in to the leaderboards.
+// Users are only ranked within a country or an age bracket when they share them.
+func HandlerGetLeaderboard(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		exerciseIDString := r.PathValue("id")
+		exerciseID, err := strconv.ParseInt(exerciseIDString, 10, 32)
+		if err != nil {
+			reqLogger.Debug("invalid exercise id format", slog.String("exercise_id", exerciseIDString))
+			util.RespondWithError(w, r, http.StatusBadRequest, "invalid exercise id format", err)
+			return
+		}
+		reqLogger = reqLogger.With(slog.Int64("exercise_id", exerciseID))
+
+		// validate the query parameters
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		problems := map[string]string{}
+		query := r.URL.Query()
+		params := database.GetLeaderboardParams{
+			ExerciseID: int32(exerciseID),
+			Metric:     MetricE1RM,
+			RowLimit:   defaultLimit,
+		}
+		if query.Has("metric") {
+			params.Metric = strings.ToLower(query.Get("metric"))
+			if !slices.Contains(Metrics, params.Metric) {
+				problems["metric"] = "invalid metric: metric must be one of " + strings.Join(Metrics, ", ")
+			}
+		}
+		window := WindowAll
+		if query.Has("window") {
+			window = strings.ToLower(query.Get("window"))
+			if !slices.Contains(Windows, window) {
+				problems["window"] = "invalid window: window must be one of " + strings.Join(Windows, ", ")
+			}
+		}
+		params.FromDate = windowStart(window, today)
+		country := query.Get("country")
+		if country != "" {
+			params.Country = pgtype.Text{String: country, Valid: true}
+		}
+		age := query.Get("age")
+		if age != "" {
+			bracket, ok := findAgeBracket(age)
+			if !ok {
+				problems["age"] = "invalid age: age must be one of " + strings.Join(AgeBrackets(), ", ")
+			}
+			params.BornAfter, params.BornBefore = bracket.birthdays(today)
+		}
+		if query.Has("limit") {
+			parsed, err := strconv.Atoi(query.Get("limit"))
+			if err != nil || parsed < 1 || parsed > maxLimit {
+				problems["limit"] = fmt.Sprintf("invalid limit: limit must be between 1 and %d", maxLimit)
+			} else {
+				params.RowLimit = int32(parsed)
+			}
+		}
+		if len(problems) > 0 {
+			reqLogger.Debug("get leaderboard failed - validation failed", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		}
+
+		exercise, err := db.GetExercise(r.Context(), int32(exerciseID))
+		if err == pgx.ErrNoRows {
+			reqLogger.Debug("get leaderboard failed - exercise not in database")
+			util.RespondWithError(w, r, http.StatusNotFound, "leaderboard not found", err)
+			return
+		} else if err != nil {
+			reqLogger.Error("get leaderboard failed - get exercise database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		if !exercise.Leaderboard {
+			reqLogger.Debug("get leaderboard failed - exercise without leaderboard")
+			util.RespondWithError(w, r, http.StatusNotFound, "leaderboard not found", nil)
+			return
+		}
+
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "get leaderboard")
+		if !ok {
+			return
+		}
+
+		rows, err := db.GetLeaderboard(r.Context(), params)
+		if err != nil {
+			reqLogger.Error("get leaderboard failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		resParams := leaderboardRes{
+			ExerciseID: exercise.ID,
+			Name:       exercise.Name,
+			Metric:     params.Metric,
+			Window:     window,
+			Country:    country,
+			Age:        age,
+			Unit:       unit,
+			Entries:    make([]entry, len(rows)),
+		}
+		ranks := rank(rows)
+		for i, row := range rows {
+			resParams.Entries[i] = entry{
+				Rank:        ranks[i],
+				DisplayName: row.DisplayName,
+				Date:        row.Date.Time.Format(apiconstants.DATE_LAYOUT),
+			}
+			if params.Metric == MetricRelative {
+				relative := math.Round(row.Score*100) / 100
+				resParams.Entries[i].RelativeE1RM = &relative
+			} else {
+				e1rm := units.FromKG(row.E1rm, unit)
+				resParams.Entries[i].E1RM = &e1rm
+			}
+		}
+		util.RespondWithJSON(w, r, http.StatusOK, resParams)
+	}
+}
@@ -0,0 +1,249 @@
+package leaderboard
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rankedUser struct {
+	username     string
+	displayName  string
+	country      string
+	age          int
+	optIn        bool
+	shareCountry bool
+	shareAge     bool
+	shareBW      bool
+}
+
+// createRankedUserDBTestHelper creates a user with the leaderboard settings
+func createRankedUserDBTestHelper(t *testing.T, db *database.Queries, u rankedUser) uuid.UUID {
+	t.Helper()
+	user, err := db.CreateUser(context.Background(), database.CreateUserParams{
+		Username:       u.username,
+		Country:        pgtype.Text{String: u.country, Valid: true},
+		HashedPassword: "hashed",
+		Birthday:       pgtype.Date{Time: time.Now().UTC().AddDate(-u.age, 0, -1), Valid: true},
+	})
+	require.NoError(t, err)
+	_, err = db.UpdateUserLeaderboard(context.Background(), database.UpdateUserLeaderboardParams{
+		LeaderboardOptIn:           pgtype.Bool{Bool: u.optIn, Valid: true},
+		DisplayName:                pgtype.Text{String: u.displayName, Valid: u.displayName != ""},
+		LeaderboardShareCountry:    pgtype.Bool{Bool: u.shareCountry, Valid: true},
+		LeaderboardShareAge:        pgtype.Bool{Bool: u.shareAge, Valid: true},
+		LeaderboardShareBodyweight: pgtype.Bool{Bool: u.shareBW, Valid: true},
+		ID:                         user.ID,
+	})
+	require.NoError(t, err)
+	return user.ID
+}
+
+// createLiftDBTestHelper logs a single of the exercise daysAgo, with the bodyweight of the user when not zero
+func createLiftDBTestHelper(t *testing.T, db *database.Queries, userID uuid.UUID, exerciseID int32, daysAgo int, weight, bodyweight float64) {
+	t.Helper()
+	session, err := db.CreateSession(context.Background(), database.CreateSessionParams{
+		Name:       "bench day",
+		Date:       pgtype.Date{Time: time.Now().UTC().AddDate(0, 0, -daysAgo), Valid: true},
+		Bodyweight: pgtype.Float8{Float64: bodyweight, Valid: bodyweight != 0},
+		UserID:     userID,
+	})
+	require.NoError(t, err)
+	setID := testutil.CreateSetDBTestHelper(t, db, session.ID, exerciseID)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 1, 1, exerciseID, setID, weight)
+}
+
+func TestHandlerGetLeaderboard(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	bench, err := db.CreateExercise(context.Background(), database.CreateExerciseParams{
+		Name:        "bench press",
+		Description: pgtype.Text{String: "", Valid: true},
+		Leaderboard: true,
+	})
+	require.NoError(t, err)
+	curlID := testutil.CreateExerciseDBTestHelper(t, db, "curl")
+
+	alice := createRankedUserDBTestHelper(t, db, rankedUser{
+		username: "alice", displayName: "Alice", country: "Spain", age: 30, optIn: true, shareCountry: true, shareAge: true,
+		shareBW: true,
+	})
+	bob := createRankedUserDBTestHelper(t, db, rankedUser{
+		username: "bobby", displayName: "Bob", country: "Spain", age: 30, optIn: true,
+	})
+	carol := createRankedUserDBTestHelper(t, db, rankedUser{
+		username: "carol", displayName: "Carol", country: "Spain", age: 30, shareCountry: true, shareAge: true,
+	})
+	dave := createRankedUserDBTestHelper(t, db, rankedUser{
+		username: "davey", displayName: "Dave", country: "France", age: 45, optIn: true, shareCountry: true, shareAge: true,
+	})
+	createLiftDBTestHelper(t, db, alice, bench.ID, 3, 100, 60)
+	createLiftDBTestHelper(t, db, bob, bench.ID, 40, 120, 100)
+	createLiftDBTestHelper(t, db, bob, bench.ID, 2, 110, 0)
+	// users who did not opt in never appear
+	createLiftDBTestHelper(t, db, carol, bench.ID, 1, 200, 80)
+	createLiftDBTestHelper(t, db, dave, bench.ID, 10, 100, 0)
+
+	type expectedEntry struct {
+		rank        int
+		displayName string
+		value       float64
+	}
+	testCases := []struct {
+		name       string
+		exerciseID string
+		query      string
+		statusCode int
+		errKeys    []string
+		expected   []expectedEntry
+	}{
+		{
+			name:       "happy path",
+			exerciseID: strconv.Itoa(int(bench.ID)),
+			statusCode: http.StatusOK,
+			expected:   []expectedEntry{{1, "Bob", 120}, {2, "Dave", 100}, {2, "Alice", 100}},
+		},
+		{
+			name:       "happy path: window",
+			exerciseID: strconv.Itoa(int(bench.ID)),
+			query:      "?window=month",
+			statusCode: http.StatusOK,
+			expected:   []expectedEntry{{1, "Bob", 110}, {2, "Dave", 100}, {2, "Alice", 100}},
+		},
+		{
+			// Bob logged his bodyweight but does not share it
+			name:       "happy path: relative strength of the users sharing their bodyweight",
+			exerciseID: strconv.Itoa(int(bench.ID)),
+			query:      "?metric=relative",
+			statusCode: http.StatusOK,
+			expected:   []expectedEntry{{1, "Alice", 1.67}},
+		},
+		{
+			name:       "happy path: country of the users sharing it",
+			exerciseID: strconv.Itoa(int(bench.ID)),
+			query:      "?country=spain",
+			statusCode: http.StatusOK,
+			expected:   []expectedEntry{{1, "Alice", 100}},
+		},
+		{
+			name:       "happy path: age bracket of the users sharing it",
+			exerciseID: strconv.Itoa(int(bench.ID)),
+			query:      "?age=40-49",
+			statusCode: http.StatusOK,
+			expected:   []expectedEntry{{1, "Dave", 100}},
+		},
+		{
+			name:       "happy path: limit",
+			exerciseID: strconv.Itoa(int(bench.ID)),
+			query:      "?limit=1&units=lb",
+			statusCode: http.StatusOK,
+			expected:   []expectedEntry{{1, "Bob", 264.555}},
+		},
+		{
+			name:       "invalid parameters",
+			exerciseID: strconv.Itoa(int(bench.ID)),
+			query:      "?metric=wilks&window=decade&age=18-30&limit=1000",
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"metric", "window", "age", "limit"},
+		},
+		{
+			name:       "exercise without leaderboard",
+			exerciseID: strconv.Itoa(int(curlID)),
+			statusCode: http.StatusNotFound,
+			errKeys:    []string{"leaderboard not found"},
+		},
+		{
+			name:       "exercise does not exist",
+			exerciseID: strconv.Itoa(int(bench.ID) + 1000),
+			statusCode: http.StatusNotFound,
+			errKeys:    []string{"leaderboard not found"},
+		},
+		{
+			name:       "invalid exercise id",
+			exerciseID: "bench",
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"invalid exercise id format"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/test"+tc.query, nil)
+			require.NoError(t, err)
+			req.SetPathValue("id", tc.exerciseID)
+			req = req.WithContext(util.ContextWithUser(req.Context(), alice))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerGetLeaderboard(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				for _, key := range tc.errKeys {
+					assert.Contains(t, rr.Body.String(), key)
+				}
+				return
+			}
+
+			var resParams leaderboardRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			assert.Equal(t, "bench press", resParams.Name)
+			require.Len(t, resParams.Entries, len(tc.expected))
+			for i, expected := range tc.expected {
+				got := resParams.Entries[i]
+				assert.Equal(t, expected.rank, got.Rank)
+				assert.Equal(t, expected.displayName, got.DisplayName)
+				if resParams.Metric == MetricRelative {
+					require.NotNil(t, got.RelativeE1RM)
+					assert.Nil(t, got.E1RM)
+					assert.Equal(t, expected.value, *got.RelativeE1RM)
+				} else {
+					require.NotNil(t, got.E1RM)
+					assert.Nil(t, got.RelativeE1RM)
+					assert.InDelta(t, expected.value, *got.E1RM, 0.001)
+				}
+			}
+		})
+	}
+}
+
+func TestHandlerGetLeaderboards(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	db := database.New(dbPool)
+	for _, name := range []string{"squat", "deadlift"} {
+		_, err := db.CreateExercise(context.Background(), database.CreateExerciseParams{
+			Name:        name,
+			Description: pgtype.Text{String: "", Valid: true},
+			Leaderboard: true,
+		})
+		require.NoError(t, err)
+	}
+	testutil.CreateExerciseDBTestHelper(t, db, "curl")
+
+	req, err := http.NewRequest("GET", "/test", nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+
+	middleware.RequestID(HandlerGetLeaderboards(db, logger)).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resParams leaderboardsRes
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+	require.Len(t, resParams.Exercises, 2)
+	assert.Equal(t, "deadlift", resParams.Exercises[0].Name)
+	assert.Equal(t, "squat", resParams.Exercises[1].Name)
+	assert.Equal(t, Metrics, resParams.Metrics)
+	assert.Equal(t, AgeBrackets(), resParams.AgeBrackets)
+}
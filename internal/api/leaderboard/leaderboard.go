@@ -0,0 +1,101 @@
+package leaderboard
+
+import (
+	"time"
+
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// what the users are ranked by
+const (
+	MetricE1RM     = "e1rm"
+	MetricRelative = "relative" // e1RM divided by the bodyweight
+)
+
+var Metrics = []string{MetricE1RM, MetricRelative}
+
+// lifts taken into account: the last 365, 30 or 7 days or all of them
+const (
+	WindowAll   = "all"
+	WindowYear  = "year"
+	WindowMonth = "month"
+	WindowWeek  = "week"
+)
+
+var Windows = []string{WindowAll, WindowYear, WindowMonth, WindowWeek}
+
+type ageBracket struct {
+	name string
+	min  int
+	max  int // zero when the bracket has no upper bound
+}
+
+// age brackets of the powerlifting federations
+var ageBrackets = []ageBracket{
+	{name: "13-18", min: 13, max: 18},
+	{name: "19-23", min: 19, max: 23},
+	{name: "24-39", min: 24, max: 39},
+	{name: "40-49", min: 40, max: 49},
+	{name: "50-59", min: 50, max: 59},
+	{name: "60-69", min: 60, max: 69},
+	{name: "70+", min: 70},
+}
+
+func AgeBrackets() []string {
+	names := make([]string, len(ageBrackets))
+	for i, b := range ageBrackets {
+		names[i] = b.name
+	}
+	return names
+}
+
+func findAgeBracket(name string) (ageBracket, bool) {
+	for _, b := range ageBrackets {
+		if b.name == name {
+			return b, true
+		}
+	}
+	return ageBracket{}, false
+}
+
+// birthdays returns the range of birthdays of the people in the bracket on today:
+// born after the first date and on or before the second one
+func (b ageBracket) birthdays(today time.Time) (pgtype.Date, pgtype.Date) {
+	var after pgtype.Date
+	if b.max > 0 {
+		after = pgtype.Date{Time: today.AddDate(-b.max-1, 0, 0), Valid: true}
+	}
+	return after, pgtype.Date{Time: today.AddDate(-b.min, 0, 0), Valid: true}
+}
+
+// windowStart returns the first day of the window ending today, not valid for all time
+func windowStart(window string, today time.Time) pgtype.Date {
+	switch window {
+	case WindowYear:
+		return pgtype.Date{Time: today.AddDate(0, 0, -364), Valid: true}
+	case WindowMonth:
+		return pgtype.Date{Time: today.AddDate(0, 0, -29), Valid: true}
+	case WindowWeek:
+		return pgtype.Date{Time: today.AddDate(0, 0, -6), Valid: true}
+	}
+	return pgtype.Date{}
+}
+
+// rank returns the positions of the rows sorted by score, ties share the position
+func rank(rows []database.GetLeaderboardRow) []int {
+	ranks := make([]int, len(rows))
+	for i, row := range rows {
+		if i > 0 && row.Score == rows[i-1].Score {
+			ranks[i] = ranks[i-1]
+			continue
+		}
+		ranks[i] = i + 1
+	}
+	return ranks
+}
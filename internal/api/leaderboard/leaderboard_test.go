@@ -0,0 +1,73 @@
+package leaderboard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgeBracketBirthdays(t *testing.T) {
+	today := time.Date(2025, time.June, 15, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		bracket    string
+		bornAfter  pgtype.Date
+		bornBefore pgtype.Date
+	}{
+		{
+			name:       "bounded bracket",
+			bracket:    "24-39",
+			bornAfter:  pgtype.Date{Time: time.Date(1985, time.June, 15, 0, 0, 0, 0, time.UTC), Valid: true},
+			bornBefore: pgtype.Date{Time: time.Date(2001, time.June, 15, 0, 0, 0, 0, time.UTC), Valid: true},
+		},
+		{
+			name:       "open bracket",
+			bracket:    "70+",
+			bornBefore: pgtype.Date{Time: time.Date(1955, time.June, 15, 0, 0, 0, 0, time.UTC), Valid: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bracket, ok := findAgeBracket(tc.bracket)
+			require.True(t, ok)
+			bornAfter, bornBefore := bracket.birthdays(today)
+			assert.Equal(t, tc.bornAfter, bornAfter)
+			assert.Equal(t, tc.bornBefore, bornBefore)
+		})
+	}
+
+	_, ok := findAgeBracket("senior")
+	assert.False(t, ok)
+}
+
+func TestWindowStart(t *testing.T) {
+	today := time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		window   string
+		expected pgtype.Date
+	}{
+		{window: WindowAll},
+		{window: WindowYear, expected: pgtype.Date{Time: time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC), Valid: true}},
+		{window: WindowMonth, expected: pgtype.Date{Time: time.Date(2025, time.March, 2, 0, 0, 0, 0, time.UTC), Valid: true}},
+		{window: WindowWeek, expected: pgtype.Date{Time: time.Date(2025, time.March, 25, 0, 0, 0, 0, time.UTC), Valid: true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.window, func(t *testing.T) {
+			assert.Equal(t, tc.expected, windowStart(tc.window, today))
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	rows := []database.GetLeaderboardRow{{Score: 200}, {Score: 180}, {Score: 180}, {Score: 150}}
+	assert.Equal(t, []int{1, 2, 2, 4}, rank(rows))
+	assert.Empty(t, rank(nil))
+}
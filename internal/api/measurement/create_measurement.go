@@ -0,0 +1,78 @@
+package measurement
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/api/validation"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func HandlerCreateMeasurement(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("create measurement failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		reqParams, problems, err := validation.DecodeValid[*MeasurementReq](r)
+		if len(problems) > 0 {
+			reqLogger.Debug("create measurement failed - validation failed", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		} else if err != nil {
+			reqLogger.Debug("create measurement failed - invalid payload", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusBadRequest, "invalid payload", err)
+			return
+		}
+
+		// the bodyweight is returned in the unit of the caller, the one without unit is entered in it too
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "create measurement")
+		if !ok {
+			return
+		}
+		enteredUnit := reqParams.Unit
+		if enteredUnit == "" {
+			enteredUnit = unit
+		}
+
+		measurement, err := db.CreateBodyMeasurement(r.Context(), database.CreateBodyMeasurementParams{
+			UserID:     userID,
+			Date:       pgtype.Date{Time: reqParams.date, Valid: true},
+			Bodyweight: reqParams.bodyweight(enteredUnit),
+			BodyFat:    float8(reqParams.BodyFat),
+			Neck:       float8(reqParams.Neck),
+			Chest:      float8(reqParams.Chest),
+			Waist:      float8(reqParams.Waist),
+			Hips:       float8(reqParams.Hips),
+			Arm:        float8(reqParams.Arm),
+			Thigh:      float8(reqParams.Thigh),
+			Calf:       float8(reqParams.Calf),
+			Notes:      pgtype.Text{String: reqParams.Notes, Valid: reqParams.Notes != ""},
+		})
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				reqLogger.Debug("create measurement failed - date already measured", slog.String("date", reqParams.Date))
+				util.RespondWithError(w, r, http.StatusConflict, "there are measurements for the date already", err)
+				return
+			}
+			reqLogger.Error("create measurement failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		reqLogger.Info("create measurement success", slog.Int64("measurement_id", measurement.ID))
+		util.RespondWithJSON(w, r, http.StatusCreated, measurementResFromDB(measurement, unit))
+	}
+}
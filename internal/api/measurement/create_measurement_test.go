@@ -0,0 +1,168 @@
+package measurement
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerCreateMeasurement(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+
+	testCases := []struct {
+		name               string
+		body               string
+		query              string
+		missingContext     bool
+		statusCode         int
+		errMsg             []string
+		expectedBodyweight *float64
+		expectedWaist      *float64
+	}{
+		{
+			name:               "happy path",
+			body:               `{"date": "2025-03-01", "bodyweight": 80.5, "body_fat": 15, "waist": 82}`,
+			statusCode:         http.StatusCreated,
+			expectedBodyweight: ptr(80.5),
+			expectedWaist:      ptr(82),
+		},
+		{
+			name:               "happy path: bodyweight in pounds returned in kilograms",
+			body:               `{"date": "2025-03-02", "bodyweight": 176.37, "unit": "lb"}`,
+			statusCode:         http.StatusCreated,
+			expectedBodyweight: ptr(80),
+		},
+		{
+			name:          "happy path: circumferences only",
+			body:          `{"date": "2025-03-03", "waist": 81}`,
+			statusCode:    http.StatusCreated,
+			expectedWaist: ptr(81),
+		},
+		{
+			name:       "date measured already",
+			body:       `{"date": "2025-03-01", "bodyweight": 81}`,
+			statusCode: http.StatusConflict,
+			errMsg:     []string{"there are measurements for the date already"},
+		},
+		{
+			name:       "no JSON sent",
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid payload"},
+		},
+		{
+			name:       "invalid payload",
+			body:       `{"date": "01-03-2025", "body_fat": 120}`,
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"date", "body_fat"},
+		},
+		{
+			name:           "user not found in context",
+			body:           `{"bodyweight": 80}`,
+			missingContext: true,
+			statusCode:     http.StatusInternalServerError,
+			errMsg:         []string{"something went wrong"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := util.ContextWithUser(context.Background(), user.ID)
+			if tc.missingContext {
+				ctx = context.Background()
+			}
+			req, err := http.NewRequestWithContext(ctx, "POST", "/test"+tc.query, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerCreateMeasurement(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				for _, message := range tc.errMsg {
+					assert.Contains(t, rr.Body.String(), message)
+				}
+				return
+			}
+
+			var resParams MeasurementRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			assert.NotZero(t, resParams.ID)
+			assert.Equal(t, "kg", resParams.Unit)
+			if tc.expectedBodyweight == nil {
+				assert.Nil(t, resParams.Bodyweight)
+			} else {
+				require.NotNil(t, resParams.Bodyweight)
+				assert.InDelta(t, *tc.expectedBodyweight, *resParams.Bodyweight, 0.01)
+			}
+			assert.Equal(t, tc.expectedWaist, resParams.Waist)
+		})
+	}
+}
+
+func TestValidateMeasurement(t *testing.T) {
+	testCases := []struct {
+		name    string
+		req     MeasurementReq
+		errKeys []string
+	}{
+		{
+			name: "valid bodyweight without date",
+			req:  MeasurementReq{Bodyweight: ptr(80)},
+		},
+		{
+			name: "valid measurements",
+			req:  MeasurementReq{Date: "2025-01-01", Unit: "lb", Bodyweight: ptr(180), BodyFat: ptr(18), Chest: ptr(100), Arm: ptr(35)},
+		},
+		{
+			name:    "no measurements",
+			req:     MeasurementReq{Date: "2025-01-01", Notes: "forgot the scale"},
+			errKeys: []string{"measurements"},
+		},
+		{
+			name:    "bodyweight out of range",
+			req:     MeasurementReq{Unit: "kg", Bodyweight: ptr(apiconstants.MaxBodyweight + 1)},
+			errKeys: []string{"bodyweight"},
+		},
+		{
+			name:    "invalid unit and body fat",
+			req:     MeasurementReq{Unit: "stone", Bodyweight: ptr(12), BodyFat: ptr(0)},
+			errKeys: []string{"unit", "body_fat"},
+		},
+		{
+			name:    "invalid circumferences",
+			req:     MeasurementReq{Neck: ptr(-1), Calf: ptr(apiconstants.MaxCircumference + 1)},
+			errKeys: []string{"neck", "calf"},
+		},
+		{
+			name:    "notes too long",
+			req:     MeasurementReq{Waist: ptr(80), Notes: strings.Repeat("a", apiconstants.MaxNotesLength+1)},
+			errKeys: []string{"notes"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			problems := tc.req.Valid(context.Background())
+			require.Len(t, problems, len(tc.errKeys), problems)
+			for _, key := range tc.errKeys {
+				assert.Contains(t, problems, key)
+			}
+		})
+	}
+}
+
+func ptr(x float64) *float64 {
+	return &x
+}
@@ -0,0 +1,31 @@
+package measurement
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5"
+)
+
+func HandlerDeleteMeasurement(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		// measurement id is stored in the context with a generic key
+		measurementID, _ := retrieveParseIDFromContext(r.Context())
+		reqLogger = reqLogger.With(slog.Int64("measurement_id", measurementID))
+
+		if _, err := db.DeleteBodyMeasurement(r.Context(), measurementID); err == pgx.ErrNoRows {
+			util.RespondWithError(w, r, http.StatusNotFound, "measurement not found", err)
+			return
+		} else if err != nil {
+			reqLogger.Error("delete measurement failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		reqLogger.Info("delete measurement success")
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,64 @@
+package measurement
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerDeleteMeasurement(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+
+	testCases := []struct {
+		name          string
+		date          string
+		measurementID int64
+		statusCode    int
+	}{
+		{
+			name:       "happy path",
+			date:       "2025-03-01",
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name:          "measurement does not exist",
+			date:          "2025-03-02",
+			measurementID: -1,
+			statusCode:    http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			measurement := createMeasurementDBTestHelper(t, db, user.ID, tc.date, 80)
+			measurementID := measurement.ID
+			if tc.measurementID != 0 {
+				measurementID = tc.measurementID
+			}
+
+			req, err := http.NewRequest("DELETE", "/test", nil)
+			require.NoError(t, err)
+			ctx := util.ContextWithUser(req.Context(), user.ID)
+			req = req.WithContext(util.ContextWithResourceID(ctx, measurementID))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerDeleteMeasurement(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode == http.StatusNoContent {
+				_, err := db.GetBodyMeasurement(context.Background(), measurement.ID)
+				assert.ErrorIs(t, err, pgx.ErrNoRows)
+			}
+		})
+	}
+}
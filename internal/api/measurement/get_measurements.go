@@ -0,0 +1,155 @@
+package measurement
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/api/validation"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// default length of the listed range in days
+const defaultMeasurementsDays = 90
+
+type measurementsRes struct {
+	From         string           `json:"from"`
+	To           string           `json:"to"`
+	Unit         string           `json:"unit"`
+	Window       int              `json:"window"`
+	Measurements []MeasurementRes `json:"measurements"`
+	// daily bodyweight, measured or logged in the sessions, with its moving average
+	BodyweightTrend []trendPoint `json:"bodyweight_trend"`
+}
+
+// HandlerGetMeasurements returns the body measurements of the user between the from and to dates,
+// with the trend of the bodyweight smoothed by a moving average of window days
+func HandlerGetMeasurements(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("get measurements failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		// validate the query parameters
+		problems := map[string]string{}
+		query := r.URL.Query()
+		now := time.Now().UTC()
+		to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		if query.Has("to") {
+			date, err := validation.Date(query.Get("to"), apiconstants.DATE_LAYOUT, nil, nil)
+			if err != nil {
+				problems["to"] = "invalid to date: " + err.Error()
+			} else {
+				to = date
+			}
+		}
+		from := to.AddDate(0, 0, -defaultMeasurementsDays)
+		if query.Has("from") {
+			date, err := validation.Date(query.Get("from"), apiconstants.DATE_LAYOUT, nil, nil)
+			if err != nil {
+				problems["from"] = "invalid from date: " + err.Error()
+			} else {
+				from = date
+			}
+		}
+		if from.After(to) {
+			problems["from"] = "invalid from date: must be before the to date"
+		}
+		window := DefaultTrendWindow
+		if query.Has("window") {
+			parsed, err := strconv.Atoi(query.Get("window"))
+			if err != nil || parsed < 1 || parsed > MaxTrendWindow {
+				problems["window"] = fmt.Sprintf("invalid window: window must be between 1 and %d days", MaxTrendWindow)
+			} else {
+				window = parsed
+			}
+		}
+		if len(problems) > 0 {
+			reqLogger.Debug("get measurements failed - validation failed", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		}
+
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "get measurements")
+		if !ok {
+			return
+		}
+
+		measurements, err := db.GetBodyMeasurements(r.Context(), database.GetBodyMeasurementsParams{
+			UserID:   userID,
+			FromDate: pgtype.Date{Time: from, Valid: true},
+			ToDate:   pgtype.Date{Time: to, Valid: true},
+		})
+		if err != nil {
+			reqLogger.Error("get measurements failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		// the readings before from feed the average of the first days
+		readings, err := db.GetDailyBodyweight(r.Context(), database.GetDailyBodyweightParams{
+			UserID:   userID,
+			FromDate: pgtype.Date{Time: from.AddDate(0, 0, -window+1), Valid: true},
+			ToDate:   pgtype.Date{Time: to, Valid: true},
+		})
+		if err != nil {
+			reqLogger.Error("get measurements failed - bodyweight database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		resParams := measurementsRes{
+			From:            from.Format(apiconstants.DATE_LAYOUT),
+			To:              to.Format(apiconstants.DATE_LAYOUT),
+			Unit:            unit,
+			Window:          window,
+			Measurements:    make([]MeasurementRes, len(measurements)),
+			BodyweightTrend: trendFrom(movingAverage(readings, window), from),
+		}
+		for i, m := range measurements {
+			resParams.Measurements[i] = measurementResFromDB(m, unit)
+		}
+		for i, p := range resParams.BodyweightTrend {
+			resParams.BodyweightTrend[i].Bodyweight = units.FromKG(p.Bodyweight, unit)
+			resParams.BodyweightTrend[i].MovingAverage = units.FromKG(p.MovingAverage, unit)
+		}
+		util.RespondWithJSON(w, r, http.StatusOK, resParams)
+	}
+}
+
+func HandlerGetMeasurement(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		// measurement id is stored in the context with a generic key
+		measurementID, _ := retrieveParseIDFromContext(r.Context())
+		reqLogger = reqLogger.With(slog.Int64("measurement_id", measurementID))
+
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "get measurement")
+		if !ok {
+			return
+		}
+
+		measurement, err := db.GetBodyMeasurement(r.Context(), measurementID)
+		if err == pgx.ErrNoRows {
+			util.RespondWithError(w, r, http.StatusNotFound, "measurement not found", err)
+			return
+		} else if err != nil {
+			reqLogger.Error("get measurement failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		util.RespondWithJSON(w, r, http.StatusOK, measurementResFromDB(measurement, unit))
+	}
+}
@@ -0,0 +1,182 @@
+package measurement
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createMeasurementDBTestHelper creates a bodyweight measurement of the user on date
+func createMeasurementDBTestHelper(t *testing.T, db *database.Queries, userID uuid.UUID, date string, bodyweight float64) database.BodyMeasurement {
+	t.Helper()
+	parsed, err := time.Parse(time.DateOnly, date)
+	require.NoError(t, err)
+	measurement, err := db.CreateBodyMeasurement(context.Background(), database.CreateBodyMeasurementParams{
+		UserID:     userID,
+		Date:       pgtype.Date{Time: parsed, Valid: true},
+		Bodyweight: pgtype.Float8{Float64: bodyweight, Valid: true},
+	})
+	require.NoError(t, err)
+	return measurement
+}
+
+func TestHandlerGetMeasurements(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+
+	// the bodyweight of the session is part of the trend, the measurement wins on the same day
+	createMeasurementDBTestHelper(t, db, user.ID, "2025-03-01", 80)
+	createMeasurementDBTestHelper(t, db, user.ID, "2025-03-03", 82)
+	createMeasurementDBTestHelper(t, db, user.ID, "2025-03-10", 81)
+	for _, s := range []struct {
+		date       time.Time
+		bodyweight float64
+	}{
+		{date: time.Date(2025, time.March, 2, 0, 0, 0, 0, time.UTC), bodyweight: 81},
+		{date: time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC), bodyweight: 90},
+	} {
+		_, err := db.CreateSession(context.Background(), database.CreateSessionParams{
+			Name:       "full body",
+			Date:       pgtype.Date{Time: s.date, Valid: true},
+			Bodyweight: pgtype.Float8{Float64: s.bodyweight, Valid: true},
+			UserID:     user.ID,
+		})
+		require.NoError(t, err)
+	}
+
+	testCases := []struct {
+		name                 string
+		query                string
+		statusCode           int
+		errKeys              []string
+		expectedMeasurements int
+		expectedTrend        []trendPoint
+	}{
+		{
+			name:                 "happy path",
+			query:                "?from=2025-03-01&to=2025-03-31&window=3",
+			statusCode:           http.StatusOK,
+			expectedMeasurements: 3,
+			expectedTrend: []trendPoint{
+				{Date: "2025-03-01", Bodyweight: 80, MovingAverage: 80},
+				{Date: "2025-03-02", Bodyweight: 81, MovingAverage: 80.5},
+				{Date: "2025-03-03", Bodyweight: 82, MovingAverage: 81},
+				{Date: "2025-03-10", Bodyweight: 81, MovingAverage: 81},
+			},
+		},
+		{
+			name:                 "happy path: earlier readings feed the average",
+			query:                "?from=2025-03-03&to=2025-03-31&window=3",
+			statusCode:           http.StatusOK,
+			expectedMeasurements: 2,
+			expectedTrend: []trendPoint{
+				{Date: "2025-03-03", Bodyweight: 82, MovingAverage: 81},
+				{Date: "2025-03-10", Bodyweight: 81, MovingAverage: 81},
+			},
+		},
+		{
+			name:                 "happy path: pounds",
+			query:                "?from=2025-03-10&to=2025-03-10&units=lb",
+			statusCode:           http.StatusOK,
+			expectedMeasurements: 1,
+			expectedTrend:        []trendPoint{{Date: "2025-03-10", Bodyweight: 178.574, MovingAverage: 178.574}},
+		},
+		{
+			name:       "invalid parameters",
+			query:      "?from=2025-04-01&to=2025-03-01&window=365",
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"from", "window"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/test"+tc.query, nil)
+			require.NoError(t, err)
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerGetMeasurements(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				var problems map[string]string
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&problems))
+				for _, key := range tc.errKeys {
+					assert.Contains(t, problems, key)
+				}
+				return
+			}
+
+			var resParams measurementsRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			assert.Len(t, resParams.Measurements, tc.expectedMeasurements)
+			require.Len(t, resParams.BodyweightTrend, len(tc.expectedTrend))
+			for i, expected := range tc.expectedTrend {
+				got := resParams.BodyweightTrend[i]
+				assert.Equal(t, expected.Date, got.Date)
+				assert.InDelta(t, expected.Bodyweight, got.Bodyweight, 0.001)
+				assert.InDelta(t, expected.MovingAverage, got.MovingAverage, 0.001)
+			}
+		})
+	}
+}
+
+func TestHandlerGetMeasurement(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	measurement := createMeasurementDBTestHelper(t, db, user.ID, "2025-03-01", 80)
+
+	testCases := []struct {
+		name          string
+		measurementID int64
+		statusCode    int
+	}{
+		{
+			name:          "happy path",
+			measurementID: measurement.ID,
+			statusCode:    http.StatusOK,
+		},
+		{
+			name:          "measurement does not exist",
+			measurementID: -1,
+			statusCode:    http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			ctx := util.ContextWithUser(req.Context(), user.ID)
+			req = req.WithContext(util.ContextWithResourceID(ctx, tc.measurementID))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerGetMeasurement(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode != http.StatusOK {
+				return
+			}
+
+			var resParams MeasurementRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			assert.Equal(t, measurement.ID, resParams.ID)
+			assert.Equal(t, "2025-03-01", resParams.Date)
+			require.NotNil(t, resParams.Bodyweight)
+			assert.Equal(t, 80.0, *resParams.Bodyweight)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package measurement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/api/validation"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// MeasurementReq is a day of body measurements, the ones not taken are left out.
+// Circumferences are in centimeters.
+type MeasurementReq struct {
+	Date       string   `json:"date,omitempty"` // defaults to today
+	Unit       string   `json:"unit,omitempty"` // unit of the bodyweight, defaults to the preferred unit
+	Bodyweight *float64 `json:"bodyweight,omitempty"`
+	BodyFat    *float64 `json:"body_fat,omitempty"` // percentage
+	Neck       *float64 `json:"neck,omitempty"`
+	Chest      *float64 `json:"chest,omitempty"`
+	Waist      *float64 `json:"waist,omitempty"`
+	Hips       *float64 `json:"hips,omitempty"`
+	Arm        *float64 `json:"arm,omitempty"`
+	Thigh      *float64 `json:"thigh,omitempty"`
+	Calf       *float64 `json:"calf,omitempty"`
+	Notes      string   `json:"notes,omitempty"`
+
+	date time.Time
+}
+
+type MeasurementRes struct {
+	ID int64 `json:"id"`
+	MeasurementReq
+}
+
+func (r *MeasurementReq) Valid(ctx context.Context) map[string]string {
+	problems := make(map[string]string)
+
+	// date validation
+	if r.Date == "" {
+		r.Date = time.Now().UTC().Format(apiconstants.DATE_LAYOUT)
+	}
+	date, err := validation.Date(r.Date, apiconstants.DATE_LAYOUT, nil, nil)
+	if err != nil {
+		problems["date"] = "invalid date: " + err.Error()
+	}
+	r.date = date
+
+	// unit validation, it is an optional parameter. The preferred unit is not known yet,
+	// so bodyweights without unit are bounded as pounds, the most permissive unit.
+	unit := units.LB
+	if r.Unit != "" {
+		if !units.Valid(r.Unit) {
+			problems["unit"] = "invalid unit: " + units.ErrInvalidUnit.Error()
+		} else {
+			unit = r.Unit
+		}
+	}
+
+	// measurements validation, at least one of them is required
+	if r.Bodyweight == nil && r.BodyFat == nil && len(r.circumferences()) == 0 {
+		problems["measurements"] = "invalid measurements: at least one measurement is required"
+	}
+	if r.Bodyweight != nil {
+		if bodyweight := units.ToKG(*r.Bodyweight, unit); bodyweight <= 0 || bodyweight > apiconstants.MaxBodyweight {
+			problems["bodyweight"] = fmt.Sprintf("invalid bodyweight: bodyweight must be between 0 and %d kg", apiconstants.MaxBodyweight)
+		}
+	}
+	if r.BodyFat != nil && (*r.BodyFat <= 0 || *r.BodyFat >= 100) {
+		problems["body_fat"] = "invalid body_fat: body_fat must be a percentage between 0 and 100"
+	}
+	for name, value := range r.circumferences() {
+		if *value <= 0 || *value > apiconstants.MaxCircumference {
+			problems[name] = fmt.Sprintf("invalid %s: %s must be between 0 and %d cm", name, name, apiconstants.MaxCircumference)
+		}
+	}
+
+	// notes validation, it is an optional parameter
+	r.Notes = strings.TrimSpace(r.Notes)
+	if err := validation.String(r.Notes, 0, apiconstants.MaxNotesLength); err != nil {
+		problems["notes"] = "invalid notes: " + err.Error()
+	}
+
+	return problems
+}
+
+// circumferences returns the circumferences that were measured by name
+func (r *MeasurementReq) circumferences() map[string]*float64 {
+	all := map[string]*float64{
+		"neck":  r.Neck,
+		"chest": r.Chest,
+		"waist": r.Waist,
+		"hips":  r.Hips,
+		"arm":   r.Arm,
+		"thigh": r.Thigh,
+		"calf":  r.Calf,
+	}
+	measured := make(map[string]*float64)
+	for name, value := range all {
+		if value != nil {
+			measured[name] = value
+		}
+	}
+	return measured
+}
+
+// bodyweight returns the bodyweight in kilograms
+func (r *MeasurementReq) bodyweight(unit string) pgtype.Float8 {
+	if r.Bodyweight == nil {
+		return pgtype.Float8{}
+	}
+	return pgtype.Float8{Float64: units.ToKG(*r.Bodyweight, unit), Valid: true}
+}
+
+func float8(value *float64) pgtype.Float8 {
+	if value == nil {
+		return pgtype.Float8{}
+	}
+	return pgtype.Float8{Float64: *value, Valid: true}
+}
+
+func pointer(value pgtype.Float8) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
+
+// measurementResFromDB builds the response of the measurement with its bodyweight converted into unit
+func measurementResFromDB(m database.BodyMeasurement, unit string) MeasurementRes {
+	res := MeasurementRes{
+		ID: m.ID,
+		MeasurementReq: MeasurementReq{
+			Date:    m.Date.Time.Format(apiconstants.DATE_LAYOUT),
+			Unit:    unit,
+			BodyFat: pointer(m.BodyFat),
+			Neck:    pointer(m.Neck),
+			Chest:   pointer(m.Chest),
+			Waist:   pointer(m.Waist),
+			Hips:    pointer(m.Hips),
+			Arm:     pointer(m.Arm),
+			Thigh:   pointer(m.Thigh),
+			Calf:    pointer(m.Calf),
+			Notes:   m.Notes.String,
+		},
+	}
+	if m.Bodyweight.Valid {
+		bodyweight := units.FromKG(m.Bodyweight.Float64, unit)
+		res.Bodyweight = &bodyweight
+	}
+	return res
+}
+
+func retrieveParseIDFromContext(ctx context.Context) (int64, error) {
+	// pull the resource from the context
+	resourceID, ok := util.ResourceIDFromContext(ctx)
+	if !ok {
+		return 0, errors.New("could not find the measurement id")
+	}
+	// coerce the resource id into int
+	measurementID, ok := resourceID.(int64)
+	if !ok {
+		return 0, errors.New("could not type coerce the measurement id into int64")
+	}
+	return measurementID, nil
+}
@@ -0,0 +1,51 @@
+package measurement
+
+import (
+	"time"
+
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+)
+
+const (
+	DefaultTrendWindow = 7  // days
+	MaxTrendWindow     = 90 // days
+)
+
+type trendPoint struct {
+	Date          string  `json:"date"`
+	Bodyweight    float64 `json:"bodyweight"`
+	MovingAverage float64 `json:"moving_average"` // of the readings in the window ending on the date
+}
+
+// movingAverage smooths the daily bodyweight readings with the average of the readings
+// in the trailing window of days ending on every reading. Readings must be sorted by date.
+func movingAverage(readings []database.GetDailyBodyweightRow, window int) []trendPoint {
+	points := make([]trendPoint, len(readings))
+	start, sum := 0, 0.0
+	for i, r := range readings {
+		sum += r.Bodyweight
+		windowStart := r.Date.Time.AddDate(0, 0, -window+1)
+		for readings[start].Date.Time.Before(windowStart) {
+			sum -= readings[start].Bodyweight
+			start++
+		}
+		points[i] = trendPoint{
+			Date:          r.Date.Time.Format(apiconstants.DATE_LAYOUT),
+			Bodyweight:    r.Bodyweight,
+			MovingAverage: sum / float64(i-start+1),
+		}
+	}
+	return points
+}
+
+// trendFrom drops the points before from, they were only needed to average the first days
+func trendFrom(points []trendPoint, from time.Time) []trendPoint {
+	first := from.Format(apiconstants.DATE_LAYOUT)
+	for i, p := range points {
+		if p.Date >= first {
+			return points[i:]
+		}
+	}
+	return []trendPoint{}
+}
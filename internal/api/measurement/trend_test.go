@@ -0,0 +1,78 @@
+package measurement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readings builds daily bodyweight readings, offsets are the days from the first of January
+func readings(values map[int]float64, offsets ...int) []database.GetDailyBodyweightRow {
+	rows := make([]database.GetDailyBodyweightRow, len(offsets))
+	for i, offset := range offsets {
+		rows[i] = database.GetDailyBodyweightRow{
+			Date:       pgtype.Date{Time: time.Date(2025, time.January, 1+offset, 0, 0, 0, 0, time.UTC), Valid: true},
+			Bodyweight: values[offset],
+		}
+	}
+	return rows
+}
+
+func TestMovingAverage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		readings []database.GetDailyBodyweightRow
+		window   int
+		expected []float64
+	}{
+		{
+			name:     "no readings",
+			window:   7,
+			expected: []float64{},
+		},
+		{
+			name:     "daily readings",
+			readings: readings(map[int]float64{0: 80, 1: 81, 2: 79, 3: 80}, 0, 1, 2, 3),
+			window:   3,
+			expected: []float64{80, 80.5, 80, 80},
+		},
+		{
+			name:     "gaps leave the old readings out of the window",
+			readings: readings(map[int]float64{0: 90, 1: 88, 10: 80, 12: 81}, 0, 1, 10, 12),
+			window:   7,
+			expected: []float64{90, 89, 80, 80.5},
+		},
+		{
+			name:     "window of one day",
+			readings: readings(map[int]float64{0: 80, 1: 82}, 0, 1),
+			window:   1,
+			expected: []float64{80, 82},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			points := movingAverage(tc.readings, tc.window)
+			require.Len(t, points, len(tc.expected))
+			for i, expected := range tc.expected {
+				assert.InDelta(t, expected, points[i].MovingAverage, 0.001)
+				assert.Equal(t, tc.readings[i].Bodyweight, points[i].Bodyweight)
+			}
+		})
+	}
+}
+
+func TestTrendFrom(t *testing.T) {
+	points := movingAverage(readings(map[int]float64{0: 80, 5: 81, 9: 82}, 0, 5, 9), 7)
+
+	trend := trendFrom(points, time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC))
+	require.Len(t, trend, 2)
+	assert.Equal(t, "2025-01-06", trend[0].Date)
+	assert.Equal(t, 80.5, trend[0].MovingAverage)
+
+	assert.Empty(t, trendFrom(points, time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,78 @@
+package measurement
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/api/validation"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// HandlerUpdateMeasurement replaces the measurements of the day, the ones left out are removed
+func HandlerUpdateMeasurement(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		// measurement id is stored in the context with a generic key
+		measurementID, _ := retrieveParseIDFromContext(r.Context())
+		reqLogger = reqLogger.With(slog.Int64("measurement_id", measurementID))
+
+		reqParams, problems, err := validation.DecodeValid[*MeasurementReq](r)
+		if len(problems) > 0 {
+			reqLogger.Debug("update measurement failed - validation failed", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		} else if err != nil {
+			reqLogger.Debug("update measurement failed - invalid payload", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusBadRequest, "invalid payload", err)
+			return
+		}
+
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "update measurement")
+		if !ok {
+			return
+		}
+		enteredUnit := reqParams.Unit
+		if enteredUnit == "" {
+			enteredUnit = unit
+		}
+
+		measurement, err := db.UpdateBodyMeasurement(r.Context(), database.UpdateBodyMeasurementParams{
+			Date:       pgtype.Date{Time: reqParams.date, Valid: true},
+			Bodyweight: reqParams.bodyweight(enteredUnit),
+			BodyFat:    float8(reqParams.BodyFat),
+			Neck:       float8(reqParams.Neck),
+			Chest:      float8(reqParams.Chest),
+			Waist:      float8(reqParams.Waist),
+			Hips:       float8(reqParams.Hips),
+			Arm:        float8(reqParams.Arm),
+			Thigh:      float8(reqParams.Thigh),
+			Calf:       float8(reqParams.Calf),
+			Notes:      pgtype.Text{String: reqParams.Notes, Valid: reqParams.Notes != ""},
+			ID:         measurementID,
+		})
+		if err == pgx.ErrNoRows {
+			util.RespondWithError(w, r, http.StatusNotFound, "measurement not found", err)
+			return
+		} else if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				reqLogger.Debug("update measurement failed - date already measured", slog.String("date", reqParams.Date))
+				util.RespondWithError(w, r, http.StatusConflict, "there are measurements for the date already", err)
+				return
+			}
+			reqLogger.Error("update measurement failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		reqLogger.Info("update measurement success")
+		util.RespondWithJSON(w, r, http.StatusOK, measurementResFromDB(measurement, unit))
+	}
+}
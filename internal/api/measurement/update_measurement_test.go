@@ -0,0 +1,90 @@
+package measurement
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerUpdateMeasurement(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	createMeasurementDBTestHelper(t, db, user.ID, "2025-02-01", 82)
+
+	testCases := []struct {
+		name          string
+		body          string
+		measurementID int64
+		statusCode    int
+		errMsg        []string
+	}{
+		{
+			name:       "happy path: measurements left out are removed",
+			body:       `{"date": "2025-03-02", "waist": 80, "notes": "morning"}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "date measured already",
+			body:       `{"date": "2025-02-01", "waist": 80}`,
+			statusCode: http.StatusConflict,
+			errMsg:     []string{"there are measurements for the date already"},
+		},
+		{
+			name:       "invalid payload",
+			body:       `{"date": "2025-03-02"}`,
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"measurements"},
+		},
+		{
+			name:          "measurement does not exist",
+			body:          `{"date": "2025-03-02", "waist": 80}`,
+			measurementID: -1,
+			statusCode:    http.StatusNotFound,
+			errMsg:        []string{"measurement not found"},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			measurement := createMeasurementDBTestHelper(t, db, user.ID, fmt.Sprintf("2025-04-%02d", i+1), 80)
+			measurementID := measurement.ID
+			if tc.measurementID != 0 {
+				measurementID = tc.measurementID
+			}
+
+			req, err := http.NewRequest("PUT", "/test", strings.NewReader(tc.body))
+			require.NoError(t, err)
+			ctx := util.ContextWithUser(req.Context(), user.ID)
+			req = req.WithContext(util.ContextWithResourceID(ctx, measurementID))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerUpdateMeasurement(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				for _, message := range tc.errMsg {
+					assert.Contains(t, rr.Body.String(), message)
+				}
+				return
+			}
+
+			var resParams MeasurementRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			assert.Equal(t, measurement.ID, resParams.ID)
+			assert.Equal(t, "2025-03-02", resParams.Date)
+			assert.Nil(t, resParams.Bodyweight)
+			assert.Equal(t, ptr(80), resParams.Waist)
+			assert.Equal(t, "morning", resParams.Notes)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package offline
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/auth"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	DEFAULT_LIMIT int32 = 500
+	MAX_LIMIT     int32 = 1000
+)
+
+// change is the current state of an entity, deleted entities come without data
+type change struct {
+	Entity   string    `json:"entity"`
+	ClientID uuid.UUID `json:"client_id"`
+	ID       string    `json:"id"`
+	Deleted  bool      `json:"deleted"`
+	Data     any       `json:"data,omitempty"`
+}
+
+type changesRes struct {
+	Changes   []change `json:"changes"`
+	SyncToken string   `json:"sync_token"`
+	HasMore   bool     `json:"has_more"`
+}
+
+// HandlerGetChanges returns the entities of the user changed since the sync token, including the deleted ones.
+// Without token every existing entity is returned. Pages are requested with the returned token until has_more is false.
+func HandlerGetChanges(db *database.Queries, authConfig *auth.Config, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("get changes failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		// validate the query parameters
+		problems := map[string]string{}
+		query := r.URL.Query()
+		params := database.GetSyncChangesParams{UserID: userID, PageLimit: DEFAULT_LIMIT}
+		if query.Has("limit") {
+			parsed, err := strconv.ParseInt(query.Get("limit"), 10, 32)
+			if err != nil {
+				problems["limit"] = "invalid limit format"
+			} else if parsed <= 0 {
+				problems["limit"] = "invalid limit value, must be positive"
+			} else if int32(parsed) > MAX_LIMIT {
+				problems["limit"] = fmt.Sprintf("invalid limit value, must be less than %d", MAX_LIMIT)
+			} else {
+				params.PageLimit = int32(parsed)
+			}
+		}
+		if query.Has("since") {
+			since, err := decodeToken(query.Get("since"), userID, authConfig.JWTsecret)
+			if err != nil {
+				problems["since"] = "invalid since: " + err.Error()
+			}
+			params.Since = since
+		}
+		if len(problems) > 0 {
+			reqLogger.Debug("get changes failed - validation failed", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		}
+
+		// one more entity is requested to know whether there is another page
+		limit := params.PageLimit
+		params.PageLimit = limit + 1
+		entities, err := db.GetSyncChanges(r.Context(), params)
+		if err != nil {
+			reqLogger.Error("get changes failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		res := changesRes{Changes: []change{}}
+		if len(entities) > int(limit) {
+			entities = entities[:limit]
+			res.HasMore = true
+		}
+
+		changes, err := loadChanges(r, db, entities)
+		if err != nil {
+			reqLogger.Error("get changes failed - get entities database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		res.Changes = changes
+
+		version := params.Since
+		if len(entities) > 0 {
+			version = entities[len(entities)-1].Version
+		}
+		res.SyncToken, err = encodeToken(version, userID, authConfig.JWTsecret)
+		if err != nil {
+			reqLogger.Error("get changes failed - sync token encoding error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		reqLogger.Info("get changes success", slog.Int("changes", len(res.Changes)), slog.Bool("has_more", res.HasMore))
+		util.RespondWithJSON(w, r, http.StatusOK, res)
+	}
+}
+
+// loadChanges reads the current state of the changed entities.
+// Entities deleted after the changes were read are left out, their tombstone comes with a later version.
+func loadChanges(r *http.Request, db *database.Queries, entities []database.SyncEntity) ([]change, error) {
+	var sessionIDs []uuid.UUID
+	var setIDs, logIDs []int64
+	for _, e := range entities {
+		if e.DeletedAt.Valid {
+			continue
+		}
+		switch {
+		case e.SessionID.Valid:
+			sessionIDs = append(sessionIDs, e.SessionID.Bytes)
+		case e.SetID.Valid:
+			setIDs = append(setIDs, e.SetID.Int64)
+		case e.LogID.Valid:
+			logIDs = append(logIDs, e.LogID.Int64)
+		}
+	}
+
+	sessions := make(map[uuid.UUID]database.Session, len(sessionIDs))
+	if len(sessionIDs) > 0 {
+		rows, err := db.GetSessionsByIDs(r.Context(), sessionIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range rows {
+			sessions[s.ID] = s
+		}
+	}
+	sets := make(map[int64]database.Set, len(setIDs))
+	if len(setIDs) > 0 {
+		rows, err := db.GetSetsByIDs(r.Context(), setIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range rows {
+			sets[s.ID] = s
+		}
+	}
+	logs := make(map[int64]database.GetSyncLogsByIDsRow, len(logIDs))
+	if len(logIDs) > 0 {
+		rows, err := db.GetSyncLogsByIDs(r.Context(), logIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range rows {
+			logs[l.ID] = l
+		}
+	}
+
+	changes := make([]change, 0, len(entities))
+	for _, e := range entities {
+		entity, id := entityOf(e)
+		c := change{Entity: entity, ClientID: e.ClientID, ID: id, Deleted: e.DeletedAt.Valid}
+		if !c.Deleted {
+			switch entity {
+			case EntitySession:
+				s, ok := sessions[e.SessionID.Bytes]
+				if !ok {
+					continue
+				}
+				c.Data = sessionDataFromDB(s)
+			case EntitySet:
+				s, ok := sets[e.SetID.Int64]
+				if !ok {
+					continue
+				}
+				c.Data = setDataFromDB(s)
+			case EntityLog:
+				l, ok := logs[e.LogID.Int64]
+				if !ok {
+					continue
+				}
+				c.Data = logDataFromDB(l)
+			}
+		}
+		changes = append(changes, c)
+	}
+	slices.SortStableFunc(changes, func(a, b change) int {
+		return entityDepth[a.Entity] - entityDepth[b.Entity]
+	})
+	return changes, nil
+}
@@ -0,0 +1,204 @@
+package offline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/signedtoken"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+)
+
+// Entities clients keep offline
+const (
+	EntitySession = "session"
+	EntitySet     = "set"
+	EntityLog     = "log"
+)
+
+var Entities = []string{EntitySession, EntitySet, EntityLog}
+
+// changes of a page are returned parents first
+var entityDepth = map[string]int{EntitySession: 0, EntitySet: 1, EntityLog: 2}
+
+var ErrInvalidToken = errors.New("invalid sync token")
+
+// The fields clients sync are named after their columns so the time each one was written at can be tracked.
+// Nullable fields are pointers, null clears them.
+type sessionData struct {
+	Name            string     `json:"name"`
+	Date            string     `json:"date"`
+	StartTimestamp  *time.Time `json:"start_timestamp"`
+	DurationMinutes *int16     `json:"duration_minutes"`
+	Tags            []string   `json:"tags"`
+	Notes           *string    `json:"notes"`
+	Rpe             *int16     `json:"rpe"`
+	SleepQuality    *int16     `json:"sleep_quality"`
+	Bodyweight      *float64   `json:"bodyweight"`
+	Mood            *int16     `json:"mood"`
+	Location        *string    `json:"location"`
+}
+
+type setData struct {
+	SessionID  uuid.UUID `json:"session_id"` // sessions use their id as client id
+	SetOrder   int32     `json:"set_order"`
+	RestTime   *int32    `json:"rest_time"`
+	ExerciseID int32     `json:"exercise_id"`
+	SetType    string    `json:"set_type"`
+	GroupKey   *string   `json:"group_key"`
+}
+
+type logData struct {
+	SetClientID    uuid.UUID `json:"set_client_id"`
+	Weight         *float64  `json:"weight"` // kilograms
+	WeightUnit     string    `json:"weight_unit"`
+	Reps           int32     `json:"reps"`
+	LogsOrder      int32     `json:"logs_order"`
+	Rpe            *float64  `json:"rpe"`
+	Rir            *int16    `json:"rir"`
+	Tempo          *string   `json:"tempo"`
+	ReachedFailure bool      `json:"reached_failure"`
+	PartialReps    int16     `json:"partial_reps"`
+	Notes          *string   `json:"notes"`
+}
+
+func sessionDataFromDB(s database.Session) sessionData {
+	data := sessionData{
+		Name:     s.Name,
+		Date:     s.Date.Time.Format(apiconstants.DATE_LAYOUT),
+		Tags:     s.Tags,
+		Notes:    textPointer(s.Notes.String, s.Notes.Valid),
+		Location: textPointer(s.Location.String, s.Location.Valid),
+	}
+	if s.StartTimestamp.Valid {
+		start := s.StartTimestamp.Time.UTC()
+		data.StartTimestamp = &start
+	}
+	if s.DurationMinutes.Valid {
+		data.DurationMinutes = &s.DurationMinutes.Int16
+	}
+	if s.Rpe.Valid {
+		data.Rpe = &s.Rpe.Int16
+	}
+	if s.SleepQuality.Valid {
+		data.SleepQuality = &s.SleepQuality.Int16
+	}
+	if s.Bodyweight.Valid {
+		data.Bodyweight = &s.Bodyweight.Float64
+	}
+	if s.Mood.Valid {
+		data.Mood = &s.Mood.Int16
+	}
+	return data
+}
+
+func setDataFromDB(s database.Set) setData {
+	data := setData{
+		SessionID:  s.SessionID,
+		SetOrder:   s.SetOrder,
+		ExerciseID: s.ExerciseID,
+		SetType:    s.SetType,
+		GroupKey:   textPointer(s.GroupKey.String, s.GroupKey.Valid),
+	}
+	if s.RestTime.Valid {
+		data.RestTime = &s.RestTime.Int32
+	}
+	return data
+}
+
+func logDataFromDB(l database.GetSyncLogsByIDsRow) logData {
+	data := logData{
+		SetClientID:    l.SetClientID,
+		WeightUnit:     l.WeightUnit,
+		Reps:           l.Reps,
+		LogsOrder:      l.LogsOrder,
+		ReachedFailure: l.ReachedFailure,
+		PartialReps:    l.PartialReps,
+		Tempo:          textPointer(l.Tempo.String, l.Tempo.Valid),
+		Notes:          textPointer(l.Notes.String, l.Notes.Valid),
+	}
+	if l.Weight.Valid {
+		data.Weight = &l.Weight.Float64
+	}
+	if l.Rpe.Valid {
+		data.Rpe = &l.Rpe.Float64
+	}
+	if l.Rir.Valid {
+		data.Rir = &l.Rir.Int16
+	}
+	return data
+}
+
+func textPointer(value string, valid bool) *string {
+	if !valid {
+		return nil
+	}
+	return &value
+}
+
+// entityOf returns the entity tracked by the sync row and its server id
+func entityOf(e database.SyncEntity) (string, string) {
+	switch {
+	case e.SessionID.Valid:
+		return EntitySession, uuid.UUID(e.SessionID.Bytes).String()
+	case e.SetID.Valid:
+		return EntitySet, strconv.FormatInt(e.SetID.Int64, 10)
+	default:
+		return EntityLog, strconv.FormatInt(e.LogID.Int64, 10)
+	}
+}
+
+// field times are stored by the database as timestamps without time zone
+const fieldTimeLayout = "2006-01-02T15:04:05.999999"
+
+// fieldTimes returns the time each field of the entity was last written at,
+// fields never written since the creation of the entity are missing
+func fieldTimes(e database.SyncEntity) (map[string]time.Time, error) {
+	var stored map[string]string
+	if err := json.Unmarshal(e.FieldModifiedAt, &stored); err != nil {
+		return nil, fmt.Errorf("could not unmarshal the field times: %w", err)
+	}
+	times := make(map[string]time.Time, len(stored))
+	for field, value := range stored {
+		t, err := time.Parse(fieldTimeLayout, value)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse the time of field %s: %w", field, err)
+		}
+		times[field] = t
+	}
+	return times, nil
+}
+
+func marshalFieldTimes(fields []string, modifiedAt time.Time) ([]byte, error) {
+	times := make(map[string]string, len(fields))
+	for _, field := range fields {
+		times[field] = modifiedAt.UTC().Format(fieldTimeLayout)
+	}
+	return json.Marshal(times)
+}
+
+type tokenPayload struct {
+	Version int64  `json:"v"`
+	UserID  string `json:"u"`
+}
+
+// encodeToken returns an opaque signed sync token.
+// Tokens are bound to their user, the version is the last one the client received.
+func encodeToken(version int64, userID uuid.UUID, secret string) (string, error) {
+	return signedtoken.Encode("sync", tokenPayload{Version: version, UserID: userID.String()}, secret)
+}
+
+func decodeToken(token string, userID uuid.UUID, secret string) (int64, error) {
+	var payload tokenPayload
+	if err := signedtoken.Decode("sync", token, secret, &payload); err != nil {
+		return 0, ErrInvalidToken
+	}
+	if payload.UserID != userID.String() || payload.Version < 0 {
+		return 0, ErrInvalidToken
+	}
+	return payload.Version, nil
+}
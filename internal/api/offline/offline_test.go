@@ -0,0 +1,266 @@
+package offline
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncToken(t *testing.T) {
+	userID := uuid.New()
+	token, err := encodeToken(42, userID, "testSecret")
+	require.NoError(t, err)
+
+	version, err := decodeToken(token, userID, "testSecret")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), version)
+
+	_, err = encodeToken(42, userID, "")
+	assert.Error(t, err)
+
+	otherToken, err := encodeToken(43, userID, "testSecret")
+	require.NoError(t, err)
+	tampered := otherToken[:len(otherToken)-1] + "A"
+	if tampered == otherToken {
+		tampered = otherToken[:len(otherToken)-1] + "B"
+	}
+
+	testCases := []struct {
+		name   string
+		token  string
+		userID uuid.UUID
+		secret string
+	}{
+		{name: "another user", token: token, userID: uuid.New(), secret: "testSecret"},
+		{name: "another secret", token: token, userID: userID, secret: "anotherSecret"},
+		{name: "tampered signature", token: tampered, userID: userID, secret: "testSecret"},
+		{name: "missing signature", token: "eyJ2Ijo0Mn0", userID: userID, secret: "testSecret"},
+		{name: "garbage", token: "not.a-token", userID: userID, secret: "testSecret"},
+		{name: "empty", token: "", userID: userID, secret: "testSecret"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := decodeToken(tc.token, tc.userID, tc.secret)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestFieldTimes(t *testing.T) {
+	modifiedAt := time.Date(2026, 3, 14, 9, 30, 15, 123456000, time.UTC)
+	encoded, err := marshalFieldTimes([]string{"reps", "weight"}, modifiedAt)
+	require.NoError(t, err)
+
+	times, err := fieldTimes(database.SyncEntity{FieldModifiedAt: encoded})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{"reps": modifiedAt, "weight": modifiedAt}, times)
+
+	// the database stores the times without time zone
+	times, err = fieldTimes(database.SyncEntity{FieldModifiedAt: []byte(`{"name": "2026-03-14T09:30:15.5"}`)})
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 14, 9, 30, 15, 500000000, time.UTC), times["name"])
+
+	_, err = fieldTimes(database.SyncEntity{FieldModifiedAt: []byte(`{"name": "yesterday"}`)})
+	assert.Error(t, err)
+}
+
+func TestMergeFields(t *testing.T) {
+	createdAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	times := map[string]time.Time{"reps": time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)}
+	weight := 100.0
+	current := logData{Weight: &weight, Reps: 5, LogsOrder: 1, WeightUnit: units.KG}
+
+	testCases := []struct {
+		name            string
+		raw             string
+		modifiedAt      time.Time
+		expectedApplied []string
+		expectedStale   []string
+		expectedWeight  *float64
+		expectedReps    int32
+		expectedErr     bool
+	}{
+		{
+			name:            "newer than every field",
+			raw:             `{"weight": 110, "reps": 3}`,
+			modifiedAt:      time.Date(2026, 3, 11, 10, 0, 0, 0, time.UTC),
+			expectedApplied: []string{"reps", "weight"},
+			expectedWeight:  func() *float64 { v := 110.0; return &v }(),
+			expectedReps:    3,
+		},
+		{
+			name:            "older than a modified field",
+			raw:             `{"weight": 110, "reps": 3}`,
+			modifiedAt:      time.Date(2026, 3, 5, 10, 0, 0, 0, time.UTC),
+			expectedApplied: []string{"weight"},
+			expectedStale:   []string{"reps"},
+			expectedWeight:  func() *float64 { v := 110.0; return &v }(),
+			expectedReps:    5,
+		},
+		{
+			name:           "ties keep the stored value",
+			raw:            `{"weight": 110}`,
+			modifiedAt:     createdAt,
+			expectedStale:  []string{"weight"},
+			expectedWeight: &weight,
+			expectedReps:   5,
+		},
+		{
+			name:            "null clears the field",
+			raw:             `{"weight": null}`,
+			modifiedAt:      time.Date(2026, 3, 11, 10, 0, 0, 0, time.UTC),
+			expectedApplied: []string{"weight"},
+			expectedReps:    5,
+		},
+		{
+			name:        "unknown field",
+			raw:         `{"weight": 110, "sets": 3}`,
+			modifiedAt:  time.Date(2026, 3, 11, 10, 0, 0, 0, time.UTC),
+			expectedErr: true,
+		},
+		{
+			name:        "invalid stale field",
+			raw:         `{"reps": "three"}`,
+			modifiedAt:  time.Date(2026, 3, 5, 10, 0, 0, 0, time.UTC),
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := current
+			applied, stale, err := mergeFields(&data, json.RawMessage(tc.raw), tc.modifiedAt, times, createdAt)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedApplied, nilIfEmpty(applied))
+			assert.Equal(t, tc.expectedStale, stale)
+			assert.Equal(t, tc.expectedWeight, data.Weight)
+			assert.Equal(t, tc.expectedReps, data.Reps)
+		})
+	}
+}
+
+func nilIfEmpty(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+func TestPushReqValid(t *testing.T) {
+	valid := mutation{
+		Entity:         EntitySession,
+		Op:             OpUpsert,
+		ClientID:       uuid.New(),
+		LastModifiedAt: time.Now(),
+		Data:           json.RawMessage(`{"name": "legs"}`),
+	}
+	testCases := []struct {
+		name        string
+		mutations   func() []mutation
+		expectedKey string
+	}{
+		{name: "valid", mutations: func() []mutation { return []mutation{valid} }},
+		{
+			name: "valid delete without data",
+			mutations: func() []mutation {
+				m := valid
+				m.Op, m.Data = OpDelete, nil
+				return []mutation{m}
+			},
+		},
+		{name: "no mutations", mutations: func() []mutation { return nil }, expectedKey: "mutations"},
+		{
+			name:        "too many mutations",
+			mutations:   func() []mutation { return make([]mutation, MAX_MUTATIONS+1) },
+			expectedKey: "mutations",
+		},
+		{
+			name:        "unknown entity",
+			mutations:   func() []mutation { m := valid; m.Entity = "exercise"; return []mutation{valid, m} },
+			expectedKey: "mutations[1].entity",
+		},
+		{
+			name:        "unknown op",
+			mutations:   func() []mutation { m := valid; m.Op = "patch"; return []mutation{m} },
+			expectedKey: "mutations[0].op",
+		},
+		{
+			name:        "missing client id",
+			mutations:   func() []mutation { m := valid; m.ClientID = uuid.Nil; return []mutation{m} },
+			expectedKey: "mutations[0].client_id",
+		},
+		{
+			name:        "missing last modified at",
+			mutations:   func() []mutation { m := valid; m.LastModifiedAt = time.Time{}; return []mutation{m} },
+			expectedKey: "mutations[0].last_modified_at",
+		},
+		{
+			name:        "upsert without data",
+			mutations:   func() []mutation { m := valid; m.Data = json.RawMessage(`[1]`); return []mutation{m} },
+			expectedKey: "mutations[0].data",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := pushReq{Mutations: tc.mutations()}
+			problems := req.Valid(context.Background())
+			if tc.expectedKey == "" {
+				assert.Empty(t, problems)
+				return
+			}
+			assert.Contains(t, problems, tc.expectedKey)
+		})
+	}
+}
+
+func TestLogDataValidate(t *testing.T) {
+	weight := 100.0
+	zero := 0.0
+	testCases := []struct {
+		name         string
+		data         logData
+		expectedKeys []string
+		expectedUnit string
+	}{
+		{name: "valid", data: logData{Weight: &weight, Reps: 5, LogsOrder: 1}, expectedUnit: units.KG},
+		{name: "unit kept", data: logData{Weight: &weight, Reps: 5, LogsOrder: 1, WeightUnit: units.LB}, expectedUnit: units.LB},
+		{name: "explicit zero rpe", data: logData{Reps: 5, LogsOrder: 1, Rpe: &zero}, expectedKeys: []string{"rpe"}},
+		{name: "negative reps", data: logData{Reps: -1, LogsOrder: 1}, expectedKeys: []string{"reps"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			problems := tc.data.validate(context.Background())
+			for _, key := range tc.expectedKeys {
+				assert.Contains(t, problems, key)
+			}
+			if len(tc.expectedKeys) == 0 {
+				assert.Empty(t, problems)
+				assert.Equal(t, tc.expectedUnit, tc.data.WeightUnit)
+			}
+		})
+	}
+}
+
+func TestSessionDataValidate(t *testing.T) {
+	data := sessionData{Name: "legs", Date: "2026-03-14", Tags: []string{" Legs", "legs", "heavy"}}
+	assert.Empty(t, data.validate(context.Background()))
+	assert.Equal(t, []string{"legs", "heavy"}, data.Tags)
+
+	rpe := int16(11)
+	invalid := sessionData{Name: "", Date: "14/03/2026", Rpe: &rpe}
+	problems := invalid.validate(context.Background())
+	assert.Contains(t, problems, "name")
+	assert.Contains(t, problems, "date")
+	assert.Contains(t, problems, "rpe")
+	assert.Equal(t, problems["date"]+"; "+problems["name"]+"; "+problems["rpe"], problemsMessage(problems))
+}
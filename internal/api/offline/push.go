@@ -0,0 +1,677 @@
+package offline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/record"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/api/validation"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const MAX_MUTATIONS = 500
+
+const (
+	OpUpsert = "upsert"
+	OpDelete = "delete"
+)
+
+// Results of a mutation
+const (
+	StatusApplied  = "applied"  // the fields older than the stored ones are listed as stale
+	StatusStale    = "stale"    // nothing was applied, the stored fields are newer or the entity was deleted
+	StatusRejected = "rejected" // the mutation is invalid, the error tells why
+)
+
+// mutation is a change made by a client while offline. Upserts create the entity when its client id is unknown,
+// otherwise only the fields in data are updated. last_modified_at is the time the change was made at on the client.
+type mutation struct {
+	Entity         string          `json:"entity"`
+	Op             string          `json:"op"`
+	ClientID       uuid.UUID       `json:"client_id"`
+	LastModifiedAt time.Time       `json:"last_modified_at"`
+	Data           json.RawMessage `json:"data,omitempty"`
+}
+
+type pushReq struct {
+	Mutations []mutation `json:"mutations"`
+}
+
+func (r *pushReq) Valid(ctx context.Context) map[string]string {
+	problems := make(map[string]string)
+	if len(r.Mutations) == 0 || len(r.Mutations) > MAX_MUTATIONS {
+		problems["mutations"] = fmt.Sprintf("invalid mutations: between 1 and %d mutations can be pushed at once", MAX_MUTATIONS)
+		return problems
+	}
+	for i, m := range r.Mutations {
+		key := fmt.Sprintf("mutations[%d]", i)
+		if !slices.Contains(Entities, m.Entity) {
+			problems[key+".entity"] = "invalid entity: entity must be one of " + strings.Join(Entities, ", ")
+		}
+		if m.Op != OpUpsert && m.Op != OpDelete {
+			problems[key+".op"] = fmt.Sprintf("invalid op: op must be %s or %s", OpUpsert, OpDelete)
+		}
+		if m.ClientID == uuid.Nil {
+			problems[key+".client_id"] = "invalid client_id: client_id is required"
+		}
+		if m.LastModifiedAt.IsZero() {
+			problems[key+".last_modified_at"] = "invalid last_modified_at: last_modified_at is required"
+		}
+		if m.Op == OpUpsert && !bytes.HasPrefix(bytes.TrimSpace(m.Data), []byte("{")) {
+			problems[key+".data"] = "invalid data: upserts need an object with the fields to write"
+		}
+	}
+	return problems
+}
+
+type mutationRes struct {
+	ClientID    uuid.UUID `json:"client_id"`
+	Entity      string    `json:"entity"`
+	ID          string    `json:"id,omitempty"`
+	Status      string    `json:"status"`
+	StaleFields []string  `json:"stale_fields,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+type pushRes struct {
+	Results []mutationRes `json:"results"`
+}
+
+// HandlerPushMutations applies a batch of mutations in order, later mutations can refer to entities created by earlier ones.
+// Conflicts are resolved per field, the last written value wins and deletes win over any change.
+// A rejected mutation does not prevent the others from being applied.
+func HandlerPushMutations(pool *pgxpool.Pool, db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("push mutations failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		reqParams, problems, err := validation.DecodeValid[*pushReq](r)
+		if len(problems) > 0 {
+			reqLogger.Debug("push mutations failed - validation failed", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		} else if err != nil {
+			reqLogger.Debug("push mutations failed - invalid payload", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusBadRequest, "invalid payload", err)
+			return
+		}
+
+		tx, err := pool.Begin(r.Context())
+		if err != nil {
+			reqLogger.Error("push mutations failed - transaction start error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		defer tx.Rollback(r.Context())
+
+		// the field times are read and written by a single writer of the user at a time, otherwise
+		// concurrent pushes could both win against the same stored time
+		if err := db.WithTx(tx).LockSyncClock(r.Context(), userID); err != nil {
+			reqLogger.Error("push mutations failed - lock sync clock database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		now := time.Now().UTC()
+		res := pushRes{Results: make([]mutationRes, len(reqParams.Mutations))}
+		applied := 0
+		for i, m := range reqParams.Mutations {
+			result, err := applyMutation(r.Context(), tx, db, userID, m, now)
+			if err != nil {
+				reqLogger.Error(
+					"push mutations failed - database error",
+					slog.String("error", err.Error()),
+					slog.Int("mutation", i),
+				)
+				util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+				return
+			}
+			if result.Status == StatusApplied {
+				applied++
+			}
+			res.Results[i] = result
+		}
+
+		if err := tx.Commit(r.Context()); err != nil {
+			reqLogger.Error("push mutations failed - transaction commit error", slog.String("error", err.Error()))
+			err = fmt.Errorf("could not commit the transaction: %w", err)
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		reqLogger.Info("push mutations success", slog.Int("mutations", len(res.Results)), slog.Int("applied", applied))
+		util.RespondWithJSON(w, r, http.StatusOK, res)
+	}
+}
+
+// applyMutation runs the mutation in a savepoint, so a rejected one leaves the rest of the batch untouched
+func applyMutation(
+	ctx context.Context,
+	tx pgx.Tx,
+	db *database.Queries,
+	userID uuid.UUID,
+	m mutation,
+	now time.Time,
+) (mutationRes, error) {
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return mutationRes{}, err
+	}
+	defer savepoint.Rollback(ctx)
+
+	// clients with a clock ahead can not win every conflict to come
+	modifiedAt := m.LastModifiedAt.UTC().Truncate(time.Microsecond)
+	if modifiedAt.After(now) {
+		modifiedAt = now
+	}
+	a := applier{ctx: ctx, q: db.WithTx(savepoint), userID: userID, m: m, modifiedAt: modifiedAt}
+	res, err := a.apply()
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) {
+			return mutationRes{}, err
+		}
+		switch pgErr.Code {
+		case "23505":
+			return a.rejected("order already in use"), nil
+		case "23503":
+			return a.rejected("referenced entity not found"), nil
+		case "23514":
+			return a.rejected("value out of range"), nil
+		}
+		return mutationRes{}, err
+	}
+	if res.Status == StatusRejected {
+		return res, nil
+	}
+	return res, savepoint.Commit(ctx)
+}
+
+type applier struct {
+	ctx        context.Context
+	q          *database.Queries
+	userID     uuid.UUID
+	m          mutation
+	modifiedAt time.Time
+	res        mutationRes
+}
+
+func (a *applier) rejected(msg string) mutationRes {
+	return mutationRes{ClientID: a.m.ClientID, Entity: a.m.Entity, Status: StatusRejected, Error: msg}
+}
+
+func (a *applier) apply() (mutationRes, error) {
+	a.res = mutationRes{ClientID: a.m.ClientID, Entity: a.m.Entity}
+	entity, err := a.q.GetSyncEntity(a.ctx, a.m.ClientID)
+	if err == pgx.ErrNoRows {
+		// deleting an entity the server never got leaves nothing to do
+		if a.m.Op == OpDelete {
+			a.res.Status = StatusApplied
+			return a.res, nil
+		}
+		return a.create()
+	} else if err != nil {
+		return mutationRes{}, err
+	}
+
+	kind, id := entityOf(entity)
+	if entity.UserID != a.userID || kind != a.m.Entity {
+		return a.rejected("client_id already in use"), nil
+	}
+	a.res.ID = id
+	if entity.DeletedAt.Valid {
+		a.res.Status = StatusStale
+		if a.m.Op == OpDelete {
+			a.res.Status = StatusApplied
+		}
+		return a.res, nil
+	}
+	if a.m.Op == OpDelete {
+		return a.delete(entity)
+	}
+	return a.update(entity)
+}
+
+func (a *applier) create() (mutationRes, error) {
+	switch a.m.Entity {
+	case EntitySession:
+		var data sessionData
+		if msg := a.decodeNew(&data, "name", "date"); msg != "" {
+			return a.rejected(msg), nil
+		}
+		if problems := data.validate(a.ctx); len(problems) > 0 {
+			return a.rejected(problemsMessage(problems)), nil
+		}
+		// sessions use the client id as their id
+		_, err := a.q.RestoreSession(a.ctx, database.RestoreSessionParams{
+			ID:              a.m.ClientID,
+			Name:            data.Name,
+			Date:            dateParam(data.Date),
+			StartTimestamp:  timestampParam(data.StartTimestamp),
+			DurationMinutes: int2Param(data.DurationMinutes),
+			UserID:          a.userID,
+			Tags:            data.Tags,
+			Notes:           textParam(data.Notes),
+			Rpe:             int2Param(data.Rpe),
+			SleepQuality:    int2Param(data.SleepQuality),
+			Bodyweight:      float8Param(data.Bodyweight),
+			Mood:            int2Param(data.Mood),
+			Location:        textParam(data.Location),
+		})
+		if err == pgx.ErrNoRows {
+			return a.rejected("client_id already in use"), nil
+		} else if err != nil {
+			return mutationRes{}, err
+		}
+		a.res.ID = a.m.ClientID.String()
+		return a.created(data)
+
+	case EntitySet:
+		var data setData
+		if msg := a.decodeNew(&data, "session_id", "exercise_id", "set_order"); msg != "" {
+			return a.rejected(msg), nil
+		}
+		if problems := data.validate(a.ctx); len(problems) > 0 {
+			return a.rejected(problemsMessage(problems)), nil
+		}
+		if _, err := a.parent(data.SessionID, EntitySession); err == pgx.ErrNoRows {
+			return a.rejected("invalid session_id: session not found"), nil
+		} else if err != nil {
+			return mutationRes{}, err
+		}
+		newSet, err := a.q.CreateSet(a.ctx, database.CreateSetParams{
+			SetOrder:   data.SetOrder,
+			RestTime:   int4Param(data.RestTime),
+			SessionID:  data.SessionID,
+			ExerciseID: data.ExerciseID,
+			SetType:    data.SetType,
+			GroupKey:   textParam(data.GroupKey),
+		})
+		if err != nil {
+			return mutationRes{}, err
+		}
+		if err := a.q.UpdateSyncClientID(a.ctx, database.UpdateSyncClientIDParams{
+			ClientID: a.m.ClientID,
+			SetID:    pgtype.Int8{Int64: newSet.ID, Valid: true},
+		}); err != nil {
+			return mutationRes{}, err
+		}
+		if err := a.shareGroupRestTime(newSet); err != nil {
+			return mutationRes{}, err
+		}
+		a.res.ID = strconv.FormatInt(newSet.ID, 10)
+		return a.created(data)
+
+	default:
+		var data logData
+		if msg := a.decodeNew(&data, "set_client_id", "reps", "logs_order"); msg != "" {
+			return a.rejected(msg), nil
+		}
+		if problems := data.validate(a.ctx); len(problems) > 0 {
+			return a.rejected(problemsMessage(problems)), nil
+		}
+		parent, err := a.parent(data.SetClientID, EntitySet)
+		if err == pgx.ErrNoRows {
+			return a.rejected("invalid set_client_id: set not found"), nil
+		} else if err != nil {
+			return mutationRes{}, err
+		}
+		// logs take the exercise of their set
+		parentSet, err := a.q.GetSet(a.ctx, parent.SetID.Int64)
+		if err != nil {
+			return mutationRes{}, err
+		}
+		newLog, err := a.q.CreateLog(a.ctx, database.CreateLogParams{
+			Weight:         float8Param(data.Weight),
+			Reps:           data.Reps,
+			LogsOrder:      data.LogsOrder,
+			ExerciseID:     parentSet.ExerciseID,
+			SetID:          parentSet.ID,
+			Rpe:            float8Param(data.Rpe),
+			Rir:            int2Param(data.Rir),
+			Tempo:          textParam(data.Tempo),
+			ReachedFailure: data.ReachedFailure,
+			PartialReps:    data.PartialReps,
+			Notes:          textParam(data.Notes),
+			WeightUnit:     data.WeightUnit,
+		})
+		if err != nil {
+			return mutationRes{}, err
+		}
+		if err := a.q.UpdateSyncClientID(a.ctx, database.UpdateSyncClientIDParams{
+			ClientID: a.m.ClientID,
+			LogID:    pgtype.Int8{Int64: newLog.ID, Valid: true},
+		}); err != nil {
+			return mutationRes{}, err
+		}
+		if _, err := record.Detect(a.ctx, a.q, a.userID, newLog); err != nil {
+			return mutationRes{}, err
+		}
+		a.res.ID = strconv.FormatInt(newLog.ID, 10)
+		return a.created(data)
+	}
+}
+
+// created records every field of the new entity as written when the client made the change
+func (a *applier) created(data any) (mutationRes, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return mutationRes{}, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return mutationRes{}, err
+	}
+	if err := a.writeFieldTimes(fieldNames(fields)); err != nil {
+		return mutationRes{}, err
+	}
+	a.res.Status = StatusApplied
+	return a.res, nil
+}
+
+func (a *applier) update(entity database.SyncEntity) (mutationRes, error) {
+	times, err := fieldTimes(entity)
+	if err != nil {
+		return mutationRes{}, err
+	}
+	createdAt := entity.CreatedAt.Time
+
+	switch a.m.Entity {
+	case EntitySession:
+		current, err := a.q.GetSession(a.ctx, entity.SessionID.Bytes)
+		if err != nil {
+			return mutationRes{}, err
+		}
+		data := sessionDataFromDB(current)
+		applied, msg := a.merge(&data, times, createdAt)
+		if msg != "" {
+			return a.rejected(msg), nil
+		} else if len(applied) == 0 {
+			return a.res, nil
+		}
+		if problems := data.validate(a.ctx); len(problems) > 0 {
+			return a.rejected(problemsMessage(problems)), nil
+		}
+		if _, err := a.q.UpdateSession(a.ctx, database.UpdateSessionParams{
+			Name:            data.Name,
+			Date:            dateParam(data.Date),
+			StartTimestamp:  timestampParam(data.StartTimestamp),
+			DurationMinutes: int2Param(data.DurationMinutes),
+			Tags:            data.Tags,
+			Notes:           textParam(data.Notes),
+			Rpe:             int2Param(data.Rpe),
+			SleepQuality:    int2Param(data.SleepQuality),
+			Bodyweight:      float8Param(data.Bodyweight),
+			Mood:            int2Param(data.Mood),
+			Location:        textParam(data.Location),
+			ID:              current.ID,
+		}); err != nil {
+			return mutationRes{}, err
+		}
+		return a.updated(applied)
+
+	case EntitySet:
+		current, err := a.q.GetSet(a.ctx, entity.SetID.Int64)
+		if err != nil {
+			return mutationRes{}, err
+		}
+		data := setDataFromDB(current)
+		applied, msg := a.merge(&data, times, createdAt)
+		if msg != "" {
+			return a.rejected(msg), nil
+		} else if data.SessionID != current.SessionID {
+			return a.rejected("invalid session_id: sets can not move to another session"), nil
+		} else if len(applied) == 0 {
+			return a.res, nil
+		}
+		if problems := data.validate(a.ctx); len(problems) > 0 {
+			return a.rejected(problemsMessage(problems)), nil
+		}
+		// the logs of the set follow its exercise
+		if data.ExerciseID != current.ExerciseID {
+			if err := a.q.UpdateLogsExerciseIDBySetID(a.ctx, database.UpdateLogsExerciseIDBySetIDParams{
+				ExerciseID: data.ExerciseID,
+				SetID:      current.ID,
+			}); err != nil {
+				return mutationRes{}, err
+			}
+		}
+		updatedSet, err := a.q.UpdateSet(a.ctx, database.UpdateSetParams{
+			SetOrder:   data.SetOrder,
+			RestTime:   int4Param(data.RestTime),
+			ExerciseID: data.ExerciseID,
+			SetType:    data.SetType,
+			GroupKey:   textParam(data.GroupKey),
+			ID:         current.ID,
+		})
+		if err != nil {
+			return mutationRes{}, err
+		}
+		if err := a.shareGroupRestTime(updatedSet); err != nil {
+			return mutationRes{}, err
+		}
+		return a.updated(applied)
+
+	default:
+		rows, err := a.q.GetSyncLogsByIDs(a.ctx, []int64{entity.LogID.Int64})
+		if err != nil {
+			return mutationRes{}, err
+		} else if len(rows) == 0 {
+			return mutationRes{}, pgx.ErrNoRows
+		}
+		current := rows[0]
+		data := logDataFromDB(current)
+		applied, msg := a.merge(&data, times, createdAt)
+		if msg != "" {
+			return a.rejected(msg), nil
+		} else if data.SetClientID != current.SetClientID {
+			return a.rejected("invalid set_client_id: logs can not move to another set"), nil
+		} else if len(applied) == 0 {
+			return a.res, nil
+		}
+		if problems := data.validate(a.ctx); len(problems) > 0 {
+			return a.rejected(problemsMessage(problems)), nil
+		}
+		updatedLog, err := a.q.UpdateLog(a.ctx, database.UpdateLogParams{
+			Weight:         float8Param(data.Weight),
+			Reps:           data.Reps,
+			LogsOrder:      data.LogsOrder,
+			Rpe:            float8Param(data.Rpe),
+			Rir:            int2Param(data.Rir),
+			Tempo:          textParam(data.Tempo),
+			ReachedFailure: data.ReachedFailure,
+			PartialReps:    data.PartialReps,
+			Notes:          textParam(data.Notes),
+			WeightUnit:     data.WeightUnit,
+			ID:             current.ID,
+		})
+		if err != nil {
+			return mutationRes{}, err
+		}
+		// the records the log held are detected again with its new values
+		if err := a.q.DeletePersonalRecordsByLogID(a.ctx, current.ID); err != nil {
+			return mutationRes{}, err
+		}
+		if _, err := record.Detect(a.ctx, a.q, a.userID, updatedLog); err != nil {
+			return mutationRes{}, err
+		}
+		return a.updated(applied)
+	}
+}
+
+func (a *applier) updated(applied []string) (mutationRes, error) {
+	if err := a.writeFieldTimes(applied); err != nil {
+		return mutationRes{}, err
+	}
+	a.res.Status = StatusApplied
+	return a.res, nil
+}
+
+func (a *applier) delete(entity database.SyncEntity) (mutationRes, error) {
+	var err error
+	switch {
+	case entity.SessionID.Valid:
+		_, err = a.q.DeleteSession(a.ctx, database.DeleteSessionParams{ID: entity.SessionID.Bytes, UserID: a.userID})
+	case entity.SetID.Valid:
+		_, err = a.q.DeleteSet(a.ctx, entity.SetID.Int64)
+	default:
+		_, err = a.q.DeleteLog(a.ctx, entity.LogID.Int64)
+	}
+	if err != nil {
+		return mutationRes{}, err
+	}
+	a.res.Status = StatusApplied
+	return a.res, nil
+}
+
+// decodeNew decodes the data of a created entity, it returns why the data is not valid
+func (a *applier) decodeNew(data any, required ...string) string {
+	fields, err := decodeFields(a.m.Data, data)
+	if err != nil {
+		return "invalid data: " + err.Error()
+	}
+	var missing []string
+	for _, field := range required {
+		if _, ok := fields[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return "invalid data: missing " + strings.Join(missing, ", ")
+	}
+	return ""
+}
+
+// merge applies onto data the fields of the mutation written after the stored ones and returns them.
+// The stale fields are kept in the result, it returns why the data is not valid.
+func (a *applier) merge(data any, times map[string]time.Time, createdAt time.Time) ([]string, string) {
+	applied, stale, err := mergeFields(data, a.m.Data, a.modifiedAt, times, createdAt)
+	if err != nil {
+		return nil, "invalid data: " + err.Error()
+	}
+	a.res.StaleFields = stale
+	a.res.Status = StatusStale
+	return applied, ""
+}
+
+func (a *applier) writeFieldTimes(fields []string) error {
+	encoded, err := marshalFieldTimes(fields, a.modifiedAt)
+	if err != nil {
+		return err
+	}
+	return a.q.UpdateSyncFieldTimes(a.ctx, database.UpdateSyncFieldTimesParams{
+		FieldModifiedAt: encoded,
+		ClientID:        a.m.ClientID,
+	})
+}
+
+// parent returns the entity of the user referred to by a mutation, it must exist and not be deleted
+func (a *applier) parent(clientID uuid.UUID, entity string) (database.SyncEntity, error) {
+	parent, err := a.q.GetSyncEntity(a.ctx, clientID)
+	if err != nil {
+		return database.SyncEntity{}, err
+	}
+	if kind, _ := entityOf(parent); kind != entity || parent.UserID != a.userID || parent.DeletedAt.Valid {
+		return database.SyncEntity{}, pgx.ErrNoRows
+	}
+	return parent, nil
+}
+
+// sets of the same group share the rest time, as the sets endpoints do
+func (a *applier) shareGroupRestTime(s database.Set) error {
+	if !s.GroupKey.Valid {
+		return nil
+	}
+	return a.q.UpdateSetsRestTimeByGroup(a.ctx, database.UpdateSetsRestTimeByGroupParams{
+		RestTime:  s.RestTime,
+		SessionID: s.SessionID,
+		GroupKey:  s.GroupKey,
+	})
+}
+
+// decodeFields decodes raw into data and returns the fields it holds, unknown fields are an error
+func decodeFields(raw json.RawMessage, data any) (map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(data); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// mergeFields decodes into data the fields of raw modified after the time they were stored at,
+// the fields missing from times were stored when the entity was created. Ties keep the stored value.
+func mergeFields(data any, raw json.RawMessage, modifiedAt time.Time, times map[string]time.Time, createdAt time.Time) ([]string, []string, error) {
+	// the whole mutation is decoded first so invalid fields are reported even when stale
+	check := newOfSameType(data)
+	fields, err := decodeFields(raw, check)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newer := make(map[string]json.RawMessage, len(fields))
+	var stale []string
+	for field, value := range fields {
+		storedAt, ok := times[field]
+		if !ok {
+			storedAt = createdAt
+		}
+		if modifiedAt.After(storedAt) {
+			newer[field] = value
+		} else {
+			stale = append(stale, field)
+		}
+	}
+	if len(newer) > 0 {
+		encoded, err := json.Marshal(newer)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := json.Unmarshal(encoded, data); err != nil {
+			return nil, nil, err
+		}
+	}
+	slices.Sort(stale)
+	return fieldNames(newer), stale, nil
+}
+
+// newOfSameType returns a new value of the type data points to
+func newOfSameType(data any) any {
+	return reflect.New(reflect.TypeOf(data).Elem()).Interface()
+}
+
+func fieldNames(fields map[string]json.RawMessage) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,298 @@
+package offline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/auth"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getChanges(t *testing.T, db *database.Queries, authConfig *auth.Config, userID uuid.UUID, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	req, err := http.NewRequest("GET", "/api/v1/sync/changes"+query, nil)
+	require.NoError(t, err)
+	req = req.WithContext(util.ContextWithUser(req.Context(), userID))
+	rr := httptest.NewRecorder()
+	middleware.RequestID(HandlerGetChanges(db, authConfig, logger)).ServeHTTP(rr, req)
+	return rr
+}
+
+func pushMutations(t *testing.T, db *database.Queries, userID uuid.UUID, mutations ...mutation) pushRes {
+	t.Helper()
+	body, err := json.Marshal(pushReq{Mutations: mutations})
+	require.NoError(t, err)
+	req, err := http.NewRequest("POST", "/api/v1/sync/mutations", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	req = req.WithContext(util.ContextWithUser(req.Context(), userID))
+	rr := httptest.NewRecorder()
+	middleware.RequestID(HandlerPushMutations(dbPool, db, logger)).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var res pushRes
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+	require.Len(t, res.Results, len(mutations))
+	return res
+}
+
+func decodeChanges(t *testing.T, rr *httptest.ResponseRecorder) changesRes {
+	t.Helper()
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var res changesRes
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+	return res
+}
+
+func TestHandlerGetChanges(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	authConfig := &auth.Config{JWTsecret: "testSecret"}
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	other := testutil.CreateUserDBTestHelper(t, db, "otheruser", "testpassword", false)
+	squatID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+	sessionID := testutil.CreateSessionDBTestHelper(t, db, "legs", user.ID)
+	setID := testutil.CreateSetDBTestHelper(t, db, sessionID, squatID)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 5, 1, squatID, setID, 100)
+	testutil.CreateSessionDBTestHelper(t, db, "hidden", other.ID)
+
+	// the first sync returns every entity, parents first
+	res := decodeChanges(t, getChanges(t, db, authConfig, user.ID, ""))
+	require.Len(t, res.Changes, 3)
+	assert.False(t, res.HasMore)
+	assert.Equal(t, EntitySession, res.Changes[0].Entity)
+	assert.Equal(t, sessionID, res.Changes[0].ClientID)
+	assert.Equal(t, EntitySet, res.Changes[1].Entity)
+	assert.Equal(t, EntityLog, res.Changes[2].Entity)
+	token := res.SyncToken
+
+	t.Run("nothing changed", func(t *testing.T) {
+		res := decodeChanges(t, getChanges(t, db, authConfig, user.ID, "?since="+token))
+		assert.Empty(t, res.Changes)
+		assert.Equal(t, token, res.SyncToken)
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		first := decodeChanges(t, getChanges(t, db, authConfig, user.ID, "?limit=2"))
+		require.Len(t, first.Changes, 2)
+		assert.True(t, first.HasMore)
+		second := decodeChanges(t, getChanges(t, db, authConfig, user.ID, "?limit=2&since="+first.SyncToken))
+		require.Len(t, second.Changes, 1)
+		assert.False(t, second.HasMore)
+		assert.Equal(t, token, second.SyncToken)
+	})
+
+	t.Run("updates and tombstones", func(t *testing.T) {
+		_, err := db.DeleteSet(t.Context(), setID)
+		require.NoError(t, err)
+		res := decodeChanges(t, getChanges(t, db, authConfig, user.ID, "?since="+token))
+		// the log is deleted along with its set
+		require.Len(t, res.Changes, 2)
+		for _, c := range res.Changes {
+			assert.True(t, c.Deleted)
+			assert.Nil(t, c.Data)
+		}
+
+		// tombstones are left out of the first sync
+		res = decodeChanges(t, getChanges(t, db, authConfig, user.ID, ""))
+		require.Len(t, res.Changes, 1)
+		assert.Equal(t, EntitySession, res.Changes[0].Entity)
+	})
+
+	otherToken, err := encodeToken(0, other.ID, authConfig.JWTsecret)
+	require.NoError(t, err)
+	testCases := []struct {
+		name  string
+		query string
+	}{
+		{name: "invalid token", query: "?since=invalid"},
+		{name: "token of another user", query: "?since=" + otherToken},
+		{name: "invalid limit", query: "?limit=abc"},
+		{name: "limit too big", query: "?limit=1001"},
+		{name: "negative limit", query: "?limit=-1"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := getChanges(t, db, authConfig, user.ID, tc.query)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	}
+}
+
+func TestHandlerPushMutations(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	other := testutil.CreateUserDBTestHelper(t, db, "otheruser", "testpassword", false)
+	squatID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+
+	sessionClientID, setClientID, logClientID := uuid.New(), uuid.New(), uuid.New()
+	offlineAt := time.Now().Add(-time.Hour)
+	raw := func(data string) json.RawMessage { return json.RawMessage(data) }
+
+	// entities created offline refer to each other by client id
+	res := pushMutations(t, db, user.ID,
+		mutation{
+			Entity: EntitySession, Op: OpUpsert, ClientID: sessionClientID, LastModifiedAt: offlineAt,
+			Data: raw(`{"name": "legs", "date": "2026-03-14", "tags": ["Heavy"]}`),
+		},
+		mutation{
+			Entity: EntitySet, Op: OpUpsert, ClientID: setClientID, LastModifiedAt: offlineAt,
+			Data: raw(`{"session_id": "` + sessionClientID.String() + `", "exercise_id": ` + jsonNumber(squatID) + `, "set_order": 1}`),
+		},
+		mutation{
+			Entity: EntityLog, Op: OpUpsert, ClientID: logClientID, LastModifiedAt: offlineAt,
+			Data: raw(`{"set_client_id": "` + setClientID.String() + `", "reps": 5, "logs_order": 1, "weight": 100}`),
+		},
+	)
+	for _, result := range res.Results {
+		assert.Equal(t, StatusApplied, result.Status, result.Error)
+		assert.NotEmpty(t, result.ID)
+	}
+	session, err := db.GetSession(t.Context(), sessionClientID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"heavy"}, session.Tags)
+	logEntity, err := db.GetSyncEntity(t.Context(), logClientID)
+	require.NoError(t, err)
+	require.True(t, logEntity.LogID.Valid)
+
+	t.Run("fields resolved by last writer", func(t *testing.T) {
+		before, err := db.GetSyncLogsByIDs(t.Context(), []int64{logEntity.LogID.Int64})
+		require.NoError(t, err)
+		require.Len(t, before, 1)
+
+		res := pushMutations(t, db, user.ID,
+			mutation{
+				Entity: EntityLog, Op: OpUpsert, ClientID: logClientID, LastModifiedAt: offlineAt.Add(time.Minute),
+				Data: raw(`{"weight": 110}`),
+			},
+			mutation{
+				Entity: EntityLog, Op: OpUpsert, ClientID: logClientID, LastModifiedAt: offlineAt.Add(-time.Minute),
+				Data: raw(`{"weight": 90, "reps": 3}`),
+			},
+			mutation{
+				Entity: EntityLog, Op: OpUpsert, ClientID: logClientID, LastModifiedAt: offlineAt,
+				Data: raw(`{"reps": 4}`),
+			},
+		)
+		assert.Equal(t, StatusApplied, res.Results[0].Status)
+		assert.Equal(t, StatusStale, res.Results[1].Status)
+		assert.Equal(t, []string{"reps", "weight"}, res.Results[1].StaleFields)
+		// ties keep the stored value
+		assert.Equal(t, StatusStale, res.Results[2].Status)
+
+		logs, err := db.GetSyncLogsByIDs(t.Context(), []int64{logEntity.LogID.Int64})
+		require.NoError(t, err)
+		require.Len(t, logs, 1)
+		assert.Equal(t, 110.0, logs[0].Weight.Float64)
+		assert.Equal(t, int32(5), logs[0].Reps)
+		assert.True(t, logs[0].LastModifiedAt.Time.After(before[0].LastModifiedAt.Time))
+	})
+
+	t.Run("invalid mutations are rejected alone", func(t *testing.T) {
+		res := pushMutations(t, db, user.ID,
+			mutation{
+				Entity: EntityLog, Op: OpUpsert, ClientID: logClientID, LastModifiedAt: time.Now(),
+				Data: raw(`{"reps": -1}`),
+			},
+			mutation{
+				Entity: EntitySet, Op: OpUpsert, ClientID: uuid.New(), LastModifiedAt: time.Now(),
+				Data: raw(`{"session_id": "` + uuid.NewString() + `", "exercise_id": ` + jsonNumber(squatID) + `, "set_order": 2}`),
+			},
+			mutation{
+				Entity: EntitySession, Op: OpUpsert, ClientID: logClientID, LastModifiedAt: time.Now(),
+				Data: raw(`{"name": "push"}`),
+			},
+			mutation{
+				Entity: EntitySession, Op: OpUpsert, ClientID: sessionClientID, LastModifiedAt: time.Now(),
+				Data: raw(`{"name": "legs day"}`),
+			},
+		)
+		assert.Equal(t, StatusRejected, res.Results[0].Status)
+		assert.Equal(t, StatusRejected, res.Results[1].Status)
+		assert.Equal(t, StatusRejected, res.Results[2].Status)
+		assert.Equal(t, StatusApplied, res.Results[3].Status)
+
+		session, err := db.GetSession(t.Context(), sessionClientID)
+		require.NoError(t, err)
+		assert.Equal(t, "legs day", session.Name)
+	})
+
+	t.Run("entities of another user", func(t *testing.T) {
+		res := pushMutations(t, db, other.ID, mutation{
+			Entity: EntitySession, Op: OpDelete, ClientID: sessionClientID, LastModifiedAt: time.Now(),
+		})
+		assert.Equal(t, StatusRejected, res.Results[0].Status)
+	})
+
+	t.Run("deletes win", func(t *testing.T) {
+		res := pushMutations(t, db, user.ID,
+			mutation{Entity: EntitySet, Op: OpDelete, ClientID: setClientID, LastModifiedAt: offlineAt},
+			mutation{
+				Entity: EntityLog, Op: OpUpsert, ClientID: logClientID, LastModifiedAt: time.Now(),
+				Data: raw(`{"reps": 8}`),
+			},
+			mutation{Entity: EntitySet, Op: OpDelete, ClientID: setClientID, LastModifiedAt: time.Now()},
+		)
+		assert.Equal(t, StatusApplied, res.Results[0].Status)
+		assert.Equal(t, StatusStale, res.Results[1].Status)
+		assert.Equal(t, StatusApplied, res.Results[2].Status)
+
+		entity, err := db.GetSyncEntity(t.Context(), logClientID)
+		require.NoError(t, err)
+		assert.True(t, entity.DeletedAt.Valid)
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/v1/sync/mutations", bytes.NewBufferString(`{"mutations": []}`))
+		require.NoError(t, err)
+		req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+		rr := httptest.NewRecorder()
+		middleware.RequestID(HandlerPushMutations(dbPool, db, logger)).ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func jsonNumber(value int32) string {
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
+
+func TestLockSyncClock(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+
+	tx, err := dbPool.Begin(t.Context())
+	require.NoError(t, err)
+	defer tx.Rollback(t.Context())
+	require.NoError(t, db.WithTx(tx).LockSyncClock(t.Context(), user.ID))
+
+	// a second push of the user waits until the first one is done
+	second, err := dbPool.Begin(t.Context())
+	require.NoError(t, err)
+	defer second.Rollback(t.Context())
+	ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+	defer cancel()
+	assert.Error(t, db.WithTx(second).LockSyncClock(ctx, user.ID))
+
+	require.NoError(t, tx.Commit(t.Context()))
+	third, err := dbPool.Begin(t.Context())
+	require.NoError(t, err)
+	defer third.Rollback(t.Context())
+	assert.NoError(t, db.WithTx(third).LockSyncClock(t.Context(), user.ID))
+}
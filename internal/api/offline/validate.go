@@ -0,0 +1,174 @@
+package offline
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/exlog"
+	"github.com/CTSDM/gogym/internal/api/session"
+	"github.com/CTSDM/gogym/internal/api/set"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/validation"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// The data of an entity is validated as a whole once the fields of a mutation are applied,
+// values are normalized the way the rest of the api stores them.
+
+func (d *sessionData) validate(ctx context.Context) map[string]string {
+	problems := make(map[string]string)
+	if err := validation.String(d.Name, apiconstants.MinSessionNameLength, apiconstants.MaxSessionNameLength); err != nil {
+		problems["name"] = "invalid name: " + err.Error()
+	}
+	if _, err := validation.Date(d.Date, apiconstants.DATE_LAYOUT, nil, nil); err != nil {
+		problems["date"] = "invalid date: " + err.Error()
+	}
+	if d.DurationMinutes != nil && *d.DurationMinutes < 0 {
+		problems["duration_minutes"] = "invalid duration_minutes: duration_minutes must be positive"
+	}
+	tags, err := session.NormalizeTags(d.Tags)
+	if err != nil {
+		problems["tags"] = "invalid tags: " + err.Error()
+	}
+	d.Tags = tags
+	if d.Notes != nil {
+		if err := validation.String(*d.Notes, 0, apiconstants.MaxNotesLength); err != nil {
+			problems["notes"] = "invalid notes: " + err.Error()
+		}
+	}
+	if d.Location != nil {
+		if err := validation.String(*d.Location, 0, apiconstants.MaxLocationLength); err != nil {
+			problems["location"] = "invalid location: " + err.Error()
+		}
+	}
+	for field, value := range map[string]*int16{"rpe": d.Rpe, "sleep_quality": d.SleepQuality, "mood": d.Mood} {
+		min, max := apiconstants.MinWellnessScore, apiconstants.MaxWellnessScore
+		if field == "rpe" {
+			min, max = apiconstants.MinRPE, apiconstants.MaxRPE
+		}
+		if value != nil && (int(*value) < min || int(*value) > max) {
+			problems[field] = fmt.Sprintf("invalid %s: %s must be between %d and %d", field, field, min, max)
+		}
+	}
+	if d.Bodyweight != nil && (*d.Bodyweight <= 0 || *d.Bodyweight > apiconstants.MaxBodyweight) {
+		problems["bodyweight"] = fmt.Sprintf("invalid bodyweight: bodyweight must be between 0 and %d", apiconstants.MaxBodyweight)
+	}
+	return problems
+}
+
+// sets are validated as the sets endpoints do
+func (d *setData) validate(ctx context.Context) map[string]string {
+	req := set.SetReq{
+		ExerciseID: d.ExerciseID,
+		SetOrder:   d.SetOrder,
+		SetType:    d.SetType,
+	}
+	if d.RestTime != nil {
+		req.RestTime = *d.RestTime
+	}
+	if d.GroupKey != nil {
+		req.GroupKey = *d.GroupKey
+	}
+	problems := req.Valid(ctx)
+
+	d.SetType = req.SetType
+	if d.RestTime != nil {
+		d.RestTime = &req.RestTime
+	}
+	d.GroupKey = textPointer(req.GroupKey, req.GroupKey != "")
+	return problems
+}
+
+// logs are validated as the logs endpoints do, their weight is always in kilograms
+func (d *logData) validate(ctx context.Context) map[string]string {
+	if d.WeightUnit == "" {
+		d.WeightUnit = units.KG
+	}
+	req := exlog.LogReq{
+		Reps:           d.Reps,
+		Order:          d.LogsOrder,
+		ReachedFailure: d.ReachedFailure,
+		PartialReps:    int32(d.PartialReps),
+		Unit:           d.WeightUnit,
+	}
+	if d.Weight != nil {
+		req.Weight = *d.Weight
+	}
+	if d.Rpe != nil {
+		req.RPE = *d.Rpe
+	}
+	if d.Rir != nil {
+		rir := int32(*d.Rir)
+		req.RIR = &rir
+	}
+	if d.Tempo != nil {
+		req.Tempo = *d.Tempo
+	}
+	if d.Notes != nil {
+		req.Notes = *d.Notes
+	}
+	problems := req.Valid(ctx)
+	if d.Rpe != nil && *d.Rpe == 0 {
+		problems["rpe"] = fmt.Sprintf("invalid rpe: rpe must be between %d and %d", apiconstants.MinRPE, apiconstants.MaxRPE)
+	}
+
+	if d.Weight != nil {
+		d.Weight = &req.Weight
+	}
+	if d.Tempo != nil {
+		d.Tempo = &req.Tempo
+	}
+	return problems
+}
+
+// problemsMessage joins the problems in a single message, sorted so it does not change between calls
+func problemsMessage(problems map[string]string) string {
+	messages := slices.Collect(maps.Values(problems))
+	slices.Sort(messages)
+	return strings.Join(messages, "; ")
+}
+
+func int2Param(value *int16) pgtype.Int2 {
+	if value == nil {
+		return pgtype.Int2{}
+	}
+	return pgtype.Int2{Int16: *value, Valid: true}
+}
+
+func int4Param(value *int32) pgtype.Int4 {
+	if value == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *value, Valid: true}
+}
+
+func float8Param(value *float64) pgtype.Float8 {
+	if value == nil {
+		return pgtype.Float8{}
+	}
+	return pgtype.Float8{Float64: *value, Valid: true}
+}
+
+func textParam(value *string) pgtype.Text {
+	if value == nil {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *value, Valid: true}
+}
+
+func timestampParam(value *time.Time) pgtype.Timestamp {
+	if value == nil {
+		return pgtype.Timestamp{}
+	}
+	return pgtype.Timestamp{Time: value.UTC(), Valid: true}
+}
+
+func dateParam(value string) pgtype.Date {
+	date, _ := time.Parse(apiconstants.DATE_LAYOUT, value)
+	return pgtype.Date{Time: date, Valid: true}
+}
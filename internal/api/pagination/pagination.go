@@ -0,0 +1,61 @@
+package pagination
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/signedtoken"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the keyset position of the last item of a page.
+// Items are ordered by date and then by id, so both are needed to resume.
+type Cursor struct {
+	Date time.Time
+	ID   string
+	Sort string
+}
+
+type cursorPayload struct {
+	Date string `json:"d"`
+	ID   string `json:"i"`
+	Sort string `json:"s,omitempty"`
+}
+
+// Encode returns an opaque signed token.
+// Signing prevents clients from crafting cursors pointing anywhere in the keyset.
+func Encode(c Cursor, secret string) (string, error) {
+	return signedtoken.Encode("cursor", cursorPayload{
+		Date: c.Date.Format(apiconstants.DATE_LAYOUT),
+		ID:   c.ID,
+		Sort: c.Sort,
+	}, secret)
+}
+
+func Decode(token, secret string) (Cursor, error) {
+	var payload cursorPayload
+	if err := signedtoken.Decode("cursor", token, secret, &payload); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	date, err := time.Parse(apiconstants.DATE_LAYOUT, payload.Date)
+	if err != nil || payload.ID == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return Cursor{Date: date, ID: payload.ID, Sort: payload.Sort}, nil
+}
+
+// NextLink builds an RFC 8288 Link header value pointing to the next page.
+// The request query is kept so filters carry over, the offset is dropped in favour of the cursor.
+func NextLink(r *http.Request, cursor string) string {
+	query := r.URL.Query()
+	query.Del("offset")
+	query.Set("cursor", cursor)
+	next := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+	return fmt.Sprintf("<%s>; rel=\"next\"", next.String())
+}
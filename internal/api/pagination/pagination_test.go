@@ -0,0 +1,76 @@
+package pagination
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	secret := "secret"
+	cursor := Cursor{
+		Date: time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC),
+		ID:   "6f0b1c2e-2b8c-4a59-9a43-3a2c1f2f7e11",
+		Sort: "date_desc",
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		token, err := Encode(cursor, secret)
+		require.NoError(t, err)
+		decoded, err := Decode(token, secret)
+		require.NoError(t, err)
+		assert.Equal(t, cursor, decoded)
+	})
+
+	t.Run("empty secret", func(t *testing.T) {
+		_, err := Encode(cursor, "")
+		assert.Error(t, err)
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		token, err := Encode(cursor, secret)
+		require.NoError(t, err)
+		other, err := Encode(Cursor{Date: cursor.Date, ID: "1"}, secret)
+		require.NoError(t, err)
+		payload, _, _ := strings.Cut(other, ".")
+		_, signature, _ := strings.Cut(token, ".")
+		_, err = Decode(payload+"."+signature, secret)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		token, err := Encode(cursor, secret)
+		require.NoError(t, err)
+		_, err = Decode(token, "another secret")
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("malformed tokens", func(t *testing.T) {
+		for _, token := range []string{"", "nodot", ".", "abc.def", "!!!.???"} {
+			_, err := Decode(token, secret)
+			assert.ErrorIs(t, err, ErrInvalidCursor, "token %q", token)
+		}
+	})
+}
+
+func TestNextLink(t *testing.T) {
+	req, err := http.NewRequest("GET", "/api/v1/sessions?offset=10&limit=5&tags=legs", nil)
+	require.NoError(t, err)
+
+	link := NextLink(req, "abc.def")
+	require.True(t, strings.HasPrefix(link, "<"))
+	require.True(t, strings.HasSuffix(link, `>; rel="next"`))
+
+	target, err := url.Parse(strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`))
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/sessions", target.Path)
+	assert.False(t, target.Query().Has("offset"))
+	assert.Equal(t, "abc.def", target.Query().Get("cursor"))
+	assert.Equal(t, "5", target.Query().Get("limit"))
+	assert.Equal(t, "legs", target.Query().Get("tags"))
+}
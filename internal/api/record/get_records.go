@@ -0,0 +1,164 @@
+package record
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type recordsRes struct {
+	Records []RecordRes `json:"records"`
+}
+
+type exerciseRecordsRes struct {
+	Current []RecordRes `json:"current"`
+	History []RecordRes `json:"history"` // every record the exercise had, newest first
+}
+
+// HandlerGetRecords returns the current records of the user, optionally filtered by exercise_id
+func HandlerGetRecords(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("get records failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		exerciseID := pgtype.Int4{}
+		if exerciseIDString := r.URL.Query().Get("exercise_id"); exerciseIDString != "" {
+			id, err := strconv.ParseInt(exerciseIDString, 10, 32)
+			if err != nil {
+				reqLogger.Debug("get records failed - invalid exercise id", slog.String("exercise_id", exerciseIDString))
+				util.RespondWithJSON(w, r, http.StatusBadRequest, map[string]string{
+					"exercise_id": "invalid exercise_id: exercise_id must be a number",
+				})
+				return
+			}
+			exerciseID = pgtype.Int4{Int32: int32(id), Valid: true}
+		}
+
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "get records")
+		if !ok {
+			return
+		}
+
+		current, err := db.GetCurrentPersonalRecords(r.Context(), database.GetCurrentPersonalRecordsParams{
+			UserID:     userID,
+			ExerciseID: exerciseID,
+		})
+		if err != nil {
+			reqLogger.Error("get records failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		resParams := recordsRes{Records: make([]RecordRes, len(current))}
+		for i, c := range current {
+			resParams.Records[i] = RecordResFromDB(currentRecord(c), c.Date, unit)
+		}
+		util.RespondWithJSON(w, r, http.StatusOK, resParams)
+	}
+}
+
+// HandlerGetExerciseRecords returns the current records of the user for the exercise and their history
+func HandlerGetExerciseRecords(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("get exercise records failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		exerciseIDString := r.PathValue("id")
+		exerciseID, err := strconv.ParseInt(exerciseIDString, 10, 32)
+		if err != nil {
+			reqLogger.Debug("invalid exercise id format", slog.String("exercise_id", exerciseIDString))
+			util.RespondWithError(w, r, http.StatusBadRequest, "invalid exercise id format", err)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()), slog.Int64("exercise_id", exerciseID))
+
+		if _, err := db.GetExercise(r.Context(), int32(exerciseID)); err == pgx.ErrNoRows {
+			reqLogger.Debug("get exercise records failed - exercise not in database")
+			util.RespondWithError(w, r, http.StatusNotFound, "exercise id not found", err)
+			return
+		} else if err != nil {
+			reqLogger.Error("get exercise records failed - get exercise database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "get exercise records")
+		if !ok {
+			return
+		}
+
+		current, err := db.GetCurrentPersonalRecords(r.Context(), database.GetCurrentPersonalRecordsParams{
+			UserID:     userID,
+			ExerciseID: pgtype.Int4{Int32: int32(exerciseID), Valid: true},
+		})
+		if err != nil {
+			reqLogger.Error("get exercise records failed - get current records database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		history, err := db.GetPersonalRecordsHistory(r.Context(), database.GetPersonalRecordsHistoryParams{
+			UserID:     userID,
+			ExerciseID: int32(exerciseID),
+		})
+		if err != nil {
+			reqLogger.Error("get exercise records failed - get history database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		resParams := exerciseRecordsRes{
+			Current: make([]RecordRes, len(current)),
+			History: make([]RecordRes, len(history)),
+		}
+		for i, c := range current {
+			resParams.Current[i] = RecordResFromDB(currentRecord(c), c.Date, unit)
+		}
+		for i, h := range history {
+			resParams.History[i] = RecordResFromDB(historyRecord(h), h.Date, unit)
+		}
+		util.RespondWithJSON(w, r, http.StatusOK, resParams)
+	}
+}
+
+func currentRecord(row database.GetCurrentPersonalRecordsRow) database.PersonalRecord {
+	return database.PersonalRecord{
+		ID:         row.ID,
+		CreatedAt:  row.CreatedAt,
+		UserID:     row.UserID,
+		ExerciseID: row.ExerciseID,
+		RecordType: row.RecordType,
+		Value:      row.Value,
+		Weight:     row.Weight,
+		LogID:      row.LogID,
+	}
+}
+
+func historyRecord(row database.GetPersonalRecordsHistoryRow) database.PersonalRecord {
+	return database.PersonalRecord{
+		ID:         row.ID,
+		CreatedAt:  row.CreatedAt,
+		UserID:     row.UserID,
+		ExerciseID: row.ExerciseID,
+		RecordType: row.RecordType,
+		Value:      row.Value,
+		Weight:     row.Weight,
+		LogID:      row.LogID,
+	}
+}
@@ -0,0 +1,194 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createRecordsTestHelper logs weight x reps in a new set of the session and detects its records
+func createRecordsTestHelper(
+	t *testing.T,
+	db *database.Queries,
+	userID, sessionID uuid.UUID,
+	exerciseID int32,
+	weight float64,
+	reps int32,
+) {
+	setID := testutil.CreateSetDBTestHelper(t, db, sessionID, exerciseID)
+	logID := testutil.CreateLogExerciseDBTestHelper(t, db, reps, 1, exerciseID, setID, weight)
+	log, err := db.GetLog(context.Background(), logID)
+	require.NoError(t, err)
+	_, err = Detect(context.Background(), db, userID, log)
+	require.NoError(t, err)
+}
+
+func TestHandlerGetRecords(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	otherUser := testutil.CreateUserDBTestHelper(t, db, "otheruser", "testpassword", false)
+	squatID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+	benchID := testutil.CreateExerciseDBTestHelper(t, db, "bench press")
+	sessionID := testutil.CreateSessionDBTestHelper(t, db, "test session", user.ID)
+	createRecordsTestHelper(t, db, user.ID, sessionID, squatID, 100, 5)
+	createRecordsTestHelper(t, db, user.ID, sessionID, squatID, 120, 1)
+	createRecordsTestHelper(t, db, user.ID, sessionID, benchID, 80, 5)
+
+	testCases := []struct {
+		name       string
+		query      string
+		userID     uuid.UUID
+		statusCode int
+		expected   map[string]float64 // value by exercise and type of the expected records
+	}{
+		{
+			name:       "happy path: current records of every exercise",
+			userID:     user.ID,
+			statusCode: http.StatusOK,
+			expected: map[string]float64{
+				"squat max_weight":           120,
+				"squat max_reps 100":         5,
+				"squat max_reps 120":         1,
+				"squat e1rm":                 120,
+				"squat session_volume":       620,
+				"bench press max_weight":     80,
+				"bench press max_reps 80":    5,
+				"bench press e1rm":           93.333,
+				"bench press session_volume": 400,
+			},
+		},
+		{
+			name:       "happy path: filtered by exercise",
+			query:      "?exercise_id=" + strconv.Itoa(int(benchID)),
+			userID:     user.ID,
+			statusCode: http.StatusOK,
+			expected: map[string]float64{
+				"bench press max_weight":     80,
+				"bench press max_reps 80":    5,
+				"bench press e1rm":           93.333,
+				"bench press session_volume": 400,
+			},
+		},
+		{
+			name:       "happy path: user without records",
+			userID:     otherUser.ID,
+			statusCode: http.StatusOK,
+			expected:   map[string]float64{},
+		},
+		{
+			name:       "invalid exercise id",
+			query:      "?exercise_id=squat",
+			userID:     user.ID,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	names := map[int32]string{squatID: "squat", benchID: "bench press"}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/test"+tc.query, bytes.NewReader(nil))
+			require.NoError(t, err)
+			req = req.WithContext(util.ContextWithUser(req.Context(), tc.userID))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerGetRecords(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				return
+			}
+
+			var resParams recordsRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			got := make(map[string]float64, len(resParams.Records))
+			for _, r := range resParams.Records {
+				key := names[r.ExerciseID] + " " + r.Type
+				if r.Weight != nil {
+					key += " " + strconv.FormatFloat(*r.Weight, 'f', -1, 64)
+				}
+				got[key] = r.Value
+			}
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestHandlerGetExerciseRecords(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	squatID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+	sessionID := testutil.CreateSessionDBTestHelper(t, db, "test session", user.ID)
+	createRecordsTestHelper(t, db, user.ID, sessionID, squatID, 100, 1)
+	createRecordsTestHelper(t, db, user.ID, sessionID, squatID, 110, 1)
+
+	testCases := []struct {
+		name            string
+		exerciseID      string
+		statusCode      int
+		expectedCurrent int
+		expectedHistory int
+	}{
+		{
+			name:       "happy path",
+			exerciseID: strconv.Itoa(int(squatID)),
+			statusCode: http.StatusOK,
+			// max weight, reps at 100 and at 110, e1rm and session volume
+			expectedCurrent: 5,
+			// the volume record of the first log was replaced by the one of the second
+			expectedHistory: 7,
+		},
+		{
+			name:       "exercise not found",
+			exerciseID: strconv.Itoa(int(squatID) + 1000),
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "invalid exercise id",
+			exerciseID: "squat",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/test", bytes.NewReader(nil))
+			require.NoError(t, err)
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			req.SetPathValue("id", tc.exerciseID)
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerGetExerciseRecords(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				return
+			}
+
+			var resParams exerciseRecordsRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			assert.Len(t, resParams.Current, tc.expectedCurrent)
+			assert.Len(t, resParams.History, tc.expectedHistory)
+			for _, r := range resParams.Current {
+				if r.Type == TypeMaxWeight {
+					assert.Equal(t, 110.0, r.Value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package record
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	TypeMaxWeight     = "max_weight"     // heaviest weight lifted
+	TypeMaxReps       = "max_reps"       // most reps performed with a given weight
+	TypeE1RM          = "e1rm"           // best estimated one rep max
+	TypeSessionVolume = "session_volume" // best weight times reps in a single session
+)
+
+// values computed by the database and by Go may differ in the last digits
+const epsilon = 1e-9
+
+type RecordRes struct {
+	ID         int64    `json:"id"`
+	ExerciseID int32    `json:"exercise_id"`
+	Type       string   `json:"type"`
+	Value      float64  `json:"value"`
+	Weight     *float64 `json:"weight,omitempty"` // only for max_reps records
+	LogID      int64    `json:"log_id"`
+	Date       string   `json:"date,omitempty"`
+	Unit       string   `json:"unit"`
+}
+
+// EstimateOneRepMax estimates the one rep max with the Epley formula
+func EstimateOneRepMax(weight float64, reps int32) float64 {
+	if reps <= 1 {
+		return weight
+	}
+	return weight * (1 + float64(reps)/30)
+}
+
+// RecordResFromDB builds the response of a record with its weights converted into unit
+func RecordResFromDB(record database.PersonalRecord, date pgtype.Date, unit string) RecordRes {
+	res := RecordRes{
+		ID:         record.ID,
+		ExerciseID: record.ExerciseID,
+		Type:       record.RecordType,
+		Value:      record.Value,
+		LogID:      record.LogID,
+		Unit:       unit,
+	}
+	// reps are not a weight
+	if record.RecordType != TypeMaxReps {
+		res.Value = units.FromKG(record.Value, unit)
+	}
+	if record.Weight.Valid {
+		weight := units.FromKG(record.Weight.Float64, unit)
+		res.Weight = &weight
+	}
+	if date.Valid {
+		res.Date = date.Time.Format(apiconstants.DATE_LAYOUT)
+	}
+	return res
+}
+
+// Detect stores the records set by the log and returns them.
+// Logs of warm-up sets and logs without weight never set records.
+func Detect(ctx context.Context, q *database.Queries, userID uuid.UUID, log database.Log) ([]database.PersonalRecord, error) {
+	records := []database.PersonalRecord{}
+	if !log.Weight.Valid {
+		return records, nil
+	}
+
+	set, err := q.GetSet(ctx, log.SetID)
+	if err != nil {
+		return nil, fmt.Errorf("get set: %w", err)
+	}
+	if set.SetType == "warm_up" {
+		return records, nil
+	}
+
+	weight := log.Weight.Float64
+	bests, err := q.GetExerciseBests(ctx, database.GetExerciseBestsParams{
+		Weight:     weight,
+		UserID:     userID,
+		ExerciseID: log.ExerciseID,
+		LogID:      log.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get exercise bests: %w", err)
+	}
+
+	candidates := []database.CreatePersonalRecordParams{}
+	if weight > 0 && weight > bests.MaxWeight+epsilon {
+		candidates = append(candidates, database.CreatePersonalRecordParams{
+			RecordType: TypeMaxWeight,
+			Value:      weight,
+		})
+	}
+	// bodyweight exercises are logged with zero weight and still have rep records
+	if log.Reps > bests.MaxReps {
+		candidates = append(candidates, database.CreatePersonalRecordParams{
+			RecordType: TypeMaxReps,
+			Value:      float64(log.Reps),
+			Weight:     pgtype.Float8{Float64: weight, Valid: true},
+		})
+	}
+	if e1rm := EstimateOneRepMax(weight, log.Reps); weight > 0 && e1rm > bests.MaxE1rm+epsilon {
+		candidates = append(candidates, database.CreatePersonalRecordParams{
+			RecordType: TypeE1RM,
+			Value:      e1rm,
+		})
+	}
+
+	// the session keeps at most one volume record, the one of its latest volume
+	if err := q.DeleteSessionVolumeRecords(ctx, database.DeleteSessionVolumeRecordsParams{
+		SessionID:  set.SessionID,
+		ExerciseID: log.ExerciseID,
+	}); err != nil {
+		return nil, fmt.Errorf("delete session volume records: %w", err)
+	}
+	volumes, err := q.GetSessionVolumes(ctx, database.GetSessionVolumesParams{
+		SessionID:  set.SessionID,
+		UserID:     userID,
+		ExerciseID: log.ExerciseID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get session volumes: %w", err)
+	}
+	if volumes.SessionVolume > 0 && volumes.SessionVolume > volumes.BestVolume+epsilon {
+		candidates = append(candidates, database.CreatePersonalRecordParams{
+			RecordType: TypeSessionVolume,
+			Value:      volumes.SessionVolume,
+		})
+	}
+
+	for _, c := range candidates {
+		c.UserID = userID
+		c.ExerciseID = log.ExerciseID
+		c.LogID = log.ID
+		record, err := q.CreatePersonalRecord(ctx, c)
+		if err != nil {
+			return nil, fmt.Errorf("create %s record: %w", c.RecordType, err)
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// BatchLog is a log of a batch with the session and the type of its set
+type BatchLog struct {
+	Log       database.Log
+	SessionID uuid.UUID
+	SetType   string
+}
+
+// exerciseBests are the bests of an exercise while the logs of a batch are walked
+type exerciseBests struct {
+	maxWeight  float64
+	maxE1RM    float64
+	maxReps    map[float64]int32 // most reps of every weight
+	bestVolume float64
+}
+
+// sessionVolume is the volume of an exercise in a session of a batch and its last log
+type sessionVolume struct {
+	exerciseID int32
+	volume     float64
+	logID      int64
+}
+
+// DetectBatch stores the records set by the logs of new sessions and returns them, the logs are in the order they were set.
+// It stores the same records as calling Detect on every log in order with two queries for the whole batch.
+func DetectBatch(ctx context.Context, q *database.Queries, userID uuid.UUID, logs []BatchLog) ([]database.PersonalRecord, error) {
+	if len(logs) == 0 {
+		return []database.PersonalRecord{}, nil
+	}
+
+	exerciseIDs := []int32{}
+	sessionIDs := []uuid.UUID{}
+	for _, l := range logs {
+		if !slices.Contains(exerciseIDs, l.Log.ExerciseID) {
+			exerciseIDs = append(exerciseIDs, l.Log.ExerciseID)
+		}
+		if !slices.Contains(sessionIDs, l.SessionID) {
+			sessionIDs = append(sessionIDs, l.SessionID)
+		}
+	}
+
+	bests := map[int32]*exerciseBests{}
+	for _, id := range exerciseIDs {
+		bests[id] = &exerciseBests{maxReps: map[float64]int32{}}
+	}
+	repBests, err := q.GetExercisesRepBests(ctx, database.GetExercisesRepBestsParams{
+		UserID:      userID,
+		ExerciseIds: exerciseIDs,
+		SessionIds:  sessionIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get exercises rep bests: %w", err)
+	}
+	for _, row := range repBests {
+		b := bests[row.ExerciseID]
+		b.maxReps[row.Weight] = row.MaxReps
+		b.maxWeight = max(b.maxWeight, row.Weight)
+		b.maxE1RM = max(b.maxE1RM, EstimateOneRepMax(row.Weight, row.MaxReps))
+	}
+	volumes, err := q.GetExercisesBestVolumes(ctx, database.GetExercisesBestVolumesParams{
+		UserID:      userID,
+		ExerciseIds: exerciseIDs,
+		SessionIds:  sessionIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get exercises best volumes: %w", err)
+	}
+	for _, row := range volumes {
+		bests[row.ExerciseID].bestVolume = row.BestVolume
+	}
+
+	records := []database.PersonalRecord{}
+	for _, c := range batchCandidates(logs, bests) {
+		c.UserID = userID
+		record, err := q.CreatePersonalRecord(ctx, c)
+		if err != nil {
+			return nil, fmt.Errorf("create %s record: %w", c.RecordType, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// batchCandidates walks the logs in order with the bests set before the batch and returns the records they set
+func batchCandidates(logs []BatchLog, bests map[int32]*exerciseBests) []database.CreatePersonalRecordParams {
+	candidates := []database.CreatePersonalRecordParams{}
+	// the volumes of every exercise in every session, in the order they were set
+	volumes := []*sessionVolume{}
+	volumeIndex := map[uuid.UUID]map[int32]*sessionVolume{}
+	for _, l := range logs {
+		if !l.Log.Weight.Valid || l.SetType == "warm_up" {
+			continue
+		}
+		b := bests[l.Log.ExerciseID]
+		weight := l.Log.Weight.Float64
+		newRecord := func(recordType string, value float64) database.CreatePersonalRecordParams {
+			return database.CreatePersonalRecordParams{
+				ExerciseID: l.Log.ExerciseID,
+				RecordType: recordType,
+				Value:      value,
+				LogID:      l.Log.ID,
+			}
+		}
+
+		if weight > 0 && weight > b.maxWeight+epsilon {
+			candidates = append(candidates, newRecord(TypeMaxWeight, weight))
+		}
+		if l.Log.Reps > b.maxReps[weight] {
+			c := newRecord(TypeMaxReps, float64(l.Log.Reps))
+			c.Weight = pgtype.Float8{Float64: weight, Valid: true}
+			candidates = append(candidates, c)
+			b.maxReps[weight] = l.Log.Reps
+		}
+		e1rm := EstimateOneRepMax(weight, l.Log.Reps)
+		if weight > 0 && e1rm > b.maxE1RM+epsilon {
+			candidates = append(candidates, newRecord(TypeE1RM, e1rm))
+		}
+		b.maxWeight = max(b.maxWeight, weight)
+		b.maxE1RM = max(b.maxE1RM, e1rm)
+
+		if volumeIndex[l.SessionID] == nil {
+			volumeIndex[l.SessionID] = map[int32]*sessionVolume{}
+		}
+		v, ok := volumeIndex[l.SessionID][l.Log.ExerciseID]
+		if !ok {
+			v = &sessionVolume{exerciseID: l.Log.ExerciseID}
+			volumeIndex[l.SessionID][l.Log.ExerciseID] = v
+			volumes = append(volumes, v)
+		}
+		v.volume += weight * float64(l.Log.Reps)
+		v.logID = l.Log.ID
+	}
+
+	// the session keeps at most one volume record, on its last log
+	for _, v := range volumes {
+		b := bests[v.exerciseID]
+		if v.volume > 0 && v.volume > b.bestVolume+epsilon {
+			candidates = append(candidates, database.CreatePersonalRecordParams{
+				ExerciseID: v.exerciseID,
+				RecordType: TypeSessionVolume,
+				Value:      v.volume,
+				LogID:      v.logID,
+			})
+		}
+		b.bestVolume = max(b.bestVolume, v.volume)
+	}
+	return candidates
+}
@@ -0,0 +1,104 @@
+package record
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateOneRepMax(t *testing.T) {
+	testCases := []struct {
+		weight   float64
+		reps     int32
+		expected float64
+	}{
+		{weight: 100, reps: 1, expected: 100},
+		{weight: 100, reps: 3, expected: 110},
+		{weight: 90, reps: 10, expected: 120},
+		{weight: 0, reps: 20, expected: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%v x %d", tc.weight, tc.reps), func(t *testing.T) {
+			assert.InDelta(t, tc.expected, EstimateOneRepMax(tc.weight, tc.reps), 1e-9)
+		})
+	}
+}
+
+func TestRecordResFromDB(t *testing.T) {
+	t.Run("weights are converted", func(t *testing.T) {
+		res := RecordResFromDB(database.PersonalRecord{
+			RecordType: TypeMaxWeight,
+			Value:      100,
+		}, pgtype.Date{}, units.LB)
+		assert.Equal(t, 220.462, res.Value)
+		assert.Nil(t, res.Weight)
+		assert.Empty(t, res.Date)
+	})
+
+	t.Run("reps are not converted", func(t *testing.T) {
+		res := RecordResFromDB(database.PersonalRecord{
+			RecordType: TypeMaxReps,
+			Value:      8,
+			Weight:     pgtype.Float8{Float64: 100, Valid: true},
+		}, pgtype.Date{}, units.LB)
+		assert.Equal(t, 8.0, res.Value)
+		require.NotNil(t, res.Weight)
+		assert.Equal(t, 220.462, *res.Weight)
+	})
+}
+
+func TestBatchCandidates(t *testing.T) {
+	morning, evening := uuid.New(), uuid.New()
+	newLog := func(id int64, sessionID uuid.UUID, setType string, weight float64, reps int32) BatchLog {
+		return BatchLog{
+			Log: database.Log{
+				ID:         id,
+				ExerciseID: 1,
+				Weight:     pgtype.Float8{Float64: weight, Valid: true},
+				Reps:       reps,
+			},
+			SessionID: sessionID,
+			SetType:   setType,
+		}
+	}
+	logs := []BatchLog{
+		newLog(1, morning, "warm_up", 200, 10),
+		newLog(2, morning, "working", 100, 5),
+		newLog(3, morning, "working", 100, 5),
+		newLog(4, evening, "working", 110, 1),
+		newLog(5, evening, "working", 80, 8),
+	}
+	// bests set before the batch: 100 x 3
+	bests := map[int32]*exerciseBests{1: {
+		maxWeight:  100,
+		maxE1RM:    EstimateOneRepMax(100, 3),
+		maxReps:    map[float64]int32{100: 3},
+		bestVolume: 300,
+	}}
+
+	type record struct {
+		logID      int64
+		recordType string
+		value      float64
+	}
+	expected := []record{
+		{logID: 2, recordType: TypeMaxReps, value: 5},
+		{logID: 2, recordType: TypeE1RM, value: EstimateOneRepMax(100, 5)},
+		{logID: 4, recordType: TypeMaxWeight, value: 110},
+		{logID: 4, recordType: TypeMaxReps, value: 1},
+		{logID: 5, recordType: TypeMaxReps, value: 8},
+		{logID: 3, recordType: TypeSessionVolume, value: 1000},
+	}
+	records := []record{}
+	for _, c := range batchCandidates(logs, bests) {
+		records = append(records, record{logID: c.LogID, recordType: c.RecordType, value: c.Value})
+	}
+	assert.Equal(t, expected, records)
+}
@@ -0,0 +1,207 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/goal"
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/record"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/api/validation"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	FormatJSON = "json"
+	FormatHTML = "html"
+)
+
+var Formats = []string{FormatJSON, FormatHTML}
+
+// HandlerGetSummary compares the training of the user in the period containing date with the previous period.
+// The report is returned as JSON or as a self-contained HTML page.
+func HandlerGetSummary(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("get summary failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		// validate the query parameters
+		problems := map[string]string{}
+		query := r.URL.Query()
+		period := PeriodMonth
+		if query.Has("period") {
+			period = strings.ToLower(query.Get("period"))
+			if !slices.Contains(Periods, period) {
+				problems["period"] = "invalid period: period must be one of " + strings.Join(Periods, ", ")
+			}
+		}
+		date := time.Now().UTC()
+		if query.Has("date") {
+			parsed, err := validation.Date(query.Get("date"), apiconstants.DATE_LAYOUT, nil, nil)
+			if err != nil {
+				problems["date"] = "invalid date: " + err.Error()
+			} else {
+				date = parsed
+			}
+		}
+		format := FormatJSON
+		if query.Has("format") {
+			format = strings.ToLower(query.Get("format"))
+			if !slices.Contains(Formats, format) {
+				problems["format"] = "invalid format: format must be one of " + strings.Join(Formats, ", ")
+			}
+		}
+		if len(problems) > 0 {
+			reqLogger.Debug("get summary failed - invalid query parameters", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		}
+
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "get summary")
+		if !ok {
+			return
+		}
+
+		from, to := periodBounds(period, date)
+		previousFrom, previousTo := periodBounds(period, from.AddDate(0, 0, -1))
+		current, err := loadPeriod(r.Context(), db, userID, from, to, unit)
+		if err != nil {
+			reqLogger.Error("get summary failed - current period database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		previous, err := loadPeriod(r.Context(), db, userID, previousFrom, previousTo, unit)
+		if err != nil {
+			reqLogger.Error("get summary failed - previous period database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		resParams := compare(period, unit, current, previous)
+		resParams.Goals, err = loadGoals(r.Context(), db, userID, from, to, unit)
+		if err != nil {
+			reqLogger.Error("get summary failed - goals database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		exercises, err := db.GetExerciseNamesByIDs(r.Context(), reportedExercises(resParams))
+		if err != nil {
+			reqLogger.Error("get summary failed - get exercises database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		names := make(map[int32]string, len(exercises))
+		for _, exercise := range exercises {
+			names[exercise.ID] = exercise.Name
+		}
+		resParams.setNames(names)
+
+		if format == FormatHTML {
+			respondWithHTML(w, r, reqLogger, resParams, names)
+			return
+		}
+		util.RespondWithJSON(w, r, http.StatusOK, resParams)
+	}
+}
+
+// loadPeriod fetches the sessions of the user between from and to, both inclusive,
+// with their sets and logs and the records set in them
+func loadPeriod(ctx context.Context, db *database.Queries, userID uuid.UUID, from, to time.Time, unit string) (periodData, error) {
+	data := periodData{from: from, to: to}
+	fromDate := pgtype.Date{Time: from, Valid: true}
+	toDate := pgtype.Date{Time: to, Valid: true}
+
+	sessions, err := db.GetSessionsByDates(ctx, database.GetSessionsByDatesParams{
+		UserID:   userID,
+		FromDate: fromDate,
+		ToDate:   toDate,
+	})
+	if err != nil {
+		return periodData{}, fmt.Errorf("get sessions: %w", err)
+	}
+	data.sessions = sessions
+	if len(sessions) == 0 {
+		return data, nil
+	}
+
+	sessionIDs := make([]uuid.UUID, len(sessions))
+	for i, s := range sessions {
+		sessionIDs[i] = s.ID
+	}
+	data.sets, err = db.GetSetsBySessionIDs(ctx, sessionIDs)
+	if err != nil {
+		return periodData{}, fmt.Errorf("get sets: %w", err)
+	}
+	if len(data.sets) > 0 {
+		setIDs := make([]int64, len(data.sets))
+		for i, s := range data.sets {
+			setIDs[i] = s.ID
+		}
+		data.logs, err = db.GetLogsBySetIDs(ctx, setIDs)
+		if err != nil {
+			return periodData{}, fmt.Errorf("get logs: %w", err)
+		}
+	}
+
+	records, err := db.GetPersonalRecordsByDate(ctx, database.GetPersonalRecordsByDateParams{
+		UserID:   userID,
+		FromDate: fromDate,
+		ToDate:   toDate,
+	})
+	if err != nil {
+		return periodData{}, fmt.Errorf("get records: %w", err)
+	}
+	data.records = make([]record.RecordRes, len(records))
+	for i, rec := range records {
+		data.records[i] = record.RecordResFromDB(database.PersonalRecord{
+			ID:         rec.ID,
+			CreatedAt:  rec.CreatedAt,
+			UserID:     rec.UserID,
+			ExerciseID: rec.ExerciseID,
+			RecordType: rec.RecordType,
+			Value:      rec.Value,
+			Weight:     rec.Weight,
+			LogID:      rec.LogID,
+		}, rec.Date, unit)
+	}
+
+	return data, nil
+}
+
+// loadGoals returns the goals of the user whose window overlaps the period between from and to,
+// with their progress as of today
+func loadGoals(ctx context.Context, db *database.Queries, userID uuid.UUID, from, to time.Time, unit string) ([]goal.GoalRes, error) {
+	goals, err := db.GetGoalsByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get goals: %w", err)
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	res := []goal.GoalRes{}
+	for _, g := range goals {
+		if g.StartDate.Time.After(to) || g.TargetDate.Time.Before(from) {
+			continue
+		}
+		evaluated, err := goal.Evaluate(ctx, db, g, unit, today)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, evaluated)
+	}
+	return res, nil
+}
@@ -0,0 +1,163 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/goal"
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/record"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerGetSummary(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	squatID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+	benchID := testutil.CreateExerciseDBTestHelper(t, db, "bench press")
+
+	// two sessions in February and three in March, the squat improves in March
+	sessions := []struct {
+		date   string
+		squat  float64
+		bench  float64
+		record bool
+	}{
+		{date: "2025-02-10", squat: 100, bench: 60},
+		{date: "2025-02-20", squat: 100, bench: 60},
+		{date: "2025-03-05", squat: 105, bench: 60},
+		{date: "2025-03-12", squat: 110, bench: 60, record: true},
+		{date: "2025-03-19", squat: 100, bench: 60},
+	}
+	for _, s := range sessions {
+		date, err := time.Parse(time.DateOnly, s.date)
+		require.NoError(t, err)
+		session, err := db.CreateSession(context.Background(), database.CreateSessionParams{
+			Name:            "full body",
+			Date:            pgtype.Date{Time: date, Valid: true},
+			DurationMinutes: pgtype.Int2{Int16: 60, Valid: true},
+			UserID:          user.ID,
+		})
+		require.NoError(t, err)
+		setID := testutil.CreateSetDBTestHelper(t, db, session.ID, squatID)
+		logID := testutil.CreateLogExerciseDBTestHelper(t, db, 5, 1, squatID, setID, s.squat)
+		setID = testutil.CreateSetDBTestHelper(t, db, session.ID, benchID)
+		testutil.CreateLogExerciseDBTestHelper(t, db, 5, 1, benchID, setID, s.bench)
+		if s.record {
+			_, err := db.CreatePersonalRecord(context.Background(), database.CreatePersonalRecordParams{
+				UserID:     user.ID,
+				ExerciseID: squatID,
+				RecordType: record.TypeMaxWeight,
+				Value:      s.squat,
+				LogID:      logID,
+			})
+			require.NoError(t, err)
+		}
+	}
+
+	// a squat goal for March, achieved on the 12th
+	_, err := db.CreateGoal(context.Background(), database.CreateGoalParams{
+		UserID:     user.ID,
+		GoalType:   goal.TypeStrength,
+		ExerciseID: pgtype.Int4{Int32: squatID, Valid: true},
+		Target:     110,
+		StartDate:  pgtype.Date{Time: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		TargetDate: pgtype.Date{Time: time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC), Valid: true},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name             string
+		query            string
+		statusCode       int
+		errKeys          []string
+		expectedSessions [2]int // current and previous
+		expectedGains    int
+		expectedRecords  int
+		expectedGoals    int
+	}{
+		{
+			name:             "happy path: month",
+			query:            "?period=month&date=2025-03-15",
+			statusCode:       http.StatusOK,
+			expectedSessions: [2]int{3, 2},
+			expectedGains:    1,
+			expectedRecords:  1,
+			expectedGoals:    1,
+		},
+		{
+			name:             "happy path: week without previous training",
+			query:            "?period=week&date=2025-02-12",
+			statusCode:       http.StatusOK,
+			expectedSessions: [2]int{1, 0},
+		},
+		{
+			name:       "invalid parameters",
+			query:      "?period=day&date=15-03-2025&format=pdf",
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"period", "date", "format"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/test"+tc.query, bytes.NewReader(nil))
+			require.NoError(t, err)
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerGetSummary(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				var problems map[string]string
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&problems))
+				for _, key := range tc.errKeys {
+					assert.Contains(t, problems, key)
+				}
+				return
+			}
+
+			var resParams summaryRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			assert.Equal(t, tc.expectedSessions[0], resParams.Current.Sessions)
+			assert.Equal(t, tc.expectedSessions[1], resParams.Previous.Sessions)
+			assert.Equal(t, tc.expectedSessions[0]*60, resParams.Current.DurationMinutes)
+			assert.Len(t, resParams.E1RMGains, tc.expectedGains)
+			assert.Len(t, resParams.NewRecords, tc.expectedRecords)
+			require.Len(t, resParams.Goals, tc.expectedGoals)
+			for _, g := range resParams.Goals {
+				assert.Equal(t, goal.StatusAchieved, g.Status)
+				assert.Equal(t, "2025-03-12", g.StatusDate)
+			}
+			for _, e := range resParams.TopExercises {
+				assert.NotEmpty(t, e.Name)
+			}
+		})
+	}
+
+	t.Run("happy path: html page", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/test?date=2025-03-15&format=html", bytes.NewReader(nil))
+		require.NoError(t, err)
+		req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+		rr := httptest.NewRecorder()
+
+		middleware.RequestID(HandlerGetSummary(db, logger)).ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Contains(t, rr.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, rr.Body.String(), "squat")
+		assert.Contains(t, rr.Body.String(), "achieved")
+	})
+}
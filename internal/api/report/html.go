@@ -0,0 +1,98 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/CTSDM/gogym/internal/api/util"
+)
+
+// The page embeds its styles so it can be saved or shared as a single file
+var summaryTemplate = template.Must(template.New("summary").Funcs(template.FuncMap{
+	"title":  func(s string) string { return strings.ToUpper(s[:1]) + s[1:] },
+	"number": func(x float64) string { return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", x), "0"), ".") },
+	"change": func(p *float64) string {
+		if p == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%+.1f%%", *p)
+	},
+	"label": func(recordType string) string { return strings.ReplaceAll(recordType, "_", " ") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{title .Period}} summary {{.Current.From}} - {{.Current.To}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem auto; max-width: 48rem; padding: 0 1rem; color: #222; }
+h1 { margin-bottom: 0; }
+.period { color: #666; margin-top: .25rem; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2rem; }
+th, td { text-align: left; padding: .4rem .6rem; border-bottom: 1px solid #ddd; }
+td.num, th.num { text-align: right; }
+</style>
+</head>
+<body>
+<h1>{{title .Period}} summary</h1>
+<p class="period">{{.Current.From}} to {{.Current.To}}, compared with {{.Previous.From}} to {{.Previous.To}}</p>
+
+<h2>Overview</h2>
+<table>
+<tr><th></th><th class="num">This {{.Period}}</th><th class="num">Previous {{.Period}}</th><th class="num">Change</th></tr>
+<tr><td>Sessions</td><td class="num">{{.Current.Sessions}}</td><td class="num">{{.Previous.Sessions}}</td><td class="num">{{change .Changes.Sessions}}</td></tr>
+<tr><td>Duration (minutes)</td><td class="num">{{.Current.DurationMinutes}}</td><td class="num">{{.Previous.DurationMinutes}}</td><td class="num">{{change .Changes.DurationMinutes}}</td></tr>
+<tr><td>Volume ({{.Unit}})</td><td class="num">{{number .Current.Volume}}</td><td class="num">{{number .Previous.Volume}}</td><td class="num">{{change .Changes.Volume}}</td></tr>
+<tr><td>New records</td><td class="num">{{.Current.NewRecords}}</td><td class="num">{{.Previous.NewRecords}}</td><td class="num">{{change .Changes.NewRecords}}</td></tr>
+</table>
+
+<h2>Top exercises</h2>
+{{if .TopExercises}}<table>
+<tr><th>Exercise</th><th class="num">Sets</th><th class="num">Volume ({{.Unit}})</th></tr>
+{{range .TopExercises}}<tr><td>{{.Name}}</td><td class="num">{{.Sets}}</td><td class="num">{{number .Volume}}</td></tr>
+{{end}}</table>{{else}}<p>No training this {{.Period}}.</p>{{end}}
+
+<h2>Biggest e1RM gains</h2>
+{{if .E1RMGains}}<table>
+<tr><th>Exercise</th><th class="num">Previous ({{.Unit}})</th><th class="num">Current ({{.Unit}})</th><th class="num">Gain</th></tr>
+{{range .E1RMGains}}<tr><td>{{.Name}}</td><td class="num">{{number .Previous}}</td><td class="num">{{number .Current}}</td><td class="num">+{{number .ChangePercent}}%</td></tr>
+{{end}}</table>{{else}}<p>No e1RM gains this {{.Period}}.</p>{{end}}
+
+<h2>New records</h2>
+{{if .NewRecords}}<table>
+<tr><th>Date</th><th>Exercise</th><th>Record</th><th class="num">Value</th></tr>
+{{range .NewRecords}}<tr><td>{{.Date}}</td><td>{{index $.Names .ExerciseID}}</td><td>{{label .Type}}</td><td class="num">{{number .Value}}</td></tr>
+{{end}}</table>{{else}}<p>No new records this {{.Period}}.</p>{{end}}
+
+<h2>Goals</h2>
+{{if .Goals}}<table>
+<tr><th>Goal</th><th>Exercise</th><th class="num">Target</th><th class="num">Progress</th><th>Target date</th><th>Status</th></tr>
+{{range .Goals}}<tr><td>{{title .Type}}</td><td>{{if .ExerciseID}}{{index $.Names .ExerciseID}}{{else}}-{{end}}</td><td class="num">{{number .Target}}{{if .Unit}} {{.Unit}}{{end}}</td><td class="num">{{number .Progress.Percent}}%</td><td>{{.TargetDate}}</td><td>{{.Status}}</td></tr>
+{{end}}</table>{{else}}<p>No goals this {{.Period}}.</p>{{end}}
+</body>
+</html>
+`))
+
+// summaryPage is the report with the names of the exercises of its records and goals
+type summaryPage struct {
+	summaryRes
+	Names map[int32]string
+}
+
+func respondWithHTML(w http.ResponseWriter, r *http.Request, reqLogger *slog.Logger, res summaryRes, names map[int32]string) {
+	var b bytes.Buffer
+	if err := summaryTemplate.Execute(&b, summaryPage{summaryRes: res, Names: names}); err != nil {
+		reqLogger.Error("get summary failed - template error", slog.String("error", err.Error()))
+		util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(b.Bytes()); err != nil {
+		reqLogger.Debug("could not write the response", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,249 @@
+package report
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/goal"
+	"github.com/CTSDM/gogym/internal/api/record"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+)
+
+const (
+	PeriodWeek  = "week" // weeks start on Monday
+	PeriodMonth = "month"
+	PeriodYear  = "year"
+)
+
+var Periods = []string{PeriodWeek, PeriodMonth, PeriodYear}
+
+// length of the top exercises and e1RM gains lists
+const topLength = 5
+
+type periodSummary struct {
+	From            string  `json:"from"`
+	To              string  `json:"to"`
+	Sessions        int     `json:"sessions"`
+	DurationMinutes int     `json:"duration_minutes"` // sessions without duration are not counted
+	Volume          float64 `json:"volume"`           // sum of weight times reps, warm-up sets excluded
+	NewRecords      int     `json:"new_records"`
+}
+
+// Percentage changes from the previous period, null when the previous value is zero
+type summaryChanges struct {
+	Sessions        *float64 `json:"sessions"`
+	DurationMinutes *float64 `json:"duration_minutes"`
+	Volume          *float64 `json:"volume"`
+	NewRecords      *float64 `json:"new_records"`
+}
+
+type topExercise struct {
+	ExerciseID int32   `json:"exercise_id"`
+	Name       string  `json:"name"`
+	Volume     float64 `json:"volume"`
+	Sets       int     `json:"sets"`
+}
+
+type e1rmGain struct {
+	ExerciseID    int32   `json:"exercise_id"`
+	Name          string  `json:"name"`
+	Previous      float64 `json:"previous"`
+	Current       float64 `json:"current"`
+	Gain          float64 `json:"gain"`
+	ChangePercent float64 `json:"change_percent"`
+}
+
+type summaryRes struct {
+	Period       string             `json:"period"`
+	Unit         string             `json:"unit"`
+	Current      periodSummary      `json:"current"`
+	Previous     periodSummary      `json:"previous"`
+	Changes      summaryChanges     `json:"changes"`
+	TopExercises []topExercise      `json:"top_exercises"` // by volume in the current period
+	NewRecords   []record.RecordRes `json:"new_records"`   // set in the current period
+	E1RMGains    []e1rmGain         `json:"e1rm_gains"`    // biggest improvements of the best e1RM, Epley formula
+	Goals        []goal.GoalRes     `json:"goals"`         // with a window overlapping the current period
+}
+
+// periodData holds the training of the user in a period
+type periodData struct {
+	from     time.Time
+	to       time.Time
+	sessions []database.Session
+	sets     []database.Set
+	logs     []database.Log
+	records  []record.RecordRes
+}
+
+type exerciseStats struct {
+	volume   float64 // in kilograms
+	sets     int
+	bestE1RM float64 // in kilograms
+}
+
+// periodBounds returns the first and last day of the period containing date
+func periodBounds(period string, date time.Time) (time.Time, time.Time) {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case PeriodWeek:
+		from := date.AddDate(0, 0, -(int(date.Weekday())+6)%7)
+		return from, from.AddDate(0, 0, 6)
+	case PeriodYear:
+		from := time.Date(date.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return from, from.AddDate(1, 0, -1)
+	default:
+		from := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return from, from.AddDate(0, 1, -1)
+	}
+}
+
+// summarize aggregates the training of the period, weights in kilograms
+func summarize(data periodData) (periodSummary, map[int32]*exerciseStats) {
+	summary := periodSummary{
+		From:       data.from.Format(apiconstants.DATE_LAYOUT),
+		To:         data.to.Format(apiconstants.DATE_LAYOUT),
+		Sessions:   len(data.sessions),
+		NewRecords: len(data.records),
+	}
+	for _, s := range data.sessions {
+		if s.DurationMinutes.Valid {
+			summary.DurationMinutes += int(s.DurationMinutes.Int16)
+		}
+	}
+
+	warmUp := make(map[int64]bool)
+	exercises := make(map[int32]*exerciseStats)
+	for _, s := range data.sets {
+		if s.SetType == "warm_up" {
+			warmUp[s.ID] = true
+			continue
+		}
+		if exercises[s.ExerciseID] == nil {
+			exercises[s.ExerciseID] = &exerciseStats{}
+		}
+		exercises[s.ExerciseID].sets++
+	}
+	for _, l := range data.logs {
+		if warmUp[l.SetID] || !l.Weight.Valid {
+			continue
+		}
+		stats := exercises[l.ExerciseID]
+		if stats == nil {
+			stats = &exerciseStats{}
+			exercises[l.ExerciseID] = stats
+		}
+		volume := l.Weight.Float64 * float64(l.Reps)
+		summary.Volume += volume
+		stats.volume += volume
+		stats.bestE1RM = max(stats.bestE1RM, record.EstimateOneRepMax(l.Weight.Float64, l.Reps))
+	}
+
+	return summary, exercises
+}
+
+// compare builds the report of the current period against the previous one
+func compare(period, unit string, current, previous periodData) summaryRes {
+	currentSummary, currentExercises := summarize(current)
+	previousSummary, previousExercises := summarize(previous)
+	currentSummary.Volume = units.FromKG(currentSummary.Volume, unit)
+	previousSummary.Volume = units.FromKG(previousSummary.Volume, unit)
+
+	res := summaryRes{
+		Period:   period,
+		Unit:     unit,
+		Current:  currentSummary,
+		Previous: previousSummary,
+		Changes: summaryChanges{
+			Sessions:        change(float64(currentSummary.Sessions), float64(previousSummary.Sessions)),
+			DurationMinutes: change(float64(currentSummary.DurationMinutes), float64(previousSummary.DurationMinutes)),
+			Volume:          change(currentSummary.Volume, previousSummary.Volume),
+			NewRecords:      change(float64(currentSummary.NewRecords), float64(previousSummary.NewRecords)),
+		},
+		TopExercises: []topExercise{},
+		NewRecords:   current.records,
+		E1RMGains:    []e1rmGain{},
+	}
+	if res.NewRecords == nil {
+		res.NewRecords = []record.RecordRes{}
+	}
+
+	for id, stats := range currentExercises {
+		if stats.volume > 0 {
+			res.TopExercises = append(res.TopExercises, topExercise{
+				ExerciseID: id,
+				Volume:     units.FromKG(stats.volume, unit),
+				Sets:       stats.sets,
+			})
+		}
+
+		before, ok := previousExercises[id]
+		if !ok || before.bestE1RM == 0 || stats.bestE1RM <= before.bestE1RM {
+			continue
+		}
+		res.E1RMGains = append(res.E1RMGains, e1rmGain{
+			ExerciseID:    id,
+			Previous:      units.FromKG(before.bestE1RM, unit),
+			Current:       units.FromKG(stats.bestE1RM, unit),
+			Gain:          units.FromKG(stats.bestE1RM-before.bestE1RM, unit),
+			ChangePercent: round((stats.bestE1RM - before.bestE1RM) / before.bestE1RM * 100),
+		})
+	}
+
+	slices.SortFunc(res.TopExercises, func(a, b topExercise) int {
+		return cmp.Or(cmp.Compare(b.Volume, a.Volume), cmp.Compare(a.ExerciseID, b.ExerciseID))
+	})
+	slices.SortFunc(res.E1RMGains, func(a, b e1rmGain) int {
+		return cmp.Or(cmp.Compare(b.ChangePercent, a.ChangePercent), cmp.Compare(a.ExerciseID, b.ExerciseID))
+	})
+	res.TopExercises = res.TopExercises[:min(len(res.TopExercises), topLength)]
+	res.E1RMGains = res.E1RMGains[:min(len(res.E1RMGains), topLength)]
+
+	return res
+}
+
+// reportedExercises returns the ids of the exercises named in the report
+func reportedExercises(res summaryRes) []int32 {
+	ids := []int32{}
+	for _, e := range res.TopExercises {
+		ids = append(ids, e.ExerciseID)
+	}
+	for _, g := range res.E1RMGains {
+		ids = append(ids, g.ExerciseID)
+	}
+	for _, rec := range res.NewRecords {
+		ids = append(ids, rec.ExerciseID)
+	}
+	for _, g := range res.Goals {
+		if g.ExerciseID != 0 {
+			ids = append(ids, g.ExerciseID)
+		}
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+// setNames names the exercises of the report by exercise id
+func (res *summaryRes) setNames(names map[int32]string) {
+	for i, e := range res.TopExercises {
+		res.TopExercises[i].Name = names[e.ExerciseID]
+	}
+	for i, g := range res.E1RMGains {
+		res.E1RMGains[i].Name = names[g.ExerciseID]
+	}
+}
+
+func change(current, previous float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	percent := round((current - previous) / previous * 100)
+	return &percent
+}
+
+func round(x float64) float64 {
+	return math.Round(x*100) / 100
+}
@@ -0,0 +1,149 @@
+package report
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/record"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriodBounds(t *testing.T) {
+	testCases := []struct {
+		period       string
+		date         string
+		expectedFrom string
+		expectedTo   string
+	}{
+		{period: PeriodWeek, date: "2025-03-12", expectedFrom: "2025-03-10", expectedTo: "2025-03-16"},
+		{period: PeriodWeek, date: "2025-03-16", expectedFrom: "2025-03-10", expectedTo: "2025-03-16"},
+		{period: PeriodMonth, date: "2024-02-15", expectedFrom: "2024-02-01", expectedTo: "2024-02-29"},
+		{period: PeriodMonth, date: "2025-12-31", expectedFrom: "2025-12-01", expectedTo: "2025-12-31"},
+		{period: PeriodYear, date: "2025-06-01", expectedFrom: "2025-01-01", expectedTo: "2025-12-31"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.period+" "+tc.date, func(t *testing.T) {
+			date, err := time.Parse(time.DateOnly, tc.date)
+			require.NoError(t, err)
+			from, to := periodBounds(tc.period, date)
+			assert.Equal(t, tc.expectedFrom, from.Format(time.DateOnly))
+			assert.Equal(t, tc.expectedTo, to.Format(time.DateOnly))
+		})
+	}
+}
+
+// periodTestHelper builds the data of a period with a single session, one set per log
+func periodTestHelper(from, to string, duration int16, logs []database.Log, setTypes []string) periodData {
+	fromDate, _ := time.Parse(time.DateOnly, from)
+	toDate, _ := time.Parse(time.DateOnly, to)
+	data := periodData{
+		from:     fromDate,
+		to:       toDate,
+		sessions: []database.Session{{DurationMinutes: pgtype.Int2{Int16: duration, Valid: duration > 0}}},
+	}
+	for i, l := range logs {
+		l.SetID = int64(i)
+		data.sets = append(data.sets, database.Set{ID: int64(i), ExerciseID: l.ExerciseID, SetType: setTypes[i]})
+		data.logs = append(data.logs, l)
+	}
+	return data
+}
+
+func logTestHelper(exerciseID int32, weight float64, reps int32) database.Log {
+	return database.Log{ExerciseID: exerciseID, Weight: pgtype.Float8{Float64: weight, Valid: true}, Reps: reps}
+}
+
+func TestCompare(t *testing.T) {
+	previous := periodTestHelper("2025-02-01", "2025-02-28", 60, []database.Log{
+		logTestHelper(1, 100, 5),
+		logTestHelper(2, 60, 5),
+		logTestHelper(3, 40, 10),
+	}, []string{"working", "working", "working"})
+	current := periodTestHelper("2025-03-01", "2025-03-31", 90, []database.Log{
+		logTestHelper(1, 60, 10), // warm-up, neither volume nor e1RM
+		logTestHelper(1, 110, 5),
+		logTestHelper(2, 60, 5),
+		logTestHelper(3, 35, 10),
+		logTestHelper(4, 20, 10),
+	}, []string{"warm_up", "working", "working", "working", "working"})
+	current.records = []record.RecordRes{{ExerciseID: 1, Type: record.TypeMaxWeight, Value: 110}}
+
+	res := compare(PeriodMonth, "kg", current, previous)
+
+	assert.Equal(t, periodSummary{
+		From: "2025-03-01", To: "2025-03-31", Sessions: 1, DurationMinutes: 90, Volume: 1400, NewRecords: 1,
+	}, res.Current)
+	assert.Equal(t, periodSummary{
+		From: "2025-02-01", To: "2025-02-28", Sessions: 1, DurationMinutes: 60, Volume: 1200,
+	}, res.Previous)
+	require.NotNil(t, res.Changes.Sessions)
+	assert.Equal(t, 0.0, *res.Changes.Sessions)
+	require.NotNil(t, res.Changes.DurationMinutes)
+	assert.Equal(t, 50.0, *res.Changes.DurationMinutes)
+	require.NotNil(t, res.Changes.Volume)
+	assert.Equal(t, 16.67, *res.Changes.Volume)
+	assert.Nil(t, res.Changes.NewRecords, "no records in the previous period")
+
+	// the warm-up set is not counted
+	require.Len(t, res.TopExercises, 4)
+	assert.Equal(t, topExercise{ExerciseID: 1, Volume: 550, Sets: 1}, res.TopExercises[0])
+	assert.Equal(t, []int32{1, 3, 2, 4}, []int32{
+		res.TopExercises[0].ExerciseID, res.TopExercises[1].ExerciseID,
+		res.TopExercises[2].ExerciseID, res.TopExercises[3].ExerciseID,
+	})
+
+	// only the exercise that improved, the new one has nothing to compare with
+	require.Len(t, res.E1RMGains, 1)
+	assert.Equal(t, int32(1), res.E1RMGains[0].ExerciseID)
+	assert.Equal(t, 116.667, res.E1RMGains[0].Previous)
+	assert.Equal(t, 128.333, res.E1RMGains[0].Current)
+	assert.Equal(t, 10.0, res.E1RMGains[0].ChangePercent)
+
+	assert.Equal(t, []int32{1, 2, 3, 4}, reportedExercises(res))
+}
+
+func TestCompareEmptyPeriods(t *testing.T) {
+	previous := periodTestHelper("2025-03-03", "2025-03-09", 0, nil, nil)
+	previous.sessions = nil
+	current := periodTestHelper("2025-03-10", "2025-03-16", 0, nil, nil)
+	current.sessions = nil
+
+	res := compare(PeriodWeek, "lb", current, previous)
+	assert.Equal(t, 0, res.Current.Sessions)
+	assert.Nil(t, res.Changes.Sessions)
+	assert.Nil(t, res.Changes.Volume)
+	assert.NotNil(t, res.TopExercises)
+	assert.NotNil(t, res.NewRecords)
+	assert.NotNil(t, res.E1RMGains)
+}
+
+func TestRespondWithHTML(t *testing.T) {
+	previous := periodTestHelper("2025-02-01", "2025-02-28", 60, []database.Log{logTestHelper(1, 100, 5)}, []string{"working"})
+	current := periodTestHelper("2025-03-01", "2025-03-31", 45, []database.Log{logTestHelper(1, 105, 5)}, []string{"working"})
+	current.records = []record.RecordRes{{ExerciseID: 1, Type: record.TypeMaxWeight, Value: 105, Date: "2025-03-04"}}
+	res := compare(PeriodMonth, "kg", current, previous)
+	names := map[int32]string{1: "<squat>"}
+	res.setNames(names)
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	rr := httptest.NewRecorder()
+	respondWithHTML(rr, req, slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)), res, names)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rr.Header().Get("Content-Type"))
+	body := rr.Body.String()
+	assert.Contains(t, body, "<title>Month summary 2025-03-01 - 2025-03-31</title>")
+	assert.Contains(t, body, "-25.0%", "duration change")
+	assert.Contains(t, body, "max weight")
+	assert.Contains(t, body, "&lt;squat&gt;", "names are escaped")
+	assert.NotContains(t, body, "<squat>")
+	assert.NotContains(t, body, "<link", "the page is self-contained")
+}
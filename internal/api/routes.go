@@ -4,11 +4,23 @@ import (
 	"log/slog"
 	"net/http"
 
+	"github.com/CTSDM/gogym/internal/api/backup"
+	"github.com/CTSDM/gogym/internal/api/calendar"
 	"github.com/CTSDM/gogym/internal/api/exercise"
 	"github.com/CTSDM/gogym/internal/api/exlog"
+	"github.com/CTSDM/gogym/internal/api/export"
+	"github.com/CTSDM/gogym/internal/api/goal"
+	"github.com/CTSDM/gogym/internal/api/importer"
+	"github.com/CTSDM/gogym/internal/api/insight"
+	"github.com/CTSDM/gogym/internal/api/leaderboard"
+	"github.com/CTSDM/gogym/internal/api/measurement"
 	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/offline"
+	"github.com/CTSDM/gogym/internal/api/record"
+	"github.com/CTSDM/gogym/internal/api/report"
 	"github.com/CTSDM/gogym/internal/api/session"
 	"github.com/CTSDM/gogym/internal/api/set"
+	"github.com/CTSDM/gogym/internal/api/stats"
 	"github.com/CTSDM/gogym/internal/api/user"
 	"github.com/CTSDM/gogym/internal/auth"
 	"github.com/CTSDM/gogym/internal/database"
@@ -52,9 +64,16 @@ func addRoutes(
 		authentication),
 	)
 
+	// profile endpoints
+	mux.HandleFunc("GET /api/v1/me", authentication(user.HandlerGetMe(db, logger)))
+	mux.HandleFunc("PUT /api/v1/me/preferences", authentication(user.HandlerUpdatePreferences(db, logger)))
+	mux.HandleFunc("PUT /api/v1/me/leaderboards", authentication(user.HandlerUpdateLeaderboardSettings(db, logger)))
+	mux.HandleFunc("GET /api/v1/me/backup", authentication(backup.HandlerGetBackup(db, logger)))
+	mux.HandleFunc("POST /api/v1/me/restore", authentication(backup.HandlerRestore(pool, db, logger)))
+
 	// sessions endpoints
 	mux.HandleFunc("POST /api/v1/sessions", authentication(session.HandlerCreateSession(db, logger)))
-	mux.HandleFunc("GET /api/v1/sessions", authentication(session.HandlerGetSessions(db, logger)))
+	mux.HandleFunc("GET /api/v1/sessions", authentication(session.HandlerGetSessions(db, authConfig, logger)))
 	mux.HandleFunc("GET /api/v1/sessions/{id}", middleware.Chain(
 		session.HandlerGetSession(db, logger),
 		middleware.Ownership("id", db.GetSessionOwnerID, logger),
@@ -67,9 +86,18 @@ func addRoutes(
 		session.HandlerDeleteSession(db, logger),
 		middleware.Ownership("id", db.GetSessionOwnerID, logger),
 		authentication))
+	mux.HandleFunc("POST /api/v1/sessions/{id}/clone", middleware.Chain(
+		session.HandlerCloneSession(pool, db, logger),
+		middleware.Ownership("id", db.GetSessionOwnerID, logger),
+		authentication))
+	mux.HandleFunc("POST /api/v1/sessions/repeat-last",
+		authentication(session.HandlerRepeatLastSession(pool, db, logger)))
 
 	// sets endpoints
-	mux.HandleFunc("POST /api/v1/sessions/{sessionID}/sets", authentication(set.HandlerCreateSet(db, logger)))
+	mux.HandleFunc("POST /api/v1/sessions/{sessionID}/sets", middleware.Chain(
+		set.HandlerCreateSet(pool, db, logger),
+		middleware.Ownership("sessionID", db.GetSessionOwnerID, logger),
+		authentication))
 	mux.HandleFunc("DELETE /api/v1/sets/{id}", middleware.Chain(
 		set.HandlerDeleteSet(db, logger),
 		middleware.Ownership("id", db.GetSetOwnerID, logger),
@@ -82,13 +110,21 @@ func addRoutes(
 		set.HandlerUpdateSet(pool, db, logger),
 		middleware.Ownership("id", db.GetSetOwnerID, logger),
 		authentication))
+	mux.HandleFunc("PUT /api/v1/sessions/{id}/sets/order", middleware.Chain(
+		session.HandlerReorderSets(pool, db, logger),
+		middleware.Ownership("id", db.GetSessionOwnerID, logger),
+		authentication))
+	mux.HandleFunc("PUT /api/v1/sets/{id}/logs/order", middleware.Chain(
+		set.HandlerReorderLogs(pool, db, logger),
+		middleware.Ownership("id", db.GetSetOwnerID, logger),
+		authentication))
 
 	// logs endpoints
-	mux.HandleFunc("GET /api/v1/logs/", authentication(exlog.HandlerGetLogs(db, logger)))
+	mux.HandleFunc("GET /api/v1/logs/", authentication(exlog.HandlerGetLogs(db, authConfig, logger)))
 	mux.HandleFunc("POST /api/v1/sessions/{sessionID}/sets/{setID}/logs",
-		authentication(exlog.HandlerCreateLog(db, logger)))
+		authentication(exlog.HandlerCreateLog(pool, db, logger)))
 	mux.HandleFunc("PUT /api/v1/logs/{id}", middleware.Chain(
-		exlog.HandlerUpdateLog(db, logger),
+		exlog.HandlerUpdateLog(pool, db, logger),
 		middleware.Ownership("id", db.GetLogOwnerID, logger),
 		authentication))
 	mux.HandleFunc("DELETE /api/v1/logs/{id}", middleware.Chain(
@@ -99,6 +135,75 @@ func addRoutes(
 	// exercises endpoints
 	mux.HandleFunc("GET /api/v1/exercises/{id}", authentication(exercise.HandlerGetExercise(db, logger)))
 	mux.HandleFunc("GET /api/v1/exercises", authentication(exercise.HandlerGetExercises(db, logger)))
+	mux.HandleFunc("GET /api/v1/exercises/{id}/records", authentication(record.HandlerGetExerciseRecords(db, logger)))
+	mux.HandleFunc("GET /api/v1/exercises/{id}/history", authentication(exercise.HandlerGetExerciseHistory(db, logger)))
+
+	// personal records endpoints
+	mux.HandleFunc("GET /api/v1/records", authentication(record.HandlerGetRecords(db, logger)))
+
+	// stats endpoints
+	mux.HandleFunc("GET /api/v1/stats/e1rm", authentication(stats.HandlerGetE1RM(db, logger)))
+	mux.HandleFunc("GET /api/v1/stats/volume", authentication(stats.HandlerGetVolume(db, logger)))
+	mux.HandleFunc("GET /api/v1/stats/workload", authentication(stats.HandlerGetWorkload(db, logger)))
+	mux.HandleFunc("GET /api/v1/stats/consistency", authentication(stats.HandlerGetConsistency(db, logger)))
+
+	// insights endpoints
+	mux.HandleFunc("GET /api/v1/insights", authentication(insight.HandlerGetInsights(db, logger)))
+
+	// reports endpoints
+	mux.HandleFunc("GET /api/v1/reports/summary", authentication(report.HandlerGetSummary(db, logger)))
+
+	// goals endpoints
+	mux.HandleFunc("POST /api/v1/goals", authentication(goal.HandlerCreateGoal(db, logger)))
+	mux.HandleFunc("GET /api/v1/goals", authentication(goal.HandlerGetGoals(db, logger)))
+	mux.HandleFunc("GET /api/v1/goals/{id}", middleware.Chain(
+		goal.HandlerGetGoal(db, logger),
+		middleware.Ownership("id", db.GetGoalOwnerID, logger),
+		authentication))
+	mux.HandleFunc("PUT /api/v1/goals/{id}", middleware.Chain(
+		goal.HandlerUpdateGoal(db, logger),
+		middleware.Ownership("id", db.GetGoalOwnerID, logger),
+		authentication))
+	mux.HandleFunc("DELETE /api/v1/goals/{id}", middleware.Chain(
+		goal.HandlerDeleteGoal(db, logger),
+		middleware.Ownership("id", db.GetGoalOwnerID, logger),
+		authentication))
+
+	// body measurements endpoints
+	mux.HandleFunc("POST /api/v1/measurements", authentication(measurement.HandlerCreateMeasurement(db, logger)))
+	mux.HandleFunc("GET /api/v1/measurements", authentication(measurement.HandlerGetMeasurements(db, logger)))
+	mux.HandleFunc("GET /api/v1/measurements/{id}", middleware.Chain(
+		measurement.HandlerGetMeasurement(db, logger),
+		middleware.Ownership("id", db.GetBodyMeasurementOwnerID, logger),
+		authentication))
+	mux.HandleFunc("PUT /api/v1/measurements/{id}", middleware.Chain(
+		measurement.HandlerUpdateMeasurement(db, logger),
+		middleware.Ownership("id", db.GetBodyMeasurementOwnerID, logger),
+		authentication))
+	mux.HandleFunc("DELETE /api/v1/measurements/{id}", middleware.Chain(
+		measurement.HandlerDeleteMeasurement(db, logger),
+		middleware.Ownership("id", db.GetBodyMeasurementOwnerID, logger),
+		authentication))
+
+	// leaderboards endpoints
+	mux.HandleFunc("GET /api/v1/leaderboards", authentication(leaderboard.HandlerGetLeaderboards(db, logger)))
+	mux.HandleFunc("GET /api/v1/leaderboards/{id}", authentication(leaderboard.HandlerGetLeaderboard(db, logger)))
+
+	// export endpoints
+	mux.HandleFunc("GET /api/v1/export.csv", authentication(export.HandlerExportCSV(db, logger)))
+
+	// import endpoints
+	mux.HandleFunc("POST /api/v1/import", authentication(importer.HandlerImport(pool, db, logger)))
+
+	// calendar endpoints
+	mux.HandleFunc("POST /api/v1/me/calendar", authentication(calendar.HandlerCreateToken(db, logger)))
+	mux.HandleFunc("GET /api/v1/me/calendar", authentication(calendar.HandlerGetToken(db, logger)))
+	mux.HandleFunc("DELETE /api/v1/me/calendar", authentication(calendar.HandlerDeleteToken(db, logger)))
+	mux.HandleFunc("GET /api/v1/calendar/{file}", calendar.HandlerGetFeed(db, logger))
+
+	// sync endpoints
+	mux.HandleFunc("GET /api/v1/sync/changes", authentication(offline.HandlerGetChanges(db, authConfig, logger)))
+	mux.HandleFunc("POST /api/v1/sync/mutations", authentication(offline.HandlerPushMutations(pool, db, logger)))
 
 	// health endpoint
 	mux.HandleFunc("GET /health", handlerHealth(pool, logger))
@@ -0,0 +1,265 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/api/validation"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// The request body is optional, by default the session is cloned for today with its sets only
+type cloneReq struct {
+	Name           string `json:"name"` // defaults to the name of the source session
+	Date           string `json:"date"` // defaults to today
+	IncludeLogs    bool   `json:"include_logs"`
+	IncludeWeights bool   `json:"include_weights"` // implies include_logs
+
+	date time.Time
+}
+
+func (r *cloneReq) Valid(ctx context.Context) map[string]string {
+	problems := make(map[string]string)
+
+	if r.Date == "" {
+		r.Date = time.Now().Format(apiconstants.DATE_LAYOUT)
+	}
+	date, err := validation.Date(r.Date, apiconstants.DATE_LAYOUT, nil, nil)
+	if err != nil {
+		problems["date"] = "invalid date: " + err.Error()
+	}
+	r.date = date
+
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name != "" {
+		if err := validation.String(r.Name, apiconstants.MinSessionNameLength, apiconstants.MaxSessionNameLength); err != nil {
+			problems["name"] = "invalid name: " + err.Error()
+		}
+	}
+
+	if r.IncludeWeights {
+		r.IncludeLogs = true
+	}
+
+	return problems
+}
+
+func HandlerCloneSession(pool *pgxpool.Pool, db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		sessionID, _ := retrieveParseUUIDFromContext(r.Context())
+		reqLogger = reqLogger.With(slog.String("session_id", sessionID.String()))
+
+		reqParams, ok := decodeCloneReq(w, r, reqLogger, "clone session")
+		if !ok {
+			return
+		}
+
+		source, err := db.GetSession(r.Context(), sessionID)
+		if err == pgx.ErrNoRows {
+			util.RespondWithError(w, r, http.StatusNotFound, "session not found", err)
+			return
+		} else if err != nil {
+			reqLogger.Error("clone session failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		respondClone(w, r, reqLogger, pool, db, source, reqParams, "clone session")
+	}
+}
+
+func HandlerRepeatLastSession(pool *pgxpool.Pool, db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("repeat last session failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		reqParams, ok := decodeCloneReq(w, r, reqLogger, "repeat last session")
+		if !ok {
+			return
+		}
+
+		// the last session can be narrowed down by name, e.g. "do what I did last leg day"
+		name := strings.TrimSpace(r.URL.Query().Get("name"))
+		source, err := db.GetLastSessionByUserID(r.Context(), database.GetLastSessionByUserIDParams{
+			UserID: userID,
+			Name:   pgtype.Text{String: name, Valid: name != ""},
+		})
+		if err == pgx.ErrNoRows {
+			util.RespondWithError(w, r, http.StatusNotFound, "session not found", err)
+			return
+		} else if err != nil {
+			reqLogger.Error("repeat last session failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		respondClone(w, r, reqLogger, pool, db, source, reqParams, "repeat last session")
+	}
+}
+
+func decodeCloneReq(w http.ResponseWriter, r *http.Request, reqLogger *slog.Logger, action string) (*cloneReq, bool) {
+	reqParams, problems, err := validation.DecodeValid[*cloneReq](r)
+	if len(problems) > 0 {
+		reqLogger.Debug(action+" failed - validation errors", slog.Any("problems", problems))
+		util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+		return nil, false
+	} else if errors.Is(err, io.EOF) {
+		// empty body, use the default values
+		reqParams = &cloneReq{}
+		reqParams.Valid(r.Context())
+	} else if err != nil {
+		reqLogger.Debug(action+" failed - invalid payload", slog.String("error", err.Error()))
+		util.RespondWithError(w, r, http.StatusBadRequest, "invalid payload", err)
+		return nil, false
+	}
+	return reqParams, true
+}
+
+func respondClone(
+	w http.ResponseWriter,
+	r *http.Request,
+	reqLogger *slog.Logger,
+	pool *pgxpool.Pool,
+	db *database.Queries,
+	source database.Session,
+	reqParams *cloneReq,
+	action string,
+) {
+	tx, err := pool.Begin(r.Context())
+	if err != nil {
+		reqLogger.Error(action+" failed - transaction start error", slog.String("error", err.Error()))
+		util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	session, err := cloneSession(r.Context(), db.WithTx(tx), source, reqParams)
+	if err != nil {
+		reqLogger.Error(action+" failed - database error", slog.String("error", err.Error()))
+		util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		reqLogger.Error(action+" failed - transaction commit error", slog.String("error", err.Error()))
+		err = fmt.Errorf("could not commit the transaction: %w", err)
+		util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+		return
+	}
+
+	reqLogger.Info(action+" success",
+		slog.String("source_session_id", source.ID.String()),
+		slog.String("session_id", session.ID.String()),
+	)
+	util.RespondWithJSON(w, r, http.StatusCreated, sessionResFromDB(session))
+}
+
+// cloneSession copies the session and its sets, and optionally its logs, into a new session.
+// Daily readings (RPE, sleep quality, bodyweight and mood) belong to the source day and are not copied.
+func cloneSession(ctx context.Context, q *database.Queries, source database.Session, params *cloneReq) (database.Session, error) {
+	name := source.Name
+	if params.Name != "" {
+		name = params.Name
+	}
+
+	// keep the time of the day of the source session
+	startTimestamp := pgtype.Timestamp{}
+	if source.StartTimestamp.Valid {
+		t := source.StartTimestamp.Time
+		startTimestamp = pgtype.Timestamp{
+			Time: time.Date(params.date.Year(), params.date.Month(), params.date.Day(),
+				t.Hour(), t.Minute(), t.Second(), 0, time.UTC),
+			Valid: true,
+		}
+	}
+
+	session, err := q.CreateSession(ctx, database.CreateSessionParams{
+		Name:            name,
+		Date:            pgtype.Date{Time: params.date, Valid: true},
+		StartTimestamp:  startTimestamp,
+		DurationMinutes: source.DurationMinutes,
+		UserID:          source.UserID,
+		Tags:            source.Tags,
+		Notes:           source.Notes,
+		Location:        source.Location,
+	})
+	if err != nil {
+		return database.Session{}, fmt.Errorf("create session: %w", err)
+	}
+
+	sets, err := q.GetSetsBySessionIDs(ctx, []uuid.UUID{source.ID})
+	if err != nil {
+		return database.Session{}, fmt.Errorf("get sets: %w", err)
+	}
+
+	newSetIDs := make(map[int64]int64, len(sets))
+	sourceSetIDs := make([]int64, len(sets))
+	for i, s := range sets {
+		newSet, err := q.CreateSet(ctx, database.CreateSetParams{
+			SetOrder:   s.SetOrder,
+			RestTime:   s.RestTime,
+			SessionID:  session.ID,
+			ExerciseID: s.ExerciseID,
+			SetType:    s.SetType,
+			GroupKey:   s.GroupKey,
+		})
+		if err != nil {
+			return database.Session{}, fmt.Errorf("create set: %w", err)
+		}
+		newSetIDs[s.ID] = newSet.ID
+		sourceSetIDs[i] = s.ID
+	}
+
+	if !params.IncludeLogs || len(sourceSetIDs) == 0 {
+		return session, nil
+	}
+
+	logs, err := q.GetLogsBySetIDs(ctx, sourceSetIDs)
+	if err != nil {
+		return database.Session{}, fmt.Errorf("get logs: %w", err)
+	}
+	for _, l := range logs {
+		weight := l.Weight
+		if !params.IncludeWeights {
+			weight = pgtype.Float8{}
+		}
+		if _, err := q.CreateLog(ctx, database.CreateLogParams{
+			Weight:         weight,
+			Reps:           l.Reps,
+			LogsOrder:      l.LogsOrder,
+			ExerciseID:     l.ExerciseID,
+			SetID:          newSetIDs[l.SetID],
+			Rpe:            l.Rpe,
+			Rir:            l.Rir,
+			Tempo:          l.Tempo,
+			ReachedFailure: l.ReachedFailure,
+			PartialReps:    l.PartialReps,
+			Notes:          l.Notes,
+			WeightUnit:     l.WeightUnit,
+		}); err != nil {
+			return database.Session{}, fmt.Errorf("create log: %w", err)
+		}
+	}
+
+	return session, nil
+}
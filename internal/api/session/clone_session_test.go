@@ -0,0 +1,198 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerCloneSession(t *testing.T) {
+	testCases := []struct {
+		name           string
+		body           string
+		missingSession bool
+		statusCode     int
+		errMsg         []string
+		expectedDate   string
+		expectedName   string
+		expectedLogs   int
+		expectWeights  bool
+	}{
+		{
+			name:         "happy path: empty body clones sets only",
+			statusCode:   http.StatusCreated,
+			expectedName: "leg day",
+		},
+		{
+			name:         "happy path: new date and name with logs but no weights",
+			body:         `{"date": "2025-11-10", "name": "leg day II", "include_logs": true}`,
+			statusCode:   http.StatusCreated,
+			expectedDate: "2025-11-10",
+			expectedName: "leg day II",
+			expectedLogs: 2,
+		},
+		{
+			name:          "happy path: include weights implies logs",
+			body:          `{"include_weights": true}`,
+			statusCode:    http.StatusCreated,
+			expectedName:  "leg day",
+			expectedLogs:  2,
+			expectWeights: true,
+		},
+		{
+			name:       "invalid date",
+			body:       `{"date": "10-11-2025"}`,
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid date"},
+		},
+		{
+			name:           "session not found",
+			missingSession: true,
+			statusCode:     http.StatusNotFound,
+			errMsg:         []string{"session not found"},
+		},
+	}
+
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	exerciseID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+	sourceID := testutil.CreateSessionDBTestHelper(t, db, "leg day", user.ID)
+	setID := testutil.CreateSetDBTestHelper(t, db, sourceID, exerciseID)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 5, 1, exerciseID, setID, 100)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 5, 2, exerciseID, setID, 100)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/test", bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err, "unexpected error while creating the request")
+
+			sid := sourceID
+			if tc.missingSession {
+				sid = uuid.New()
+			}
+			ctx := util.ContextWithUser(req.Context(), user.ID)
+			ctx = util.ContextWithResourceID(ctx, sid)
+			req = req.WithContext(ctx)
+			rr := httptest.NewRecorder()
+
+			handler := HandlerCloneSession(dbPool, db, logger)
+			middleware.RequestID(handler).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				for _, msg := range tc.errMsg {
+					assert.Contains(t, rr.Body.String(), msg)
+				}
+				return
+			}
+
+			var resParams sessionRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			assert.NotEqual(t, sourceID.String(), resParams.ID)
+			assert.Equal(t, tc.expectedName, resParams.Name)
+			if tc.expectedDate != "" {
+				assert.Equal(t, tc.expectedDate, resParams.Date)
+			}
+
+			cloneID, err := uuid.Parse(resParams.ID)
+			require.NoError(t, err)
+			sets, err := db.GetSetsBySessionIDs(context.Background(), []uuid.UUID{cloneID})
+			require.NoError(t, err)
+			require.Len(t, sets, 1)
+			assert.Equal(t, exerciseID, sets[0].ExerciseID)
+
+			logs, err := db.GetLogsBySetIDs(context.Background(), []int64{sets[0].ID})
+			require.NoError(t, err)
+			assert.Len(t, logs, tc.expectedLogs)
+			for _, l := range logs {
+				assert.Equal(t, int32(5), l.Reps)
+				assert.Equal(t, tc.expectWeights, l.Weight.Valid)
+			}
+		})
+	}
+}
+
+func TestHandlerRepeatLastSession(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	otherUser := testutil.CreateUserDBTestHelper(t, db, "otheruser", "testpassword", false)
+
+	testCases := []struct {
+		name         string
+		query        string
+		userID       uuid.UUID
+		statusCode   int
+		expectedName string
+	}{
+		{
+			name:         "happy path: last session",
+			userID:       user.ID,
+			statusCode:   http.StatusCreated,
+			expectedName: "pull day",
+		},
+		{
+			name:         "happy path: last session by name is case insensitive",
+			query:        "?name=PUSH%20DAY",
+			userID:       user.ID,
+			statusCode:   http.StatusCreated,
+			expectedName: "push day",
+		},
+		{
+			name:       "no session with that name",
+			query:      "?name=legs",
+			userID:     user.ID,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "user without sessions",
+			userID:     otherUser.ID,
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	_, err := db.CreateSession(context.Background(), database.CreateSessionParams{
+		Name:   "push day",
+		Date:   pgtype.Date{Time: time.Now().AddDate(0, 0, -2), Valid: true},
+		UserID: user.ID,
+	})
+	require.NoError(t, err)
+	testutil.CreateSessionDBTestHelper(t, db, "pull day", user.ID)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/test"+tc.query, bytes.NewReader(nil))
+			require.NoError(t, err, "unexpected error while creating the request")
+			req = req.WithContext(util.ContextWithUser(req.Context(), tc.userID))
+			rr := httptest.NewRecorder()
+
+			handler := HandlerRepeatLastSession(dbPool, db, logger)
+			middleware.RequestID(handler).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				return
+			}
+			var resParams sessionRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			assert.Equal(t, tc.expectedName, resParams.Name)
+		})
+	}
+}
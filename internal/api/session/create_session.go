@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"math"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/CTSDM/gogym/internal/api/middleware"
@@ -17,14 +18,25 @@ import (
 )
 
 type sessionReq struct {
-	Name            string `json:"name"`
-	Date            string `json:"date"`
-	StartTimestamp  int64  `json:"start_timestamp"` // UTC
-	DurationMinutes int    `json:"duration_minutes"`
+	Name            string   `json:"name"`
+	Date            string   `json:"date"`
+	StartTimestamp  int64    `json:"start_timestamp"` // UTC
+	DurationMinutes int      `json:"duration_minutes"`
+	Tags            []string `json:"tags"`
+	Notes           string   `json:"notes"`
+	RPE             int      `json:"rpe,omitempty"`           // session RPE, 1 to 10
+	SleepQuality    int      `json:"sleep_quality,omitempty"` // 1 to 5
+	Bodyweight      float64  `json:"bodyweight,omitempty"`    // kilograms
+	Mood            int      `json:"mood,omitempty"`          // 1 to 5
+	Location        string   `json:"location"`
 
 	date            time.Time
 	startTimestamp  time.Time
 	durationMinutes int16
+	rpe             pgtype.Int2
+	sleepQuality    pgtype.Int2
+	bodyweight      pgtype.Float8
+	mood            pgtype.Int2
 }
 
 type sessionRes struct {
@@ -70,9 +82,58 @@ func (r *sessionReq) Valid(ctx context.Context) map[string]string {
 		r.durationMinutes = int16(r.DurationMinutes)
 	}
 
+	// tags validation, tags are stored normalized
+	tags, err := NormalizeTags(r.Tags)
+	if err != nil {
+		problems["tags"] = "invalid tags: " + err.Error()
+	}
+	r.Tags = tags
+
+	// notes and location validation, both are optional
+	if err := validation.String(r.Notes, 0, apiconstants.MaxNotesLength); err != nil {
+		problems["notes"] = "invalid notes: " + err.Error()
+	}
+	if err := validation.String(r.Location, 0, apiconstants.MaxLocationLength); err != nil {
+		problems["location"] = "invalid location: " + err.Error()
+	}
+
+	// optional scales, zero means the value was not provided
+	rpe, err := optionalScale(r.RPE, apiconstants.MinRPE, apiconstants.MaxRPE)
+	if err != nil {
+		problems["rpe"] = "invalid rpe: " + err.Error()
+	}
+	r.rpe = rpe
+	sleepQuality, err := optionalScale(r.SleepQuality, apiconstants.MinWellnessScore, apiconstants.MaxWellnessScore)
+	if err != nil {
+		problems["sleep_quality"] = "invalid sleep_quality: " + err.Error()
+	}
+	r.sleepQuality = sleepQuality
+	mood, err := optionalScale(r.Mood, apiconstants.MinWellnessScore, apiconstants.MaxWellnessScore)
+	if err != nil {
+		problems["mood"] = "invalid mood: " + err.Error()
+	}
+	r.mood = mood
+
+	// bodyweight validation
+	if r.Bodyweight < 0 || r.Bodyweight > apiconstants.MaxBodyweight {
+		problems["bodyweight"] = fmt.Sprintf("invalid bodyweight: bodyweight must be between 0 and %d", apiconstants.MaxBodyweight)
+	} else if r.Bodyweight > 0 {
+		r.bodyweight = pgtype.Float8{Float64: r.Bodyweight, Valid: true}
+	}
+
 	return problems
 }
 
+func optionalScale(value, min, max int) (pgtype.Int2, error) {
+	if value == 0 {
+		return pgtype.Int2{}, nil
+	}
+	if value < min || value > max {
+		return pgtype.Int2{}, fmt.Errorf("must be between %d and %d", min, max)
+	}
+	return pgtype.Int2{Int16: int16(value), Valid: true}, nil
+}
+
 func HandlerCreateSession(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		reqLogger := middleware.BasicReqLogger(logger, r)
@@ -104,6 +165,13 @@ func HandlerCreateSession(db *database.Queries, logger *slog.Logger) http.Handle
 			UserID:          userID,
 			StartTimestamp:  pgtype.Timestamp{Time: reqParams.startTimestamp, Valid: true},
 			DurationMinutes: pgtype.Int2{Int16: reqParams.durationMinutes, Valid: true},
+			Tags:            reqParams.Tags,
+			Notes:           pgtype.Text{String: reqParams.Notes, Valid: reqParams.Notes != ""},
+			Rpe:             reqParams.rpe,
+			SleepQuality:    reqParams.sleepQuality,
+			Bodyweight:      reqParams.bodyweight,
+			Mood:            reqParams.mood,
+			Location:        pgtype.Text{String: reqParams.Location, Valid: reqParams.Location != ""},
 		}
 
 		session, err := db.CreateSession(r.Context(), dbParams)
@@ -117,17 +185,52 @@ func HandlerCreateSession(db *database.Queries, logger *slog.Logger) http.Handle
 		}
 
 		reqLogger.Info("create session success", slog.String("session_id", session.ID.String()))
-		util.RespondWithJSON(w, r, http.StatusCreated,
-			sessionRes{
-				ID: session.ID.String(),
-				sessionReq: sessionReq{
-					Name:            session.Name,
-					Date:            session.Date.Time.Format(apiconstants.DATE_LAYOUT),
-					StartTimestamp:  session.StartTimestamp.Time.Unix(),
-					DurationMinutes: int(session.DurationMinutes.Int16),
-				},
-			})
+		util.RespondWithJSON(w, r, http.StatusCreated, sessionResFromDB(session))
+	}
+}
+
+func sessionResFromDB(session database.Session) sessionRes {
+	tags := session.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return sessionRes{
+		ID: session.ID.String(),
+		sessionReq: sessionReq{
+			Name:            session.Name,
+			Date:            session.Date.Time.Format(apiconstants.DATE_LAYOUT),
+			StartTimestamp:  session.StartTimestamp.Time.Unix(),
+			DurationMinutes: int(session.DurationMinutes.Int16),
+			Tags:            tags,
+			Notes:           session.Notes.String,
+			RPE:             int(session.Rpe.Int16),
+			SleepQuality:    int(session.SleepQuality.Int16),
+			Bodyweight:      session.Bodyweight.Float64,
+			Mood:            int(session.Mood.Int16),
+			Location:        session.Location.String,
+		},
+	}
+}
+
+// NormalizeTags trims, lowercases and deduplicates the tags keeping the original order
+func NormalizeTags(tags []string) ([]string, error) {
+	if len(tags) > apiconstants.MaxSessionTags {
+		return nil, fmt.Errorf("a session can have at most %d tags", apiconstants.MaxSessionTags)
+	}
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if err := validation.String(tag, apiconstants.MinTagLength, apiconstants.MaxTagLength); err != nil {
+			return nil, fmt.Errorf("tag %q: %w", tag, err)
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		normalized = append(normalized, tag)
 	}
+	return normalized, nil
 }
 
 // Populate needed empty fields: name and date
@@ -7,8 +7,8 @@ import (
 	"github.com/CTSDM/gogym/internal/api/exlog"
 	"github.com/CTSDM/gogym/internal/api/middleware"
 	"github.com/CTSDM/gogym/internal/api/set"
+	"github.com/CTSDM/gogym/internal/api/units"
 	"github.com/CTSDM/gogym/internal/api/util"
-	"github.com/CTSDM/gogym/internal/apiconstants"
 	"github.com/CTSDM/gogym/internal/database"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -21,7 +21,8 @@ func HandlerGetSession(db *database.Queries, logger *slog.Logger) http.HandlerFu
 	}
 	type res struct {
 		sessionRes
-		Sets []setItem `json:"sets"`
+		Sets   []setItem      `json:"sets"`
+		Groups []set.GroupRes `json:"groups"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -34,6 +35,11 @@ func HandlerGetSession(db *database.Queries, logger *slog.Logger) http.HandlerFu
 		// Get session id from the context
 		sessionID, _ := retrieveParseUUIDFromContext(r.Context())
 
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "get session")
+		if !ok {
+			return
+		}
+
 		// Fetch the session
 		sessionRow, err := db.GetSession(r.Context(), sessionID)
 		if err == pgx.ErrNoRows {
@@ -71,46 +77,24 @@ func HandlerGetSession(db *database.Queries, logger *slog.Logger) http.HandlerFu
 		// build response structure
 		logsBySetID := make(map[int64][]exlog.LogRes)
 		for _, log := range logs {
-			logsBySetID[log.SetID] = append(logsBySetID[log.SetID], exlog.LogRes{
-				ID:    log.ID,
-				SetID: log.SetID,
-				LogReq: exlog.LogReq{
-					ExerciseID: log.ExerciseID,
-					Weight:     log.Weight.Float64,
-					Reps:       log.Reps,
-					Order:      log.LogsOrder,
-				},
-			})
+			logsBySetID[log.SetID] = append(logsBySetID[log.SetID], exlog.LogResFromDB(log, unit))
 		}
 
 		setsBySessionID := make(map[string][]setItem)
+		dbSetsBySessionID := make(map[string][]database.Set)
 		for _, s := range setRows {
 			sessionID := s.SessionID.String()
+			dbSetsBySessionID[sessionID] = append(dbSetsBySessionID[sessionID], s)
 			setsBySessionID[sessionID] = append(setsBySessionID[sessionID], setItem{
-				SetRes: set.SetRes{
-					ID:        s.ID,
-					SessionID: sessionID,
-					SetReq: set.SetReq{
-						ExerciseID: s.ExerciseID,
-						SetOrder:   s.SetOrder,
-						RestTime:   s.RestTime.Int32,
-					},
-				},
-				Logs: logsBySetID[s.ID],
+				SetRes: set.SetResFromDB(s),
+				Logs:   logsBySetID[s.ID],
 			})
 		}
 
 		resParams := res{
-			sessionRes: sessionRes{
-				ID: sessionID.String(),
-				sessionReq: sessionReq{
-					Name:            sessionRow.Name,
-					Date:            sessionRow.Date.Time.Format(apiconstants.DATE_LAYOUT),
-					StartTimestamp:  sessionRow.StartTimestamp.Time.Unix(),
-					DurationMinutes: int(sessionRow.DurationMinutes.Int16),
-				},
-			},
-			Sets: setsBySessionID[sessionID.String()],
+			sessionRes: sessionResFromDB(sessionRow),
+			Sets:       setsBySessionID[sessionID.String()],
+			Groups:     set.GroupsFromDB(dbSetsBySessionID[sessionID.String()]),
 		}
 
 		util.RespondWithJSON(w, r, http.StatusOK, resParams)
@@ -5,62 +5,86 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/CTSDM/gogym/internal/api/exlog"
 	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/pagination"
 	"github.com/CTSDM/gogym/internal/api/set"
+	"github.com/CTSDM/gogym/internal/api/units"
 	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/api/validation"
 	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/auth"
 	"github.com/CTSDM/gogym/internal/database"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const (
-	MAX_LIMIT      int32 = 20
-	DEFAULT_LIMIT  int32 = 10
-	DEFAULT_OFFSET int32 = 0
+	MAX_LIMIT      int32  = 20
+	DEFAULT_LIMIT  int32  = 10
+	DEFAULT_OFFSET int32  = 0
+	DEFAULT_SORT   string = "date_desc"
 )
 
-func HandlerGetSessions(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+var sortOptions = []string{
+	"date_desc",
+	"date_asc",
+	"name_asc",
+	"name_desc",
+	"duration_desc",
+	"duration_asc",
+}
+
+// sort options supporting cursor pagination
+var keysetSortOptions = []string{"date_desc", "date_asc"}
+
+func HandlerGetSessions(db *database.Queries, authConfig *auth.Config, logger *slog.Logger) http.HandlerFunc {
 	type setItem struct {
 		set.SetRes
 		Logs []exlog.LogRes `json:"logs"`
 	}
 	type sessionItem struct {
 		sessionRes
-		Sets []setItem `json:"sets"`
+		Sets   []setItem      `json:"sets"`
+		Groups []set.GroupRes `json:"groups"`
 	}
 	type res struct {
-		Sessions []sessionItem `json:"sessions"`
-		Limit    int32
-		Offset   int32
-		Total    int `json:"total"` // total number of sessions for a given user
+		Sessions   []sessionItem `json:"sessions"`
+		Limit      int32         `json:"limit"`
+		Offset     int32         `json:"offset"`
+		NextCursor string        `json:"next_cursor,omitempty"`
+		Total      int           `json:"total"` // total number of sessions matching the filters
 	}
 
-	validateQueryParams := func(r *http.Request) (int32, int32, map[string]string) {
+	validateQueryParams := func(r *http.Request) (database.GetSessionsFilteredParams, map[string]string) {
 		problems := map[string]string{}
-		var offset int32
-		var limit int32
+		params := database.GetSessionsFilteredParams{
+			PageOffset: DEFAULT_OFFSET,
+			PageLimit:  DEFAULT_LIMIT,
+			Sort:       DEFAULT_SORT,
+		}
+		query := r.URL.Query()
 
 		// validate and offset
-		if r.URL.Query().Has("offset") {
-			parsed, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 32)
+		if query.Has("offset") {
+			parsed, err := strconv.ParseInt(query.Get("offset"), 10, 32)
 			if err != nil {
 				problems["offset"] = "invalid offset format"
 			} else if parsed < 0 {
 				problems["offset"] = "invalid offset value, must be positive"
 			} else {
-				offset = int32(parsed)
+				params.PageOffset = int32(parsed)
 			}
-		} else {
-			offset = DEFAULT_OFFSET
 		}
 
 		// validate and limit
-		if r.URL.Query().Has("limit") {
-			parsed, err := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 32)
+		if query.Has("limit") {
+			parsed, err := strconv.ParseInt(query.Get("limit"), 10, 32)
 			if err != nil {
 				problems["limit"] = "invalid limit format"
 			} else if parsed < 0 {
@@ -68,13 +92,88 @@ func HandlerGetSessions(db *database.Queries, logger *slog.Logger) http.HandlerF
 			} else if int32(parsed) > MAX_LIMIT {
 				problems["limit"] = fmt.Sprintf("invalid limit value, must be less than %d", MAX_LIMIT)
 			} else {
-				limit = int32(parsed)
+				params.PageLimit = int32(parsed)
+			}
+		}
+
+		// validate the date range, both ends are inclusive
+		if query.Has("from") {
+			date, err := validation.Date(query.Get("from"), apiconstants.DATE_LAYOUT, nil, nil)
+			if err != nil {
+				problems["from"] = "invalid from date: " + err.Error()
+			} else {
+				params.FromDate = pgtype.Date{Time: date, Valid: true}
 			}
-		} else {
-			limit = DEFAULT_LIMIT
+		}
+		if query.Has("to") {
+			date, err := validation.Date(query.Get("to"), apiconstants.DATE_LAYOUT, nil, nil)
+			if err != nil {
+				problems["to"] = "invalid to date: " + err.Error()
+			} else {
+				params.ToDate = pgtype.Date{Time: date, Valid: true}
+			}
+		}
+		if params.FromDate.Valid && params.ToDate.Valid && params.FromDate.Time.After(params.ToDate.Time) {
+			problems["from"] = "invalid from date: must be before the to date"
 		}
 
-		return offset, limit, problems
+		// validate the name substring
+		if query.Has("name") {
+			name := query.Get("name")
+			if err := validation.String(name, apiconstants.MinSessionNameLength, apiconstants.MaxSessionNameLength); err != nil {
+				problems["name"] = "invalid name: " + err.Error()
+			} else {
+				params.Name = pgtype.Text{String: escapeLikePattern(name), Valid: true}
+			}
+		}
+
+		// validate the exercise
+		if query.Has("exercise_id") {
+			parsed, err := strconv.ParseInt(query.Get("exercise_id"), 10, 32)
+			if err != nil {
+				problems["exercise_id"] = "invalid exercise_id format"
+			} else {
+				params.ExerciseID = pgtype.Int4{Int32: int32(parsed), Valid: true}
+			}
+		}
+
+		// validate the tags, sessions must contain all of them
+		if query.Has("tags") {
+			tags, err := NormalizeTags(strings.Split(query.Get("tags"), ","))
+			if err != nil {
+				problems["tags"] = "invalid tags: " + err.Error()
+			} else {
+				params.Tags = tags
+			}
+		}
+
+		// validate the sort option
+		if query.Has("sort") {
+			sort := query.Get("sort")
+			if !slices.Contains(sortOptions, sort) {
+				problems["sort"] = "invalid sort value, must be one of " + strings.Join(sortOptions, ", ")
+			} else {
+				params.Sort = sort
+			}
+		}
+
+		// validate the cursor, only date sorting has a stable keyset to resume from
+		if query.Has("cursor") {
+			cursor, err := pagination.Decode(query.Get("cursor"), authConfig.JWTsecret)
+			cursorID, errID := uuid.Parse(cursor.ID)
+			if err != nil || errID != nil {
+				problems["cursor"] = "invalid cursor"
+			} else if query.Has("offset") {
+				problems["cursor"] = "invalid cursor: cursor and offset cannot be used together"
+			} else if cursor.Sort != params.Sort {
+				problems["cursor"] = "invalid cursor: cursor was issued for a different sort"
+			} else {
+				params.CursorDate = pgtype.Date{Time: cursor.Date, Valid: true}
+				params.CursorID = pgtype.UUID{Bytes: cursorID, Valid: true}
+			}
+		}
+
+		return params, problems
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -89,16 +188,29 @@ func HandlerGetSessions(db *database.Queries, logger *slog.Logger) http.HandlerF
 		}
 
 		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
-		// Get offset and limit from the query parameters
-		offset, limit, problems := validateQueryParams(r)
+		// Get the filters, sorting and pagination from the query parameters
+		filterParams, problems := validateQueryParams(r)
 		if len(problems) > 0 {
 			reqLogger.Debug("get sessions failed - validation error", slog.Any("problem", problems))
 			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
 			return
 		}
 
-		// Get total number of sessions
-		sessionsCount, err := db.GetNumberSessionsByUserID(r.Context(), userID)
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "get sessions")
+		if !ok {
+			return
+		}
+
+		filterParams.UserID = userID
+		// Get total number of sessions matching the filters
+		sessionsCount, err := db.GetNumberSessionsFiltered(r.Context(), database.GetNumberSessionsFilteredParams{
+			UserID:     filterParams.UserID,
+			FromDate:   filterParams.FromDate,
+			ToDate:     filterParams.ToDate,
+			Name:       filterParams.Name,
+			ExerciseID: filterParams.ExerciseID,
+			Tags:       filterParams.Tags,
+		})
 		if err == pgx.ErrNoRows {
 			// early return with empty structure
 			util.RespondWithJSON(w, r, http.StatusOK, res{Sessions: make([]sessionItem, 0), Total: 0})
@@ -109,12 +221,11 @@ func HandlerGetSessions(db *database.Queries, logger *slog.Logger) http.HandlerF
 			return
 		}
 
-		// fetch sessions with pagination and offset
-		sessions, err := db.GetSessionsPaginated(r.Context(), database.GetSessionsPaginatedParams{
-			UserID: userID,
-			Offset: offset,
-			Limit:  limit,
-		})
+		// fetch sessions with filters, sorting and pagination
+		// one extra row is requested to know whether there is a next page
+		limit := filterParams.PageLimit
+		filterParams.PageLimit = limit + 1
+		sessions, err := db.GetSessionsFiltered(r.Context(), filterParams)
 		if err != nil {
 			reqLogger.Error("get sessions failed - database error", slog.String("error", err.Error()))
 			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
@@ -122,10 +233,36 @@ func HandlerGetSessions(db *database.Queries, logger *slog.Logger) http.HandlerF
 		}
 
 		if len(sessions) == 0 {
-			util.RespondWithJSON(w, r, http.StatusOK, res{Sessions: []sessionItem{}, Total: int(sessionsCount)})
+			util.RespondWithJSON(w, r, http.StatusOK, res{
+				Sessions: []sessionItem{},
+				Total:    int(sessionsCount),
+				Limit:    limit,
+				Offset:   filterParams.PageOffset,
+			})
 			return
 		}
 
+		// generate the cursor pointing after the last session of the page
+		var nextCursor string
+		hasNext := len(sessions) > int(limit)
+		if hasNext {
+			sessions = sessions[:limit]
+		}
+		if hasNext && limit > 0 && slices.Contains(keysetSortOptions, filterParams.Sort) {
+			last := sessions[len(sessions)-1]
+			nextCursor, err = pagination.Encode(pagination.Cursor{
+				Date: last.Date.Time,
+				ID:   last.ID.String(),
+				Sort: filterParams.Sort,
+			}, authConfig.JWTsecret)
+			if err != nil {
+				reqLogger.Error("get sessions failed - cursor encoding error", slog.String("error", err.Error()))
+				util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+				return
+			}
+			w.Header().Set("Link", pagination.NextLink(r, nextCursor))
+		}
+
 		// collect session IDs
 		sessionIDs := make([]uuid.UUID, len(sessions))
 		for i, s := range sessions {
@@ -163,32 +300,17 @@ func HandlerGetSessions(db *database.Queries, logger *slog.Logger) http.HandlerF
 		// build response structure
 		logsBySetID := make(map[int64][]exlog.LogRes)
 		for _, log := range logs {
-			logsBySetID[log.SetID] = append(logsBySetID[log.SetID], exlog.LogRes{
-				ID:    log.ID,
-				SetID: log.SetID,
-				LogReq: exlog.LogReq{
-					ExerciseID: log.ExerciseID,
-					Weight:     log.Weight.Float64,
-					Reps:       log.Reps,
-					Order:      log.LogsOrder,
-				},
-			})
+			logsBySetID[log.SetID] = append(logsBySetID[log.SetID], exlog.LogResFromDB(log, unit))
 		}
 
 		setsBySessionID := make(map[string][]setItem)
+		dbSetsBySessionID := make(map[string][]database.Set)
 		for _, s := range sets {
 			sessionID := s.SessionID.String()
+			dbSetsBySessionID[sessionID] = append(dbSetsBySessionID[sessionID], s)
 			setsBySessionID[sessionID] = append(setsBySessionID[sessionID], setItem{
-				SetRes: set.SetRes{
-					ID:        s.ID,
-					SessionID: sessionID,
-					SetReq: set.SetReq{
-						ExerciseID: s.ExerciseID,
-						SetOrder:   s.SetOrder,
-						RestTime:   s.RestTime.Int32,
-					},
-				},
-				Logs: logsBySetID[s.ID],
+				SetRes: set.SetResFromDB(s),
+				Logs:   logsBySetID[s.ID],
 			})
 		}
 
@@ -196,24 +318,24 @@ func HandlerGetSessions(db *database.Queries, logger *slog.Logger) http.HandlerF
 		for _, s := range sessions {
 			sessionID := s.ID.String()
 			result = append(result, sessionItem{
-				sessionRes: sessionRes{
-					ID: sessionID,
-					sessionReq: sessionReq{
-						Name:            s.Name,
-						Date:            s.Date.Time.Format(apiconstants.DATE_LAYOUT),
-						StartTimestamp:  s.StartTimestamp.Time.Unix(),
-						DurationMinutes: int(s.DurationMinutes.Int16),
-					},
-				},
-				Sets: setsBySessionID[sessionID],
+				sessionRes: sessionResFromDB(s),
+				Sets:       setsBySessionID[sessionID],
+				Groups:     set.GroupsFromDB(dbSetsBySessionID[sessionID]),
 			})
 		}
 
 		util.RespondWithJSON(w, r, http.StatusOK, res{
-			Sessions: result,
-			Total:    int(sessionsCount),
-			Limit:    limit,
-			Offset:   offset,
+			Sessions:   result,
+			Total:      int(sessionsCount),
+			Limit:      limit,
+			Offset:     filterParams.PageOffset,
+			NextCursor: nextCursor,
 		})
 	}
 }
+
+// Escapes the LIKE wildcards so the name filter matches a literal substring
+func escapeLikePattern(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(s)
+}
@@ -1,16 +1,24 @@
 package session
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/CTSDM/gogym/internal/api/middleware"
 	"github.com/CTSDM/gogym/internal/api/testutil"
 	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/auth"
 	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -147,6 +155,7 @@ func TestHandlerGetSessions(t *testing.T) {
 	require.NoError(t, testutil.Cleanup(dbPool, "users"))
 
 	db := database.New(dbPool)
+	authConfig := &auth.Config{JWTsecret: "testSecret"}
 	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
 
 	for _, tc := range testCases {
@@ -197,7 +206,7 @@ func TestHandlerGetSessions(t *testing.T) {
 
 			rr := httptest.NewRecorder()
 
-			handler := HandlerGetSessions(db, logger)
+			handler := HandlerGetSessions(db, authConfig, logger)
 			middleware.RequestID(handler).ServeHTTP(rr, req)
 			if tc.statusCode != rr.Code {
 				t.Logf("Status code do not match, want %d, got %d", tc.statusCode, rr.Code)
@@ -256,3 +265,291 @@ func TestHandlerGetSessions(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlerGetSessionsFilters(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+
+	db := database.New(dbPool)
+	authConfig := &auth.Config{JWTsecret: "testSecret"}
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	squatsID := testutil.CreateExerciseDBTestHelper(t, db, "squats")
+	benchID := testutil.CreateExerciseDBTestHelper(t, db, "bench press")
+
+	setupSessions := []struct {
+		name       string
+		date       time.Time
+		duration   int16
+		tags       []string
+		exerciseID int32
+	}{
+		{name: "Leg day", date: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), duration: 60, tags: []string{"legs"}, exerciseID: squatsID},
+		{name: "Push day", date: time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC), duration: 45, tags: []string{"push", "upper"}, exerciseID: benchID},
+		{name: "Leg day 100%", date: time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), duration: 90, tags: []string{"legs", "heavy"}, exerciseID: squatsID},
+	}
+	for _, s := range setupSessions {
+		session, err := db.CreateSession(context.Background(), database.CreateSessionParams{
+			Name:            s.name,
+			Date:            pgtype.Date{Time: s.date, Valid: true},
+			DurationMinutes: pgtype.Int2{Int16: s.duration, Valid: true},
+			UserID:          user.ID,
+			Tags:            s.tags,
+		})
+		require.NoError(t, err)
+		testutil.CreateSetDBTestHelper(t, db, session.ID, s.exerciseID)
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		expectedNames []string
+		statusCode    int
+		errMsg        []string
+	}{
+		{
+			name:          "default sort by date descending",
+			expectedNames: []string{"Leg day 100%", "Push day", "Leg day"},
+			statusCode:    http.StatusOK,
+		},
+		{
+			name:          "date range",
+			query:         "from=2025-02-01&to=2025-03-10",
+			expectedNames: []string{"Leg day 100%", "Push day"},
+			statusCode:    http.StatusOK,
+		},
+		{
+			name:          "name substring is case insensitive",
+			query:         "name=LEG",
+			expectedNames: []string{"Leg day 100%", "Leg day"},
+			statusCode:    http.StatusOK,
+		},
+		{
+			name:          "name wildcards are matched literally",
+			query:         "name=" + url.QueryEscape("100%"),
+			expectedNames: []string{"Leg day 100%"},
+			statusCode:    http.StatusOK,
+		},
+		{
+			name:          "contains exercise",
+			query:         fmt.Sprintf("exercise_id=%d", benchID),
+			expectedNames: []string{"Push day"},
+			statusCode:    http.StatusOK,
+		},
+		{
+			name:          "must contain all tags",
+			query:         "tags=legs,Heavy",
+			expectedNames: []string{"Leg day 100%"},
+			statusCode:    http.StatusOK,
+		},
+		{
+			name:          "sort by duration ascending",
+			query:         "sort=duration_asc",
+			expectedNames: []string{"Push day", "Leg day", "Leg day 100%"},
+			statusCode:    http.StatusOK,
+		},
+		{
+			name:          "sort by name descending with filter",
+			query:         "sort=name_desc&tags=legs",
+			expectedNames: []string{"Leg day 100%", "Leg day"},
+			statusCode:    http.StatusOK,
+		},
+		{
+			name:          "no matches",
+			query:         "from=2026-01-01",
+			expectedNames: []string{},
+			statusCode:    http.StatusOK,
+		},
+		{
+			name:       "invalid from date",
+			query:      "from=2025-13-01",
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid from date"},
+		},
+		{
+			name:       "from after to",
+			query:      "from=2025-03-01&to=2025-02-01",
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"must be before the to date"},
+		},
+		{
+			name:       "invalid exercise id",
+			query:      "exercise_id=squats",
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid exercise_id format"},
+		},
+		{
+			name:       "invalid sort",
+			query:      "sort=random",
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid sort value"},
+		},
+		{
+			name:       "invalid empty tag",
+			query:      "tags=legs,,push",
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid tags"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/test?"+tc.query, nil)
+			require.NoError(t, err, "unexpected error while creating the request")
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			rr := httptest.NewRecorder()
+
+			handler := HandlerGetSessions(db, authConfig, logger)
+			middleware.RequestID(handler).ServeHTTP(rr, req)
+			if tc.statusCode != rr.Code {
+				t.Logf("Status code do not match, want %d, got %d", tc.statusCode, rr.Code)
+				t.Fatalf("Body response: %s", rr.Body.String())
+			}
+
+			if tc.statusCode > 399 {
+				for _, message := range tc.errMsg {
+					assert.Contains(t, rr.Body.String(), message)
+				}
+				return
+			}
+
+			type res struct {
+				Sessions []struct {
+					Name string   `json:"name"`
+					Tags []string `json:"tags"`
+				} `json:"sessions"`
+				Total int `json:"total"`
+			}
+			var resParams res
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			names := make([]string, len(resParams.Sessions))
+			for i, s := range resParams.Sessions {
+				names[i] = s.Name
+				assert.NotNil(t, s.Tags)
+			}
+			assert.Equal(t, tc.expectedNames, names)
+			assert.Equal(t, len(tc.expectedNames), resParams.Total)
+		})
+	}
+}
+
+func TestHandlerGetSessionsCursor(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+
+	db := database.New(dbPool)
+	authConfig := &auth.Config{JWTsecret: "testSecret"}
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+
+	// several sessions share the same date so the id is needed to break ties
+	const numSessions = 7
+	for i := range numSessions {
+		_, err := db.CreateSession(context.Background(), database.CreateSessionParams{
+			Name:   fmt.Sprintf("session-%d", i),
+			Date:   pgtype.Date{Time: time.Date(2025, 1, 1+i/3, 0, 0, 0, 0, time.UTC), Valid: true},
+			UserID: user.ID,
+		})
+		require.NoError(t, err)
+	}
+
+	type res struct {
+		Sessions []struct {
+			ID   string `json:"id"`
+			Date string `json:"date"`
+		} `json:"sessions"`
+		Limit      int32  `json:"limit"`
+		NextCursor string `json:"next_cursor"`
+		Total      int    `json:"total"`
+	}
+
+	doRequest := func(t *testing.T, target string) (*httptest.ResponseRecorder, res) {
+		req, err := http.NewRequest("GET", target, nil)
+		require.NoError(t, err, "unexpected error while creating the request")
+		req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+		rr := httptest.NewRecorder()
+		middleware.RequestID(HandlerGetSessions(db, authConfig, logger)).ServeHTTP(rr, req)
+
+		var resParams res
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.NewDecoder(bytes.NewReader(rr.Body.Bytes())).Decode(&resParams))
+		}
+		return rr, resParams
+	}
+
+	for _, sort := range []string{"date_desc", "date_asc"} {
+		t.Run("walk all pages sorted by "+sort, func(t *testing.T) {
+			seen := map[string]struct{}{}
+			dates := []string{}
+			target := "/api/v1/sessions?limit=3&sort=" + sort
+			pages := 0
+			for target != "" {
+				rr, resParams := doRequest(t, target)
+				require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+				assert.Equal(t, numSessions, resParams.Total)
+				assert.Equal(t, int32(3), resParams.Limit)
+				for _, s := range resParams.Sessions {
+					_, ok := seen[s.ID]
+					assert.False(t, ok, "session %s returned twice", s.ID)
+					seen[s.ID] = struct{}{}
+					dates = append(dates, s.Date)
+				}
+
+				link := rr.Header().Get("Link")
+				if resParams.NextCursor == "" {
+					assert.Empty(t, link)
+					target = ""
+				} else {
+					require.Contains(t, link, `rel="next"`)
+					target = strings.TrimPrefix(strings.Split(link, ">")[0], "<")
+				}
+				pages++
+			}
+			assert.Equal(t, 3, pages)
+			assert.Len(t, seen, numSessions)
+			assert.True(t, slices.IsSortedFunc(dates, func(a, b string) int {
+				if sort == "date_asc" {
+					return strings.Compare(a, b)
+				}
+				return strings.Compare(b, a)
+			}))
+		})
+	}
+
+	t.Run("new sessions do not shift the next page", func(t *testing.T) {
+		_, first := doRequest(t, "/api/v1/sessions?limit=3")
+		require.NotEmpty(t, first.NextCursor)
+		testutil.CreateSessionDBTestHelper(t, db, "new session", user.ID)
+		_, second := doRequest(t, "/api/v1/sessions?limit=3&cursor="+url.QueryEscape(first.NextCursor))
+		for _, s := range second.Sessions {
+			for _, prev := range first.Sessions {
+				assert.NotEqual(t, prev.ID, s.ID)
+			}
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		rr, _ := doRequest(t, "/api/v1/sessions?cursor=invalid")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "invalid cursor")
+	})
+
+	t.Run("cursor with offset", func(t *testing.T) {
+		_, first := doRequest(t, "/api/v1/sessions?limit=3")
+		rr, _ := doRequest(t, "/api/v1/sessions?offset=1&cursor="+url.QueryEscape(first.NextCursor))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "cursor and offset cannot be used together")
+	})
+
+	t.Run("cursor with a different sort", func(t *testing.T) {
+		_, first := doRequest(t, "/api/v1/sessions?limit=3")
+		rr, _ := doRequest(t, "/api/v1/sessions?sort=date_asc&cursor="+url.QueryEscape(first.NextCursor))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "different sort")
+	})
+
+	t.Run("no cursor for non date sorts", func(t *testing.T) {
+		rr, resParams := doRequest(t, "/api/v1/sessions?limit=3&sort=name_asc")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Empty(t, resParams.NextCursor)
+		assert.Empty(t, rr.Header().Get("Link"))
+	})
+}
@@ -0,0 +1,102 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/set"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/api/validation"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type reorderSetsReq struct {
+	IDs []int64 `json:"ids"` // every set of the session, in the new order
+}
+
+func (r *reorderSetsReq) Valid(ctx context.Context) map[string]string {
+	problems := make(map[string]string)
+	if len(r.IDs) == 0 {
+		problems["ids"] = "invalid ids: ids cannot be empty"
+	}
+	return problems
+}
+
+func HandlerReorderSets(pool *pgxpool.Pool, db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		sessionID, _ := retrieveParseUUIDFromContext(r.Context())
+		reqLogger = reqLogger.With(slog.String("session_id", sessionID.String()))
+
+		reqParams, problems, err := validation.DecodeValid[*reorderSetsReq](r)
+		if len(problems) > 0 {
+			reqLogger.Debug("reorder sets failed - validation errors", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		} else if err != nil {
+			reqLogger.Debug("reorder sets failed - invalid payload", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusBadRequest, "invalid payload", err)
+			return
+		}
+
+		tx, err := pool.Begin(r.Context())
+		if err != nil {
+			reqLogger.Error("reorder sets failed - transaction start error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		txQueries := db.WithTx(tx)
+		defer tx.Rollback(r.Context())
+
+		// Lock the sets of the session so concurrent requests cannot interleave with the new order
+		currentIDs, err := txQueries.GetSetIDsBySessionIDForUpdate(r.Context(), sessionID)
+		if err != nil {
+			reqLogger.Error("reorder sets failed - get sets database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		if err := validation.Order(reqParams.IDs, currentIDs); err != nil {
+			reqLogger.Debug("reorder sets failed - incomplete order", slog.String("error", err.Error()))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, map[string]string{
+				"ids": "invalid ids: " + err.Error(),
+			})
+			return
+		}
+
+		if err := txQueries.ReorderSets(r.Context(), database.ReorderSetsParams{
+			Ids:       reqParams.IDs,
+			SessionID: sessionID,
+		}); err != nil {
+			reqLogger.Error("reorder sets failed - update order database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		sets, err := txQueries.GetSetsBySessionIDs(r.Context(), []uuid.UUID{sessionID})
+		if err != nil {
+			reqLogger.Error("reorder sets failed - get sets database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		if err := tx.Commit(r.Context()); err != nil {
+			reqLogger.Error("reorder sets failed - transaction commit error", slog.String("error", err.Error()))
+			err = fmt.Errorf("could not commit the transaction: %w", err)
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		resParams := make([]set.SetRes, len(sets))
+		for i, s := range sets {
+			resParams[i] = set.SetResFromDB(s)
+		}
+
+		reqLogger.Info("reorder sets success")
+		util.RespondWithJSON(w, r, http.StatusOK, resParams)
+	}
+}
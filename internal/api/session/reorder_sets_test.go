@@ -0,0 +1,117 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/set"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerReorderSets(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	exerciseID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+	sessionID := testutil.CreateSessionDBTestHelper(t, db, "leg day", user.ID)
+	otherSessionID := testutil.CreateSessionDBTestHelper(t, db, "push day", user.ID)
+	first := testutil.CreateSetDBTestHelper(t, db, sessionID, exerciseID)
+	second := testutil.CreateSetDBTestHelper(t, db, sessionID, exerciseID)
+	third := testutil.CreateSetDBTestHelper(t, db, sessionID, exerciseID)
+	foreign := testutil.CreateSetDBTestHelper(t, db, otherSessionID, exerciseID)
+
+	testCases := []struct {
+		name          string
+		ids           []int64
+		statusCode    int
+		errMsg        []string
+		expectedOrder []int64
+	}{
+		{
+			name:          "happy path: reverse order",
+			ids:           []int64{third, second, first},
+			statusCode:    http.StatusOK,
+			expectedOrder: []int64{third, second, first},
+		},
+		{
+			name:          "happy path: move one set",
+			ids:           []int64{first, third, second},
+			statusCode:    http.StatusOK,
+			expectedOrder: []int64{first, third, second},
+		},
+		{
+			name:       "empty ids",
+			ids:        []int64{},
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid ids"},
+		},
+		{
+			name:       "missing set",
+			ids:        []int64{first, second},
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid ids"},
+		},
+		{
+			name:       "repeated set",
+			ids:        []int64{first, first, second},
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid ids"},
+		},
+		{
+			name:       "set from another session",
+			ids:        []int64{first, second, foreign},
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid ids"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := json.Marshal(reorderSetsReq{IDs: tc.ids})
+			require.NoError(t, err)
+			req, err := http.NewRequest("PUT", "/test", bytes.NewReader(body))
+			require.NoError(t, err, "unexpected error while creating the request")
+			ctx := util.ContextWithUser(req.Context(), user.ID)
+			ctx = util.ContextWithResourceID(ctx, sessionID)
+			req = req.WithContext(ctx)
+			rr := httptest.NewRecorder()
+
+			handler := HandlerReorderSets(dbPool, db, logger)
+			middleware.RequestID(handler).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				for _, msg := range tc.errMsg {
+					assert.Contains(t, rr.Body.String(), msg)
+				}
+				return
+			}
+
+			var resParams []set.SetRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			require.Len(t, resParams, len(tc.expectedOrder))
+			for i, s := range resParams {
+				assert.Equal(t, tc.expectedOrder[i], s.ID)
+				assert.Equal(t, int32(i+1), s.SetOrder)
+			}
+
+			sets, err := db.GetSetsBySessionIDs(context.Background(), []uuid.UUID{sessionID})
+			require.NoError(t, err)
+			for i, s := range sets {
+				assert.Equal(t, tc.expectedOrder[i], s.ID)
+			}
+		})
+	}
+}
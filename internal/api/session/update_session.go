@@ -7,7 +7,6 @@ import (
 	"github.com/CTSDM/gogym/internal/api/middleware"
 	"github.com/CTSDM/gogym/internal/api/util"
 	"github.com/CTSDM/gogym/internal/api/validation"
-	"github.com/CTSDM/gogym/internal/apiconstants"
 	"github.com/CTSDM/gogym/internal/database"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -38,6 +37,13 @@ func HandlerUpdateSession(db *database.Queries, logger *slog.Logger) http.Handle
 			Date:            pgtype.Date{Time: reqParams.date, Valid: true},
 			StartTimestamp:  pgtype.Timestamp{Time: reqParams.startTimestamp, Valid: true},
 			DurationMinutes: pgtype.Int2{Int16: reqParams.durationMinutes, Valid: true},
+			Tags:            reqParams.Tags,
+			Notes:           pgtype.Text{String: reqParams.Notes, Valid: reqParams.Notes != ""},
+			Rpe:             reqParams.rpe,
+			SleepQuality:    reqParams.sleepQuality,
+			Bodyweight:      reqParams.bodyweight,
+			Mood:            reqParams.mood,
+			Location:        pgtype.Text{String: reqParams.Location, Valid: reqParams.Location != ""},
 		}
 		updatedSession, err := db.UpdateSession(r.Context(), dbParams)
 		if err == pgx.ErrNoRows {
@@ -51,14 +57,6 @@ func HandlerUpdateSession(db *database.Queries, logger *slog.Logger) http.Handle
 		}
 
 		reqLogger.Info("update session success")
-		util.RespondWithJSON(w, r, http.StatusOK, sessionRes{
-			ID: updatedSession.ID.String(),
-			sessionReq: sessionReq{
-				Name:            updatedSession.Name,
-				Date:            updatedSession.Date.Time.Format(apiconstants.DATE_LAYOUT),
-				DurationMinutes: int(updatedSession.DurationMinutes.Int16),
-				StartTimestamp:  updatedSession.StartTimestamp.Time.Unix(),
-			},
-		})
+		util.RespondWithJSON(w, r, http.StatusOK, sessionResFromDB(updatedSession))
 	}
 }
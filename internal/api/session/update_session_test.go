@@ -156,3 +156,114 @@ func TestHandlerUpdateSession(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlerUpdateSessionDetails(t *testing.T) {
+	testCases := []struct {
+		name       string
+		reqParams  sessionReq
+		statusCode int
+		errMsg     []string
+	}{
+		{
+			name: "happy path: all details",
+			reqParams: sessionReq{
+				Name:         "updated session",
+				Date:         "2025-11-08",
+				Tags:         []string{" Legs ", "legs", "heavy"},
+				Notes:        "felt strong",
+				RPE:          8,
+				SleepQuality: 4,
+				Bodyweight:   82.5,
+				Mood:         5,
+				Location:     "home gym",
+			},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "happy path: details are optional",
+			reqParams:  sessionReq{Name: "updated session", Date: "2025-11-08"},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "rpe out of range",
+			reqParams:  sessionReq{Name: "updated session", Date: "2025-11-08", RPE: 11},
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid rpe"},
+		},
+		{
+			name:       "sleep quality out of range",
+			reqParams:  sessionReq{Name: "updated session", Date: "2025-11-08", SleepQuality: -1},
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid sleep_quality"},
+		},
+		{
+			name:       "mood out of range",
+			reqParams:  sessionReq{Name: "updated session", Date: "2025-11-08", Mood: 6},
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid mood"},
+		},
+		{
+			name:       "negative bodyweight",
+			reqParams:  sessionReq{Name: "updated session", Date: "2025-11-08", Bodyweight: -80},
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid bodyweight"},
+		},
+		{
+			name:       "too many tags",
+			reqParams:  sessionReq{Name: "updated session", Date: "2025-11-08", Tags: make([]string, 21)},
+			statusCode: http.StatusBadRequest,
+			errMsg:     []string{"invalid tags"},
+		},
+	}
+
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	sessionID := testutil.CreateSessionDBTestHelper(t, db, "test session", user.ID)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := json.Marshal(tc.reqParams)
+			require.NoError(t, err, "unexpected JSON marshal error")
+			req, err := http.NewRequest("PUT", "/test", bytes.NewReader(body))
+			require.NoError(t, err, "unexpected error while creating the request")
+
+			ctx := util.ContextWithUser(req.Context(), user.ID)
+			ctx = util.ContextWithResourceID(ctx, sessionID)
+			req = req.WithContext(ctx)
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerUpdateSession(db, logger)).ServeHTTP(rr, req)
+			if tc.statusCode != rr.Code {
+				t.Logf("mismatch in status code, want %d, gots %d", tc.statusCode, rr.Code)
+				t.Fatalf("Body response: %s", rr.Body.String())
+			}
+			if tc.statusCode > 399 {
+				for _, message := range tc.errMsg {
+					assert.Contains(t, rr.Body.String(), message)
+				}
+				return
+			}
+
+			var resParams sessionRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			assert.Equal(t, tc.reqParams.Notes, resParams.Notes)
+			assert.Equal(t, tc.reqParams.RPE, resParams.RPE)
+			assert.Equal(t, tc.reqParams.SleepQuality, resParams.SleepQuality)
+			assert.Equal(t, tc.reqParams.Bodyweight, resParams.Bodyweight)
+			assert.Equal(t, tc.reqParams.Mood, resParams.Mood)
+			assert.Equal(t, tc.reqParams.Location, resParams.Location)
+
+			// the stored session must be returned the same way by the getter
+			sessionDB, err := db.GetSession(req.Context(), sessionID)
+			require.NoError(t, err)
+			assert.Equal(t, resParams, sessionResFromDB(sessionDB))
+			if tc.reqParams.Tags != nil {
+				assert.Equal(t, []string{"legs", "heavy"}, resParams.Tags)
+			} else {
+				assert.Empty(t, resParams.Tags)
+			}
+		})
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/units"
 	"github.com/CTSDM/gogym/internal/api/util"
 	"github.com/CTSDM/gogym/internal/api/validation"
 	"github.com/CTSDM/gogym/internal/apiconstants"
@@ -16,12 +18,34 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const (
+	SetTypeWarmUp  = "warm_up"
+	SetTypeWorking = "working"
+	SetTypeDrop    = "drop"
+	SetTypeAMRAP   = "amrap"
+	SetTypeBackOff = "back_off"
+	SetTypeCluster = "cluster"
+)
+
+var SetTypes = []string{
+	SetTypeWarmUp,
+	SetTypeWorking,
+	SetTypeDrop,
+	SetTypeAMRAP,
+	SetTypeBackOff,
+	SetTypeCluster,
+}
+
 type SetReq struct {
-	ExerciseID int32 `json:"exercise_id"`
-	SetOrder   int32 `json:"set_order"`
-	RestTime   int32 `json:"rest_time"`
+	ExerciseID int32  `json:"exercise_id"`
+	SetOrder   int32  `json:"set_order"`
+	RestTime   int32  `json:"rest_time"`
+	SetType    string `json:"set_type"`
+	// Sets of a session sharing a group key form a superset or a circuit and share the rest time
+	GroupKey string `json:"group_key,omitempty"`
 }
 
 type SetRes struct {
@@ -30,6 +54,12 @@ type SetRes struct {
 	SetReq
 }
 
+// Created sets carry the previous performance of the exercise, null when it is logged for the first time
+type createSetRes struct {
+	SetRes
+	LastPerformance *LastPerformanceRes `json:"last_performance"`
+}
+
 func (r *SetReq) Valid(ctx context.Context) map[string]string {
 	problems := make(map[string]string)
 
@@ -46,10 +76,42 @@ func (r *SetReq) Valid(ctx context.Context) map[string]string {
 		r.RestTime = 0
 	}
 
+	// set type validation, sets are working sets by default
+	if r.SetType == "" {
+		r.SetType = SetTypeWorking
+	}
+	if !slices.Contains(SetTypes, r.SetType) {
+		problems["set_type"] = "invalid set_type: set_type must be one of " + strings.Join(SetTypes, ", ")
+	}
+
+	// group key validation, it is an optional parameter
+	r.GroupKey = strings.TrimSpace(r.GroupKey)
+	if err := validation.String(r.GroupKey, 0, apiconstants.MaxGroupKeyLength); err != nil {
+		problems["group_key"] = "invalid group_key: " + err.Error()
+	}
+
 	return problems
 }
 
-func HandlerCreateSet(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+func (r *SetReq) groupKey() pgtype.Text {
+	return pgtype.Text{String: r.GroupKey, Valid: r.GroupKey != ""}
+}
+
+func SetResFromDB(set database.Set) SetRes {
+	return SetRes{
+		ID:        set.ID,
+		SessionID: set.SessionID.String(),
+		SetReq: SetReq{
+			ExerciseID: set.ExerciseID,
+			SetOrder:   set.SetOrder,
+			RestTime:   set.RestTime.Int32,
+			SetType:    set.SetType,
+			GroupKey:   set.GroupKey.String,
+		},
+	}
+}
+
+func HandlerCreateSet(pool *pgxpool.Pool, db *database.Queries, logger *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		reqLogger := middleware.BasicReqLogger(logger, r)
 		// session id must be a valid uuid
@@ -75,17 +137,39 @@ func HandlerCreateSet(db *database.Queries, logger *slog.Logger) http.HandlerFun
 			return
 		}
 
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "create set")
+		if !ok {
+			return
+		}
+
+		// Begin transaction, grouped sets update the rest time of the whole group
+		tx, err := pool.Begin(r.Context())
+		if err != nil {
+			reqLogger.Error("create set failed - transaction start error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		txQueries := db.WithTx(tx)
+		defer tx.Rollback(r.Context())
+
 		// Record the set into the database
 		dbParams := database.CreateSetParams{
 			SessionID:  sessionID,
 			SetOrder:   reqParams.SetOrder,
 			ExerciseID: reqParams.ExerciseID,
 			RestTime:   pgtype.Int4{Int32: reqParams.RestTime, Valid: true},
+			SetType:    reqParams.SetType,
+			GroupKey:   reqParams.groupKey(),
 		}
 
-		set, err := db.CreateSet(r.Context(), dbParams)
+		set, err := txQueries.CreateSet(r.Context(), dbParams)
 		if err != nil {
 			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				reqLogger.Debug("create set failed - set order already in use", slog.Int64("set_order", int64(reqParams.SetOrder)))
+				util.RespondWithError(w, r, http.StatusConflict, "set_order already in use in the session", err)
+				return
+			}
 			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
 				if strings.Contains(err.Error(), "session") {
 					reqLogger.Warn("create set failed - session not found")
@@ -110,15 +194,36 @@ func HandlerCreateSet(db *database.Queries, logger *slog.Logger) http.HandlerFun
 			return
 		}
 
+		if set.GroupKey.Valid {
+			if err := txQueries.UpdateSetsRestTimeByGroup(r.Context(), database.UpdateSetsRestTimeByGroupParams{
+				RestTime:  set.RestTime,
+				SessionID: set.SessionID,
+				GroupKey:  set.GroupKey,
+			}); err != nil {
+				reqLogger.Error("create set failed - update group rest time database error", slog.String("error", err.Error()))
+				util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+				return
+			}
+		}
+
+		last, err := lastPerformance(r.Context(), txQueries, set.SessionID, set.ExerciseID, unit)
+		if err != nil {
+			reqLogger.Error("create set failed - last performance database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		if err := tx.Commit(r.Context()); err != nil {
+			reqLogger.Error("create set failed - transaction commit error", slog.String("error", err.Error()))
+			err = fmt.Errorf("could not commit the transaction: %w", err)
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
 		reqLogger.Info("create set success", slog.Int64("set_id", set.ID))
-		util.RespondWithJSON(w, r, http.StatusCreated,
-			SetRes{
-				ID:        set.ID,
-				SessionID: set.SessionID.String(),
-				SetReq: SetReq{
-					SetOrder: set.SetOrder,
-					RestTime: set.RestTime.Int32,
-				},
-			})
+		util.RespondWithJSON(w, r, http.StatusCreated, createSetRes{
+			SetRes:          SetResFromDB(set),
+			LastPerformance: last,
+		})
 	}
 }
@@ -7,12 +7,15 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/CTSDM/gogym/internal/api/middleware"
 	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
 	"github.com/CTSDM/gogym/internal/apiconstants"
 	"github.com/CTSDM/gogym/internal/database"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -24,6 +27,8 @@ func TestCreateSet(t *testing.T) {
 		restTime     int32
 		statusCode   int
 		exerciseID   int32
+		setType      string
+		groupKey     string
 		sessionIDStr string
 		hasEmptyJSON bool
 		errMessage   []string
@@ -69,6 +74,26 @@ func TestCreateSet(t *testing.T) {
 			statusCode: http.StatusBadRequest,
 			errMessage: []string{"must be less than"},
 		},
+		{
+			name:       "warm up set with group key",
+			order:      1,
+			restTime:   60,
+			setType:    SetTypeWarmUp,
+			groupKey:   "A",
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "invalid set type",
+			setType:    "heavy",
+			statusCode: http.StatusBadRequest,
+			errMessage: []string{"invalid set_type"},
+		},
+		{
+			name:       "group key too long",
+			groupKey:   "superset-number-one",
+			statusCode: http.StatusBadRequest,
+			errMessage: []string{"invalid group_key"},
+		},
 	}
 
 	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
@@ -91,6 +116,8 @@ func TestCreateSet(t *testing.T) {
 					RestTime:   tc.restTime,
 					SetOrder:   tc.order,
 					ExerciseID: exerciseID,
+					SetType:    tc.setType,
+					GroupKey:   tc.groupKey,
 				}
 
 				if tc.exerciseID != 0 {
@@ -111,7 +138,7 @@ func TestCreateSet(t *testing.T) {
 			rr := httptest.NewRecorder()
 
 			// call the function
-			handler := HandlerCreateSet(db, logger)
+			handler := HandlerCreateSet(dbPool, db, logger)
 			middleware.RequestID(handler).ServeHTTP(rr, req)
 			if tc.statusCode != rr.Code {
 				t.Logf("Status code do not match, want %d, got %d", tc.statusCode, rr.Code)
@@ -135,6 +162,13 @@ func TestCreateSet(t *testing.T) {
 					assert.Equal(t, int32(0), resParams.RestTime)
 				}
 				assert.Equal(t, tc.order, resParams.SetOrder)
+				assert.Equal(t, exerciseID, resParams.ExerciseID)
+				if tc.setType != "" {
+					assert.Equal(t, tc.setType, resParams.SetType)
+				} else {
+					assert.Equal(t, SetTypeWorking, resParams.SetType)
+				}
+				assert.Equal(t, tc.groupKey, resParams.GroupKey)
 				// check that the created user is on the database
 				_, err := db.GetSet(context.Background(), resParams.ID)
 				assert.NoError(t, err)
@@ -142,3 +176,167 @@ func TestCreateSet(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateSetGroupRestTime(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "usertest", "passwordtest", false)
+	sessionID := testutil.CreateSessionDBTestHelper(t, db, "test name", user.ID)
+	exerciseID := testutil.CreateExerciseDBTestHelper(t, db, "pull ups")
+
+	// the last set of the group defines the shared rest time
+	restTimes := []int32{30, 45, 120}
+	for i, restTime := range restTimes {
+		body, err := json.Marshal(SetReq{
+			ExerciseID: exerciseID,
+			SetOrder:   int32(i),
+			RestTime:   restTime,
+			GroupKey:   "A",
+		})
+		require.NoError(t, err)
+		req, err := http.NewRequest("POST", "/test", bytes.NewReader(body))
+		require.NoError(t, err)
+		req.SetPathValue("sessionID", sessionID.String())
+		rr := httptest.NewRecorder()
+		middleware.RequestID(HandlerCreateSet(dbPool, db, logger)).ServeHTTP(rr, req)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	}
+
+	sets, err := db.GetSetsBySessionIDs(context.Background(), []uuid.UUID{sessionID})
+	require.NoError(t, err)
+	require.Len(t, sets, len(restTimes))
+	for _, s := range sets {
+		assert.Equal(t, int32(120), s.RestTime.Int32)
+	}
+
+	groups := GroupsFromDB(sets)
+	require.Len(t, groups, 1)
+	assert.Equal(t, GroupKindCircuit, groups[0].Kind)
+	assert.Equal(t, int32(120), groups[0].RestTime)
+	assert.Len(t, groups[0].SetIDs, len(restTimes))
+
+	// other users can not change the rest time of the group
+	other := testutil.CreateUserDBTestHelper(t, db, "otheruser", "passwordtest", false)
+	body, err := json.Marshal(SetReq{ExerciseID: exerciseID, SetOrder: int32(len(restTimes)), RestTime: 5, GroupKey: "A"})
+	require.NoError(t, err)
+	req, err := http.NewRequest("POST", "/test", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.SetPathValue("sessionID", sessionID.String())
+	req = req.WithContext(util.ContextWithUser(req.Context(), other.ID))
+	rr := httptest.NewRecorder()
+	handler := middleware.Chain(
+		HandlerCreateSet(dbPool, db, logger),
+		middleware.Ownership("sessionID", db.GetSessionOwnerID, logger),
+	)
+	middleware.RequestID(handler).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	sets, err = db.GetSetsBySessionIDs(context.Background(), []uuid.UUID{sessionID})
+	require.NoError(t, err)
+	require.Len(t, sets, len(restTimes))
+	for _, s := range sets {
+		assert.Equal(t, int32(120), s.RestTime.Int32)
+	}
+}
+
+func TestCreateSetLastPerformance(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "usertest", "passwordtest", false)
+	squatID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+	benchID := testutil.CreateExerciseDBTestHelper(t, db, "bench press")
+
+	// two earlier sessions with squats, the latest one is the reference
+	var lastSessionID uuid.UUID
+	for i, weight := range []float64{100, 110} {
+		session, err := db.CreateSession(context.Background(), database.CreateSessionParams{
+			Name:   "legs",
+			Date:   pgtype.Date{Time: time.Now().AddDate(0, 0, i-7), Valid: true},
+			UserID: user.ID,
+		})
+		require.NoError(t, err)
+		setID := testutil.CreateSetDBTestHelper(t, db, session.ID, squatID)
+		testutil.CreateLogExerciseDBTestHelper(t, db, 5, 1, squatID, setID, weight)
+		testutil.CreateLogExerciseDBTestHelper(t, db, 3, 2, squatID, setID, weight+10)
+		lastSessionID = session.ID
+	}
+	sessionID := testutil.CreateSessionDBTestHelper(t, db, "legs", user.ID)
+
+	testCases := []struct {
+		name         string
+		exerciseID   int32
+		query        string
+		expectLast   bool
+		expectedLogs []lastPerformanceLog
+	}{
+		{
+			name:       "happy path: logs of the latest earlier session",
+			exerciseID: squatID,
+			expectLast: true,
+			expectedLogs: []lastPerformanceLog{
+				{Weight: 110, Reps: 5},
+				{Weight: 120, Reps: 3},
+			},
+		},
+		{
+			name:       "happy path: weights in the requested units",
+			exerciseID: squatID,
+			query:      "?units=lb",
+			expectLast: true,
+			expectedLogs: []lastPerformanceLog{
+				{Weight: 242.508, Reps: 5},
+				{Weight: 264.555, Reps: 3},
+			},
+		},
+		{
+			name:       "exercise never performed",
+			exerciseID: benchID,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := json.Marshal(SetReq{ExerciseID: tc.exerciseID, SetOrder: int32(i)})
+			require.NoError(t, err)
+			req, err := http.NewRequest("POST", "/test"+tc.query, bytes.NewReader(body))
+			require.NoError(t, err)
+			req.SetPathValue("sessionID", sessionID.String())
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			rr := httptest.NewRecorder()
+			middleware.RequestID(HandlerCreateSet(dbPool, db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+			var resParams createSetRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			if !tc.expectLast {
+				assert.Nil(t, resParams.LastPerformance)
+				return
+			}
+			require.NotNil(t, resParams.LastPerformance)
+			assert.Equal(t, lastSessionID.String(), resParams.LastPerformance.SessionID)
+			assert.Equal(t, tc.expectedLogs, resParams.LastPerformance.Logs)
+		})
+	}
+
+	t.Run("session of another user", func(t *testing.T) {
+		other := testutil.CreateUserDBTestHelper(t, db, "otheruser", "passwordtest", false)
+		body, err := json.Marshal(SetReq{ExerciseID: squatID, SetOrder: int32(len(testCases))})
+		require.NoError(t, err)
+		req, err := http.NewRequest("POST", "/test", bytes.NewReader(body))
+		require.NoError(t, err)
+		req.SetPathValue("sessionID", sessionID.String())
+		req = req.WithContext(util.ContextWithUser(req.Context(), other.ID))
+		rr := httptest.NewRecorder()
+		handler := middleware.Chain(
+			HandlerCreateSet(dbPool, db, logger),
+			middleware.Ownership("sessionID", db.GetSessionOwnerID, logger),
+		)
+		middleware.RequestID(handler).ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "last_performance")
+	})
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/CTSDM/gogym/internal/api/exlog"
 	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/units"
 	"github.com/CTSDM/gogym/internal/api/util"
 	"github.com/CTSDM/gogym/internal/database"
 	"github.com/jackc/pgx/v5"
@@ -22,6 +23,11 @@ func HandlerGetSet(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
 		setID, _ := retrieveParseIDFromContext(r.Context())
 		reqLogger = reqLogger.With(slog.Int64("set_id", setID))
 
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "get set")
+		if !ok {
+			return
+		}
+
 		// fetch a set by set id
 		setDB, err := db.GetSet(r.Context(), setID)
 		if err == pgx.ErrNoRows {
@@ -42,29 +48,12 @@ func HandlerGetSet(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
 		}
 		logsResParams := make([]exlog.LogRes, len(logsDB))
 		for i, logDB := range logsDB {
-			logsResParams[i] = exlog.LogRes{
-				ID:    logDB.ID,
-				SetID: logDB.SetID,
-				LogReq: exlog.LogReq{
-					ExerciseID: logDB.ExerciseID,
-					Weight:     logDB.Weight.Float64,
-					Reps:       logDB.Reps,
-					Order:      logDB.LogsOrder,
-				},
-			}
+			logsResParams[i] = exlog.LogResFromDB(logDB, unit)
 		}
 
 		resParams := res{
-			SetRes: SetRes{
-				ID:        setDB.ID,
-				SessionID: setDB.SessionID.String(),
-				SetReq: SetReq{
-					ExerciseID: setDB.ExerciseID,
-					SetOrder:   setDB.SetOrder,
-					RestTime:   setDB.RestTime.Int32,
-				},
-			},
-			Logs: logsResParams,
+			SetRes: SetResFromDB(setDB),
+			Logs:   logsResParams,
 		}
 
 		util.RespondWithJSON(w, r, http.StatusOK, resParams)
@@ -0,0 +1,43 @@
+package set
+
+import "github.com/CTSDM/gogym/internal/database"
+
+const (
+	GroupKindSuperset = "superset"
+	GroupKindCircuit  = "circuit"
+)
+
+type GroupRes struct {
+	Key      string  `json:"key"`
+	Kind     string  `json:"kind"` // superset for two sets, circuit for three or more
+	RestTime int32   `json:"rest_time"`
+	SetIDs   []int64 `json:"set_ids"`
+}
+
+// GroupsFromDB summarizes the grouped sets of a single session keeping the order of the first set of each group.
+// Sets without a group key are performed on their own and are not part of any group.
+func GroupsFromDB(sets []database.Set) []GroupRes {
+	groups := []GroupRes{}
+	indexByKey := make(map[string]int)
+	for _, s := range sets {
+		if !s.GroupKey.Valid {
+			continue
+		}
+		i, ok := indexByKey[s.GroupKey.String]
+		if !ok {
+			i = len(groups)
+			indexByKey[s.GroupKey.String] = i
+			groups = append(groups, GroupRes{Key: s.GroupKey.String, RestTime: s.RestTime.Int32})
+		}
+		groups[i].SetIDs = append(groups[i].SetIDs, s.ID)
+	}
+
+	for i := range groups {
+		groups[i].Kind = GroupKindSuperset
+		if len(groups[i].SetIDs) > 2 {
+			groups[i].Kind = GroupKindCircuit
+		}
+	}
+
+	return groups
+}
@@ -0,0 +1,64 @@
+package set
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+)
+
+// LastPerformanceRes is what the user did with the exercise the previous time,
+// a reference while logging the new set
+type LastPerformanceRes struct {
+	SessionID string               `json:"session_id"`
+	Date      string               `json:"date"`
+	Unit      string               `json:"unit"`
+	Logs      []lastPerformanceLog `json:"logs"`
+}
+
+type lastPerformanceLog struct {
+	Weight float64  `json:"weight"`
+	Reps   int32    `json:"reps"`
+	RPE    *float64 `json:"rpe,omitempty"`
+	RIR    *int32   `json:"rir,omitempty"`
+}
+
+// lastPerformance returns the logs of the exercise in the latest earlier session of the user,
+// nil when the exercise was never logged before
+func lastPerformance(ctx context.Context, db *database.Queries, sessionID uuid.UUID, exerciseID int32, unit string) (*LastPerformanceRes, error) {
+	rows, err := db.GetLastPerformance(ctx, database.GetLastPerformanceParams{
+		SessionID:  sessionID,
+		ExerciseID: exerciseID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get last performance: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	res := &LastPerformanceRes{
+		SessionID: rows[0].SessionID.String(),
+		Date:      rows[0].Date.Time.Format(apiconstants.DATE_LAYOUT),
+		Unit:      unit,
+		Logs:      make([]lastPerformanceLog, len(rows)),
+	}
+	for i, row := range rows {
+		log := lastPerformanceLog{
+			Weight: units.FromKG(row.Weight.Float64, unit),
+			Reps:   row.Reps,
+		}
+		if row.Rpe.Valid {
+			log.RPE = &row.Rpe.Float64
+		}
+		if row.Rir.Valid {
+			rir := int32(row.Rir.Int16)
+			log.RIR = &rir
+		}
+		res.Logs[i] = log
+	}
+	return res, nil
+}
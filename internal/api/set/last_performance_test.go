@@ -0,0 +1,89 @@
+package set
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastPerformanceSameDay(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "usertest", "passwordtest", false)
+	squatID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	createSession := func(date time.Time, start pgtype.Timestamp) uuid.UUID {
+		session, err := db.CreateSession(context.Background(), database.CreateSessionParams{
+			Name:           "legs",
+			Date:           pgtype.Date{Time: date, Valid: true},
+			StartTimestamp: start,
+			UserID:         user.ID,
+		})
+		require.NoError(t, err)
+		return session.ID
+	}
+	morning := pgtype.Timestamp{Time: today.Add(8 * time.Hour), Valid: true}
+	evening := pgtype.Timestamp{Time: today.Add(18 * time.Hour), Valid: true}
+
+	lastWeekID := createSession(today.AddDate(0, 0, -7), pgtype.Timestamp{})
+	setID := testutil.CreateSetDBTestHelper(t, db, lastWeekID, squatID)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 5, 1, squatID, setID, 90)
+
+	// the morning session starts with a warm-up set
+	morningID := createSession(today, morning)
+	warmUp, err := db.CreateSet(context.Background(), database.CreateSetParams{
+		SetOrder:   1,
+		SessionID:  morningID,
+		ExerciseID: squatID,
+		SetType:    "warm_up",
+	})
+	require.NoError(t, err)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 10, 1, squatID, warmUp.ID, 60)
+	setID = testutil.CreateSetDBTestHelper(t, db, morningID, squatID)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 5, 1, squatID, setID, 100)
+
+	eveningID := createSession(today, evening)
+	setID = testutil.CreateSetDBTestHelper(t, db, eveningID, squatID)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 5, 1, squatID, setID, 150)
+
+	testCases := []struct {
+		name         string
+		sessionID    uuid.UUID
+		expectedID   uuid.UUID
+		expectedLogs []lastPerformanceLog
+	}{
+		{
+			name:         "a later session of the same day is not the last time",
+			sessionID:    morningID,
+			expectedID:   lastWeekID,
+			expectedLogs: []lastPerformanceLog{{Weight: 90, Reps: 5}},
+		},
+		{
+			name:         "an earlier session of the same day without its warm-up sets",
+			sessionID:    eveningID,
+			expectedID:   morningID,
+			expectedLogs: []lastPerformanceLog{{Weight: 100, Reps: 5}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := lastPerformance(context.Background(), db, tc.sessionID, squatID, units.KG)
+			require.NoError(t, err)
+			require.NotNil(t, res)
+			assert.Equal(t, tc.expectedID.String(), res.SessionID)
+			assert.Equal(t, tc.expectedLogs, res.Logs)
+		})
+	}
+}
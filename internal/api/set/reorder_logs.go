@@ -0,0 +1,107 @@
+package set
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/CTSDM/gogym/internal/api/exlog"
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/api/validation"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type reorderLogsReq struct {
+	IDs []int64 `json:"ids"` // every log of the set, in the new order
+}
+
+func (r *reorderLogsReq) Valid(ctx context.Context) map[string]string {
+	problems := make(map[string]string)
+	if len(r.IDs) == 0 {
+		problems["ids"] = "invalid ids: ids cannot be empty"
+	}
+	return problems
+}
+
+func HandlerReorderLogs(pool *pgxpool.Pool, db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		setID, _ := retrieveParseIDFromContext(r.Context())
+		reqLogger = reqLogger.With(slog.Int64("set_id", setID))
+
+		reqParams, problems, err := validation.DecodeValid[*reorderLogsReq](r)
+		if len(problems) > 0 {
+			reqLogger.Debug("reorder logs failed - validation errors", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		} else if err != nil {
+			reqLogger.Debug("reorder logs failed - invalid payload", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusBadRequest, "invalid payload", err)
+			return
+		}
+
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "reorder logs")
+		if !ok {
+			return
+		}
+
+		tx, err := pool.Begin(r.Context())
+		if err != nil {
+			reqLogger.Error("reorder logs failed - transaction start error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		txQueries := db.WithTx(tx)
+		defer tx.Rollback(r.Context())
+
+		// Lock the logs of the set so concurrent requests cannot interleave with the new order
+		currentIDs, err := txQueries.GetLogIDsBySetIDForUpdate(r.Context(), setID)
+		if err != nil {
+			reqLogger.Error("reorder logs failed - get logs database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		if err := validation.Order(reqParams.IDs, currentIDs); err != nil {
+			reqLogger.Debug("reorder logs failed - incomplete order", slog.String("error", err.Error()))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, map[string]string{
+				"ids": "invalid ids: " + err.Error(),
+			})
+			return
+		}
+
+		if err := txQueries.ReorderLogs(r.Context(), database.ReorderLogsParams{
+			Ids:   reqParams.IDs,
+			SetID: setID,
+		}); err != nil {
+			reqLogger.Error("reorder logs failed - update order database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		logs, err := txQueries.GetLogsBySetID(r.Context(), setID)
+		if err != nil {
+			reqLogger.Error("reorder logs failed - get logs database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		if err := tx.Commit(r.Context()); err != nil {
+			reqLogger.Error("reorder logs failed - transaction commit error", slog.String("error", err.Error()))
+			err = fmt.Errorf("could not commit the transaction: %w", err)
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		resParams := make([]exlog.LogRes, len(logs))
+		for i, l := range logs {
+			resParams[i] = exlog.LogResFromDB(l, unit)
+		}
+
+		reqLogger.Info("reorder logs success")
+		util.RespondWithJSON(w, r, http.StatusOK, resParams)
+	}
+}
@@ -0,0 +1,93 @@
+package set
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CTSDM/gogym/internal/api/exlog"
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerReorderLogs(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	exerciseID := testutil.CreateExerciseDBTestHelper(t, db, "bench press")
+	sessionID := testutil.CreateSessionDBTestHelper(t, db, "push day", user.ID)
+	setID := testutil.CreateSetDBTestHelper(t, db, sessionID, exerciseID)
+	otherSetID := testutil.CreateSetDBTestHelper(t, db, sessionID, exerciseID)
+	first := testutil.CreateLogExerciseDBTestHelper(t, db, 10, 1, exerciseID, setID, 60)
+	second := testutil.CreateLogExerciseDBTestHelper(t, db, 8, 2, exerciseID, setID, 70)
+	foreign := testutil.CreateLogExerciseDBTestHelper(t, db, 8, 1, exerciseID, otherSetID, 70)
+
+	testCases := []struct {
+		name          string
+		ids           []int64
+		statusCode    int
+		expectedOrder []int64
+	}{
+		{
+			name:          "happy path: swap logs",
+			ids:           []int64{second, first},
+			statusCode:    http.StatusOK,
+			expectedOrder: []int64{second, first},
+		},
+		{
+			name:       "missing log",
+			ids:        []int64{first},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "log from another set",
+			ids:        []int64{first, foreign},
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := json.Marshal(reorderLogsReq{IDs: tc.ids})
+			require.NoError(t, err)
+			req, err := http.NewRequest("PUT", "/test", bytes.NewReader(body))
+			require.NoError(t, err, "unexpected error while creating the request")
+			ctx := util.ContextWithUser(req.Context(), user.ID)
+			ctx = util.ContextWithResourceID(ctx, setID)
+			req = req.WithContext(ctx)
+			rr := httptest.NewRecorder()
+
+			handler := HandlerReorderLogs(dbPool, db, logger)
+			middleware.RequestID(handler).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				assert.Contains(t, rr.Body.String(), "invalid ids")
+				return
+			}
+
+			var resParams []exlog.LogRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			require.Len(t, resParams, len(tc.expectedOrder))
+			for i, l := range resParams {
+				assert.Equal(t, tc.expectedOrder[i], l.ID)
+				assert.Equal(t, int32(i+1), l.Order)
+			}
+
+			logs, err := db.GetLogsBySetID(context.Background(), setID)
+			require.NoError(t, err)
+			for i, l := range logs {
+				assert.Equal(t, tc.expectedOrder[i], l.ID)
+			}
+		})
+	}
+}
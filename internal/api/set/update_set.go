@@ -1,6 +1,7 @@
 package set
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"github.com/CTSDM/gogym/internal/api/validation"
 	"github.com/CTSDM/gogym/internal/database"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -75,17 +77,42 @@ func HandlerUpdateSet(pool *pgxpool.Pool, db *database.Queries, logger *slog.Log
 			}
 		}
 		// Update the set information
-		if _, err := txQueries.UpdateSet(r.Context(), database.UpdateSetParams{
+		updatedSet, err := txQueries.UpdateSet(r.Context(), database.UpdateSetParams{
 			SetOrder:   reqParams.SetOrder,
 			RestTime:   pgtype.Int4{Int32: reqParams.RestTime, Valid: true},
 			ExerciseID: reqParams.ExerciseID,
+			SetType:    reqParams.SetType,
+			GroupKey:   reqParams.groupKey(),
 			ID:         setID,
-		}); err != nil {
+		})
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				reqLogger.Debug("update set failed - set order already in use", slog.Int64("set_order", int64(reqParams.SetOrder)))
+				util.RespondWithError(w, r, http.StatusConflict, "set_order already in use in the session", err)
+				return
+			}
 			reqLogger.Error("update set failed - update set database error", slog.String("error", err.Error()))
 			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
 			return
 		}
 
+		// Sets of the same group share the rest time
+		if updatedSet.GroupKey.Valid {
+			if err := txQueries.UpdateSetsRestTimeByGroup(r.Context(), database.UpdateSetsRestTimeByGroupParams{
+				RestTime:  updatedSet.RestTime,
+				SessionID: updatedSet.SessionID,
+				GroupKey:  updatedSet.GroupKey,
+			}); err != nil {
+				reqLogger.Error(
+					"update set failed - update group rest time database error",
+					slog.String("error", err.Error()),
+				)
+				util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+				return
+			}
+		}
+
 		if err := tx.Commit(r.Context()); err != nil {
 			reqLogger.Error("update set failed - transaction commit error", slog.String("error", err.Error()))
 			err = fmt.Errorf("could not commit the transaction: %w", err)
@@ -95,7 +95,7 @@ func TestHandlerUpdateSet(t *testing.T) {
 
 			if tc.createLogs {
 				for i := range 3 {
-					testutil.CreateLogExerciseDBTestHelper(t, db, int32(i+1), int32(10), exerciseID, setID, float64(100))
+					testutil.CreateLogExerciseDBTestHelper(t, db, int32(i+1), int32(i+1), exerciseID, setID, float64(100))
 				}
 			}
 
@@ -0,0 +1,55 @@
+package signedtoken
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Encode returns an opaque token: the base64 JSON payload followed by its HMAC signature.
+// The kind of token is signed with the payload so a token of one kind is never accepted as another.
+func Encode(kind string, payload any, secret string) (string, error) {
+	if secret == "" {
+		return "", fmt.Errorf("%s secret cannot be empty", kind)
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal the %s: %w", kind, err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payloadBytes)
+	signature := base64.RawURLEncoding.EncodeToString(sign(kind, encoded, secret))
+	return encoded + "." + signature, nil
+}
+
+// Decode checks the signature of the token and unmarshals its payload into v
+func Decode(kind, token, secret string, v any) error {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrInvalidToken
+	}
+	signatureBytes, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(signatureBytes, sign(kind, encoded, secret)) {
+		return ErrInvalidToken
+	}
+
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	if err := json.Unmarshal(payloadBytes, v); err != nil {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+func sign(kind, payload, secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(kind + ":" + payload))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,43 @@
+package signedtoken
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type payload struct {
+	Value int `json:"v"`
+}
+
+func TestSignedToken(t *testing.T) {
+	token, err := Encode("cursor", payload{Value: 42}, "secret")
+	require.NoError(t, err)
+
+	var decoded payload
+	require.NoError(t, Decode("cursor", token, "secret", &decoded))
+	assert.Equal(t, 42, decoded.Value)
+
+	_, err = Encode("cursor", payload{Value: 42}, "")
+	assert.Error(t, err)
+
+	testCases := []struct {
+		name   string
+		kind   string
+		token  string
+		secret string
+	}{
+		{name: "another kind", kind: "sync", token: token, secret: "secret"},
+		{name: "another secret", kind: "cursor", token: token, secret: "other"},
+		{name: "missing signature", kind: "cursor", token: "eyJ2Ijo0Mn0", secret: "secret"},
+		{name: "garbage", kind: "cursor", token: "not.a-token", secret: "secret"},
+		{name: "empty", kind: "cursor", token: "", secret: "secret"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var decoded payload
+			assert.ErrorIs(t, Decode(tc.kind, tc.token, tc.secret, &decoded), ErrInvalidToken)
+		})
+	}
+}
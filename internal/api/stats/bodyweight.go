@@ -0,0 +1,34 @@
+package stats
+
+import (
+	"slices"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/database"
+)
+
+// nearestBodyweight returns the bodyweight reading closest to date, the earlier one on ties.
+// Readings must be sorted by date.
+func nearestBodyweight(readings []database.GetBodyweightsRow, date time.Time) (float64, bool) {
+	if len(readings) == 0 {
+		return 0, false
+	}
+	i, found := slices.BinarySearchFunc(readings, date, func(r database.GetBodyweightsRow, t time.Time) int {
+		return r.Date.Time.Compare(t)
+	})
+	switch {
+	case found || i == 0:
+		return readings[i].Bodyweight, true
+	case i == len(readings):
+		return readings[i-1].Bodyweight, true
+	case date.Sub(readings[i-1].Date.Time) <= readings[i].Date.Time.Sub(date):
+		return readings[i-1].Bodyweight, true
+	default:
+		return readings[i].Bodyweight, true
+	}
+}
+
+// effectiveLoad is the load lifted in a bodyweight exercise, the added weight plus the share of the bodyweight moved
+func effectiveLoad(weight, ratio, bodyweight float64) float64 {
+	return weight + ratio*bodyweight
+}
@@ -0,0 +1,48 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNearestBodyweight(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, time.March, d, 0, 0, 0, 0, time.UTC) }
+	readings := []database.GetBodyweightsRow{
+		{Date: pgtype.Date{Time: day(5), Valid: true}, Bodyweight: 80},
+		{Date: pgtype.Date{Time: day(15), Valid: true}, Bodyweight: 82},
+	}
+
+	testCases := []struct {
+		name     string
+		readings []database.GetBodyweightsRow
+		date     time.Time
+		expected float64
+		ok       bool
+	}{
+		{name: "no readings", date: day(5)},
+		{name: "same day", readings: readings, date: day(15), expected: 82, ok: true},
+		{name: "before the first reading", readings: readings, date: day(1), expected: 80, ok: true},
+		{name: "after the last reading", readings: readings, date: day(30), expected: 82, ok: true},
+		{name: "closer to the later reading", readings: readings, date: day(12), expected: 82, ok: true},
+		{name: "closer to the earlier reading", readings: readings, date: day(8), expected: 80, ok: true},
+		{name: "ties go to the earlier reading", readings: readings, date: day(10), expected: 80, ok: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bodyweight, ok := nearestBodyweight(tc.readings, tc.date)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, bodyweight)
+		})
+	}
+}
+
+func TestEffectiveLoad(t *testing.T) {
+	assert.Equal(t, 80.0, effectiveLoad(0, 1, 80))
+	assert.Equal(t, 100.0, effectiveLoad(20, 1, 80))
+	assert.InDelta(t, 51.2, effectiveLoad(0, 0.64, 80), 0.001)
+}
@@ -0,0 +1,176 @@
+package stats
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+)
+
+// weeks averaged by sessions per week, the current one included
+const frequencyWeeks = 12
+
+const minHeatmapYear = 1970
+
+type streak struct {
+	Current int `json:"current"` // still alive when the last training was in the current or previous day (week)
+	Longest int `json:"longest"`
+}
+
+type weekdayItem struct {
+	Weekday  string `json:"weekday"`
+	Sessions int64  `json:"sessions"`
+}
+
+type heatmapDay struct {
+	Date     string `json:"date"`
+	Sessions int64  `json:"sessions"`
+}
+
+type heatmap struct {
+	Year int          `json:"year"`
+	Days []heatmapDay `json:"days"` // every day of the year
+}
+
+type consistencyRes struct {
+	Timezone        string        `json:"timezone"`
+	Today           string        `json:"today"`
+	TotalSessions   int64         `json:"total_sessions"`
+	DailyStreak     streak        `json:"daily_streak"`
+	WeeklyStreak    streak        `json:"weekly_streak"` // weeks start on Monday
+	SessionsPerWeek float64       `json:"sessions_per_week"`
+	Weekdays        []weekdayItem `json:"weekdays"` // Monday first
+	Heatmap         heatmap       `json:"heatmap"`
+}
+
+// HandlerGetConsistency returns the training streaks and frequency of the user
+// and a heatmap of the sessions of a year, today being the current day in the timezone of the user.
+func HandlerGetConsistency(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("get consistency failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		timezone, err := db.GetUserTimezone(r.Context(), userID)
+		if err != nil {
+			reqLogger.Error("get consistency failed - get timezone database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			reqLogger.Warn("unknown timezone, using UTC", slog.String("timezone", timezone))
+			timezone, location = "UTC", time.UTC
+		}
+		now := time.Now().In(location)
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+		year := today.Year()
+		if query := r.URL.Query(); query.Has("year") {
+			parsed, err := strconv.Atoi(query.Get("year"))
+			if err != nil || parsed < minHeatmapYear || parsed > today.Year()+1 {
+				reqLogger.Debug("get consistency failed - invalid year", slog.String("year", query.Get("year")))
+				util.RespondWithJSON(w, r, http.StatusBadRequest, map[string]string{
+					"year": fmt.Sprintf("invalid year: year must be between %d and %d", minHeatmapYear, today.Year()+1),
+				})
+				return
+			}
+			year = parsed
+		}
+
+		rows, err := db.GetSessionCountsByDate(r.Context(), userID)
+		if err != nil {
+			reqLogger.Error("get consistency failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		counts := make(map[time.Time]int64, len(rows))
+		for _, row := range rows {
+			counts[row.Date.Time] = row.Sessions
+		}
+
+		resParams := consistency(counts, today, year)
+		resParams.Timezone = timezone
+		util.RespondWithJSON(w, r, http.StatusOK, resParams)
+	}
+}
+
+// consistency computes the stats from the number of sessions by date.
+// Dates are days at midnight UTC, sessions planned after today do not count for the streaks.
+func consistency(counts map[time.Time]int64, today time.Time, year int) consistencyRes {
+	res := consistencyRes{
+		Today:    today.Format(apiconstants.DATE_LAYOUT),
+		Weekdays: make([]weekdayItem, 7),
+		Heatmap:  heatmap{Year: year, Days: []heatmapDay{}},
+	}
+	for i := range res.Weekdays {
+		res.Weekdays[i].Weekday = time.Weekday((i + 1) % 7).String()
+	}
+
+	days := []time.Time{}
+	trainedWeeks := map[time.Time]bool{}
+	frequencyFrom := weekStart(today).AddDate(0, 0, -7*(frequencyWeeks-1))
+	frequencySessions := int64(0)
+	for date, sessions := range counts {
+		res.TotalSessions += sessions
+		res.Weekdays[(int(date.Weekday())+6)%7].Sessions += sessions
+		if date.After(today) {
+			continue
+		}
+		days = append(days, date)
+		trainedWeeks[weekStart(date)] = true
+		if !date.Before(frequencyFrom) {
+			frequencySessions += sessions
+		}
+	}
+	res.SessionsPerWeek = round(float64(frequencySessions) / frequencyWeeks)
+	res.DailyStreak = streaks(days, today, 1)
+	res.WeeklyStreak = streaks(slices.Collect(maps.Keys(trainedWeeks)), weekStart(today), 7)
+
+	for day := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC); day.Year() == year; day = day.AddDate(0, 0, 1) {
+		res.Heatmap.Days = append(res.Heatmap.Days, heatmapDay{
+			Date:     day.Format(apiconstants.DATE_LAYOUT),
+			Sessions: counts[day],
+		})
+	}
+
+	return res
+}
+
+// streaks finds the runs of consecutive periods of step days.
+// The current run must reach the last period or the one before.
+func streaks(periods []time.Time, last time.Time, step int) streak {
+	slices.SortFunc(periods, func(a, b time.Time) int { return a.Compare(b) })
+	res := streak{}
+	run := 0
+	for i, p := range periods {
+		if i > 0 && periods[i-1].AddDate(0, 0, step).Equal(p) {
+			run++
+		} else {
+			run = 1
+		}
+		res.Longest = max(res.Longest, run)
+	}
+	if len(periods) > 0 && !periods[len(periods)-1].Before(last.AddDate(0, 0, -step)) {
+		res.Current = run
+	}
+	return res
+}
+
+// weekStart returns the Monday of the week of date
+func weekStart(date time.Time) time.Time {
+	return date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+}
@@ -0,0 +1,156 @@
+package stats
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsistency(t *testing.T) {
+	date := func(value string) time.Time {
+		parsed, err := time.Parse("2006-01-02", value)
+		require.NoError(t, err)
+		return parsed
+	}
+	// a Wednesday
+	today := date("2025-03-12")
+
+	t.Run("streaks, frequency and heatmap", func(t *testing.T) {
+		counts := map[time.Time]int64{
+			date("2025-02-01"): 1,
+			date("2025-02-02"): 1,
+			date("2025-02-03"): 1,
+			date("2025-02-04"): 1,
+			date("2025-03-10"): 1,
+			date("2025-03-11"): 1,
+			date("2025-03-12"): 1,
+			date("2025-03-20"): 1, // planned, not part of the streaks
+		}
+		res := consistency(counts, today, 2025)
+
+		assert.Equal(t, "2025-03-12", res.Today)
+		assert.Equal(t, int64(8), res.TotalSessions)
+		assert.Equal(t, streak{Current: 3, Longest: 4}, res.DailyStreak)
+		assert.Equal(t, streak{Current: 1, Longest: 2}, res.WeeklyStreak)
+		assert.Equal(t, 0.583, res.SessionsPerWeek)
+		assert.Equal(t, []weekdayItem{
+			{Weekday: "Monday", Sessions: 2},
+			{Weekday: "Tuesday", Sessions: 2},
+			{Weekday: "Wednesday", Sessions: 1},
+			{Weekday: "Thursday", Sessions: 1},
+			{Weekday: "Friday", Sessions: 0},
+			{Weekday: "Saturday", Sessions: 1},
+			{Weekday: "Sunday", Sessions: 1},
+		}, res.Weekdays)
+		require.Len(t, res.Heatmap.Days, 365)
+		assert.Equal(t, heatmapDay{Date: "2025-01-01", Sessions: 0}, res.Heatmap.Days[0])
+		assert.Equal(t, heatmapDay{Date: "2025-03-10", Sessions: 1}, res.Heatmap.Days[68])
+	})
+
+	t.Run("streaks survive until the end of the next day or week", func(t *testing.T) {
+		counts := map[time.Time]int64{
+			date("2025-03-04"): 1,
+			date("2025-03-11"): 2,
+		}
+		res := consistency(counts, today, 2024)
+
+		assert.Equal(t, streak{Current: 1, Longest: 1}, res.DailyStreak)
+		assert.Equal(t, streak{Current: 2, Longest: 2}, res.WeeklyStreak)
+		assert.Equal(t, 0.25, res.SessionsPerWeek)
+		// 2024 is a leap year
+		assert.Len(t, res.Heatmap.Days, 366)
+	})
+
+	t.Run("broken streaks", func(t *testing.T) {
+		counts := map[time.Time]int64{
+			date("2025-02-24"): 1,
+			date("2025-02-25"): 1,
+		}
+		res := consistency(counts, today, 2025)
+
+		assert.Equal(t, streak{Current: 0, Longest: 2}, res.DailyStreak)
+		assert.Equal(t, streak{Current: 0, Longest: 1}, res.WeeklyStreak)
+	})
+
+	t.Run("no sessions", func(t *testing.T) {
+		res := consistency(map[time.Time]int64{}, today, 2025)
+
+		assert.Equal(t, streak{}, res.DailyStreak)
+		assert.Equal(t, streak{}, res.WeeklyStreak)
+		assert.Equal(t, 0.0, res.SessionsPerWeek)
+	})
+}
+
+func TestHandlerGetConsistency(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	_, err := db.UpdateUserPreferences(context.Background(), database.UpdateUserPreferencesParams{
+		Timezone: pgtype.Text{String: "Pacific/Kiritimati", Valid: true},
+		ID:       user.ID,
+	})
+	require.NoError(t, err)
+	createSessionOnDateTestHelper(t, db, user.ID, "2024-06-03")
+	createSessionOnDateTestHelper(t, db, user.ID, "2024-06-04")
+
+	testCases := []struct {
+		name       string
+		query      string
+		statusCode int
+	}{
+		{
+			name:       "happy path",
+			query:      "?year=2024",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "invalid year",
+			query:      "?year=twenty",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "year too far in the future",
+			query:      "?year=9999",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/test"+tc.query, bytes.NewReader(nil))
+			require.NoError(t, err)
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerGetConsistency(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				assert.Contains(t, rr.Body.String(), "invalid year")
+				return
+			}
+
+			var resParams consistencyRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			// the day in the timezone of the user, up to 14 hours ahead of UTC
+			kiritimati := time.FixedZone("LINT", 14*60*60)
+			assert.Equal(t, "Pacific/Kiritimati", resParams.Timezone)
+			assert.Equal(t, time.Now().In(kiritimati).Format("2006-01-02"), resParams.Today)
+			assert.Equal(t, int64(2), resParams.TotalSessions)
+			assert.Equal(t, 2, resParams.DailyStreak.Longest)
+			assert.Len(t, resParams.Heatmap.Days, 366)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package stats
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// default length of the series in days
+const defaultE1RMDays = 365
+
+type e1rmPoint struct {
+	Date   string  `json:"date"`
+	E1RM   float64 `json:"e1rm"`
+	Weight float64 `json:"weight"`
+	Reps   int32   `json:"reps"`
+	LogID  int64   `json:"log_id"` // log the best estimation of the day comes from
+	// added weight plus the moved share of the bodyweight, only for bodyweight exercises
+	EffectiveLoad *float64 `json:"effective_load,omitempty"`
+	Bodyweight    *float64 `json:"bodyweight,omitempty"`    // reading nearest to the date
+	RelativeE1RM  *float64 `json:"relative_e1rm,omitempty"` // e1RM divided by the bodyweight
+}
+
+type e1rmRes struct {
+	ExerciseID int32       `json:"exercise_id"`
+	Formula    string      `json:"formula"`
+	From       string      `json:"from"`
+	To         string      `json:"to"`
+	Unit       string      `json:"unit"`
+	Series     []e1rmPoint `json:"series"`
+}
+
+// HandlerGetE1RM returns the best estimated one rep max of every training day of an exercise.
+// Warm-up sets are not taken into account. The estimations of bodyweight exercises are computed
+// on the effective load, and every point has the relative strength when the bodyweight is known.
+func HandlerGetE1RM(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("get e1rm failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		// validate the query parameters
+		problems := map[string]string{}
+		query := r.URL.Query()
+		exerciseID, err := strconv.ParseInt(query.Get("exercise_id"), 10, 32)
+		if err != nil {
+			problems["exercise_id"] = "invalid exercise_id: exercise_id is required and must be a number"
+		}
+		formula := FormulaEpley
+		if query.Has("formula") {
+			formula = strings.ToLower(query.Get("formula"))
+			if !validFormula(formula) {
+				problems["formula"] = "invalid formula: formula must be one of " + strings.Join(Formulas, ", ")
+			}
+		}
+		from, to := dateRange(query, problems, defaultE1RMDays)
+		if len(problems) > 0 {
+			reqLogger.Debug("get e1rm failed - validation failed", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		}
+		reqLogger = reqLogger.With(slog.Int64("exercise_id", exerciseID))
+
+		exercise, err := db.GetExercise(r.Context(), int32(exerciseID))
+		if err == pgx.ErrNoRows {
+			reqLogger.Debug("get e1rm failed - exercise not in database")
+			util.RespondWithError(w, r, http.StatusNotFound, "exercise id not found", err)
+			return
+		} else if err != nil {
+			reqLogger.Error("get e1rm failed - get exercise database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "get e1rm")
+		if !ok {
+			return
+		}
+
+		logs, err := db.GetExerciseLogsByDate(r.Context(), database.GetExerciseLogsByDateParams{
+			UserID:     userID,
+			ExerciseID: int32(exerciseID),
+			FromDate:   pgtype.Date{Time: from, Valid: true},
+			ToDate:     pgtype.Date{Time: to, Valid: true},
+		})
+		if err != nil {
+			reqLogger.Error("get e1rm failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+		bodyweights, err := db.GetBodyweights(r.Context(), userID)
+		if err != nil {
+			reqLogger.Error("get e1rm failed - get bodyweights database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		resParams := e1rmRes{
+			ExerciseID: int32(exerciseID),
+			Formula:    formula,
+			From:       from.Format(apiconstants.DATE_LAYOUT),
+			To:         to.Format(apiconstants.DATE_LAYOUT),
+			Unit:       unit,
+			Series:     []e1rmPoint{},
+		}
+		for _, point := range bestDailyE1RM(logs, formula, exercise.BodyweightRatio, bodyweights) {
+			point.E1RM = units.FromKG(point.E1RM, unit)
+			point.Weight = units.FromKG(point.Weight, unit)
+			for _, weight := range []*float64{point.EffectiveLoad, point.Bodyweight} {
+				if weight != nil {
+					*weight = units.FromKG(*weight, unit)
+				}
+			}
+			resParams.Series = append(resParams.Series, point)
+		}
+		util.RespondWithJSON(w, r, http.StatusOK, resParams)
+	}
+}
+
+// bestDailyE1RM keeps the best estimation of every day, logs and bodyweights must be sorted by date.
+// Bodyweight exercises, the ones with a bodyweight ratio, are estimated on their effective load.
+func bestDailyE1RM(
+	logs []database.GetExerciseLogsByDateRow,
+	formula string,
+	ratio pgtype.Float8,
+	bodyweights []database.GetBodyweightsRow,
+) []e1rmPoint {
+	series := []e1rmPoint{}
+	for _, l := range logs {
+		// the RPE is derived from the reps in reserve when it was not logged
+		rpe := l.Rpe.Float64
+		if !l.Rpe.Valid && l.Rir.Valid {
+			rpe = float64(10 - l.Rir.Int16)
+		}
+		bodyweight, hasBodyweight := nearestBodyweight(bodyweights, l.Date.Time)
+		load := l.Weight.Float64
+		if ratio.Valid && hasBodyweight {
+			load = effectiveLoad(load, ratio.Float64, bodyweight)
+		}
+		e1rm, ok := OneRepMax(formula, load, l.Reps, rpe)
+		if !ok {
+			continue
+		}
+
+		point := e1rmPoint{
+			Date:   l.Date.Time.Format(apiconstants.DATE_LAYOUT),
+			E1RM:   e1rm,
+			Weight: l.Weight.Float64,
+			Reps:   l.Reps,
+			LogID:  l.ID,
+		}
+		if ratio.Valid && hasBodyweight {
+			point.EffectiveLoad = &load
+		}
+		if hasBodyweight {
+			relative := math.Round(e1rm/bodyweight*100) / 100
+			point.Bodyweight = &bodyweight
+			point.RelativeE1RM = &relative
+		}
+		if last := len(series) - 1; last >= 0 && series[last].Date == point.Date {
+			if point.E1RM > series[last].E1RM {
+				series[last] = point
+			}
+			continue
+		}
+		series = append(series, point)
+	}
+	return series
+}
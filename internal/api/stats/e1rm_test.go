@@ -0,0 +1,168 @@
+package stats
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createSessionOnDateTestHelper creates a session of the user on date
+func createSessionOnDateTestHelper(t *testing.T, db *database.Queries, userID uuid.UUID, date string) uuid.UUID {
+	parsed, err := time.Parse("2006-01-02", date)
+	require.NoError(t, err)
+	session, err := db.CreateSession(context.Background(), database.CreateSessionParams{
+		Name:   "session " + date,
+		Date:   pgtype.Date{Time: parsed, Valid: true},
+		UserID: userID,
+	})
+	require.NoError(t, err)
+	return session.ID
+}
+
+func TestHandlerGetE1RM(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	squatID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+
+	// two logs on the first day, the best one is kept
+	firstDay := createSessionOnDateTestHelper(t, db, user.ID, "2025-03-01")
+	setID := testutil.CreateSetDBTestHelper(t, db, firstDay, squatID)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 5, 1, squatID, setID, 100)
+	bestLogID := testutil.CreateLogExerciseDBTestHelper(t, db, 3, 2, squatID, setID, 120)
+	secondDay := createSessionOnDateTestHelper(t, db, user.ID, "2025-03-08")
+	setID = testutil.CreateSetDBTestHelper(t, db, secondDay, squatID)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 1, 1, squatID, setID, 130)
+	// warm-ups are ignored
+	warmUp, err := db.CreateSet(context.Background(), database.CreateSetParams{
+		SetOrder:   2,
+		SessionID:  secondDay,
+		ExerciseID: squatID,
+		SetType:    "warm_up",
+	})
+	require.NoError(t, err)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 1, 1, squatID, warmUp.ID, 200)
+	// the estimation of bodyweight exercises is computed on the added weight plus the bodyweight
+	pullUp, err := db.CreateExercise(context.Background(), database.CreateExerciseParams{
+		Name:            "pull up",
+		Description:     pgtype.Text{String: "", Valid: true},
+		BodyweightRatio: pgtype.Float8{Float64: 1, Valid: true},
+	})
+	require.NoError(t, err)
+	setID = testutil.CreateSetDBTestHelper(t, db, secondDay, pullUp.ID)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 3, 1, pullUp.ID, setID, 20)
+	_, err = db.CreateBodyMeasurement(context.Background(), database.CreateBodyMeasurementParams{
+		UserID:     user.ID,
+		Date:       pgtype.Date{Time: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		Bodyweight: pgtype.Float8{Float64: 80, Valid: true},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		query      string
+		statusCode int
+		expected   []e1rmPoint
+		errKeys    []string
+	}{
+		{
+			name:       "happy path",
+			query:      "?exercise_id=" + strconv.Itoa(int(squatID)) + "&from=2025-01-01&to=2025-12-31",
+			statusCode: http.StatusOK,
+			expected: []e1rmPoint{
+				{Date: "2025-03-01", E1RM: 132, Weight: 120, Reps: 3, LogID: bestLogID},
+				{Date: "2025-03-08", E1RM: 130, Weight: 130, Reps: 1},
+			},
+		},
+		{
+			name:       "happy path: date range",
+			query:      "?exercise_id=" + strconv.Itoa(int(squatID)) + "&from=2025-03-05&to=2025-12-31",
+			statusCode: http.StatusOK,
+			expected: []e1rmPoint{
+				{Date: "2025-03-08", E1RM: 130, Weight: 130, Reps: 1},
+			},
+		},
+		{
+			name:       "happy path: bodyweight exercise",
+			query:      "?exercise_id=" + strconv.Itoa(int(pullUp.ID)) + "&from=2025-01-01&to=2025-12-31",
+			statusCode: http.StatusOK,
+			expected: []e1rmPoint{
+				{Date: "2025-03-08", E1RM: 110, Weight: 20, Reps: 3, EffectiveLoad: ptr(100), RelativeE1RM: ptr(1.38)},
+			},
+		},
+		{
+			name:       "happy path: rpe formula without rpe",
+			query:      "?exercise_id=" + strconv.Itoa(int(squatID)) + "&from=2025-01-01&to=2025-12-31&formula=rpe",
+			statusCode: http.StatusOK,
+			expected:   []e1rmPoint{},
+		},
+		{
+			name:       "missing exercise",
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"exercise_id"},
+		},
+		{
+			name:       "invalid formula and dates",
+			query:      "?exercise_id=" + strconv.Itoa(int(squatID)) + "&formula=mayhew&from=2025-12-31&to=2025-01-01",
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"formula", "from"},
+		},
+		{
+			name:       "exercise not found",
+			query:      "?exercise_id=" + strconv.Itoa(int(squatID)+1000),
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/test"+tc.query, bytes.NewReader(nil))
+			require.NoError(t, err)
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerGetE1RM(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				for _, key := range tc.errKeys {
+					assert.Contains(t, rr.Body.String(), key)
+				}
+				return
+			}
+
+			var resParams e1rmRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			require.Len(t, resParams.Series, len(tc.expected))
+			for i, expected := range tc.expected {
+				got := resParams.Series[i]
+				assert.Equal(t, expected.Date, got.Date)
+				assert.Equal(t, expected.E1RM, got.E1RM)
+				assert.Equal(t, expected.Weight, got.Weight)
+				assert.Equal(t, expected.Reps, got.Reps)
+				if expected.LogID != 0 {
+					assert.Equal(t, expected.LogID, got.LogID)
+				}
+				if expected.RelativeE1RM != nil {
+					assert.Equal(t, expected.EffectiveLoad, got.EffectiveLoad)
+					assert.Equal(t, expected.RelativeE1RM, got.RelativeE1RM)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package stats
+
+import (
+	"math"
+	"slices"
+)
+
+const (
+	FormulaEpley    = "epley"
+	FormulaBrzycki  = "brzycki"
+	FormulaLombardi = "lombardi"
+	FormulaRPE      = "rpe" // percentage of the one rep max by reps and RPE
+)
+
+var Formulas = []string{FormulaEpley, FormulaBrzycki, FormulaLombardi, FormulaRPE}
+
+// Percentages of the one rep max by reps and RPE, the RPE chart used in RTS programming.
+// Half an RPE moves one position along the sequence and one rep moves two,
+// so the value for reps at rpe is rpeChart[2*(reps-1) + 2*(10-rpe)].
+var rpeChart = []float64{
+	100, 97.8, 95.5, 93.9, 92.2, 90.7, 89.2, 87.8, 86.3, 85.0,
+	83.7, 82.4, 81.1, 79.9, 78.6, 77.4, 76.2, 75.1, 73.9, 72.3,
+	70.7, 69.4, 68.0, 66.7, 65.3, 64.0, 62.6, 61.3, 59.9, 58.6,
+}
+
+const (
+	minChartRPE  = 6.5
+	maxChartReps = 12
+)
+
+// Brzycki diverges as the reps approach 37
+const maxBrzyckiReps = 36
+
+// OneRepMax estimates the one rep max of weight lifted for reps with formula.
+// The RPE formula needs the RPE of the set, zero when unknown.
+// It returns false when the formula can not estimate the set.
+func OneRepMax(formula string, weight float64, reps int32, rpe float64) (float64, bool) {
+	if weight <= 0 || reps <= 0 {
+		return 0, false
+	}
+
+	switch formula {
+	case FormulaEpley:
+		if reps == 1 {
+			return weight, true
+		}
+		return weight * (1 + float64(reps)/30), true
+	case FormulaBrzycki:
+		if reps > maxBrzyckiReps {
+			return 0, false
+		}
+		return weight * 36 / float64(37-reps), true
+	case FormulaLombardi:
+		return weight * math.Pow(float64(reps), 0.1), true
+	case FormulaRPE:
+		if reps > maxChartReps || rpe < minChartRPE || rpe > 10 {
+			return 0, false
+		}
+		i := 2*(int(reps)-1) + int(math.Round(2*(10-rpe)))
+		return weight * 100 / rpeChart[i], true
+	}
+	return 0, false
+}
+
+func validFormula(formula string) bool {
+	return slices.Contains(Formulas, formula)
+}
@@ -0,0 +1,43 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOneRepMax(t *testing.T) {
+	testCases := []struct {
+		name     string
+		formula  string
+		weight   float64
+		reps     int32
+		rpe      float64
+		expected float64
+		ok       bool
+	}{
+		{name: "epley single", formula: FormulaEpley, weight: 100, reps: 1, expected: 100, ok: true},
+		{name: "epley", formula: FormulaEpley, weight: 100, reps: 3, expected: 110, ok: true},
+		{name: "brzycki single", formula: FormulaBrzycki, weight: 100, reps: 1, expected: 100, ok: true},
+		{name: "brzycki", formula: FormulaBrzycki, weight: 100, reps: 10, expected: 133.333, ok: true},
+		{name: "brzycki too many reps", formula: FormulaBrzycki, weight: 100, reps: 37},
+		{name: "lombardi single", formula: FormulaLombardi, weight: 100, reps: 1, expected: 100, ok: true},
+		{name: "lombardi", formula: FormulaLombardi, weight: 100, reps: 10, expected: 125.893, ok: true},
+		{name: "rpe top single", formula: FormulaRPE, weight: 100, reps: 1, rpe: 10, expected: 100, ok: true},
+		{name: "rpe", formula: FormulaRPE, weight: 100, reps: 5, rpe: 8, expected: 123.305, ok: true},
+		{name: "rpe half step", formula: FormulaRPE, weight: 100, reps: 12, rpe: 6.5, expected: 170.648, ok: true},
+		{name: "rpe unknown", formula: FormulaRPE, weight: 100, reps: 5},
+		{name: "rpe too low", formula: FormulaRPE, weight: 100, reps: 5, rpe: 6},
+		{name: "rpe too many reps", formula: FormulaRPE, weight: 100, reps: 13, rpe: 8},
+		{name: "no weight", formula: FormulaEpley, weight: 0, reps: 5},
+		{name: "unknown formula", formula: "mayhew", weight: 100, reps: 5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e1rm, ok := OneRepMax(tc.formula, tc.weight, tc.reps, tc.rpe)
+			assert.Equal(t, tc.ok, ok)
+			assert.InDelta(t, tc.expected, e1rm, 1e-3)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package stats
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/validation"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+)
+
+// longest range of days a request can span, days are computed one by one
+const maxRangeDays = 2 * 365
+
+// dateRange validates the inclusive from and to query parameters.
+// The range ends today and spans defaultDays when they are not provided.
+func dateRange(query url.Values, problems map[string]string, defaultDays int) (time.Time, time.Time) {
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if query.Has("to") {
+		date, err := validation.Date(query.Get("to"), apiconstants.DATE_LAYOUT, nil, nil)
+		if err != nil {
+			problems["to"] = "invalid to date: " + err.Error()
+		} else {
+			to = date
+		}
+	}
+
+	from := to.AddDate(0, 0, -defaultDays)
+	if query.Has("from") {
+		date, err := validation.Date(query.Get("from"), apiconstants.DATE_LAYOUT, nil, nil)
+		if err != nil {
+			problems["from"] = "invalid from date: " + err.Error()
+		} else {
+			from = date
+		}
+	}
+	if from.After(to) {
+		problems["from"] = "invalid from date: must be before the to date"
+	} else if to.Sub(from) > maxRangeDays*24*time.Hour {
+		problems["from"] = fmt.Sprintf("invalid from date: the range can span at most %d days", maxRangeDays)
+	}
+
+	return from, to
+}
@@ -0,0 +1,52 @@
+package stats
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDateRange(t *testing.T) {
+	testCases := []struct {
+		name         string
+		query        string
+		expectedFrom time.Time
+		expectedTo   time.Time
+		errKeys      []string
+	}{
+		{
+			name:         "dates provided",
+			query:        "from=2025-01-01&to=2025-03-31",
+			expectedFrom: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			expectedTo:   time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "longest range",
+			query:        "from=2023-01-01&to=2024-12-31",
+			expectedFrom: time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+			expectedTo:   time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{name: "range too long", query: "from=0001-01-01&to=9999-12-31", errKeys: []string{"from"}},
+		{name: "from after to", query: "from=2025-03-31&to=2025-01-01", errKeys: []string{"from"}},
+		{name: "invalid dates", query: "from=yesterday&to=today", errKeys: []string{"from", "to"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tc.query)
+			assert.NoError(t, err)
+			problems := map[string]string{}
+			from, to := dateRange(query, problems, 30)
+			for _, key := range tc.errKeys {
+				assert.Contains(t, problems, key)
+			}
+			if len(tc.errKeys) > 0 {
+				return
+			}
+			assert.Empty(t, problems)
+			assert.Equal(t, tc.expectedFrom, from)
+			assert.Equal(t, tc.expectedTo, to)
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package stats
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week" // weeks start on Monday
+	PeriodMonth = "month"
+
+	GroupByExercise    = "exercise"
+	GroupByMuscleGroup = "muscle_group"
+)
+
+var (
+	Periods  = []string{PeriodDay, PeriodWeek, PeriodMonth}
+	GroupsBy = []string{GroupByExercise, GroupByMuscleGroup}
+)
+
+// default length of the volume range in days
+const defaultVolumeDays = 90
+
+type volumeItem struct {
+	PeriodStart string  `json:"period_start"`
+	ExerciseID  int32   `json:"exercise_id,omitempty"`
+	Name        string  `json:"name,omitempty"`
+	MuscleGroup string  `json:"muscle_group,omitempty"` // exercises without muscle group are grouped as "other"
+	Tonnage     float64 `json:"tonnage"`                // sum of weight times reps
+	Sets        int64   `json:"sets"`
+	HardSets    int64   `json:"hard_sets"` // sets close to failure, RPE 7+ and 3 or fewer reps in reserve when logged
+	Reps        int64   `json:"reps"`
+}
+
+type volumeRes struct {
+	Period  string       `json:"period"`
+	GroupBy string       `json:"group_by"`
+	From    string       `json:"from"`
+	To      string       `json:"to"`
+	Unit    string       `json:"unit"`
+	Volume  []volumeItem `json:"volume"`
+}
+
+// HandlerGetVolume returns the training volume of the user by period and exercise or muscle group.
+// Warm-up sets are not taken into account.
+func HandlerGetVolume(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("get volume failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		// validate the query parameters
+		problems := map[string]string{}
+		query := r.URL.Query()
+		period := PeriodWeek
+		if query.Has("period") {
+			period = strings.ToLower(query.Get("period"))
+			if !slices.Contains(Periods, period) {
+				problems["period"] = "invalid period: period must be one of " + strings.Join(Periods, ", ")
+			}
+		}
+		groupBy := GroupByExercise
+		if query.Has("group_by") {
+			groupBy = strings.ToLower(query.Get("group_by"))
+			if !slices.Contains(GroupsBy, groupBy) {
+				problems["group_by"] = "invalid group_by: group_by must be one of " + strings.Join(GroupsBy, ", ")
+			}
+		}
+		exerciseID := pgtype.Int4{}
+		if query.Has("exercise_id") {
+			parsed, err := strconv.ParseInt(query.Get("exercise_id"), 10, 32)
+			if err != nil {
+				problems["exercise_id"] = "invalid exercise_id: exercise_id must be a number"
+			} else {
+				exerciseID = pgtype.Int4{Int32: int32(parsed), Valid: true}
+			}
+		}
+		from, to := dateRange(query, problems, defaultVolumeDays)
+		if len(problems) > 0 {
+			reqLogger.Debug("get volume failed - validation failed", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		}
+
+		unit, ok := units.ResolveOrRespond(w, r, reqLogger, db, "get volume")
+		if !ok {
+			return
+		}
+
+		resParams := volumeRes{
+			Period:  period,
+			GroupBy: groupBy,
+			From:    from.Format(apiconstants.DATE_LAYOUT),
+			To:      to.Format(apiconstants.DATE_LAYOUT),
+			Unit:    unit,
+			Volume:  []volumeItem{},
+		}
+		fromDate := pgtype.Date{Time: from, Valid: true}
+		toDate := pgtype.Date{Time: to, Valid: true}
+		if groupBy == GroupByExercise {
+			rows, err := db.GetVolumeByExercise(r.Context(), database.GetVolumeByExerciseParams{
+				Period:     period,
+				UserID:     userID,
+				FromDate:   fromDate,
+				ToDate:     toDate,
+				ExerciseID: exerciseID,
+			})
+			if err != nil {
+				reqLogger.Error("get volume failed - database error", slog.String("error", err.Error()))
+				util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+				return
+			}
+			for _, row := range rows {
+				resParams.Volume = append(resParams.Volume, volumeItem{
+					PeriodStart: row.PeriodStart.Time.Format(apiconstants.DATE_LAYOUT),
+					ExerciseID:  row.ExerciseID,
+					Name:        row.Name,
+					Tonnage:     units.FromKG(row.Tonnage, unit),
+					Sets:        row.Sets,
+					HardSets:    row.HardSets,
+					Reps:        row.Reps,
+				})
+			}
+		} else {
+			rows, err := db.GetVolumeByMuscleGroup(r.Context(), database.GetVolumeByMuscleGroupParams{
+				Period:     period,
+				UserID:     userID,
+				FromDate:   fromDate,
+				ToDate:     toDate,
+				ExerciseID: exerciseID,
+			})
+			if err != nil {
+				reqLogger.Error("get volume failed - database error", slog.String("error", err.Error()))
+				util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+				return
+			}
+			for _, row := range rows {
+				resParams.Volume = append(resParams.Volume, volumeItem{
+					PeriodStart: row.PeriodStart.Time.Format(apiconstants.DATE_LAYOUT),
+					MuscleGroup: row.MuscleGroup,
+					Tonnage:     units.FromKG(row.Tonnage, unit),
+					Sets:        row.Sets,
+					HardSets:    row.HardSets,
+					Reps:        row.Reps,
+				})
+			}
+		}
+
+		util.RespondWithJSON(w, r, http.StatusOK, resParams)
+	}
+}
@@ -0,0 +1,129 @@
+package stats
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerGetVolume(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	// the exercises take the muscle group of their names
+	squatID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+	lungeID := testutil.CreateExerciseDBTestHelper(t, db, "lunge")
+	curlID := testutil.CreateExerciseDBTestHelper(t, db, "curl")
+
+	// Monday and Wednesday of the same week and the Monday of the following one
+	monday := createSessionOnDateTestHelper(t, db, user.ID, "2025-03-03")
+	setID := testutil.CreateSetDBTestHelper(t, db, monday, squatID)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 5, 1, squatID, setID, 100)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 5, 2, squatID, setID, 100)
+	setID = testutil.CreateSetDBTestHelper(t, db, monday, curlID)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 10, 1, curlID, setID, 20)
+	// warm-ups are ignored
+	warmUp, err := db.CreateSet(context.Background(), database.CreateSetParams{
+		SetOrder:   3,
+		SessionID:  monday,
+		ExerciseID: squatID,
+		SetType:    "warm_up",
+	})
+	require.NoError(t, err)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 10, 1, squatID, warmUp.ID, 60)
+	wednesday := createSessionOnDateTestHelper(t, db, user.ID, "2025-03-05")
+	setID = testutil.CreateSetDBTestHelper(t, db, wednesday, lungeID)
+	_, err = db.CreateLog(context.Background(), database.CreateLogParams{
+		SetID:      setID,
+		ExerciseID: lungeID,
+		Weight:     pgtype.Float8{Float64: 40, Valid: true},
+		Reps:       10,
+		LogsOrder:  1,
+		Rpe:        pgtype.Float8{Float64: 6, Valid: true}, // not a hard set
+		WeightUnit: "kg",
+	})
+	require.NoError(t, err)
+	nextMonday := createSessionOnDateTestHelper(t, db, user.ID, "2025-03-10")
+	setID = testutil.CreateSetDBTestHelper(t, db, nextMonday, squatID)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 3, 1, squatID, setID, 120)
+
+	dates := "&from=2025-03-01&to=2025-03-31"
+	testCases := []struct {
+		name       string
+		query      string
+		statusCode int
+		expected   []volumeItem
+		errKeys    []string
+	}{
+		{
+			name:       "happy path: weekly by exercise",
+			query:      "?period=week" + dates,
+			statusCode: http.StatusOK,
+			expected: []volumeItem{
+				{PeriodStart: "2025-03-03", ExerciseID: squatID, Name: "squat", Tonnage: 1000, Sets: 2, HardSets: 2, Reps: 10},
+				{PeriodStart: "2025-03-03", ExerciseID: lungeID, Name: "lunge", Tonnage: 400, Sets: 1, HardSets: 0, Reps: 10},
+				{PeriodStart: "2025-03-03", ExerciseID: curlID, Name: "curl", Tonnage: 200, Sets: 1, HardSets: 1, Reps: 10},
+				{PeriodStart: "2025-03-10", ExerciseID: squatID, Name: "squat", Tonnage: 360, Sets: 1, HardSets: 1, Reps: 3},
+			},
+		},
+		{
+			name:       "happy path: monthly by muscle group",
+			query:      "?period=month&group_by=muscle_group" + dates,
+			statusCode: http.StatusOK,
+			expected: []volumeItem{
+				{PeriodStart: "2025-03-01", MuscleGroup: "biceps", Tonnage: 200, Sets: 1, HardSets: 1, Reps: 10},
+				{PeriodStart: "2025-03-01", MuscleGroup: "quads", Tonnage: 1760, Sets: 4, HardSets: 3, Reps: 23},
+			},
+		},
+		{
+			name:       "happy path: daily for a single exercise",
+			query:      "?period=day&exercise_id=" + strconv.Itoa(int(lungeID)) + dates,
+			statusCode: http.StatusOK,
+			expected: []volumeItem{
+				{PeriodStart: "2025-03-05", ExerciseID: lungeID, Name: "lunge", Tonnage: 400, Sets: 1, HardSets: 0, Reps: 10},
+			},
+		},
+		{
+			name:       "invalid parameters",
+			query:      "?period=year&group_by=session&exercise_id=squat",
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"period", "group_by", "exercise_id"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/test"+tc.query, bytes.NewReader(nil))
+			require.NoError(t, err)
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerGetVolume(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				for _, key := range tc.errKeys {
+					assert.Contains(t, rr.Body.String(), key)
+				}
+				return
+			}
+
+			var resParams volumeRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			assert.ElementsMatch(t, tc.expected, resParams.Volume)
+		})
+	}
+}
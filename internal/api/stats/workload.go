@@ -0,0 +1,236 @@
+package stats
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	LoadVolume     = "volume" // weight times reps of the working sets
+	LoadSessionRPE = "srpe"   // session RPE times its duration in minutes
+)
+
+var Loads = []string{LoadVolume, LoadSessionRPE}
+
+const (
+	acuteDays           = 7
+	chronicDays         = 28
+	defaultWorkloadDays = 28
+
+	defaultACWRHigh     = 1.5
+	defaultACWRLow      = 0.8
+	defaultMonotonyHigh = 2.0
+)
+
+const (
+	FlagACWRHigh     = "acwr_high"
+	FlagACWRLow      = "acwr_low"
+	FlagMonotonyHigh = "monotony_high"
+)
+
+type thresholds struct {
+	ACWRHigh     float64 `json:"acwr_high"`
+	ACWRLow      float64 `json:"acwr_low"`
+	MonotonyHigh float64 `json:"monotony_high"`
+}
+
+type workloadDay struct {
+	Date     string   `json:"date"`
+	Load     float64  `json:"load"`
+	Acute    float64  `json:"acute"`    // mean daily load of the last 7 days
+	Chronic  float64  `json:"chronic"`  // mean daily load of the last 28 days
+	ACWR     *float64 `json:"acwr"`     // acute over chronic, null without chronic load
+	Monotony *float64 `json:"monotony"` // mean over standard deviation of the last 7 days, null when the load did not vary
+	Strain   *float64 `json:"strain"`   // load of the last 7 days times monotony
+	Flags    []string `json:"flags"`
+}
+
+type workloadRes struct {
+	Load       string        `json:"load"`
+	From       string        `json:"from"`
+	To         string        `json:"to"`
+	Unit       string        `json:"unit,omitempty"` // only for volume loads
+	Thresholds thresholds    `json:"thresholds"`
+	Days       []workloadDay `json:"days"`
+}
+
+// HandlerGetWorkload returns the daily training load of the user with its rolling acute and chronic loads,
+// the acute:chronic workload ratio, monotony and strain, flagging the days beyond the thresholds.
+func HandlerGetWorkload(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("get workload failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		// validate the query parameters
+		problems := map[string]string{}
+		query := r.URL.Query()
+		load := LoadVolume
+		if query.Has("load") {
+			load = strings.ToLower(query.Get("load"))
+			if !slices.Contains(Loads, load) {
+				problems["load"] = "invalid load: load must be one of " + strings.Join(Loads, ", ")
+			}
+		}
+		th := thresholds{
+			ACWRHigh:     threshold(query, problems, "acwr_high", defaultACWRHigh),
+			ACWRLow:      threshold(query, problems, "acwr_low", defaultACWRLow),
+			MonotonyHigh: threshold(query, problems, "monotony_high", defaultMonotonyHigh),
+		}
+		if th.ACWRLow >= th.ACWRHigh && problems["acwr_low"] == "" && problems["acwr_high"] == "" {
+			problems["acwr_low"] = "invalid acwr_low: must be lower than acwr_high"
+		}
+		from, to := dateRange(query, problems, defaultWorkloadDays)
+		if len(problems) > 0 {
+			reqLogger.Debug("get workload failed - validation failed", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		}
+
+		unit := ""
+		if load == LoadVolume {
+			if unit, ok = units.ResolveOrRespond(w, r, reqLogger, db, "get workload"); !ok {
+				return
+			}
+		}
+
+		// the chronic load of the first day needs the previous 27 days
+		start := from.AddDate(0, 0, 1-chronicDays)
+		dbParams := database.GetDailyVolumeLoadParams{
+			UserID:   userID,
+			FromDate: pgtype.Date{Time: start, Valid: true},
+			ToDate:   pgtype.Date{Time: to, Valid: true},
+		}
+		loads := map[string]float64{}
+		if load == LoadVolume {
+			rows, err := db.GetDailyVolumeLoad(r.Context(), dbParams)
+			if err != nil {
+				reqLogger.Error("get workload failed - database error", slog.String("error", err.Error()))
+				util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+				return
+			}
+			for _, row := range rows {
+				loads[row.Date.Time.Format(apiconstants.DATE_LAYOUT)] = units.FromKG(row.Load, unit)
+			}
+		} else {
+			rows, err := db.GetDailySessionRPELoad(r.Context(), database.GetDailySessionRPELoadParams(dbParams))
+			if err != nil {
+				reqLogger.Error("get workload failed - database error", slog.String("error", err.Error()))
+				util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+				return
+			}
+			for _, row := range rows {
+				loads[row.Date.Time.Format(apiconstants.DATE_LAYOUT)] = row.Load
+			}
+		}
+
+		util.RespondWithJSON(w, r, http.StatusOK, workloadRes{
+			Load:       load,
+			From:       from.Format(apiconstants.DATE_LAYOUT),
+			To:         to.Format(apiconstants.DATE_LAYOUT),
+			Unit:       unit,
+			Thresholds: th,
+			Days:       workload(dailyLoads(loads, start, to), from, th),
+		})
+	}
+}
+
+func threshold(query url.Values, problems map[string]string, key string, defaultValue float64) float64 {
+	if !query.Has(key) {
+		return defaultValue
+	}
+	value, err := strconv.ParseFloat(query.Get(key), 64)
+	if err != nil || value <= 0 || math.IsInf(value, 0) {
+		problems[key] = fmt.Sprintf("invalid %s: %s must be a positive number", key, key)
+		return defaultValue
+	}
+	return value
+}
+
+// dailyLoads lays out the loads by date of every day between start and end, both inclusive
+func dailyLoads(loads map[string]float64, start, end time.Time) []float64 {
+	daily := []float64{}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		daily = append(daily, loads[day.Format(apiconstants.DATE_LAYOUT)])
+	}
+	return daily
+}
+
+// workload computes the indicators of every day from the first one on.
+// daily holds the load of every day starting chronicDays-1 days before first.
+func workload(daily []float64, first time.Time, th thresholds) []workloadDay {
+	days := []workloadDay{}
+	for i := chronicDays - 1; i < len(daily); i++ {
+		acute := daily[i-acuteDays+1 : i+1]
+		chronic := daily[i-chronicDays+1 : i+1]
+		acuteSum, chronicSum := sum(acute), sum(chronic)
+
+		day := workloadDay{
+			Date:    first.AddDate(0, 0, i-chronicDays+1).Format(apiconstants.DATE_LAYOUT),
+			Load:    daily[i],
+			Acute:   round(acuteSum / acuteDays),
+			Chronic: round(chronicSum / chronicDays),
+			Flags:   []string{},
+		}
+		if chronicSum > 0 {
+			acwr := round(acuteSum / acuteDays / (chronicSum / chronicDays))
+			day.ACWR = &acwr
+			if acwr > th.ACWRHigh {
+				day.Flags = append(day.Flags, FlagACWRHigh)
+			} else if acwr < th.ACWRLow {
+				day.Flags = append(day.Flags, FlagACWRLow)
+			}
+		}
+		if sd := stdDev(acute); sd > 0 {
+			monotony := round(acuteSum / acuteDays / sd)
+			strain := round(acuteSum * monotony)
+			day.Monotony, day.Strain = &monotony, &strain
+			if monotony > th.MonotonyHigh {
+				day.Flags = append(day.Flags, FlagMonotonyHigh)
+			}
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+func sum(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+// stdDev is the population standard deviation of values
+func stdDev(values []float64) float64 {
+	mean := sum(values) / float64(len(values))
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(variance / float64(len(values)))
+}
+
+func round(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
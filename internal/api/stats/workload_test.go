@@ -0,0 +1,204 @@
+package stats
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkload(t *testing.T) {
+	repeat := func(value float64, n int) []float64 {
+		return slices.Repeat([]float64{value}, n)
+	}
+	th := thresholds{ACWRHigh: defaultACWRHigh, ACWRLow: defaultACWRLow, MonotonyHigh: defaultMonotonyHigh}
+
+	testCases := []struct {
+		name             string
+		daily            []float64
+		expectedACWR     *float64
+		expectedMonotony *float64
+		expectedFlags    []string
+	}{
+		{
+			name:          "steady load",
+			daily:         repeat(100, 28),
+			expectedACWR:  ptr(1.0),
+			expectedFlags: []string{},
+		},
+		{
+			name:          "spike after rest",
+			daily:         append(repeat(0, 21), repeat(100, 7)...),
+			expectedACWR:  ptr(4.0),
+			expectedFlags: []string{FlagACWRHigh},
+		},
+		{
+			name:             "deload week",
+			daily:            append(repeat(100, 21), 100, 0, 100, 0, 100, 0, 100),
+			expectedACWR:     ptr(0.64),
+			expectedMonotony: ptr(1.155),
+			expectedFlags:    []string{FlagACWRLow},
+		},
+		{
+			name:             "monotonous week",
+			daily:            append(repeat(100, 27), 90),
+			expectedACWR:     ptr(0.989),
+			expectedMonotony: ptr(28.169),
+			expectedFlags:    []string{FlagMonotonyHigh},
+		},
+		{
+			name:          "no load",
+			daily:         repeat(0, 28),
+			expectedFlags: []string{},
+		},
+	}
+
+	first := time.Date(2025, time.March, 28, 0, 0, 0, 0, time.UTC)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			days := workload(tc.daily, first, th)
+			require.Len(t, days, 1)
+			day := days[0]
+			assert.Equal(t, "2025-03-28", day.Date)
+			assert.Equal(t, tc.expectedFlags, day.Flags)
+			if tc.expectedACWR == nil {
+				assert.Nil(t, day.ACWR)
+			} else {
+				require.NotNil(t, day.ACWR)
+				assert.InDelta(t, *tc.expectedACWR, *day.ACWR, 1e-3)
+			}
+			if tc.expectedMonotony == nil {
+				assert.Nil(t, day.Monotony)
+				assert.Nil(t, day.Strain)
+			} else {
+				require.NotNil(t, day.Monotony)
+				assert.InDelta(t, *tc.expectedMonotony, *day.Monotony, 1e-3)
+			}
+		})
+	}
+}
+
+func TestWorkloadDays(t *testing.T) {
+	start := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, chronicDays+1)
+	daily := dailyLoads(map[string]float64{"2025-03-01": 280, "2025-03-30": 70}, start, end)
+	require.Len(t, daily, chronicDays+2)
+
+	days := workload(daily, start.AddDate(0, 0, chronicDays-1), thresholds{ACWRHigh: 1.5, ACWRLow: 0.8, MonotonyHigh: 2})
+	require.Len(t, days, 3)
+	assert.Equal(t, []string{"2025-03-28", "2025-03-29", "2025-03-30"}, []string{days[0].Date, days[1].Date, days[2].Date})
+	// the first load leaves the chronic window on the 29th
+	assert.Equal(t, 10.0, days[0].Chronic)
+	assert.Nil(t, days[1].ACWR)
+	assert.Equal(t, 70.0, days[2].Load)
+	assert.Equal(t, 10.0, days[2].Acute)
+	assert.Equal(t, 2.5, days[2].Chronic)
+}
+
+func TestHandlerGetWorkload(t *testing.T) {
+	require.NoError(t, testutil.Cleanup(dbPool, "sessions"))
+	require.NoError(t, testutil.Cleanup(dbPool, "exercises"))
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "testuser", "testpassword", false)
+	squatID := testutil.CreateExerciseDBTestHelper(t, db, "squat")
+
+	// a session with RPE 8 during 60 minutes and 500 kg of volume
+	session, err := db.CreateSession(context.Background(), database.CreateSessionParams{
+		Name:            "leg day",
+		Date:            pgtype.Date{Time: time.Date(2025, time.March, 28, 0, 0, 0, 0, time.UTC), Valid: true},
+		DurationMinutes: pgtype.Int2{Int16: 60, Valid: true},
+		Rpe:             pgtype.Int2{Int16: 8, Valid: true},
+		UserID:          user.ID,
+	})
+	require.NoError(t, err)
+	setID := testutil.CreateSetDBTestHelper(t, db, session.ID, squatID)
+	testutil.CreateLogExerciseDBTestHelper(t, db, 5, 1, squatID, setID, 100)
+
+	dates := "&from=2025-03-27&to=2025-03-28"
+	testCases := []struct {
+		name         string
+		query        string
+		statusCode   int
+		expectedLoad float64
+		expectedUnit string
+		errKeys      []string
+	}{
+		{
+			name:         "happy path: volume load",
+			query:        "?load=volume" + dates,
+			statusCode:   http.StatusOK,
+			expectedLoad: 500,
+			expectedUnit: "kg",
+		},
+		{
+			name:         "happy path: session RPE load",
+			query:        "?load=srpe" + dates,
+			statusCode:   http.StatusOK,
+			expectedLoad: 480,
+		},
+		{
+			name:       "invalid parameters",
+			query:      "?load=tonnage&acwr_high=-1&monotony_high=abc",
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"load", "acwr_high", "monotony_high"},
+		},
+		{
+			name:       "range too long",
+			query:      "?from=0001-01-01&to=9999-12-31",
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"from"},
+		},
+		{
+			name:       "low threshold above the high one",
+			query:      "?acwr_low=2&acwr_high=1.2",
+			statusCode: http.StatusBadRequest,
+			errKeys:    []string{"acwr_low"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/test"+tc.query, bytes.NewReader(nil))
+			require.NoError(t, err)
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerGetWorkload(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				for _, key := range tc.errKeys {
+					assert.Contains(t, rr.Body.String(), key)
+				}
+				return
+			}
+
+			var resParams workloadRes
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resParams))
+			assert.Equal(t, tc.expectedUnit, resParams.Unit)
+			require.Len(t, resParams.Days, 2)
+			assert.Equal(t, 0.0, resParams.Days[0].Load)
+			assert.Equal(t, tc.expectedLoad, resParams.Days[1].Load)
+			require.NotNil(t, resParams.Days[1].ACWR)
+			// a single training day is four times the chronic load
+			assert.Equal(t, 4.0, *resParams.Days[1].ACWR)
+			assert.Contains(t, resParams.Days[1].Flags, FlagACWRHigh)
+		})
+	}
+}
+
+func ptr(value float64) *float64 {
+	return &value
+}
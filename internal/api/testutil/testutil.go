@@ -161,12 +161,17 @@ func CreateSessionDBTestHelper(t testing.TB, db *database.Queries, name string,
 }
 
 func CreateSetDBTestHelper(t testing.TB, db *database.Queries, sessionID uuid.UUID, exerciseID int32) int64 {
+	// orders are unique within a session, the new set goes last
+	setIDs, err := db.GetSetIDsBySessionIDForUpdate(context.Background(), sessionID)
+	require.NoError(t, err)
+
 	set, err := db.CreateSet(context.Background(),
 		database.CreateSetParams{
-			SetOrder:   1,
+			SetOrder:   int32(len(setIDs) + 1),
 			RestTime:   pgtype.Int4{Int32: 90, Valid: true},
 			SessionID:  sessionID,
 			ExerciseID: exerciseID,
+			SetType:    "working",
 		})
 	require.NoError(t, err)
 
@@ -203,6 +208,7 @@ func CreateLogExerciseDBTestHelper(
 		Weight:     pgtype.Float8{Float64: weight, Valid: true},
 		Reps:       reps,
 		LogsOrder:  order,
+		WeightUnit: "kg",
 	})
 	require.NoError(t, err)
 	return newLog.ID
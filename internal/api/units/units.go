@@ -0,0 +1,88 @@
+package units
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	KG = "kg"
+	LB = "lb"
+)
+
+var Units = []string{KG, LB}
+
+// exact by definition of the international pound
+const kgPerLB = 0.45359237
+
+// converted weights are rounded so the value entered by the user is recovered exactly
+const precision = 1000
+
+var ErrInvalidUnit = errors.New("unit must be one of kg, lb")
+
+func Valid(unit string) bool {
+	return slices.Contains(Units, unit)
+}
+
+// ToKG converts a weight expressed in unit into the canonical kilograms
+func ToKG(weight float64, unit string) float64 {
+	if unit == LB {
+		return weight * kgPerLB
+	}
+	return weight
+}
+
+// FromKG converts a weight in kilograms into unit
+func FromKG(weight float64, unit string) float64 {
+	if unit == LB {
+		weight = weight / kgPerLB
+	}
+	return math.Round(weight*precision) / precision
+}
+
+// Resolve returns the unit weights are expressed in for the request:
+// the units query parameter when present, otherwise the preference of the user in the context.
+func Resolve(r *http.Request, db *database.Queries) (string, error) {
+	if override := r.URL.Query().Get("units"); override != "" {
+		if !Valid(override) {
+			return "", fmt.Errorf("%w, got %q", ErrInvalidUnit, override)
+		}
+		return override, nil
+	}
+
+	userID, ok := util.UserFromContext(r.Context())
+	if !ok {
+		return KG, nil
+	}
+	unit, err := db.GetUserPreferredUnit(r.Context(), userID)
+	if err == pgx.ErrNoRows {
+		return KG, nil
+	} else if err != nil {
+		return "", fmt.Errorf("get preferred unit: %w", err)
+	}
+	return unit, nil
+}
+
+// ResolveOrRespond resolves the unit weights are returned in, and entered in when the request has no unit.
+// When it can not be resolved the failure of action is logged and answered, handlers only have to return.
+func ResolveOrRespond(w http.ResponseWriter, r *http.Request, reqLogger *slog.Logger, db *database.Queries, action string) (string, bool) {
+	unit, err := Resolve(r, db)
+	if errors.Is(err, ErrInvalidUnit) {
+		reqLogger.Debug(action+" failed - invalid units", slog.String("error", err.Error()))
+		util.RespondWithJSON(w, r, http.StatusBadRequest, map[string]string{"units": "invalid units: " + err.Error()})
+		return "", false
+	} else if err != nil {
+		reqLogger.Error(action+" failed - get preferred unit database error", slog.String("error", err.Error()))
+		util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+		return "", false
+	}
+	return unit, true
+}
@@ -0,0 +1,42 @@
+package units
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConversionIsLossless(t *testing.T) {
+	testCases := []struct {
+		weight float64
+		unit   string
+	}{
+		{weight: 100, unit: LB},
+		{weight: 2.5, unit: LB},
+		{weight: 225, unit: LB},
+		{weight: 137.75, unit: LB},
+		{weight: 0, unit: LB},
+		{weight: 102.5, unit: KG},
+		{weight: 1.25, unit: KG},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%v %s", tc.weight, tc.unit), func(t *testing.T) {
+			assert.Equal(t, tc.weight, FromKG(ToKG(tc.weight, tc.unit), tc.unit))
+		})
+	}
+}
+
+func TestFromKG(t *testing.T) {
+	assert.Equal(t, 220.462, FromKG(100, LB))
+	assert.Equal(t, 45.359, FromKG(ToKG(100, LB), KG))
+	assert.Equal(t, 100.0, FromKG(100, KG))
+}
+
+func TestValid(t *testing.T) {
+	assert.True(t, Valid(KG))
+	assert.True(t, Valid(LB))
+	assert.False(t, Valid("stone"))
+	assert.False(t, Valid(""))
+}
@@ -107,11 +107,12 @@ func HandlerCreateUser(db *database.Queries, logger *slog.Logger) http.HandlerFu
 		}
 		reqLogger.Info("create user success", slog.String("user_id", user.ID.String()))
 		util.RespondWithJSON(w, r, http.StatusCreated, User{
-			ID:        user.ID.String(),
-			Username:  user.Username,
-			Country:   user.Country.String,
-			CreatedAt: user.CreatedAt.Time.String(),
-			Birthday:  user.Birthday.Time.Format(apiconstants.DATE_LAYOUT),
+			ID:            user.ID.String(),
+			Username:      user.Username,
+			Country:       user.Country.String,
+			CreatedAt:     user.CreatedAt.Time.String(),
+			Birthday:      user.Birthday.Time.Format(apiconstants.DATE_LAYOUT),
+			PreferredUnit: user.PreferredUnit,
 		})
 	}
 }
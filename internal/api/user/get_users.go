@@ -6,7 +6,6 @@ import (
 
 	"github.com/CTSDM/gogym/internal/api/middleware"
 	"github.com/CTSDM/gogym/internal/api/util"
-	"github.com/CTSDM/gogym/internal/apiconstants"
 	"github.com/CTSDM/gogym/internal/database"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -17,11 +16,15 @@ type getUsersResponse struct {
 }
 
 type User struct {
-	ID        string `json:"id"`
-	Username  string `json:"username"`
-	Country   string `json:"country"`
-	CreatedAt string `json:"created_at"`
-	Birthday  string `json:"birthday,omitempty"`
+	ID            string `json:"id"`
+	Username      string `json:"username"`
+	Country       string `json:"country"`
+	CreatedAt     string `json:"created_at"`
+	Birthday      string `json:"birthday,omitempty"`
+	PreferredUnit string `json:"preferred_unit"` // weights are returned in this unit unless overridden
+	Timezone      string `json:"timezone"`
+
+	Leaderboards leaderboardSettings `json:"leaderboards"`
 }
 
 func HandlerGetUsers(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
@@ -37,13 +40,7 @@ func HandlerGetUsers(db *database.Queries, logger *slog.Logger) http.HandlerFunc
 
 		responseVals := getUsersResponse{Users: make([]User, len(users))}
 		for i, user := range users {
-			responseVals.Users[i].ID = user.ID.String()
-			responseVals.Users[i].Username = user.Username
-			responseVals.Users[i].Country = user.Country.String
-			responseVals.Users[i].CreatedAt = user.CreatedAt.Time.Format(apiconstants.DATE_LAYOUT)
-			if user.Birthday.Valid {
-				responseVals.Users[i].Birthday = user.Birthday.Time.Format(apiconstants.DATE_LAYOUT)
-			}
+			responseVals.Users[i] = userFromDB(user)
 		}
 		util.RespondWithJSON(w, r, http.StatusOK, responseVals)
 	}
@@ -75,18 +72,6 @@ func HandlerGetUser(db *database.Queries, logger *slog.Logger) http.HandlerFunc
 			return
 		}
 
-		// Create response
-		user := User{
-			ID:        userDB.ID.String(),
-			Username:  userDB.Username,
-			Country:   userDB.Country.String,
-			CreatedAt: userDB.CreatedAt.Time.Format(apiconstants.DATE_LAYOUT),
-		}
-		// Only add the birthday if it has been defined
-		if userDB.Birthday.Valid {
-			user.Birthday = userDB.Birthday.Time.Format(apiconstants.DATE_LAYOUT)
-		}
-
-		util.RespondWithJSON(w, r, http.StatusOK, user)
+		util.RespondWithJSON(w, r, http.StatusOK, userFromDB(userDB))
 	}
 }
@@ -2,6 +2,7 @@ package user
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -11,6 +12,7 @@ import (
 	"github.com/CTSDM/gogym/internal/api/testutil"
 	"github.com/CTSDM/gogym/internal/database"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 )
 
@@ -156,6 +158,12 @@ func TestHandlerGetUsers(t *testing.T) {
 		require.NoError(t, testutil.Cleanup(dbPool, "users"))
 
 		user := testutil.CreateUserDBTestHelper(t, db, "user_structure_test", "password", true)
+		_, err := db.UpdateUserLeaderboard(context.Background(), database.UpdateUserLeaderboardParams{
+			LeaderboardOptIn: pgtype.Bool{Bool: true, Valid: true},
+			DisplayName:      pgtype.Text{String: "lifter", Valid: true},
+			ID:               user.ID,
+		})
+		require.NoError(t, err)
 
 		req, err := http.NewRequest("GET", "/test", bytes.NewReader([]byte{}))
 		require.NoError(t, err, "unexpected error while setting up the request")
@@ -177,6 +185,8 @@ func TestHandlerGetUsers(t *testing.T) {
 				require.NotEmpty(t, u.Username)
 				require.NotEmpty(t, u.CreatedAt)
 				require.Empty(t, u.Country)
+				require.Equal(t, "UTC", u.Timezone)
+				require.Equal(t, leaderboardSettings{OptIn: true, DisplayName: "lifter"}, u.Leaderboards)
 				if user.Birthday.Valid {
 					require.NotEmpty(t, u.Birthday)
 				}
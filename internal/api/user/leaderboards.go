@@ -0,0 +1,144 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/api/validation"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Users only appear on the leaderboards under their display name after opting in,
+// the country and the age are only used to filter them when they are shared.
+// Users are only ranked by relative strength when they share their bodyweight.
+type leaderboardSettings struct {
+	OptIn           bool   `json:"opt_in"`
+	DisplayName     string `json:"display_name,omitempty"`
+	ShareCountry    bool   `json:"share_country"`
+	ShareAge        bool   `json:"share_age"`
+	ShareBodyweight bool   `json:"share_bodyweight"`
+}
+
+// Settings not provided are kept, an empty display name clears it
+type leaderboardSettingsReq struct {
+	OptIn           *bool   `json:"opt_in"`
+	DisplayName     *string `json:"display_name"`
+	ShareCountry    *bool   `json:"share_country"`
+	ShareAge        *bool   `json:"share_age"`
+	ShareBodyweight *bool   `json:"share_bodyweight"`
+}
+
+func (r *leaderboardSettingsReq) Valid(ctx context.Context) map[string]string {
+	problems := make(map[string]string)
+	if r.OptIn == nil && r.DisplayName == nil && r.ShareCountry == nil && r.ShareAge == nil && r.ShareBodyweight == nil {
+		problems["settings"] = "invalid settings: at least one of opt_in, display_name, share_country, share_age or share_bodyweight is required"
+		return problems
+	}
+	if r.DisplayName != nil && *r.DisplayName != "" {
+		if err := validation.String(*r.DisplayName, apiconstants.MinDisplayNameLength, apiconstants.MaxDisplayNameLength); err != nil {
+			problems["display_name"] = fmt.Sprintf("invalid display_name: %s", err.Error())
+		} else if strings.TrimSpace(*r.DisplayName) != *r.DisplayName {
+			problems["display_name"] = "invalid display_name: display_name can not start or end with spaces"
+		}
+	}
+	return problems
+}
+
+func leaderboardSettingsFromDB(userDB database.User) leaderboardSettings {
+	return leaderboardSettings{
+		OptIn:           userDB.LeaderboardOptIn,
+		DisplayName:     userDB.DisplayName.String,
+		ShareCountry:    userDB.LeaderboardShareCountry,
+		ShareAge:        userDB.LeaderboardShareAge,
+		ShareBodyweight: userDB.LeaderboardShareBodyweight,
+	}
+}
+
+func HandlerUpdateLeaderboardSettings(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("update leaderboard settings failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		reqParams, problems, err := validation.DecodeValid[*leaderboardSettingsReq](r)
+		if len(problems) > 0 {
+			reqLogger.Debug("update leaderboard settings failed - validation errors", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		} else if err != nil {
+			reqLogger.Debug("update leaderboard settings failed - invalid payload", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusBadRequest, "invalid payload", err)
+			return
+		}
+
+		userDB, err := db.UpdateUserLeaderboard(r.Context(), database.UpdateUserLeaderboardParams{
+			LeaderboardOptIn:           boolParam(reqParams.OptIn),
+			DisplayName:                textParam(reqParams.DisplayName),
+			LeaderboardShareCountry:    boolParam(reqParams.ShareCountry),
+			LeaderboardShareAge:        boolParam(reqParams.ShareAge),
+			LeaderboardShareBodyweight: boolParam(reqParams.ShareBodyweight),
+			ID:                         userID,
+		})
+		if err == pgx.ErrNoRows {
+			util.RespondWithError(w, r, http.StatusNotFound, "user not found", err)
+			return
+		} else if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) {
+				switch {
+				case pgErr.Code == "23505":
+					reqLogger.Debug("update leaderboard settings failed - display name taken")
+					util.RespondWithError(w, r, http.StatusConflict, "display name already taken", err)
+					return
+				case pgErr.Code == "23514" && pgErr.ConstraintName == "display_name_to_opt_in":
+					reqLogger.Debug("update leaderboard settings failed - opt in without display name")
+					util.RespondWithJSON(w, r, http.StatusBadRequest, map[string]string{
+						"display_name": "invalid display_name: a display_name is required to opt in",
+					})
+					return
+				case pgErr.Code == "23514" && pgErr.ConstraintName == "display_name_not_username":
+					reqLogger.Debug("update leaderboard settings failed - display name is the username")
+					util.RespondWithJSON(w, r, http.StatusBadRequest, map[string]string{
+						"display_name": "invalid display_name: display_name can not be the username",
+					})
+					return
+				}
+			}
+			reqLogger.Error("update leaderboard settings failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		reqLogger.Info("update leaderboard settings success", slog.Bool("opt_in", userDB.LeaderboardOptIn))
+		util.RespondWithJSON(w, r, http.StatusOK, leaderboardSettingsFromDB(userDB))
+	}
+}
+
+func boolParam(value *bool) pgtype.Bool {
+	if value == nil {
+		return pgtype.Bool{}
+	}
+	return pgtype.Bool{Bool: *value, Valid: true}
+}
+
+func textParam(value *string) pgtype.Text {
+	if value == nil {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *value, Valid: true}
+}
@@ -0,0 +1,132 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerUpdateLeaderboardSettings(t *testing.T) {
+	testCases := []struct {
+		name       string
+		body       string
+		statusCode int
+		errMsg     string
+		expected   leaderboardSettings
+	}{
+		{
+			name:       "opt in without display name",
+			body:       `{"opt_in": true}`,
+			statusCode: http.StatusBadRequest,
+			errMsg:     "a display_name is required to opt in",
+		},
+		{
+			name:       "display name is the username",
+			body:       `{"display_name": "Username"}`,
+			statusCode: http.StatusBadRequest,
+			errMsg:     "display_name can not be the username",
+		},
+		{
+			name:       "display name taken",
+			body:       `{"display_name": "The Rock"}`,
+			statusCode: http.StatusConflict,
+			errMsg:     "display name already taken",
+		},
+		{
+			name:       "happy path: opt in",
+			body:       `{"opt_in": true, "display_name": "Iron Lifter"}`,
+			statusCode: http.StatusOK,
+			expected:   leaderboardSettings{OptIn: true, DisplayName: "Iron Lifter"},
+		},
+		{
+			name:       "happy path: sharing keeps the rest",
+			body:       `{"share_country": true, "share_age": true, "share_bodyweight": true}`,
+			statusCode: http.StatusOK,
+			expected: leaderboardSettings{
+				OptIn: true, DisplayName: "Iron Lifter", ShareCountry: true, ShareAge: true, ShareBodyweight: true,
+			},
+		},
+		{
+			name:       "clear display name while opted in",
+			body:       `{"display_name": ""}`,
+			statusCode: http.StatusBadRequest,
+			errMsg:     "a display_name is required to opt in",
+		},
+		{
+			name:       "happy path: opt out",
+			body:       `{"opt_in": false}`,
+			statusCode: http.StatusOK,
+			expected:   leaderboardSettings{DisplayName: "Iron Lifter", ShareCountry: true, ShareAge: true, ShareBodyweight: true},
+		},
+		{
+			name:       "happy path: clear display name",
+			body:       `{"display_name": ""}`,
+			statusCode: http.StatusOK,
+			expected:   leaderboardSettings{ShareCountry: true, ShareAge: true, ShareBodyweight: true},
+		},
+		{
+			name:       "invalid display name",
+			body:       `{"display_name": " x"}`,
+			statusCode: http.StatusBadRequest,
+			errMsg:     "invalid display_name",
+		},
+		{
+			name:       "missing settings",
+			body:       `{}`,
+			statusCode: http.StatusBadRequest,
+			errMsg:     "invalid settings",
+		},
+	}
+
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "username", "password", false)
+	other := testutil.CreateUserDBTestHelper(t, db, "otheruser", "password", false)
+	_, err := db.UpdateUserLeaderboard(context.Background(), database.UpdateUserLeaderboardParams{
+		DisplayName: pgtype.Text{String: "The Rock", Valid: true},
+		ID:          other.ID,
+	})
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("PUT", "/test", bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err)
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerUpdateLeaderboardSettings(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				assert.Contains(t, rr.Body.String(), tc.errMsg)
+				return
+			}
+
+			var response leaderboardSettings
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.Equal(t, tc.expected, response)
+
+			// the profile reflects the new settings
+			req, err = http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			rr = httptest.NewRecorder()
+			middleware.RequestID(HandlerGetMe(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, http.StatusOK, rr.Code)
+			var me User
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &me))
+			assert.Equal(t, tc.expected, me.Leaderboards)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package user
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/api/validation"
+	"github.com/CTSDM/gogym/internal/apiconstants"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Preferences not provided are kept
+type preferencesReq struct {
+	PreferredUnit string `json:"preferred_unit,omitempty"`
+	Timezone      string `json:"timezone,omitempty"` // IANA name, e.g. "Europe/Madrid"
+}
+
+func (r *preferencesReq) Valid(ctx context.Context) map[string]string {
+	problems := make(map[string]string)
+	if r.PreferredUnit == "" && r.Timezone == "" {
+		problems["preferences"] = "invalid preferences: at least one of preferred_unit or timezone is required"
+		return problems
+	}
+	if r.PreferredUnit != "" && !units.Valid(r.PreferredUnit) {
+		problems["preferred_unit"] = "invalid preferred_unit: " + units.ErrInvalidUnit.Error()
+	}
+	// an empty name or "Local" would be accepted by LoadLocation
+	if r.Timezone != "" {
+		if _, err := time.LoadLocation(r.Timezone); err != nil || r.Timezone == "Local" {
+			problems["timezone"] = "invalid timezone: timezone must be an IANA timezone name"
+		}
+	}
+	return problems
+}
+
+func userFromDB(userDB database.User) User {
+	user := User{
+		ID:            userDB.ID.String(),
+		Username:      userDB.Username,
+		Country:       userDB.Country.String,
+		CreatedAt:     userDB.CreatedAt.Time.Format(apiconstants.DATE_LAYOUT),
+		PreferredUnit: userDB.PreferredUnit,
+		Timezone:      userDB.Timezone,
+		Leaderboards:  leaderboardSettingsFromDB(userDB),
+	}
+	// Only add the birthday if it has been defined
+	if userDB.Birthday.Valid {
+		user.Birthday = userDB.Birthday.Time.Format(apiconstants.DATE_LAYOUT)
+	}
+	return user
+}
+
+func HandlerGetMe(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("get me failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		userDB, err := db.GetUser(r.Context(), userID)
+		if err == pgx.ErrNoRows {
+			util.RespondWithError(w, r, http.StatusNotFound, "user not found", err)
+			return
+		} else if err != nil {
+			reqLogger.Error("get me failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		util.RespondWithJSON(w, r, http.StatusOK, userFromDB(userDB))
+	}
+}
+
+func HandlerUpdatePreferences(db *database.Queries, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqLogger := middleware.BasicReqLogger(logger, r)
+		userID, ok := util.UserFromContext(r.Context())
+		if !ok {
+			reqLogger.Error("update preferences failed - user not in context")
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", nil)
+			return
+		}
+		reqLogger = reqLogger.With(slog.String("user_id", userID.String()))
+
+		reqParams, problems, err := validation.DecodeValid[*preferencesReq](r)
+		if len(problems) > 0 {
+			reqLogger.Debug("update preferences failed - validation errors", slog.Any("problems", problems))
+			util.RespondWithJSON(w, r, http.StatusBadRequest, problems)
+			return
+		} else if err != nil {
+			reqLogger.Debug("update preferences failed - invalid payload", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusBadRequest, "invalid payload", err)
+			return
+		}
+
+		userDB, err := db.UpdateUserPreferences(r.Context(), database.UpdateUserPreferencesParams{
+			PreferredUnit: pgtype.Text{String: reqParams.PreferredUnit, Valid: reqParams.PreferredUnit != ""},
+			Timezone:      pgtype.Text{String: reqParams.Timezone, Valid: reqParams.Timezone != ""},
+			ID:            userID,
+		})
+		if err == pgx.ErrNoRows {
+			util.RespondWithError(w, r, http.StatusNotFound, "user not found", err)
+			return
+		} else if err != nil {
+			reqLogger.Error("update preferences failed - database error", slog.String("error", err.Error()))
+			util.RespondWithError(w, r, http.StatusInternalServerError, "something went wrong", err)
+			return
+		}
+
+		reqLogger.Info("update preferences success")
+		util.RespondWithJSON(w, r, http.StatusOK, userFromDB(userDB))
+	}
+}
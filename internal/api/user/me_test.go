@@ -0,0 +1,112 @@
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CTSDM/gogym/internal/api/middleware"
+	"github.com/CTSDM/gogym/internal/api/testutil"
+	"github.com/CTSDM/gogym/internal/api/units"
+	"github.com/CTSDM/gogym/internal/api/util"
+	"github.com/CTSDM/gogym/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerUpdatePreferences(t *testing.T) {
+	testCases := []struct {
+		name       string
+		body       string
+		statusCode int
+		errMsg     string
+		expected   string
+		timezone   string
+	}{
+		{
+			name:       "happy path: pounds",
+			body:       `{"preferred_unit": "lb"}`,
+			statusCode: http.StatusOK,
+			expected:   units.LB,
+		},
+		{
+			name:       "happy path: back to kilograms",
+			body:       `{"preferred_unit": "kg"}`,
+			statusCode: http.StatusOK,
+			expected:   units.KG,
+		},
+		{
+			name:       "happy path: timezone keeps the unit",
+			body:       `{"timezone": "Europe/Madrid"}`,
+			statusCode: http.StatusOK,
+			expected:   units.KG,
+			timezone:   "Europe/Madrid",
+		},
+		{
+			name:       "happy path: unit keeps the timezone",
+			body:       `{"preferred_unit": "lb"}`,
+			statusCode: http.StatusOK,
+			expected:   units.LB,
+			timezone:   "Europe/Madrid",
+		},
+		{
+			name:       "unknown timezone",
+			body:       `{"timezone": "Mars/Olympus_Mons"}`,
+			statusCode: http.StatusBadRequest,
+			errMsg:     "invalid timezone",
+		},
+		{
+			name:       "unknown unit",
+			body:       `{"preferred_unit": "stone"}`,
+			statusCode: http.StatusBadRequest,
+			errMsg:     "invalid preferred_unit",
+		},
+		{
+			name:       "missing preferences",
+			body:       `{}`,
+			statusCode: http.StatusBadRequest,
+			errMsg:     "invalid preferences",
+		},
+	}
+
+	require.NoError(t, testutil.Cleanup(dbPool, "users"))
+	db := database.New(dbPool)
+	user := testutil.CreateUserDBTestHelper(t, db, "username", "password", false)
+	assert.Equal(t, units.KG, user.PreferredUnit)
+	assert.Equal(t, "UTC", user.Timezone)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("PUT", "/test", bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err)
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			rr := httptest.NewRecorder()
+
+			middleware.RequestID(HandlerUpdatePreferences(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, tc.statusCode, rr.Code, rr.Body.String())
+			if tc.statusCode > 399 {
+				assert.Contains(t, rr.Body.String(), tc.errMsg)
+				return
+			}
+
+			var response User
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.Equal(t, tc.expected, response.PreferredUnit)
+			if tc.timezone != "" {
+				assert.Equal(t, tc.timezone, response.Timezone)
+			}
+
+			// the profile reflects the new preference
+			req, err = http.NewRequest("GET", "/test", nil)
+			require.NoError(t, err)
+			req = req.WithContext(util.ContextWithUser(req.Context(), user.ID))
+			rr = httptest.NewRecorder()
+			middleware.RequestID(HandlerGetMe(db, logger)).ServeHTTP(rr, req)
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.Equal(t, tc.expected, response.PreferredUnit)
+		})
+	}
+}
@@ -61,3 +61,25 @@ func Date(value, format string, min, max *time.Time) (time.Time, error) {
 
 	return date, nil
 }
+
+// Order checks that ids is a complete ordering of current: every current id exactly once and nothing else
+func Order(ids, current []int64) error {
+	if len(ids) != len(current) {
+		return fmt.Errorf("expected %d ids, got %d", len(current), len(ids))
+	}
+	seen := make(map[int64]bool, len(current))
+	for _, id := range current {
+		seen[id] = false
+	}
+	for _, id := range ids {
+		listed, ok := seen[id]
+		if !ok {
+			return fmt.Errorf("id %d does not belong to the parent resource", id)
+		}
+		if listed {
+			return fmt.Errorf("id %d is repeated", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
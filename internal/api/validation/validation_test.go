@@ -106,3 +106,57 @@ func TestValidateStringWithOptionalField(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateOrder(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ids      []int64
+		current  []int64
+		hasError bool
+	}{
+		{
+			name:    "same order",
+			ids:     []int64{1, 2, 3},
+			current: []int64{1, 2, 3},
+		},
+		{
+			name:    "reversed order",
+			ids:     []int64{3, 2, 1},
+			current: []int64{1, 2, 3},
+		},
+		{
+			name:    "empty parent",
+			ids:     []int64{},
+			current: []int64{},
+		},
+		{
+			name:     "missing id",
+			ids:      []int64{1, 2},
+			current:  []int64{1, 2, 3},
+			hasError: true,
+		},
+		{
+			name:     "repeated id",
+			ids:      []int64{1, 1, 3},
+			current:  []int64{1, 2, 3},
+			hasError: true,
+		},
+		{
+			name:     "foreign id",
+			ids:      []int64{1, 2, 4},
+			current:  []int64{1, 2, 3},
+			hasError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Order(tc.ids, tc.current)
+			if tc.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -16,6 +16,21 @@ const (
 	MaxRestTimeSeconds          = 3600
 	MaxExerciseLength           = 200
 	MaxDescriptionLength        = 500
+	MinTagLength                = 1
+	MaxTagLength                = 30
+	MaxSessionTags              = 20
+	MaxNotesLength              = 2000
+	MaxLocationLength           = 100
+	MinRPE                      = 1
+	MaxRPE                      = 10
+	MaxRIR                      = 10
+	MinWellnessScore            = 1
+	MaxWellnessScore            = 5
+	MaxBodyweight               = 500
+	MaxCircumference            = 300
+	MinDisplayNameLength        = 3
+	MaxDisplayNameLength        = 30
+	MaxGroupKeyLength           = 10
 )
 
 var (
@@ -12,37 +12,91 @@ import (
 )
 
 const createExercise = `-- name: CreateExercise :one
-INSERT INTO exercises (name, description)
-VALUES ($1, $2)
-RETURNING id, name, description
+INSERT INTO exercises (name, description, muscle_group, bodyweight_ratio, leaderboard)
+VALUES ($1, $2, COALESCE($3, exercise_muscle_group($1)), $4, $5)
+RETURNING id, name, description, muscle_group, bodyweight_ratio, leaderboard
 `
 
 type CreateExerciseParams struct {
-	Name        string
-	Description pgtype.Text
+	Name            string
+	Description     pgtype.Text
+	MuscleGroup     pgtype.Text
+	BodyweightRatio pgtype.Float8
+	Leaderboard     bool
 }
 
+// exercises without a muscle group take the one of their name
 func (q *Queries) CreateExercise(ctx context.Context, arg CreateExerciseParams) (Exercise, error) {
-	row := q.db.QueryRow(ctx, createExercise, arg.Name, arg.Description)
+	row := q.db.QueryRow(ctx, createExercise,
+		arg.Name,
+		arg.Description,
+		arg.MuscleGroup,
+		arg.BodyweightRatio,
+		arg.Leaderboard,
+	)
 	var i Exercise
-	err := row.Scan(&i.ID, &i.Name, &i.Description)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.MuscleGroup,
+		&i.BodyweightRatio,
+		&i.Leaderboard,
+	)
 	return i, err
 }
 
 const getExercise = `-- name: GetExercise :one
-SELECT id, name, description FROM exercises
+SELECT id, name, description, muscle_group, bodyweight_ratio, leaderboard FROM exercises
 WHERE id = $1
 `
 
 func (q *Queries) GetExercise(ctx context.Context, id int32) (Exercise, error) {
 	row := q.db.QueryRow(ctx, getExercise, id)
 	var i Exercise
-	err := row.Scan(&i.ID, &i.Name, &i.Description)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.MuscleGroup,
+		&i.BodyweightRatio,
+		&i.Leaderboard,
+	)
 	return i, err
 }
 
+const getExerciseNamesByIDs = `-- name: GetExerciseNamesByIDs :many
+SELECT id, name FROM exercises
+WHERE id = ANY($1::integer[])
+`
+
+type GetExerciseNamesByIDsRow struct {
+	ID   int32
+	Name string
+}
+
+func (q *Queries) GetExerciseNamesByIDs(ctx context.Context, ids []int32) ([]GetExerciseNamesByIDsRow, error) {
+	rows, err := q.db.Query(ctx, getExerciseNamesByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetExerciseNamesByIDsRow
+	for rows.Next() {
+		var i GetExerciseNamesByIDsRow
+		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getExercises = `-- name: GetExercises :many
-SELECT id, name, description FROM exercises
+SELECT id, name, description, muscle_group, bodyweight_ratio, leaderboard FROM exercises
 `
 
 func (q *Queries) GetExercises(ctx context.Context) ([]Exercise, error) {
@@ -54,7 +108,47 @@ func (q *Queries) GetExercises(ctx context.Context) ([]Exercise, error) {
 	var items []Exercise
 	for rows.Next() {
 		var i Exercise
-		if err := rows.Scan(&i.ID, &i.Name, &i.Description); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.MuscleGroup,
+			&i.BodyweightRatio,
+			&i.Leaderboard,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLeaderboardExercises = `-- name: GetLeaderboardExercises :many
+SELECT id, name, description, muscle_group, bodyweight_ratio, leaderboard FROM exercises
+WHERE leaderboard
+ORDER BY name
+`
+
+func (q *Queries) GetLeaderboardExercises(ctx context.Context) ([]Exercise, error) {
+	rows, err := q.db.Query(ctx, getLeaderboardExercises)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Exercise
+	for rows.Next() {
+		var i Exercise
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.MuscleGroup,
+			&i.BodyweightRatio,
+			&i.Leaderboard,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -13,17 +13,27 @@ import (
 )
 
 const createLog = `-- name: CreateLog :one
-INSERT INTO logs (weight, reps, logs_order, exercise_id, set_id)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, created_at, last_modified_at, weight, reps, logs_order, exercise_id, set_id
+INSERT INTO logs (
+    weight, reps, logs_order, exercise_id, set_id,
+    rpe, rir, tempo, reached_failure, partial_reps, notes, weight_unit
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING id, created_at, last_modified_at, weight, reps, logs_order, exercise_id, set_id, rpe, rir, tempo, reached_failure, partial_reps, notes, weight_unit
 `
 
 type CreateLogParams struct {
-	Weight     pgtype.Float8
-	Reps       int32
-	LogsOrder  int32
-	ExerciseID int32
-	SetID      int64
+	Weight         pgtype.Float8
+	Reps           int32
+	LogsOrder      int32
+	ExerciseID     int32
+	SetID          int64
+	Rpe            pgtype.Float8
+	Rir            pgtype.Int2
+	Tempo          pgtype.Text
+	ReachedFailure bool
+	PartialReps    int16
+	Notes          pgtype.Text
+	WeightUnit     string
 }
 
 func (q *Queries) CreateLog(ctx context.Context, arg CreateLogParams) (Log, error) {
@@ -33,6 +43,13 @@ func (q *Queries) CreateLog(ctx context.Context, arg CreateLogParams) (Log, erro
 		arg.LogsOrder,
 		arg.ExerciseID,
 		arg.SetID,
+		arg.Rpe,
+		arg.Rir,
+		arg.Tempo,
+		arg.ReachedFailure,
+		arg.PartialReps,
+		arg.Notes,
+		arg.WeightUnit,
 	)
 	var i Log
 	err := row.Scan(
@@ -44,6 +61,13 @@ func (q *Queries) CreateLog(ctx context.Context, arg CreateLogParams) (Log, erro
 		&i.LogsOrder,
 		&i.ExerciseID,
 		&i.SetID,
+		&i.Rpe,
+		&i.Rir,
+		&i.Tempo,
+		&i.ReachedFailure,
+		&i.PartialReps,
+		&i.Notes,
+		&i.WeightUnit,
 	)
 	return i, err
 }
@@ -51,7 +75,7 @@ func (q *Queries) CreateLog(ctx context.Context, arg CreateLogParams) (Log, erro
 const deleteLog = `-- name: DeleteLog :one
 DELETE FROM logs
 WHERE id = $1
-RETURNING id, created_at, last_modified_at, weight, reps, logs_order, exercise_id, set_id
+RETURNING id, created_at, last_modified_at, weight, reps, logs_order, exercise_id, set_id, rpe, rir, tempo, reached_failure, partial_reps, notes, weight_unit
 `
 
 func (q *Queries) DeleteLog(ctx context.Context, id int64) (Log, error) {
@@ -66,12 +90,85 @@ func (q *Queries) DeleteLog(ctx context.Context, id int64) (Log, error) {
 		&i.LogsOrder,
 		&i.ExerciseID,
 		&i.SetID,
+		&i.Rpe,
+		&i.Rir,
+		&i.Tempo,
+		&i.ReachedFailure,
+		&i.PartialReps,
+		&i.Notes,
+		&i.WeightUnit,
 	)
 	return i, err
 }
 
+const getLastPerformance = `-- name: GetLastPerformance :many
+WITH last_session AS (
+    SELECT previous.id, previous.date
+    FROM sessions AS this_session
+    JOIN sessions AS previous
+        ON previous.user_id = this_session.user_id
+        AND previous.id <> this_session.id
+        AND (previous.date, COALESCE(previous.start_timestamp, '-infinity'), previous.id)
+            < (this_session.date, COALESCE(this_session.start_timestamp, '-infinity'), this_session.id)
+    JOIN sets ON sets.session_id = previous.id
+    JOIN logs ON logs.set_id = sets.id
+    WHERE this_session.id = $1 AND sets.exercise_id = $2 AND sets.set_type <> 'warm_up'
+    ORDER BY previous.date DESC, previous.start_timestamp DESC NULLS LAST, previous.id DESC
+    LIMIT 1
+)
+SELECT last_session.id AS session_id, last_session.date, logs.weight, logs.reps, logs.rpe, logs.rir
+FROM last_session
+JOIN sets ON sets.session_id = last_session.id
+JOIN logs ON logs.set_id = sets.id
+WHERE sets.exercise_id = $2 AND sets.set_type <> 'warm_up'
+ORDER BY sets.set_order, logs.logs_order
+`
+
+type GetLastPerformanceParams struct {
+	SessionID  uuid.UUID
+	ExerciseID int32
+}
+
+type GetLastPerformanceRow struct {
+	SessionID uuid.UUID
+	Date      pgtype.Date
+	Weight    pgtype.Float8
+	Reps      int32
+	Rpe       pgtype.Float8
+	Rir       pgtype.Int2
+}
+
+// Working logs of the exercise in the latest session of the owner held before the given session,
+// by date, start time and id
+func (q *Queries) GetLastPerformance(ctx context.Context, arg GetLastPerformanceParams) ([]GetLastPerformanceRow, error) {
+	rows, err := q.db.Query(ctx, getLastPerformance, arg.SessionID, arg.ExerciseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLastPerformanceRow
+	for rows.Next() {
+		var i GetLastPerformanceRow
+		if err := rows.Scan(
+			&i.SessionID,
+			&i.Date,
+			&i.Weight,
+			&i.Reps,
+			&i.Rpe,
+			&i.Rir,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getLog = `-- name: GetLog :one
-SELECT id, created_at, last_modified_at, weight, reps, logs_order, exercise_id, set_id FROM logs
+SELECT id, created_at, last_modified_at, weight, reps, logs_order, exercise_id, set_id, rpe, rir, tempo, reached_failure, partial_reps, notes, weight_unit FROM logs
 WHERE id = $1
 `
 
@@ -87,10 +184,44 @@ func (q *Queries) GetLog(ctx context.Context, id int64) (Log, error) {
 		&i.LogsOrder,
 		&i.ExerciseID,
 		&i.SetID,
+		&i.Rpe,
+		&i.Rir,
+		&i.Tempo,
+		&i.ReachedFailure,
+		&i.PartialReps,
+		&i.Notes,
+		&i.WeightUnit,
 	)
 	return i, err
 }
 
+const getLogIDsBySetIDForUpdate = `-- name: GetLogIDsBySetIDForUpdate :many
+SELECT id FROM logs
+WHERE set_id = $1
+ORDER BY logs_order
+FOR UPDATE
+`
+
+func (q *Queries) GetLogIDsBySetIDForUpdate(ctx context.Context, setID int64) ([]int64, error) {
+	rows, err := q.db.Query(ctx, getLogIDsBySetIDForUpdate, setID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getLogOwnerID = `-- name: GetLogOwnerID :one
 SELECT sessions.user_id FROM logs
 JOIN sets
@@ -108,7 +239,7 @@ func (q *Queries) GetLogOwnerID(ctx context.Context, id int64) (uuid.UUID, error
 }
 
 const getLogsBySetID = `-- name: GetLogsBySetID :many
-SELECT id, created_at, last_modified_at, weight, reps, logs_order, exercise_id, set_id FROM logs
+SELECT id, created_at, last_modified_at, weight, reps, logs_order, exercise_id, set_id, rpe, rir, tempo, reached_failure, partial_reps, notes, weight_unit FROM logs
 WHERE set_id = $1
 ORDER BY logs_order ASC
 `
@@ -131,6 +262,13 @@ func (q *Queries) GetLogsBySetID(ctx context.Context, setID int64) ([]Log, error
 			&i.LogsOrder,
 			&i.ExerciseID,
 			&i.SetID,
+			&i.Rpe,
+			&i.Rir,
+			&i.Tempo,
+			&i.ReachedFailure,
+			&i.PartialReps,
+			&i.Notes,
+			&i.WeightUnit,
 		); err != nil {
 			return nil, err
 		}
@@ -143,7 +281,7 @@ func (q *Queries) GetLogsBySetID(ctx context.Context, setID int64) ([]Log, error
 }
 
 const getLogsBySetIDs = `-- name: GetLogsBySetIDs :many
-SELECT id, created_at, last_modified_at, weight, reps, logs_order, exercise_id, set_id FROM logs
+SELECT id, created_at, last_modified_at, weight, reps, logs_order, exercise_id, set_id, rpe, rir, tempo, reached_failure, partial_reps, notes, weight_unit FROM logs
 WHERE set_id = ANY($1::bigint[])
 ORDER BY set_id, logs_order
 `
@@ -166,6 +304,13 @@ func (q *Queries) GetLogsBySetIDs(ctx context.Context, dollar_1 []int64) ([]Log,
 			&i.LogsOrder,
 			&i.ExerciseID,
 			&i.SetID,
+			&i.Rpe,
+			&i.Rir,
+			&i.Tempo,
+			&i.ReachedFailure,
+			&i.PartialReps,
+			&i.Notes,
+			&i.WeightUnit,
 		); err != nil {
 			return nil, err
 		}
@@ -178,20 +323,24 @@ func (q *Queries) GetLogsBySetIDs(ctx context.Context, dollar_1 []int64) ([]Log,
 }
 
 const getLogsByUserID = `-- name: GetLogsByUserID :many
-SELECT sessions.date, logs.id, logs.created_at, logs.last_modified_at, logs.weight, logs.reps, logs.logs_order, logs.exercise_id, logs.set_id
+SELECT sessions.date, logs.id, logs.created_at, logs.last_modified_at, logs.weight, logs.reps, logs.logs_order, logs.exercise_id, logs.set_id, logs.rpe, logs.rir, logs.tempo, logs.reached_failure, logs.partial_reps, logs.notes, logs.weight_unit
 FROM logs
-LEFT JOIN sets ON sets.id = logs.set_id
-LEFT JOIN sessions ON sessions.id = sets.session_id
+JOIN sets ON sets.id = logs.set_id
+JOIN sessions ON sessions.id = sets.session_id
 WHERE sessions.user_id = $1
-ORDER BY sessions.date DESC
-OFFSET $2
-LIMIT $3
+    AND ($2::date IS NULL
+        OR (sessions.date, logs.id) < ($2, $3::bigint))
+ORDER BY sessions.date DESC, logs.id DESC
+OFFSET $4
+LIMIT $5
 `
 
 type GetLogsByUserIDParams struct {
-	UserID uuid.UUID
-	Offset int32
-	Limit  int32
+	UserID     uuid.UUID
+	CursorDate pgtype.Date
+	CursorID   pgtype.Int8
+	PageOffset int32
+	PageLimit  int32
 }
 
 type GetLogsByUserIDRow struct {
@@ -204,10 +353,23 @@ type GetLogsByUserIDRow struct {
 	LogsOrder      int32
 	ExerciseID     int32
 	SetID          int64
+	Rpe            pgtype.Float8
+	Rir            pgtype.Int2
+	Tempo          pgtype.Text
+	ReachedFailure bool
+	PartialReps    int16
+	Notes          pgtype.Text
+	WeightUnit     string
 }
 
 func (q *Queries) GetLogsByUserID(ctx context.Context, arg GetLogsByUserIDParams) ([]GetLogsByUserIDRow, error) {
-	rows, err := q.db.Query(ctx, getLogsByUserID, arg.UserID, arg.Offset, arg.Limit)
+	rows, err := q.db.Query(ctx, getLogsByUserID,
+		arg.UserID,
+		arg.CursorDate,
+		arg.CursorID,
+		arg.PageOffset,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -225,6 +387,13 @@ func (q *Queries) GetLogsByUserID(ctx context.Context, arg GetLogsByUserIDParams
 			&i.LogsOrder,
 			&i.ExerciseID,
 			&i.SetID,
+			&i.Rpe,
+			&i.Rir,
+			&i.Tempo,
+			&i.ReachedFailure,
+			&i.PartialReps,
+			&i.Notes,
+			&i.WeightUnit,
 		); err != nil {
 			return nil, err
 		}
@@ -236,18 +405,52 @@ func (q *Queries) GetLogsByUserID(ctx context.Context, arg GetLogsByUserIDParams
 	return items, nil
 }
 
+const reorderLogs = `-- name: ReorderLogs :exec
+UPDATE logs
+SET logs_order = new_order.position, last_modified_at = timezone('utc', now())
+FROM unnest($1::bigint[]) WITH ORDINALITY AS new_order(id, position)
+WHERE logs.id = new_order.id AND logs.set_id = $2
+`
+
+type ReorderLogsParams struct {
+	Ids   []int64
+	SetID int64
+}
+
+func (q *Queries) ReorderLogs(ctx context.Context, arg ReorderLogsParams) error {
+	_, err := q.db.Exec(ctx, reorderLogs, arg.Ids, arg.SetID)
+	return err
+}
+
 const updateLog = `-- name: UpdateLog :one
 UPDATE logs
-SET weight = $1, reps = $2, logs_order = $3
-WHERE id = $4
-RETURNING id, created_at, last_modified_at, weight, reps, logs_order, exercise_id, set_id
+SET weight = $1,
+    reps = $2,
+    logs_order = $3,
+    rpe = $4,
+    rir = $5,
+    tempo = $6,
+    reached_failure = $7,
+    partial_reps = $8,
+    notes = $9,
+    weight_unit = $10,
+    last_modified_at = timezone('utc', now())
+WHERE id = $11
+RETURNING id, created_at, last_modified_at, weight, reps, logs_order, exercise_id, set_id, rpe, rir, tempo, reached_failure, partial_reps, notes, weight_unit
 `
 
 type UpdateLogParams struct {
-	Weight    pgtype.Float8
-	Reps      int32
-	LogsOrder int32
-	ID        int64
+	Weight         pgtype.Float8
+	Reps           int32
+	LogsOrder      int32
+	Rpe            pgtype.Float8
+	Rir            pgtype.Int2
+	Tempo          pgtype.Text
+	ReachedFailure bool
+	PartialReps    int16
+	Notes          pgtype.Text
+	WeightUnit     string
+	ID             int64
 }
 
 func (q *Queries) UpdateLog(ctx context.Context, arg UpdateLogParams) (Log, error) {
@@ -255,6 +458,13 @@ func (q *Queries) UpdateLog(ctx context.Context, arg UpdateLogParams) (Log, erro
 		arg.Weight,
 		arg.Reps,
 		arg.LogsOrder,
+		arg.Rpe,
+		arg.Rir,
+		arg.Tempo,
+		arg.ReachedFailure,
+		arg.PartialReps,
+		arg.Notes,
+		arg.WeightUnit,
 		arg.ID,
 	)
 	var i Log
@@ -267,13 +477,20 @@ func (q *Queries) UpdateLog(ctx context.Context, arg UpdateLogParams) (Log, erro
 		&i.LogsOrder,
 		&i.ExerciseID,
 		&i.SetID,
+		&i.Rpe,
+		&i.Rir,
+		&i.Tempo,
+		&i.ReachedFailure,
+		&i.PartialReps,
+		&i.Notes,
+		&i.WeightUnit,
 	)
 	return i, err
 }
 
 const updateLogsExerciseIDBySetID = `-- name: UpdateLogsExerciseIDBySetID :exec
 UPDATE logs
-SET exercise_id = $1
+SET exercise_id = $1, last_modified_at = timezone('utc', now())
 WHERE set_id = $2
 `
 
@@ -9,10 +9,77 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type BodyMeasurement struct {
+	ID         int64
+	CreatedAt  pgtype.Timestamp
+	UserID     uuid.UUID
+	Date       pgtype.Date
+	Bodyweight pgtype.Float8
+	BodyFat    pgtype.Float8
+	Neck       pgtype.Float8
+	Chest      pgtype.Float8
+	Waist      pgtype.Float8
+	Hips       pgtype.Float8
+	Arm        pgtype.Float8
+	Thigh      pgtype.Float8
+	Calf       pgtype.Float8
+	Notes      pgtype.Text
+}
+
+type Bodyweight struct {
+	UserID     uuid.UUID
+	Date       pgtype.Date
+	Bodyweight pgtype.Float8
+}
+
+type CalendarToken struct {
+	Token     string
+	CreatedAt pgtype.Timestamp
+	UserID    uuid.UUID
+}
+
 type Exercise struct {
-	ID          int32
-	Name        string
-	Description pgtype.Text
+	ID              int32
+	Name            string
+	Description     pgtype.Text
+	MuscleGroup     pgtype.Text
+	BodyweightRatio pgtype.Float8
+	Leaderboard     bool
+}
+
+type Goal struct {
+	ID         int64
+	CreatedAt  pgtype.Timestamp
+	UserID     uuid.UUID
+	GoalType   string
+	ExerciseID pgtype.Int4
+	Target     float64
+	Baseline   pgtype.Float8
+	StartDate  pgtype.Date
+	TargetDate pgtype.Date
+	Status     string
+	StatusDate pgtype.Date
+	Notes      pgtype.Text
+}
+
+type Insight struct {
+	UserID      uuid.UUID
+	ExerciseID  int32
+	Metric      string
+	Status      string
+	Change      float64
+	Baseline    float64
+	Recent      float64
+	Days        int32
+	Suggestions []string
+}
+
+type InsightRun struct {
+	UserID     uuid.UUID
+	Weeks      int32
+	FromDate   pgtype.Date
+	ToDate     pgtype.Date
+	AnalyzedAt pgtype.Timestamp
 }
 
 type Log struct {
@@ -24,6 +91,24 @@ type Log struct {
 	LogsOrder      int32
 	ExerciseID     int32
 	SetID          int64
+	Rpe            pgtype.Float8
+	Rir            pgtype.Int2
+	Tempo          pgtype.Text
+	ReachedFailure bool
+	PartialReps    int16
+	Notes          pgtype.Text
+	WeightUnit     string
+}
+
+type PersonalRecord struct {
+	ID         int64
+	CreatedAt  pgtype.Timestamp
+	UserID     uuid.UUID
+	ExerciseID int32
+	RecordType string
+	Value      float64
+	Weight     pgtype.Float8
+	LogID      int64
 }
 
 type RefreshToken struct {
@@ -41,6 +126,13 @@ type Session struct {
 	StartTimestamp  pgtype.Timestamp
 	DurationMinutes pgtype.Int2
 	UserID          uuid.UUID
+	Tags            []string
+	Notes           pgtype.Text
+	Rpe             pgtype.Int2
+	SleepQuality    pgtype.Int2
+	Bodyweight      pgtype.Float8
+	Mood            pgtype.Int2
+	Location        pgtype.Text
 }
 
 type Set struct {
@@ -49,14 +141,40 @@ type Set struct {
 	RestTime   pgtype.Int4
 	SessionID  uuid.UUID
 	ExerciseID int32
+	SetType    string
+	GroupKey   pgtype.Text
+}
+
+type SyncClock struct {
+	UserID  uuid.UUID
+	Version int64
+}
+
+type SyncEntity struct {
+	ClientID        uuid.UUID
+	CreatedAt       pgtype.Timestamp
+	UserID          uuid.UUID
+	SessionID       pgtype.UUID
+	SetID           pgtype.Int8
+	LogID           pgtype.Int8
+	Version         int64
+	DeletedAt       pgtype.Timestamp
+	FieldModifiedAt []byte
 }
 
 type User struct {
-	ID             uuid.UUID
-	Username       string
-	HashedPassword string
-	IsAdmin        pgtype.Bool
-	CreatedAt      pgtype.Timestamp
-	Country        pgtype.Text
-	Birthday       pgtype.Date
+	ID                         uuid.UUID
+	Username                   string
+	HashedPassword             string
+	IsAdmin                    pgtype.Bool
+	CreatedAt                  pgtype.Timestamp
+	Country                    pgtype.Text
+	Birthday                   pgtype.Date
+	PreferredUnit              string
+	Timezone                   string
+	DisplayName                pgtype.Text
+	LeaderboardOptIn           bool
+	LeaderboardShareCountry    bool
+	LeaderboardShareAge        bool
+	LeaderboardShareBodyweight bool
 }
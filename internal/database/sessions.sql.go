@@ -13,11 +13,15 @@ import (
 )
 
 const createSession = `-- name: CreateSession :one
-INSERT INTO sessions (name, date, start_timestamp, duration_minutes, user_id)
+INSERT INTO sessions (
+    name, date, start_timestamp, duration_minutes, user_id, tags,
+    notes, rpe, sleep_quality, bodyweight, mood, location
+)
 VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, COALESCE($6::text[], '{}'),
+    $7, $8, $9, $10, $11, $12
 )
-RETURNING id, name, date, start_timestamp, duration_minutes, user_id
+RETURNING id, name, date, start_timestamp, duration_minutes, user_id, tags, notes, rpe, sleep_quality, bodyweight, mood, location
 `
 
 type CreateSessionParams struct {
@@ -26,6 +30,13 @@ type CreateSessionParams struct {
 	StartTimestamp  pgtype.Timestamp
 	DurationMinutes pgtype.Int2
 	UserID          uuid.UUID
+	Tags            []string
+	Notes           pgtype.Text
+	Rpe             pgtype.Int2
+	SleepQuality    pgtype.Int2
+	Bodyweight      pgtype.Float8
+	Mood            pgtype.Int2
+	Location        pgtype.Text
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
@@ -35,6 +46,13 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		arg.StartTimestamp,
 		arg.DurationMinutes,
 		arg.UserID,
+		arg.Tags,
+		arg.Notes,
+		arg.Rpe,
+		arg.SleepQuality,
+		arg.Bodyweight,
+		arg.Mood,
+		arg.Location,
 	)
 	var i Session
 	err := row.Scan(
@@ -44,6 +62,13 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		&i.StartTimestamp,
 		&i.DurationMinutes,
 		&i.UserID,
+		&i.Tags,
+		&i.Notes,
+		&i.Rpe,
+		&i.SleepQuality,
+		&i.Bodyweight,
+		&i.Mood,
+		&i.Location,
 	)
 	return i, err
 }
@@ -51,7 +76,7 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 const deleteSession = `-- name: DeleteSession :one
 DELETE FROM sessions
 WHERE id = $1 and user_id = $2
-RETURNING id, name, date, start_timestamp, duration_minutes, user_id
+RETURNING id, name, date, start_timestamp, duration_minutes, user_id, tags, notes, rpe, sleep_quality, bodyweight, mood, location
 `
 
 type DeleteSessionParams struct {
@@ -69,6 +94,72 @@ func (q *Queries) DeleteSession(ctx context.Context, arg DeleteSessionParams) (S
 		&i.StartTimestamp,
 		&i.DurationMinutes,
 		&i.UserID,
+		&i.Tags,
+		&i.Notes,
+		&i.Rpe,
+		&i.SleepQuality,
+		&i.Bodyweight,
+		&i.Mood,
+		&i.Location,
+	)
+	return i, err
+}
+
+const deleteSessionsByIDs = `-- name: DeleteSessionsByIDs :exec
+DELETE FROM sessions
+WHERE user_id = $1 AND id = ANY($2::uuid[])
+`
+
+type DeleteSessionsByIDsParams struct {
+	UserID uuid.UUID
+	Ids    []uuid.UUID
+}
+
+func (q *Queries) DeleteSessionsByIDs(ctx context.Context, arg DeleteSessionsByIDsParams) error {
+	_, err := q.db.Exec(ctx, deleteSessionsByIDs, arg.UserID, arg.Ids)
+	return err
+}
+
+const deleteSessionsByUserID = `-- name: DeleteSessionsByUserID :exec
+DELETE FROM sessions
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteSessionsByUserID(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteSessionsByUserID, userID)
+	return err
+}
+
+const getLastSessionByUserID = `-- name: GetLastSessionByUserID :one
+SELECT id, name, date, start_timestamp, duration_minutes, user_id, tags, notes, rpe, sleep_quality, bodyweight, mood, location FROM sessions
+WHERE user_id = $1
+    AND ($2::text IS NULL OR lower(name) = lower($2))
+ORDER BY date DESC, start_timestamp DESC NULLS LAST, id DESC
+LIMIT 1
+`
+
+type GetLastSessionByUserIDParams struct {
+	UserID uuid.UUID
+	Name   pgtype.Text
+}
+
+func (q *Queries) GetLastSessionByUserID(ctx context.Context, arg GetLastSessionByUserIDParams) (Session, error) {
+	row := q.db.QueryRow(ctx, getLastSessionByUserID, arg.UserID, arg.Name)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Date,
+		&i.StartTimestamp,
+		&i.DurationMinutes,
+		&i.UserID,
+		&i.Tags,
+		&i.Notes,
+		&i.Rpe,
+		&i.SleepQuality,
+		&i.Bodyweight,
+		&i.Mood,
+		&i.Location,
 	)
 	return i, err
 }
@@ -85,8 +176,44 @@ func (q *Queries) GetNumberSessionsByUserID(ctx context.Context, userID uuid.UUI
 	return count, err
 }
 
+const getNumberSessionsFiltered = `-- name: GetNumberSessionsFiltered :one
+SELECT count(id) FROM sessions
+WHERE user_id = $1
+    AND ($2::date IS NULL OR date >= $2)
+    AND ($3::date IS NULL OR date <= $3)
+    AND ($4::text IS NULL OR name ILIKE '%' || $4 || '%')
+    AND ($5::integer IS NULL OR EXISTS (
+        SELECT 1 FROM sets
+        WHERE sets.session_id = sessions.id AND sets.exercise_id = $5
+    ))
+    AND ($6::text[] IS NULL OR tags @> $6)
+`
+
+type GetNumberSessionsFilteredParams struct {
+	UserID     uuid.UUID
+	FromDate   pgtype.Date
+	ToDate     pgtype.Date
+	Name       pgtype.Text
+	ExerciseID pgtype.Int4
+	Tags       []string
+}
+
+func (q *Queries) GetNumberSessionsFiltered(ctx context.Context, arg GetNumberSessionsFilteredParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getNumberSessionsFiltered,
+		arg.UserID,
+		arg.FromDate,
+		arg.ToDate,
+		arg.Name,
+		arg.ExerciseID,
+		arg.Tags,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const getSession = `-- name: GetSession :one
-SELECT id, name, date, start_timestamp, duration_minutes, user_id FROM sessions
+SELECT id, name, date, start_timestamp, duration_minutes, user_id, tags, notes, rpe, sleep_quality, bodyweight, mood, location FROM sessions
 WHERE id = $1
 `
 
@@ -100,10 +227,53 @@ func (q *Queries) GetSession(ctx context.Context, id uuid.UUID) (Session, error)
 		&i.StartTimestamp,
 		&i.DurationMinutes,
 		&i.UserID,
+		&i.Tags,
+		&i.Notes,
+		&i.Rpe,
+		&i.SleepQuality,
+		&i.Bodyweight,
+		&i.Mood,
+		&i.Location,
 	)
 	return i, err
 }
 
+const getSessionNamesByDates = `-- name: GetSessionNamesByDates :many
+SELECT date, name FROM sessions
+WHERE user_id = $1 AND date >= $2 AND date <= $3
+`
+
+type GetSessionNamesByDatesParams struct {
+	UserID   uuid.UUID
+	FromDate pgtype.Date
+	ToDate   pgtype.Date
+}
+
+type GetSessionNamesByDatesRow struct {
+	Date pgtype.Date
+	Name string
+}
+
+func (q *Queries) GetSessionNamesByDates(ctx context.Context, arg GetSessionNamesByDatesParams) ([]GetSessionNamesByDatesRow, error) {
+	rows, err := q.db.Query(ctx, getSessionNamesByDates, arg.UserID, arg.FromDate, arg.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSessionNamesByDatesRow
+	for rows.Next() {
+		var i GetSessionNamesByDatesRow
+		if err := rows.Scan(&i.Date, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getSessionOwnerID = `-- name: GetSessionOwnerID :one
 SELECT user_id FROM sessions
 WHERE id = $1
@@ -116,8 +286,93 @@ func (q *Queries) GetSessionOwnerID(ctx context.Context, id uuid.UUID) (uuid.UUI
 	return user_id, err
 }
 
+const getSessionsByDates = `-- name: GetSessionsByDates :many
+SELECT id, name, date, start_timestamp, duration_minutes, user_id, tags, notes, rpe, sleep_quality, bodyweight, mood, location FROM sessions
+WHERE user_id = $1 AND date >= $2 AND date <= $3
+ORDER BY date, id
+`
+
+type GetSessionsByDatesParams struct {
+	UserID   uuid.UUID
+	FromDate pgtype.Date
+	ToDate   pgtype.Date
+}
+
+func (q *Queries) GetSessionsByDates(ctx context.Context, arg GetSessionsByDatesParams) ([]Session, error) {
+	rows, err := q.db.Query(ctx, getSessionsByDates, arg.UserID, arg.FromDate, arg.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Session
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Date,
+			&i.StartTimestamp,
+			&i.DurationMinutes,
+			&i.UserID,
+			&i.Tags,
+			&i.Notes,
+			&i.Rpe,
+			&i.SleepQuality,
+			&i.Bodyweight,
+			&i.Mood,
+			&i.Location,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSessionsByIDs = `-- name: GetSessionsByIDs :many
+SELECT id, name, date, start_timestamp, duration_minutes, user_id, tags, notes, rpe, sleep_quality, bodyweight, mood, location FROM sessions
+WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) GetSessionsByIDs(ctx context.Context, ids []uuid.UUID) ([]Session, error) {
+	rows, err := q.db.Query(ctx, getSessionsByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Session
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Date,
+			&i.StartTimestamp,
+			&i.DurationMinutes,
+			&i.UserID,
+			&i.Tags,
+			&i.Notes,
+			&i.Rpe,
+			&i.SleepQuality,
+			&i.Bodyweight,
+			&i.Mood,
+			&i.Location,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getSessionsByUserID = `-- name: GetSessionsByUserID :many
-SELECT id, name, date, start_timestamp, duration_minutes, user_id FROM sessions
+SELECT id, name, date, start_timestamp, duration_minutes, user_id, tags, notes, rpe, sleep_quality, bodyweight, mood, location FROM sessions
 WHERE user_id = $1
 ORDER BY date DESC
 `
@@ -138,6 +393,101 @@ func (q *Queries) GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]
 			&i.StartTimestamp,
 			&i.DurationMinutes,
 			&i.UserID,
+			&i.Tags,
+			&i.Notes,
+			&i.Rpe,
+			&i.SleepQuality,
+			&i.Bodyweight,
+			&i.Mood,
+			&i.Location,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSessionsFiltered = `-- name: GetSessionsFiltered :many
+SELECT id, name, date, start_timestamp, duration_minutes, user_id, tags, notes, rpe, sleep_quality, bodyweight, mood, location FROM sessions
+WHERE user_id = $1
+    AND ($2::date IS NULL OR date >= $2)
+    AND ($3::date IS NULL OR date <= $3)
+    AND ($4::text IS NULL OR name ILIKE '%' || $4 || '%')
+    AND ($5::integer IS NULL OR EXISTS (
+        SELECT 1 FROM sets
+        WHERE sets.session_id = sessions.id AND sets.exercise_id = $5
+    ))
+    AND ($6::text[] IS NULL OR tags @> $6)
+    AND ($7::date IS NULL
+        OR ($8::text = 'date_asc' AND (date, id) > ($7, $9::uuid))
+        OR ($8::text = 'date_desc' AND (date, id) < ($7, $9::uuid)))
+ORDER BY
+    CASE WHEN $8::text = 'date_asc' THEN date END ASC,
+    CASE WHEN $8::text = 'date_asc' THEN id END ASC,
+    CASE WHEN $8::text = 'date_desc' THEN date END DESC,
+    CASE WHEN $8::text = 'name_asc' THEN name END ASC,
+    CASE WHEN $8::text = 'name_desc' THEN name END DESC,
+    CASE WHEN $8::text = 'duration_asc' THEN duration_minutes END ASC,
+    CASE WHEN $8::text = 'duration_desc' THEN duration_minutes END DESC,
+    date DESC,
+    id DESC
+OFFSET $10
+LIMIT $11
+`
+
+type GetSessionsFilteredParams struct {
+	UserID     uuid.UUID
+	FromDate   pgtype.Date
+	ToDate     pgtype.Date
+	Name       pgtype.Text
+	ExerciseID pgtype.Int4
+	Tags       []string
+	CursorDate pgtype.Date
+	Sort       string
+	CursorID   pgtype.UUID
+	PageOffset int32
+	PageLimit  int32
+}
+
+func (q *Queries) GetSessionsFiltered(ctx context.Context, arg GetSessionsFilteredParams) ([]Session, error) {
+	rows, err := q.db.Query(ctx, getSessionsFiltered,
+		arg.UserID,
+		arg.FromDate,
+		arg.ToDate,
+		arg.Name,
+		arg.ExerciseID,
+		arg.Tags,
+		arg.CursorDate,
+		arg.Sort,
+		arg.CursorID,
+		arg.PageOffset,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Session
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Date,
+			&i.StartTimestamp,
+			&i.DurationMinutes,
+			&i.UserID,
+			&i.Tags,
+			&i.Notes,
+			&i.Rpe,
+			&i.SleepQuality,
+			&i.Bodyweight,
+			&i.Mood,
+			&i.Location,
 		); err != nil {
 			return nil, err
 		}
@@ -150,7 +500,7 @@ func (q *Queries) GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]
 }
 
 const getSessionsPaginated = `-- name: GetSessionsPaginated :many
-SELECT id, name, date, start_timestamp, duration_minutes, user_id FROM sessions
+SELECT id, name, date, start_timestamp, duration_minutes, user_id, tags, notes, rpe, sleep_quality, bodyweight, mood, location FROM sessions
 WHERE user_id = $1
 ORDER BY date DESC
 OFFSET $2
@@ -179,6 +529,13 @@ func (q *Queries) GetSessionsPaginated(ctx context.Context, arg GetSessionsPagin
 			&i.StartTimestamp,
 			&i.DurationMinutes,
 			&i.UserID,
+			&i.Tags,
+			&i.Notes,
+			&i.Rpe,
+			&i.SleepQuality,
+			&i.Bodyweight,
+			&i.Mood,
+			&i.Location,
 		); err != nil {
 			return nil, err
 		}
@@ -190,14 +547,86 @@ func (q *Queries) GetSessionsPaginated(ctx context.Context, arg GetSessionsPagin
 	return items, nil
 }
 
+const restoreSession = `-- name: RestoreSession :one
+INSERT INTO sessions (
+    id, name, date, start_timestamp, duration_minutes, user_id, tags,
+    notes, rpe, sleep_quality, bodyweight, mood, location
+)
+VALUES (
+    $1, $2, $3, $4, $5, $6, COALESCE($7::text[], '{}'),
+    $8, $9, $10, $11, $12, $13
+)
+ON CONFLICT (id) DO NOTHING
+RETURNING id, name, date, start_timestamp, duration_minutes, user_id, tags, notes, rpe, sleep_quality, bodyweight, mood, location
+`
+
+type RestoreSessionParams struct {
+	ID              uuid.UUID
+	Name            string
+	Date            pgtype.Date
+	StartTimestamp  pgtype.Timestamp
+	DurationMinutes pgtype.Int2
+	UserID          uuid.UUID
+	Tags            []string
+	Notes           pgtype.Text
+	Rpe             pgtype.Int2
+	SleepQuality    pgtype.Int2
+	Bodyweight      pgtype.Float8
+	Mood            pgtype.Int2
+	Location        pgtype.Text
+}
+
+// restored sessions keep their id, nothing is returned when the id is taken
+func (q *Queries) RestoreSession(ctx context.Context, arg RestoreSessionParams) (Session, error) {
+	row := q.db.QueryRow(ctx, restoreSession,
+		arg.ID,
+		arg.Name,
+		arg.Date,
+		arg.StartTimestamp,
+		arg.DurationMinutes,
+		arg.UserID,
+		arg.Tags,
+		arg.Notes,
+		arg.Rpe,
+		arg.SleepQuality,
+		arg.Bodyweight,
+		arg.Mood,
+		arg.Location,
+	)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Date,
+		&i.StartTimestamp,
+		&i.DurationMinutes,
+		&i.UserID,
+		&i.Tags,
+		&i.Notes,
+		&i.Rpe,
+		&i.SleepQuality,
+		&i.Bodyweight,
+		&i.Mood,
+		&i.Location,
+	)
+	return i, err
+}
+
 const updateSession = `-- name: UpdateSession :one
 UPDATE sessions
 SET name = $1,
     date = $2,
     start_timestamp = $3,
-    duration_minutes = $4
-WHERE id = $5
-RETURNING id, name, date, start_timestamp, duration_minutes, user_id
+    duration_minutes = $4,
+    tags = COALESCE($5::text[], '{}'),
+    notes = $6,
+    rpe = $7,
+    sleep_quality = $8,
+    bodyweight = $9,
+    mood = $10,
+    location = $11
+WHERE id = $12
+RETURNING id, name, date, start_timestamp, duration_minutes, user_id, tags, notes, rpe, sleep_quality, bodyweight, mood, location
 `
 
 type UpdateSessionParams struct {
@@ -205,6 +634,13 @@ type UpdateSessionParams struct {
 	Date            pgtype.Date
 	StartTimestamp  pgtype.Timestamp
 	DurationMinutes pgtype.Int2
+	Tags            []string
+	Notes           pgtype.Text
+	Rpe             pgtype.Int2
+	SleepQuality    pgtype.Int2
+	Bodyweight      pgtype.Float8
+	Mood            pgtype.Int2
+	Location        pgtype.Text
 	ID              uuid.UUID
 }
 
@@ -214,6 +650,13 @@ func (q *Queries) UpdateSession(ctx context.Context, arg UpdateSessionParams) (S
 		arg.Date,
 		arg.StartTimestamp,
 		arg.DurationMinutes,
+		arg.Tags,
+		arg.Notes,
+		arg.Rpe,
+		arg.SleepQuality,
+		arg.Bodyweight,
+		arg.Mood,
+		arg.Location,
 		arg.ID,
 	)
 	var i Session
@@ -224,6 +667,13 @@ func (q *Queries) UpdateSession(ctx context.Context, arg UpdateSessionParams) (S
 		&i.StartTimestamp,
 		&i.DurationMinutes,
 		&i.UserID,
+		&i.Tags,
+		&i.Notes,
+		&i.Rpe,
+		&i.SleepQuality,
+		&i.Bodyweight,
+		&i.Mood,
+		&i.Location,
 	)
 	return i, err
 }
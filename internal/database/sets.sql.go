@@ -13,9 +13,9 @@ import (
 )
 
 const createSet = `-- name: CreateSet :one
-INSERT INTO sets (set_order, rest_time, session_id, exercise_id)
-VALUES ($1, $2, $3, $4)
-RETURNING id, set_order, rest_time, session_id, exercise_id
+INSERT INTO sets (set_order, rest_time, session_id, exercise_id, set_type, group_key)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, set_order, rest_time, session_id, exercise_id, set_type, group_key
 `
 
 type CreateSetParams struct {
@@ -23,6 +23,8 @@ type CreateSetParams struct {
 	RestTime   pgtype.Int4
 	SessionID  uuid.UUID
 	ExerciseID int32
+	SetType    string
+	GroupKey   pgtype.Text
 }
 
 func (q *Queries) CreateSet(ctx context.Context, arg CreateSetParams) (Set, error) {
@@ -31,6 +33,8 @@ func (q *Queries) CreateSet(ctx context.Context, arg CreateSetParams) (Set, erro
 		arg.RestTime,
 		arg.SessionID,
 		arg.ExerciseID,
+		arg.SetType,
+		arg.GroupKey,
 	)
 	var i Set
 	err := row.Scan(
@@ -39,6 +43,8 @@ func (q *Queries) CreateSet(ctx context.Context, arg CreateSetParams) (Set, erro
 		&i.RestTime,
 		&i.SessionID,
 		&i.ExerciseID,
+		&i.SetType,
+		&i.GroupKey,
 	)
 	return i, err
 }
@@ -46,7 +52,7 @@ func (q *Queries) CreateSet(ctx context.Context, arg CreateSetParams) (Set, erro
 const deleteSet = `-- name: DeleteSet :one
 DELETE FROM sets
 WHERE id = $1
-RETURNING id, set_order, rest_time, session_id, exercise_id
+RETURNING id, set_order, rest_time, session_id, exercise_id, set_type, group_key
 `
 
 func (q *Queries) DeleteSet(ctx context.Context, id int64) (Set, error) {
@@ -58,12 +64,14 @@ func (q *Queries) DeleteSet(ctx context.Context, id int64) (Set, error) {
 		&i.RestTime,
 		&i.SessionID,
 		&i.ExerciseID,
+		&i.SetType,
+		&i.GroupKey,
 	)
 	return i, err
 }
 
 const getSet = `-- name: GetSet :one
-SELECT id, set_order, rest_time, session_id, exercise_id FROM sets
+SELECT id, set_order, rest_time, session_id, exercise_id, set_type, group_key FROM sets
 WHERE id = $1
 `
 
@@ -76,10 +84,67 @@ func (q *Queries) GetSet(ctx context.Context, id int64) (Set, error) {
 		&i.RestTime,
 		&i.SessionID,
 		&i.ExerciseID,
+		&i.SetType,
+		&i.GroupKey,
 	)
 	return i, err
 }
 
+const getSetBySessionAndUser = `-- name: GetSetBySessionAndUser :one
+SELECT sets.id, sets.set_order, sets.rest_time, sets.session_id, sets.exercise_id, sets.set_type, sets.group_key FROM sets
+JOIN sessions
+ON sets.session_id = sessions.id
+WHERE sets.id = $1 AND sets.session_id = $2 AND sessions.user_id = $3
+`
+
+type GetSetBySessionAndUserParams struct {
+	ID        int64
+	SessionID uuid.UUID
+	UserID    uuid.UUID
+}
+
+func (q *Queries) GetSetBySessionAndUser(ctx context.Context, arg GetSetBySessionAndUserParams) (Set, error) {
+	row := q.db.QueryRow(ctx, getSetBySessionAndUser, arg.ID, arg.SessionID, arg.UserID)
+	var i Set
+	err := row.Scan(
+		&i.ID,
+		&i.SetOrder,
+		&i.RestTime,
+		&i.SessionID,
+		&i.ExerciseID,
+		&i.SetType,
+		&i.GroupKey,
+	)
+	return i, err
+}
+
+const getSetIDsBySessionIDForUpdate = `-- name: GetSetIDsBySessionIDForUpdate :many
+SELECT id FROM sets
+WHERE session_id = $1
+ORDER BY set_order
+FOR UPDATE
+`
+
+func (q *Queries) GetSetIDsBySessionIDForUpdate(ctx context.Context, sessionID uuid.UUID) ([]int64, error) {
+	rows, err := q.db.Query(ctx, getSetIDsBySessionIDForUpdate, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getSetOwnerID = `-- name: GetSetOwnerID :one
 SELECT sessions.user_id FROM sets
 JOIN sessions
@@ -94,8 +159,41 @@ func (q *Queries) GetSetOwnerID(ctx context.Context, id int64) (uuid.UUID, error
 	return user_id, err
 }
 
+const getSetsByIDs = `-- name: GetSetsByIDs :many
+SELECT id, set_order, rest_time, session_id, exercise_id, set_type, group_key FROM sets
+WHERE id = ANY($1::bigint[])
+`
+
+func (q *Queries) GetSetsByIDs(ctx context.Context, ids []int64) ([]Set, error) {
+	rows, err := q.db.Query(ctx, getSetsByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Set
+	for rows.Next() {
+		var i Set
+		if err := rows.Scan(
+			&i.ID,
+			&i.SetOrder,
+			&i.RestTime,
+			&i.SessionID,
+			&i.ExerciseID,
+			&i.SetType,
+			&i.GroupKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getSetsBySessionIDs = `-- name: GetSetsBySessionIDs :many
-SELECT id, set_order, rest_time, session_id, exercise_id FROM sets
+SELECT id, set_order, rest_time, session_id, exercise_id, set_type, group_key FROM sets
 WHERE session_id = ANY($1::uuid[])
 ORDER BY session_id, set_order
 `
@@ -115,6 +213,8 @@ func (q *Queries) GetSetsBySessionIDs(ctx context.Context, dollar_1 []uuid.UUID)
 			&i.RestTime,
 			&i.SessionID,
 			&i.ExerciseID,
+			&i.SetType,
+			&i.GroupKey,
 		); err != nil {
 			return nil, err
 		}
@@ -126,20 +226,41 @@ func (q *Queries) GetSetsBySessionIDs(ctx context.Context, dollar_1 []uuid.UUID)
 	return items, nil
 }
 
+const reorderSets = `-- name: ReorderSets :exec
+UPDATE sets
+SET set_order = new_order.position
+FROM unnest($1::bigint[]) WITH ORDINALITY AS new_order(id, position)
+WHERE sets.id = new_order.id AND sets.session_id = $2
+`
+
+type ReorderSetsParams struct {
+	Ids       []int64
+	SessionID uuid.UUID
+}
+
+func (q *Queries) ReorderSets(ctx context.Context, arg ReorderSetsParams) error {
+	_, err := q.db.Exec(ctx, reorderSets, arg.Ids, arg.SessionID)
+	return err
+}
+
 const updateSet = `-- name: UpdateSet :one
 UPDATE sets
 SET
     set_order = $1,
     rest_time = $2,
-    exercise_id = $3
-WHERE id = $4
-RETURNING id, set_order, rest_time, session_id, exercise_id
+    exercise_id = $3,
+    set_type = $4,
+    group_key = $5
+WHERE id = $6
+RETURNING id, set_order, rest_time, session_id, exercise_id, set_type, group_key
 `
 
 type UpdateSetParams struct {
 	SetOrder   int32
 	RestTime   pgtype.Int4
 	ExerciseID int32
+	SetType    string
+	GroupKey   pgtype.Text
 	ID         int64
 }
 
@@ -148,6 +269,8 @@ func (q *Queries) UpdateSet(ctx context.Context, arg UpdateSetParams) (Set, erro
 		arg.SetOrder,
 		arg.RestTime,
 		arg.ExerciseID,
+		arg.SetType,
+		arg.GroupKey,
 		arg.ID,
 	)
 	var i Set
@@ -157,6 +280,25 @@ func (q *Queries) UpdateSet(ctx context.Context, arg UpdateSetParams) (Set, erro
 		&i.RestTime,
 		&i.SessionID,
 		&i.ExerciseID,
+		&i.SetType,
+		&i.GroupKey,
 	)
 	return i, err
 }
+
+const updateSetsRestTimeByGroup = `-- name: UpdateSetsRestTimeByGroup :exec
+UPDATE sets
+SET rest_time = $1
+WHERE session_id = $2 AND group_key = $3
+`
+
+type UpdateSetsRestTimeByGroupParams struct {
+	RestTime  pgtype.Int4
+	SessionID uuid.UUID
+	GroupKey  pgtype.Text
+}
+
+func (q *Queries) UpdateSetsRestTimeByGroup(ctx context.Context, arg UpdateSetsRestTimeByGroupParams) error {
+	_, err := q.db.Exec(ctx, updateSetsRestTimeByGroup, arg.RestTime, arg.SessionID, arg.GroupKey)
+	return err
+}
@@ -15,7 +15,7 @@ import (
 const createAdmin = `-- name: CreateAdmin :one
 INSERT INTO users (id, username, is_admin, country, hashed_password, birthday)
 VALUES (gen_random_uuid(), $1, TRUE, $2, $3, $4)
-RETURNING id, username, hashed_password, is_admin, created_at, country, birthday
+RETURNING id, username, hashed_password, is_admin, created_at, country, birthday, preferred_unit, timezone, display_name, leaderboard_opt_in, leaderboard_share_country, leaderboard_share_age, leaderboard_share_bodyweight
 `
 
 type CreateAdminParams struct {
@@ -41,6 +41,13 @@ func (q *Queries) CreateAdmin(ctx context.Context, arg CreateAdminParams) (User,
 		&i.CreatedAt,
 		&i.Country,
 		&i.Birthday,
+		&i.PreferredUnit,
+		&i.Timezone,
+		&i.DisplayName,
+		&i.LeaderboardOptIn,
+		&i.LeaderboardShareCountry,
+		&i.LeaderboardShareAge,
+		&i.LeaderboardShareBodyweight,
 	)
 	return i, err
 }
@@ -50,7 +57,7 @@ INSERT INTO users (id, username, country, hashed_password, birthday)
 VALUES (
     gen_random_uuid(), $1, $2, $3, $4
 )
-RETURNING id, username, hashed_password, is_admin, created_at, country, birthday
+RETURNING id, username, hashed_password, is_admin, created_at, country, birthday, preferred_unit, timezone, display_name, leaderboard_opt_in, leaderboard_share_country, leaderboard_share_age, leaderboard_share_bodyweight
 `
 
 type CreateUserParams struct {
@@ -76,6 +83,13 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.Country,
 		&i.Birthday,
+		&i.PreferredUnit,
+		&i.Timezone,
+		&i.DisplayName,
+		&i.LeaderboardOptIn,
+		&i.LeaderboardShareCountry,
+		&i.LeaderboardShareAge,
+		&i.LeaderboardShareBodyweight,
 	)
 	return i, err
 }
@@ -83,7 +97,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 const deleteUser = `-- name: DeleteUser :one
 DELETE FROM users
 WHERE id = $1
-RETURNING id, username, hashed_password, is_admin, created_at, country, birthday
+RETURNING id, username, hashed_password, is_admin, created_at, country, birthday, preferred_unit, timezone, display_name, leaderboard_opt_in, leaderboard_share_country, leaderboard_share_age, leaderboard_share_bodyweight
 `
 
 func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) (User, error) {
@@ -97,12 +111,19 @@ func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.CreatedAt,
 		&i.Country,
 		&i.Birthday,
+		&i.PreferredUnit,
+		&i.Timezone,
+		&i.DisplayName,
+		&i.LeaderboardOptIn,
+		&i.LeaderboardShareCountry,
+		&i.LeaderboardShareAge,
+		&i.LeaderboardShareBodyweight,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, username, hashed_password, is_admin, created_at, country, birthday FROM users
+SELECT id, username, hashed_password, is_admin, created_at, country, birthday, preferred_unit, timezone, display_name, leaderboard_opt_in, leaderboard_share_country, leaderboard_share_age, leaderboard_share_bodyweight FROM users
 WHERE id = $1
 `
 
@@ -117,12 +138,19 @@ func (q *Queries) GetUser(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.CreatedAt,
 		&i.Country,
 		&i.Birthday,
+		&i.PreferredUnit,
+		&i.Timezone,
+		&i.DisplayName,
+		&i.LeaderboardOptIn,
+		&i.LeaderboardShareCountry,
+		&i.LeaderboardShareAge,
+		&i.LeaderboardShareBodyweight,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, hashed_password, is_admin, created_at, country, birthday FROM users
+SELECT id, username, hashed_password, is_admin, created_at, country, birthday, preferred_unit, timezone, display_name, leaderboard_opt_in, leaderboard_share_country, leaderboard_share_age, leaderboard_share_bodyweight FROM users
 WHERE username = $1
 `
 
@@ -137,12 +165,43 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.CreatedAt,
 		&i.Country,
 		&i.Birthday,
+		&i.PreferredUnit,
+		&i.Timezone,
+		&i.DisplayName,
+		&i.LeaderboardOptIn,
+		&i.LeaderboardShareCountry,
+		&i.LeaderboardShareAge,
+		&i.LeaderboardShareBodyweight,
 	)
 	return i, err
 }
 
+const getUserPreferredUnit = `-- name: GetUserPreferredUnit :one
+SELECT preferred_unit FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserPreferredUnit(ctx context.Context, id uuid.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, getUserPreferredUnit, id)
+	var preferred_unit string
+	err := row.Scan(&preferred_unit)
+	return preferred_unit, err
+}
+
+const getUserTimezone = `-- name: GetUserTimezone :one
+SELECT timezone FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserTimezone(ctx context.Context, id uuid.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, getUserTimezone, id)
+	var timezone string
+	err := row.Scan(&timezone)
+	return timezone, err
+}
+
 const getUsers = `-- name: GetUsers :many
-SELECT id, username, hashed_password, is_admin, created_at, country, birthday FROM users
+SELECT id, username, hashed_password, is_admin, created_at, country, birthday, preferred_unit, timezone, display_name, leaderboard_opt_in, leaderboard_share_country, leaderboard_share_age, leaderboard_share_bodyweight FROM users
 `
 
 func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
@@ -162,6 +221,13 @@ func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
 			&i.CreatedAt,
 			&i.Country,
 			&i.Birthday,
+			&i.PreferredUnit,
+			&i.Timezone,
+			&i.DisplayName,
+			&i.LeaderboardOptIn,
+			&i.LeaderboardShareCountry,
+			&i.LeaderboardShareAge,
+			&i.LeaderboardShareBodyweight,
 		); err != nil {
 			return nil, err
 		}
@@ -172,3 +238,151 @@ func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
 	}
 	return items, nil
 }
+
+const restoreUserProfile = `-- name: RestoreUserProfile :one
+UPDATE users
+SET country = $1,
+    birthday = $2,
+    preferred_unit = $3,
+    timezone = $4,
+    display_name = $5,
+    leaderboard_opt_in = $6,
+    leaderboard_share_country = $7,
+    leaderboard_share_age = $8,
+    leaderboard_share_bodyweight = $9
+WHERE id = $10
+RETURNING id, username, hashed_password, is_admin, created_at, country, birthday, preferred_unit, timezone, display_name, leaderboard_opt_in, leaderboard_share_country, leaderboard_share_age, leaderboard_share_bodyweight
+`
+
+type RestoreUserProfileParams struct {
+	Country                    pgtype.Text
+	Birthday                   pgtype.Date
+	PreferredUnit              string
+	Timezone                   string
+	DisplayName                pgtype.Text
+	LeaderboardOptIn           bool
+	LeaderboardShareCountry    bool
+	LeaderboardShareAge        bool
+	LeaderboardShareBodyweight bool
+	ID                         uuid.UUID
+}
+
+func (q *Queries) RestoreUserProfile(ctx context.Context, arg RestoreUserProfileParams) (User, error) {
+	row := q.db.QueryRow(ctx, restoreUserProfile,
+		arg.Country,
+		arg.Birthday,
+		arg.PreferredUnit,
+		arg.Timezone,
+		arg.DisplayName,
+		arg.LeaderboardOptIn,
+		arg.LeaderboardShareCountry,
+		arg.LeaderboardShareAge,
+		arg.LeaderboardShareBodyweight,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.IsAdmin,
+		&i.CreatedAt,
+		&i.Country,
+		&i.Birthday,
+		&i.PreferredUnit,
+		&i.Timezone,
+		&i.DisplayName,
+		&i.LeaderboardOptIn,
+		&i.LeaderboardShareCountry,
+		&i.LeaderboardShareAge,
+		&i.LeaderboardShareBodyweight,
+	)
+	return i, err
+}
+
+const updateUserLeaderboard = `-- name: UpdateUserLeaderboard :one
+UPDATE users
+SET leaderboard_opt_in = COALESCE($1, leaderboard_opt_in),
+    display_name = CASE WHEN $2::text = '' THEN NULL ELSE COALESCE($2, display_name) END,
+    leaderboard_share_country = COALESCE($3, leaderboard_share_country),
+    leaderboard_share_age = COALESCE($4, leaderboard_share_age),
+    leaderboard_share_bodyweight = COALESCE($5, leaderboard_share_bodyweight)
+WHERE id = $6
+RETURNING id, username, hashed_password, is_admin, created_at, country, birthday, preferred_unit, timezone, display_name, leaderboard_opt_in, leaderboard_share_country, leaderboard_share_age, leaderboard_share_bodyweight
+`
+
+type UpdateUserLeaderboardParams struct {
+	LeaderboardOptIn           pgtype.Bool
+	DisplayName                pgtype.Text
+	LeaderboardShareCountry    pgtype.Bool
+	LeaderboardShareAge        pgtype.Bool
+	LeaderboardShareBodyweight pgtype.Bool
+	ID                         uuid.UUID
+}
+
+// settings not provided are kept, an empty display name clears it
+func (q *Queries) UpdateUserLeaderboard(ctx context.Context, arg UpdateUserLeaderboardParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserLeaderboard,
+		arg.LeaderboardOptIn,
+		arg.DisplayName,
+		arg.LeaderboardShareCountry,
+		arg.LeaderboardShareAge,
+		arg.LeaderboardShareBodyweight,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.IsAdmin,
+		&i.CreatedAt,
+		&i.Country,
+		&i.Birthday,
+		&i.PreferredUnit,
+		&i.Timezone,
+		&i.DisplayName,
+		&i.LeaderboardOptIn,
+		&i.LeaderboardShareCountry,
+		&i.LeaderboardShareAge,
+		&i.LeaderboardShareBodyweight,
+	)
+	return i, err
+}
+
+const updateUserPreferences = `-- name: UpdateUserPreferences :one
+UPDATE users
+SET preferred_unit = COALESCE($1, preferred_unit),
+    timezone = COALESCE($2, timezone)
+WHERE id = $3
+RETURNING id, username, hashed_password, is_admin, created_at, country, birthday, preferred_unit, timezone, display_name, leaderboard_opt_in, leaderboard_share_country, leaderboard_share_age, leaderboard_share_bodyweight
+`
+
+type UpdateUserPreferencesParams struct {
+	PreferredUnit pgtype.Text
+	Timezone      pgtype.Text
+	ID            uuid.UUID
+}
+
+// preferences not provided are kept
+func (q *Queries) UpdateUserPreferences(ctx context.Context, arg UpdateUserPreferencesParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserPreferences, arg.PreferredUnit, arg.Timezone, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.IsAdmin,
+		&i.CreatedAt,
+		&i.Country,
+		&i.Birthday,
+		&i.PreferredUnit,
+		&i.Timezone,
+		&i.DisplayName,
+		&i.LeaderboardOptIn,
+		&i.LeaderboardShareCountry,
+		&i.LeaderboardShareAge,
+		&i.LeaderboardShareBodyweight,
+	)
+	return i, err
+}
@@ -0,0 +1,86 @@
+-- name: CreateBodyMeasurement :one
+INSERT INTO body_measurements (user_id, date, bodyweight, body_fat, neck, chest, waist, hips, arm, thigh, calf, notes)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING *;
+
+-- name: GetBodyMeasurement :one
+SELECT * FROM body_measurements
+WHERE id = $1;
+
+-- name: GetBodyMeasurements :many
+SELECT * FROM body_measurements
+WHERE user_id = @user_id
+    AND date BETWEEN @from_date AND @to_date
+ORDER BY date;
+
+-- name: GetBodyMeasurementOwnerID :one
+SELECT user_id FROM body_measurements
+WHERE id = $1;
+
+-- name: UpdateBodyMeasurement :one
+UPDATE body_measurements
+SET
+    date = $1,
+    bodyweight = $2,
+    body_fat = $3,
+    neck = $4,
+    chest = $5,
+    waist = $6,
+    hips = $7,
+    arm = $8,
+    thigh = $9,
+    calf = $10,
+    notes = $11
+WHERE id = $12
+RETURNING *;
+
+-- name: DeleteBodyMeasurement :one
+DELETE FROM body_measurements
+WHERE id = $1
+RETURNING *;
+
+-- name: GetBodyweights :many
+SELECT date, bodyweight::float FROM bodyweights
+WHERE user_id = $1
+ORDER BY date;
+
+-- name: GetDailyBodyweight :many
+SELECT date, bodyweight::float FROM bodyweights
+WHERE user_id = @user_id
+    AND date BETWEEN @from_date AND @to_date
+ORDER BY date;
+
+-- name: GetLatestBodyweight :one
+-- last bodyweight reading of the user up to the date
+SELECT bodyweight::float FROM bodyweights
+WHERE user_id = @user_id
+    AND date <= @date
+ORDER BY date DESC
+LIMIT 1;
+
+-- name: GetBodyMeasurementsByUserID :many
+SELECT * FROM body_measurements
+WHERE user_id = $1
+ORDER BY date;
+
+-- name: UpsertBodyMeasurement :one
+-- measurements are unique per day, the ones of an existing day are replaced
+INSERT INTO body_measurements (user_id, date, bodyweight, body_fat, neck, chest, waist, hips, arm, thigh, calf, notes)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (user_id, date) DO UPDATE
+SET
+    bodyweight = EXCLUDED.bodyweight,
+    body_fat = EXCLUDED.body_fat,
+    neck = EXCLUDED.neck,
+    chest = EXCLUDED.chest,
+    waist = EXCLUDED.waist,
+    hips = EXCLUDED.hips,
+    arm = EXCLUDED.arm,
+    thigh = EXCLUDED.thigh,
+    calf = EXCLUDED.calf,
+    notes = EXCLUDED.notes
+RETURNING *;
+
+-- name: DeleteBodyMeasurementsByUserID :exec
+DELETE FROM body_measurements
+WHERE user_id = $1;
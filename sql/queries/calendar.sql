@@ -0,0 +1,34 @@
+-- name: DeleteCalendarToken :one
+DELETE FROM calendar_tokens
+WHERE user_id = $1
+RETURNING *;
+
+-- name: GetCalendarEvents :many
+-- one row per exercise of each session, sessions without sets get a single row without exercise
+SELECT
+    sessions.id, sessions.name, sessions.date, sessions.start_timestamp, sessions.duration_minutes,
+    sessions.notes, sessions.location, exercises.name AS exercise_name, COUNT(sets.id) AS set_count
+FROM sessions
+LEFT JOIN sets ON sets.session_id = sessions.id
+LEFT JOIN exercises ON exercises.id = sets.exercise_id
+WHERE sessions.user_id = @user_id AND sessions.date >= @from_date
+GROUP BY sessions.id, exercises.id
+ORDER BY sessions.date, sessions.start_timestamp, sessions.id, MIN(sets.set_order);
+
+-- name: GetCalendarToken :one
+SELECT *
+FROM calendar_tokens
+WHERE user_id = $1;
+
+-- name: GetCalendarTokenUserID :one
+SELECT user_id
+FROM calendar_tokens
+WHERE token = $1;
+
+-- name: UpsertCalendarToken :one
+-- the previous token of the user is replaced
+INSERT INTO calendar_tokens (token, user_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET token = EXCLUDED.token, created_at = timezone('utc', now())
+RETURNING *;
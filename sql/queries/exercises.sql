@@ -1,11 +1,21 @@
 -- name: CreateExercise :one
-INSERT INTO exercises (name, description)
-VALUES ($1, $2)
+-- exercises without a muscle group take the one of their name
+INSERT INTO exercises (name, description, muscle_group, bodyweight_ratio, leaderboard)
+VALUES ($1, $2, COALESCE($3, exercise_muscle_group($1)), $4, $5)
 RETURNING *;
 
 -- name: GetExercise :one
 SELECT * FROM exercises
 WHERE id = $1;
 
+-- name: GetExerciseNamesByIDs :many
+SELECT id, name FROM exercises
+WHERE id = ANY(@ids::integer[]);
+
 -- name: GetExercises :many
 SELECT * FROM exercises;
+
+-- name: GetLeaderboardExercises :many
+SELECT * FROM exercises
+WHERE leaderboard
+ORDER BY name;
@@ -0,0 +1,27 @@
+-- name: GetExportRows :many
+-- logs of the user in the order they are exported, resumed after the last exported log when given
+SELECT
+    sessions.id AS session_id,
+    sessions.date,
+    sessions.name AS session_name,
+    sets.set_order,
+    sets.set_type,
+    sets.rest_time,
+    exercises.name AS exercise_name,
+    logs.logs_order,
+    logs.weight,
+    logs.reps,
+    logs.rpe,
+    logs.rir
+FROM logs
+JOIN sets ON sets.id = logs.set_id
+JOIN sessions ON sessions.id = sets.session_id
+JOIN exercises ON exercises.id = logs.exercise_id
+WHERE sessions.user_id = @user_id
+    AND (sqlc.narg('from_date')::date IS NULL OR sessions.date >= sqlc.narg('from_date'))
+    AND (sqlc.narg('to_date')::date IS NULL OR sessions.date <= sqlc.narg('to_date'))
+    AND (sqlc.narg('after_date')::date IS NULL
+        OR (sessions.date, sessions.id, sets.set_order, logs.logs_order)
+            > (sqlc.narg('after_date'), @after_session_id::uuid, @after_set_order::integer, @after_logs_order::integer))
+ORDER BY sessions.date, sessions.id, sets.set_order, logs.logs_order
+LIMIT @row_limit;
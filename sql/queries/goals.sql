@@ -0,0 +1,72 @@
+-- name: CreateGoal :one
+INSERT INTO goals (user_id, goal_type, exercise_id, target, baseline, start_date, target_date, notes)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetGoal :one
+SELECT * FROM goals
+WHERE id = $1;
+
+-- name: GetGoalsByUserID :many
+SELECT * FROM goals
+WHERE user_id = $1
+ORDER BY target_date, id;
+
+-- name: GetGoalOwnerID :one
+SELECT user_id FROM goals
+WHERE id = $1;
+
+-- name: UpdateGoal :one
+-- updating a goal reopens it
+UPDATE goals
+SET
+    goal_type = $1,
+    exercise_id = $2,
+    target = $3,
+    baseline = $4,
+    start_date = $5,
+    target_date = $6,
+    notes = $7,
+    status = 'active',
+    status_date = NULL
+WHERE id = $8
+RETURNING *;
+
+-- name: UpdateGoalStatus :exec
+UPDATE goals
+SET status = $1, status_date = $2
+WHERE id = $3;
+
+-- name: DeleteGoal :one
+DELETE FROM goals
+WHERE id = $1
+RETURNING *;
+
+-- name: GetDailyMaxWeight :many
+SELECT sessions.date, MAX(logs.weight)::float AS max_weight
+FROM logs
+JOIN sets ON sets.id = logs.set_id
+JOIN sessions ON sessions.id = sets.session_id
+WHERE sessions.user_id = @user_id
+    AND logs.exercise_id = @exercise_id
+    AND sessions.date BETWEEN @from_date AND @to_date
+    AND sets.set_type <> 'warm_up'
+    AND logs.weight > 0
+GROUP BY sessions.date
+ORDER BY sessions.date;
+
+-- name: GetDailyVolume :many
+SELECT sessions.date, COALESCE(SUM(logs.weight * logs.reps), 0)::float AS volume
+FROM logs
+JOIN sets ON sets.id = logs.set_id
+JOIN sessions ON sessions.id = sets.session_id
+WHERE sessions.user_id = @user_id
+    AND (sqlc.narg('exercise_id')::integer IS NULL OR logs.exercise_id = sqlc.narg('exercise_id'))
+    AND sessions.date BETWEEN @from_date AND @to_date
+    AND sets.set_type <> 'warm_up'
+GROUP BY sessions.date
+ORDER BY sessions.date;
+
+-- name: DeleteGoalsByUserID :exec
+DELETE FROM goals
+WHERE user_id = $1;
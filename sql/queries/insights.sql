@@ -0,0 +1,55 @@
+-- name: GetDailyTopSets :many
+-- the top set of an exercise in a day is the one with the best estimated one rep max
+SELECT DISTINCT ON (logs.exercise_id, sessions.date)
+    logs.exercise_id,
+    exercises.name,
+    sessions.date,
+    logs.weight::float AS weight,
+    logs.reps,
+    (CASE WHEN logs.reps = 1 THEN logs.weight ELSE logs.weight * (1 + logs.reps / 30.0) END)::float AS e1rm
+FROM logs
+JOIN sets ON sets.id = logs.set_id
+JOIN sessions ON sessions.id = sets.session_id
+JOIN exercises ON exercises.id = logs.exercise_id
+WHERE sessions.user_id = @user_id
+    AND sessions.date BETWEEN @from_date AND @to_date
+    AND sets.set_type <> 'warm_up'
+    AND logs.weight > 0
+ORDER BY logs.exercise_id, sessions.date, e1rm DESC, logs.id;
+
+-- name: GetInsightUserIDs :many
+-- users who trained since the date and the ones analyzed before, whose insights may be outdated
+SELECT user_id FROM sessions
+WHERE date >= @from_date
+UNION
+SELECT user_id FROM insight_runs;
+
+-- name: DeleteInsightsByUserID :exec
+DELETE FROM insights
+WHERE user_id = $1;
+
+-- name: CreateInsight :exec
+INSERT INTO insights (user_id, exercise_id, metric, status, change, baseline, recent, days, suggestions)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: UpsertInsightRun :exec
+INSERT INTO insight_runs (user_id, weeks, from_date, to_date)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id) DO UPDATE
+SET weeks = EXCLUDED.weeks,
+    from_date = EXCLUDED.from_date,
+    to_date = EXCLUDED.to_date,
+    analyzed_at = timezone('utc', now());
+
+-- name: GetInsightRun :one
+SELECT * FROM insight_runs
+WHERE user_id = $1;
+
+-- name: GetInsights :many
+SELECT
+    insights.exercise_id, exercises.name, insights.status, insights.change,
+    insights.baseline, insights.recent, insights.days, insights.suggestions
+FROM insights
+JOIN exercises ON exercises.id = insights.exercise_id
+WHERE insights.user_id = @user_id AND insights.metric = @metric
+ORDER BY insights.exercise_id;
@@ -0,0 +1,55 @@
+-- name: GetLeaderboard :many
+-- best estimated one rep max of every user on the leaderboards, the bodyweight is the reading nearest to the day.
+-- Bodyweight exercises are estimated on the effective load and the relative metric ranks by e1RM / bodyweight,
+-- only users sharing their bodyweight are ranked on it as it can be derived from both metrics.
+WITH efforts AS (
+    SELECT
+        users.id AS user_id,
+        users.display_name,
+        users.leaderboard_share_bodyweight AS share_bodyweight,
+        sessions.date,
+        logs.reps,
+        logs.weight + COALESCE(exercises.bodyweight_ratio * nearest.bodyweight, 0) AS load,
+        nearest.bodyweight
+    FROM logs
+    JOIN sets ON sets.id = logs.set_id
+    JOIN sessions ON sessions.id = sets.session_id
+    JOIN users ON users.id = sessions.user_id
+    JOIN exercises ON exercises.id = logs.exercise_id
+    LEFT JOIN LATERAL (
+        SELECT bodyweights.bodyweight
+        FROM bodyweights
+        WHERE bodyweights.user_id = users.id
+        ORDER BY abs(bodyweights.date - sessions.date), bodyweights.date
+        LIMIT 1
+    ) AS nearest ON TRUE
+    WHERE logs.exercise_id = @exercise_id
+        AND sets.set_type <> 'warm_up'
+        AND users.leaderboard_opt_in
+        AND (sqlc.narg('from_date')::date IS NULL OR sessions.date >= sqlc.narg('from_date'))
+        AND (sqlc.narg('country')::text IS NULL
+            OR (users.leaderboard_share_country AND lower(users.country) = lower(sqlc.narg('country'))))
+        AND (sqlc.narg('born_after')::date IS NULL
+            OR (users.leaderboard_share_age AND users.birthday > sqlc.narg('born_after')))
+        AND (sqlc.narg('born_before')::date IS NULL
+            OR (users.leaderboard_share_age AND users.birthday <= sqlc.narg('born_before')))
+),
+estimations AS (
+    SELECT
+        user_id, display_name, date, bodyweight, share_bodyweight,
+        CASE WHEN reps = 1 THEN load ELSE load * (1 + reps / 30.0) END AS e1rm
+    FROM efforts
+    WHERE load > 0 AND reps > 0
+),
+bests AS (
+    SELECT DISTINCT ON (user_id)
+        display_name, date, e1rm, bodyweight,
+        CASE WHEN @metric::text = 'relative' THEN e1rm / bodyweight ELSE e1rm END AS score
+    FROM estimations
+    WHERE @metric::text <> 'relative' OR (share_bodyweight AND bodyweight IS NOT NULL)
+    ORDER BY user_id, score DESC, date
+)
+SELECT display_name::text, date, e1rm::float, bodyweight, score::float
+FROM bests
+ORDER BY score DESC, date, display_name
+LIMIT @row_limit;
@@ -1,6 +1,9 @@
 -- name: CreateLog :one
-INSERT INTO logs (weight, reps, logs_order, exercise_id, set_id)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO logs (
+    weight, reps, logs_order, exercise_id, set_id,
+    rpe, rir, tempo, reached_failure, partial_reps, notes, weight_unit
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 RETURNING *;
 
 -- name: GetLog :one
@@ -9,8 +12,18 @@ WHERE id = $1;
 
 -- name: UpdateLog :one
 UPDATE logs
-SET weight = $1, reps = $2, logs_order = $3
-WHERE id = $4
+SET weight = $1,
+    reps = $2,
+    logs_order = $3,
+    rpe = $4,
+    rir = $5,
+    tempo = $6,
+    reached_failure = $7,
+    partial_reps = $8,
+    notes = $9,
+    weight_unit = $10,
+    last_modified_at = timezone('utc', now())
+WHERE id = $11
 RETURNING *;
 
 -- name: GetLogOwnerID :one
@@ -28,7 +41,7 @@ ORDER BY set_id, logs_order;
 
 -- name: UpdateLogsExerciseIDBySetID :exec
 UPDATE logs
-SET exercise_id = $1
+SET exercise_id = $1, last_modified_at = timezone('utc', now())
 WHERE set_id = $2;
 
 -- name: GetLogsBySetID :many
@@ -44,9 +57,47 @@ RETURNING *;
 -- name: GetLogsByUserID :many
 SELECT sessions.date, logs.*
 FROM logs
-LEFT JOIN sets ON sets.id = logs.set_id
-LEFT JOIN sessions ON sessions.id = sets.session_id
-WHERE sessions.user_id = $1
-ORDER BY sessions.date DESC, logs.logs_order DESC
-OFFSET $2
-LIMIT $3;
+JOIN sets ON sets.id = logs.set_id
+JOIN sessions ON sessions.id = sets.session_id
+WHERE sessions.user_id = @user_id
+    AND (sqlc.narg('cursor_date')::date IS NULL
+        OR (sessions.date, logs.id) < (sqlc.narg('cursor_date'), sqlc.narg('cursor_id')::bigint))
+ORDER BY sessions.date DESC, logs.id DESC
+OFFSET @page_offset
+LIMIT @page_limit;
+
+-- name: GetLogIDsBySetIDForUpdate :many
+SELECT id FROM logs
+WHERE set_id = $1
+ORDER BY logs_order
+FOR UPDATE;
+
+-- name: ReorderLogs :exec
+UPDATE logs
+SET logs_order = new_order.position, last_modified_at = timezone('utc', now())
+FROM unnest(@ids::bigint[]) WITH ORDINALITY AS new_order(id, position)
+WHERE logs.id = new_order.id AND logs.set_id = @set_id;
+
+-- name: GetLastPerformance :many
+-- Working logs of the exercise in the latest session of the owner held before the given session,
+-- by date, start time and id
+WITH last_session AS (
+    SELECT previous.id, previous.date
+    FROM sessions AS this_session
+    JOIN sessions AS previous
+        ON previous.user_id = this_session.user_id
+        AND previous.id <> this_session.id
+        AND (previous.date, COALESCE(previous.start_timestamp, '-infinity'), previous.id)
+            < (this_session.date, COALESCE(this_session.start_timestamp, '-infinity'), this_session.id)
+    JOIN sets ON sets.session_id = previous.id
+    JOIN logs ON logs.set_id = sets.id
+    WHERE this_session.id = @session_id AND sets.exercise_id = @exercise_id AND sets.set_type <> 'warm_up'
+    ORDER BY previous.date DESC, previous.start_timestamp DESC NULLS LAST, previous.id DESC
+    LIMIT 1
+)
+SELECT last_session.id AS session_id, last_session.date, logs.weight, logs.reps, logs.rpe, logs.rir
+FROM last_session
+JOIN sets ON sets.session_id = last_session.id
+JOIN logs ON logs.set_id = sets.id
+WHERE sets.exercise_id = @exercise_id AND sets.set_type <> 'warm_up'
+ORDER BY sets.set_order, logs.logs_order;
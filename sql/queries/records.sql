@@ -0,0 +1,105 @@
+-- name: CreatePersonalRecord :one
+INSERT INTO personal_records (user_id, exercise_id, record_type, value, weight, log_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: DeletePersonalRecordsByLogID :exec
+DELETE FROM personal_records
+WHERE log_id = $1;
+
+-- name: DeleteSessionVolumeRecords :exec
+DELETE FROM personal_records
+USING logs, sets
+WHERE personal_records.log_id = logs.id
+    AND logs.set_id = sets.id
+    AND sets.session_id = $1
+    AND personal_records.exercise_id = $2
+    AND personal_records.record_type = 'session_volume';
+
+-- name: GetExerciseBests :one
+SELECT
+    COALESCE(MAX(logs.weight), 0)::float AS max_weight,
+    COALESCE(MAX(logs.reps) FILTER (WHERE logs.weight = sqlc.arg('weight')::float), 0)::integer AS max_reps,
+    COALESCE(MAX(CASE WHEN logs.reps = 1 THEN logs.weight ELSE logs.weight * (1 + logs.reps / 30.0) END), 0)::float AS max_e1rm
+FROM logs
+JOIN sets ON sets.id = logs.set_id
+JOIN sessions ON sessions.id = sets.session_id
+WHERE sessions.user_id = @user_id
+    AND logs.exercise_id = @exercise_id
+    AND logs.id <> @log_id
+    AND sets.set_type <> 'warm_up';
+
+-- name: GetExercisesRepBests :many
+-- most reps of every weight lifted in the exercises outside the sessions
+SELECT logs.exercise_id, logs.weight::float AS weight, MAX(logs.reps)::integer AS max_reps
+FROM logs
+JOIN sets ON sets.id = logs.set_id
+JOIN sessions ON sessions.id = sets.session_id
+WHERE sessions.user_id = @user_id
+    AND logs.exercise_id = ANY(@exercise_ids::integer[])
+    AND NOT sets.session_id = ANY(@session_ids::uuid[])
+    AND sets.set_type <> 'warm_up'
+    AND logs.weight IS NOT NULL
+GROUP BY logs.exercise_id, logs.weight;
+
+-- name: GetExercisesBestVolumes :many
+-- best session volume of the exercises outside the sessions
+SELECT exercise_id, COALESCE(MAX(volume), 0)::float AS best_volume
+FROM (
+    SELECT logs.exercise_id, sets.session_id, SUM(logs.weight * logs.reps) AS volume
+    FROM logs
+    JOIN sets ON sets.id = logs.set_id
+    JOIN sessions ON sessions.id = sets.session_id
+    WHERE sessions.user_id = @user_id
+        AND logs.exercise_id = ANY(@exercise_ids::integer[])
+        AND NOT sets.session_id = ANY(@session_ids::uuid[])
+        AND sets.set_type <> 'warm_up'
+    GROUP BY logs.exercise_id, sets.session_id
+) AS volumes
+GROUP BY exercise_id;
+
+-- name: GetSessionVolumes :one
+SELECT
+    COALESCE(SUM(volume) FILTER (WHERE session_id = @session_id), 0)::float AS session_volume,
+    COALESCE(MAX(volume) FILTER (WHERE session_id <> @session_id), 0)::float AS best_volume
+FROM (
+    SELECT sets.session_id, SUM(logs.weight * logs.reps) AS volume
+    FROM logs
+    JOIN sets ON sets.id = logs.set_id
+    JOIN sessions ON sessions.id = sets.session_id
+    WHERE sessions.user_id = @user_id
+        AND logs.exercise_id = @exercise_id
+        AND sets.set_type <> 'warm_up'
+    GROUP BY sets.session_id
+) AS volumes;
+
+-- name: GetCurrentPersonalRecords :many
+SELECT DISTINCT ON (personal_records.exercise_id, personal_records.record_type, personal_records.weight)
+    personal_records.*, sessions.date
+FROM personal_records
+JOIN logs ON logs.id = personal_records.log_id
+JOIN sets ON sets.id = logs.set_id
+JOIN sessions ON sessions.id = sets.session_id
+WHERE personal_records.user_id = @user_id
+    AND (sqlc.narg('exercise_id')::integer IS NULL OR personal_records.exercise_id = sqlc.narg('exercise_id'))
+ORDER BY personal_records.exercise_id, personal_records.record_type, personal_records.weight,
+    personal_records.value DESC, personal_records.id DESC;
+
+-- name: GetPersonalRecordsHistory :many
+SELECT personal_records.*, sessions.date
+FROM personal_records
+JOIN logs ON logs.id = personal_records.log_id
+JOIN sets ON sets.id = logs.set_id
+JOIN sessions ON sessions.id = sets.session_id
+WHERE personal_records.user_id = $1 AND personal_records.exercise_id = $2
+ORDER BY sessions.date DESC, personal_records.id DESC;
+
+-- name: GetPersonalRecordsByDate :many
+SELECT personal_records.*, sessions.date
+FROM personal_records
+JOIN logs ON logs.id = personal_records.log_id
+JOIN sets ON sets.id = logs.set_id
+JOIN sessions ON sessions.id = sets.session_id
+WHERE personal_records.user_id = @user_id
+    AND sessions.date >= @from_date AND sessions.date <= @to_date
+ORDER BY sessions.date, personal_records.id;
@@ -1,7 +1,11 @@
 -- name: CreateSession :one
-INSERT INTO sessions (name, date, start_timestamp, duration_minutes, user_id)
+INSERT INTO sessions (
+    name, date, start_timestamp, duration_minutes, user_id, tags,
+    notes, rpe, sleep_quality, bodyweight, mood, location
+)
 VALUES (
-    $1, $2, $3, $4, $5
+    @name, @date, @start_timestamp, @duration_minutes, @user_id, COALESCE(sqlc.narg('tags')::text[], '{}'),
+    @notes, @rpe, @sleep_quality, @bodyweight, @mood, @location
 )
 RETURNING *;
 
@@ -18,6 +22,22 @@ ORDER BY date DESC;
 SELECT * FROM sessions
 WHERE id = $1;
 
+-- name: GetSessionsByIDs :many
+SELECT * FROM sessions
+WHERE id = ANY(@ids::uuid[]);
+
+-- name: GetSessionsByDates :many
+SELECT * FROM sessions
+WHERE user_id = @user_id AND date >= @from_date AND date <= @to_date
+ORDER BY date, id;
+
+-- name: GetLastSessionByUserID :one
+SELECT * FROM sessions
+WHERE user_id = @user_id
+    AND (sqlc.narg('name')::text IS NULL OR lower(name) = lower(sqlc.narg('name')))
+ORDER BY date DESC, start_timestamp DESC NULLS LAST, id DESC
+LIMIT 1;
+
 -- name: GetSessionsPaginated :many
 SELECT * FROM sessions
 WHERE user_id = $1
@@ -25,20 +45,91 @@ ORDER BY date DESC
 OFFSET $2
 LIMIT $3;
 
+-- name: GetSessionsFiltered :many
+SELECT * FROM sessions
+WHERE user_id = @user_id
+    AND (sqlc.narg('from_date')::date IS NULL OR date >= sqlc.narg('from_date'))
+    AND (sqlc.narg('to_date')::date IS NULL OR date <= sqlc.narg('to_date'))
+    AND (sqlc.narg('name')::text IS NULL OR name ILIKE '%' || sqlc.narg('name') || '%')
+    AND (sqlc.narg('exercise_id')::integer IS NULL OR EXISTS (
+        SELECT 1 FROM sets
+        WHERE sets.session_id = sessions.id AND sets.exercise_id = sqlc.narg('exercise_id')
+    ))
+    AND (sqlc.narg('tags')::text[] IS NULL OR tags @> sqlc.narg('tags'))
+    AND (sqlc.narg('cursor_date')::date IS NULL
+        OR (@sort::text = 'date_asc' AND (date, id) > (sqlc.narg('cursor_date'), sqlc.narg('cursor_id')::uuid))
+        OR (@sort::text = 'date_desc' AND (date, id) < (sqlc.narg('cursor_date'), sqlc.narg('cursor_id')::uuid)))
+ORDER BY
+    CASE WHEN @sort::text = 'date_asc' THEN date END ASC,
+    CASE WHEN @sort::text = 'date_asc' THEN id END ASC,
+    CASE WHEN @sort::text = 'date_desc' THEN date END DESC,
+    CASE WHEN @sort::text = 'name_asc' THEN name END ASC,
+    CASE WHEN @sort::text = 'name_desc' THEN name END DESC,
+    CASE WHEN @sort::text = 'duration_asc' THEN duration_minutes END ASC,
+    CASE WHEN @sort::text = 'duration_desc' THEN duration_minutes END DESC,
+    date DESC,
+    id DESC
+OFFSET @page_offset
+LIMIT @page_limit;
+
+-- name: GetNumberSessionsFiltered :one
+SELECT count(id) FROM sessions
+WHERE user_id = @user_id
+    AND (sqlc.narg('from_date')::date IS NULL OR date >= sqlc.narg('from_date'))
+    AND (sqlc.narg('to_date')::date IS NULL OR date <= sqlc.narg('to_date'))
+    AND (sqlc.narg('name')::text IS NULL OR name ILIKE '%' || sqlc.narg('name') || '%')
+    AND (sqlc.narg('exercise_id')::integer IS NULL OR EXISTS (
+        SELECT 1 FROM sets
+        WHERE sets.session_id = sessions.id AND sets.exercise_id = sqlc.narg('exercise_id')
+    ))
+    AND (sqlc.narg('tags')::text[] IS NULL OR tags @> sqlc.narg('tags'));
+
+-- name: GetSessionNamesByDates :many
+SELECT date, name FROM sessions
+WHERE user_id = @user_id AND date >= @from_date AND date <= @to_date;
+
 -- name: GetSessionOwnerID :one
 SELECT user_id FROM sessions
 WHERE id = $1;
 
 -- name: UpdateSession :one
 UPDATE sessions
-SET name = $1,
-    date = $2,
-    start_timestamp = $3,
-    duration_minutes = $4
-WHERE id = $5
+SET name = @name,
+    date = @date,
+    start_timestamp = @start_timestamp,
+    duration_minutes = @duration_minutes,
+    tags = COALESCE(sqlc.narg('tags')::text[], '{}'),
+    notes = @notes,
+    rpe = @rpe,
+    sleep_quality = @sleep_quality,
+    bodyweight = @bodyweight,
+    mood = @mood,
+    location = @location
+WHERE id = @id
 RETURNING *;
 
 -- name: DeleteSession :one
 DELETE FROM sessions
 WHERE id = $1 and user_id = $2
 RETURNING *;
+
+-- name: DeleteSessionsByIDs :exec
+DELETE FROM sessions
+WHERE user_id = @user_id AND id = ANY(@ids::uuid[]);
+
+-- name: DeleteSessionsByUserID :exec
+DELETE FROM sessions
+WHERE user_id = $1;
+
+-- name: RestoreSession :one
+-- restored sessions keep their id, nothing is returned when the id is taken
+INSERT INTO sessions (
+    id, name, date, start_timestamp, duration_minutes, user_id, tags,
+    notes, rpe, sleep_quality, bodyweight, mood, location
+)
+VALUES (
+    @id, @name, @date, @start_timestamp, @duration_minutes, @user_id, COALESCE(sqlc.narg('tags')::text[], '{}'),
+    @notes, @rpe, @sleep_quality, @bodyweight, @mood, @location
+)
+ON CONFLICT (id) DO NOTHING
+RETURNING *;
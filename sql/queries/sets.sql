@@ -1,6 +1,6 @@
 -- name: CreateSet :one
-INSERT INTO sets (set_order, rest_time, session_id, exercise_id)
-VALUES ($1, $2, $3, $4)
+INSERT INTO sets (set_order, rest_time, session_id, exercise_id, set_type, group_key)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
 -- name: UpdateSet :one
@@ -8,14 +8,25 @@ UPDATE sets
 SET
     set_order = $1,
     rest_time = $2,
-    exercise_id = $3
-WHERE id = $4
+    exercise_id = $3,
+    set_type = $4,
+    group_key = $5
+WHERE id = $6
 RETURNING *;
 
+-- name: UpdateSetsRestTimeByGroup :exec
+UPDATE sets
+SET rest_time = $1
+WHERE session_id = $2 AND group_key = $3;
+
 -- name: GetSet :one
 SELECT * FROM sets
 WHERE id = $1;
 
+-- name: GetSetsByIDs :many
+SELECT * FROM sets
+WHERE id = ANY(@ids::bigint[]);
+
 -- name: GetSetsBySessionIDs :many
 SELECT * FROM sets
 WHERE session_id = ANY($1::uuid[])
@@ -31,3 +42,21 @@ SELECT sessions.user_id FROM sets
 JOIN sessions
 ON sets.session_id = sessions.id
 WHERE sets.id = $1;
+
+-- name: GetSetIDsBySessionIDForUpdate :many
+SELECT id FROM sets
+WHERE session_id = $1
+ORDER BY set_order
+FOR UPDATE;
+
+-- name: ReorderSets :exec
+UPDATE sets
+SET set_order = new_order.position
+FROM unnest(@ids::bigint[]) WITH ORDINALITY AS new_order(id, position)
+WHERE sets.id = new_order.id AND sets.session_id = @session_id;
+
+-- name: GetSetBySessionAndUser :one
+SELECT sets.* FROM sets
+JOIN sessions
+ON sets.session_id = sessions.id
+WHERE sets.id = @id AND sets.session_id = @session_id AND sessions.user_id = @user_id;
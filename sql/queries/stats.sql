@@ -0,0 +1,81 @@
+-- name: GetDailySessionRPELoad :many
+-- session RPE times its duration, sessions missing any of them have no load
+SELECT date, SUM(rpe * duration_minutes)::float AS load
+FROM sessions
+WHERE user_id = @user_id
+    AND date BETWEEN @from_date AND @to_date
+    AND rpe IS NOT NULL
+    AND duration_minutes IS NOT NULL
+GROUP BY date
+ORDER BY date;
+
+-- name: GetDailyVolumeLoad :many
+SELECT sessions.date, COALESCE(SUM(logs.weight * logs.reps), 0)::float AS load
+FROM logs
+JOIN sets ON sets.id = logs.set_id
+JOIN sessions ON sessions.id = sets.session_id
+WHERE sessions.user_id = @user_id
+    AND sessions.date BETWEEN @from_date AND @to_date
+    AND sets.set_type <> 'warm_up'
+GROUP BY sessions.date
+ORDER BY sessions.date;
+
+-- name: GetExerciseLogsByDate :many
+SELECT logs.id, sessions.date, logs.weight, logs.reps, logs.rpe, logs.rir
+FROM logs
+JOIN sets ON sets.id = logs.set_id
+JOIN sessions ON sessions.id = sets.session_id
+JOIN exercises ON exercises.id = logs.exercise_id
+WHERE sessions.user_id = @user_id
+    AND logs.exercise_id = @exercise_id
+    AND sessions.date BETWEEN @from_date AND @to_date
+    AND sets.set_type <> 'warm_up'
+    AND (logs.weight > 0 OR exercises.bodyweight_ratio IS NOT NULL)
+ORDER BY sessions.date, logs.id;
+
+-- name: GetSessionCountsByDate :many
+SELECT date, COUNT(*) AS sessions
+FROM sessions
+WHERE user_id = $1
+GROUP BY date
+ORDER BY date;
+
+-- name: GetVolumeByExercise :many
+-- logs are single sets, the ones without effort data count as hard sets
+SELECT
+    date_trunc(@period::text, sessions.date)::date AS period_start,
+    exercises.id AS exercise_id,
+    exercises.name,
+    COALESCE(SUM(logs.weight * logs.reps), 0)::float AS tonnage,
+    COUNT(logs.id) AS sets,
+    COUNT(logs.id) FILTER (WHERE COALESCE(logs.rpe, 10) >= 7 AND COALESCE(logs.rir, 0) <= 3) AS hard_sets,
+    COALESCE(SUM(logs.reps), 0)::bigint AS reps
+FROM logs
+JOIN sets ON sets.id = logs.set_id
+JOIN sessions ON sessions.id = sets.session_id
+JOIN exercises ON exercises.id = logs.exercise_id
+WHERE sessions.user_id = @user_id
+    AND sessions.date BETWEEN @from_date AND @to_date
+    AND sets.set_type <> 'warm_up'
+    AND (sqlc.narg('exercise_id')::integer IS NULL OR logs.exercise_id = sqlc.narg('exercise_id'))
+GROUP BY period_start, exercises.id
+ORDER BY period_start, exercises.id;
+
+-- name: GetVolumeByMuscleGroup :many
+SELECT
+    date_trunc(@period::text, sessions.date)::date AS period_start,
+    COALESCE(exercises.muscle_group, 'other')::text AS muscle_group,
+    COALESCE(SUM(logs.weight * logs.reps), 0)::float AS tonnage,
+    COUNT(logs.id) AS sets,
+    COUNT(logs.id) FILTER (WHERE COALESCE(logs.rpe, 10) >= 7 AND COALESCE(logs.rir, 0) <= 3) AS hard_sets,
+    COALESCE(SUM(logs.reps), 0)::bigint AS reps
+FROM logs
+JOIN sets ON sets.id = logs.set_id
+JOIN sessions ON sessions.id = sets.session_id
+JOIN exercises ON exercises.id = logs.exercise_id
+WHERE sessions.user_id = @user_id
+    AND sessions.date BETWEEN @from_date AND @to_date
+    AND sets.set_type <> 'warm_up'
+    AND (sqlc.narg('exercise_id')::integer IS NULL OR logs.exercise_id = sqlc.narg('exercise_id'))
+GROUP BY period_start, muscle_group
+ORDER BY period_start, muscle_group;
@@ -0,0 +1,35 @@
+-- name: GetSyncChanges :many
+-- the first sync of a device does not need the tombstones
+SELECT * FROM sync_entities
+WHERE user_id = @user_id AND version > @since
+    AND (@since > 0 OR deleted_at IS NULL)
+ORDER BY version
+LIMIT @page_limit;
+
+-- name: GetSyncEntity :one
+SELECT * FROM sync_entities
+WHERE client_id = $1;
+
+-- name: GetSyncLogsByIDs :many
+-- logs along with the client id of their set
+SELECT logs.*, sync_entities.client_id AS set_client_id
+FROM logs
+JOIN sync_entities ON sync_entities.set_id = logs.set_id
+WHERE logs.id = ANY(@ids::bigint[]);
+
+-- name: LockSyncClock :exec
+-- the clock of the user stays locked until commit, so its changes are applied one writer after the other
+INSERT INTO sync_clocks (user_id, version)
+VALUES ($1, 0)
+ON CONFLICT (user_id) DO UPDATE SET version = sync_clocks.version;
+
+-- name: UpdateSyncClientID :exec
+-- sets and logs created by a client take the client id it gave them
+UPDATE sync_entities
+SET client_id = @client_id
+WHERE set_id = sqlc.narg('set_id') OR log_id = sqlc.narg('log_id');
+
+-- name: UpdateSyncFieldTimes :exec
+UPDATE sync_entities
+SET field_modified_at = field_modified_at || @field_modified_at::jsonb
+WHERE client_id = @client_id;
@@ -25,3 +25,44 @@ WHERE username = $1;
 INSERT INTO users (id, username, is_admin, country, hashed_password, birthday)
 VALUES (gen_random_uuid(), $1, TRUE, $2, $3, $4)
 RETURNING *;
+
+-- name: GetUserPreferredUnit :one
+SELECT preferred_unit FROM users
+WHERE id = $1;
+
+-- name: GetUserTimezone :one
+SELECT timezone FROM users
+WHERE id = $1;
+
+-- name: UpdateUserPreferences :one
+-- preferences not provided are kept
+UPDATE users
+SET preferred_unit = COALESCE(sqlc.narg('preferred_unit'), preferred_unit),
+    timezone = COALESCE(sqlc.narg('timezone'), timezone)
+WHERE id = @id
+RETURNING *;
+
+-- name: UpdateUserLeaderboard :one
+-- settings not provided are kept, an empty display name clears it
+UPDATE users
+SET leaderboard_opt_in = COALESCE(sqlc.narg('leaderboard_opt_in'), leaderboard_opt_in),
+    display_name = CASE WHEN sqlc.narg('display_name')::text = '' THEN NULL ELSE COALESCE(sqlc.narg('display_name'), display_name) END,
+    leaderboard_share_country = COALESCE(sqlc.narg('leaderboard_share_country'), leaderboard_share_country),
+    leaderboard_share_age = COALESCE(sqlc.narg('leaderboard_share_age'), leaderboard_share_age),
+    leaderboard_share_bodyweight = COALESCE(sqlc.narg('leaderboard_share_bodyweight'), leaderboard_share_bodyweight)
+WHERE id = @id
+RETURNING *;
+
+-- name: RestoreUserProfile :one
+UPDATE users
+SET country = @country,
+    birthday = @birthday,
+    preferred_unit = @preferred_unit,
+    timezone = @timezone,
+    display_name = @display_name,
+    leaderboard_opt_in = @leaderboard_opt_in,
+    leaderboard_share_country = @leaderboard_share_country,
+    leaderboard_share_age = @leaderboard_share_age,
+    leaderboard_share_bodyweight = @leaderboard_share_bodyweight
+WHERE id = @id
+RETURNING *;
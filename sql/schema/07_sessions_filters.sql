@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE sessions
+ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_sessions_user_id_date ON sessions (user_id, date DESC);
+CREATE INDEX idx_sessions_tags ON sessions USING GIN (tags);
+CREATE INDEX idx_sets_session_id_exercise_id ON sets (session_id, exercise_id);
+
+-- +goose Down
+DROP INDEX idx_sets_session_id_exercise_id;
+DROP INDEX idx_sessions_tags;
+DROP INDEX idx_sessions_user_id_date;
+
+ALTER TABLE sessions
+DROP COLUMN tags;
@@ -0,0 +1,9 @@
+-- +goose Up
+DROP INDEX idx_sessions_user_id_date;
+CREATE INDEX idx_sessions_user_id_date_id ON sessions (user_id, date DESC, id DESC);
+CREATE INDEX idx_logs_set_id ON logs (set_id);
+
+-- +goose Down
+DROP INDEX idx_logs_set_id;
+DROP INDEX idx_sessions_user_id_date_id;
+CREATE INDEX idx_sessions_user_id_date ON sessions (user_id, date DESC);
@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE sessions
+ADD COLUMN notes TEXT,
+ADD COLUMN rpe SMALLINT CHECK (rpe BETWEEN 1 AND 10),
+ADD COLUMN sleep_quality SMALLINT CHECK (sleep_quality BETWEEN 1 AND 5),
+ADD COLUMN bodyweight FLOAT CHECK (bodyweight > 0),
+ADD COLUMN mood SMALLINT CHECK (mood BETWEEN 1 AND 5),
+ADD COLUMN location TEXT;
+
+-- +goose Down
+ALTER TABLE sessions
+DROP COLUMN location,
+DROP COLUMN mood,
+DROP COLUMN bodyweight,
+DROP COLUMN sleep_quality,
+DROP COLUMN rpe,
+DROP COLUMN notes;
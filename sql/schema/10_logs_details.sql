@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE logs
+ADD COLUMN rpe FLOAT CHECK (rpe BETWEEN 1 AND 10),
+ADD COLUMN rir SMALLINT CHECK (rir >= 0),
+ADD COLUMN tempo TEXT,
+ADD COLUMN reached_failure BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN partial_reps SMALLINT NOT NULL DEFAULT 0 CHECK (partial_reps >= 0),
+ADD COLUMN notes TEXT;
+
+-- +goose Down
+ALTER TABLE logs
+DROP COLUMN notes,
+DROP COLUMN partial_reps,
+DROP COLUMN reached_failure,
+DROP COLUMN tempo,
+DROP COLUMN rir,
+DROP COLUMN rpe;
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE sets
+ADD COLUMN set_type TEXT NOT NULL DEFAULT 'working'
+    CHECK (set_type IN ('warm_up', 'working', 'drop', 'amrap', 'back_off', 'cluster')),
+ADD COLUMN group_key TEXT;
+
+CREATE INDEX idx_sets_session_id_group_key ON sets (session_id, group_key)
+WHERE group_key IS NOT NULL;
+
+-- +goose Down
+DROP INDEX idx_sets_session_id_group_key;
+
+ALTER TABLE sets
+DROP COLUMN group_key,
+DROP COLUMN set_type;
@@ -0,0 +1,36 @@
+-- +goose Up
+-- renumber the sets and logs of parents holding duplicated orders before enforcing uniqueness
+UPDATE sets SET set_order = numbered.position
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY session_id ORDER BY set_order, id) AS position
+    FROM sets
+    WHERE session_id IN (
+        SELECT session_id FROM sets
+        GROUP BY session_id, set_order
+        HAVING count(*) > 1
+    )
+) AS numbered
+WHERE sets.id = numbered.id;
+
+UPDATE logs SET logs_order = numbered.position
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY set_id ORDER BY logs_order, id) AS position
+    FROM logs
+    WHERE set_id IN (
+        SELECT set_id FROM logs
+        GROUP BY set_id, logs_order
+        HAVING count(*) > 1
+    )
+) AS numbered
+WHERE logs.id = numbered.id;
+
+-- deferrable so a whole reorder can be applied by a single statement
+ALTER TABLE sets
+ADD CONSTRAINT unique_sets_session_id_set_order UNIQUE (session_id, set_order) DEFERRABLE;
+
+ALTER TABLE logs
+ADD CONSTRAINT unique_logs_set_id_logs_order UNIQUE (set_id, logs_order) DEFERRABLE;
+
+-- +goose Down
+ALTER TABLE logs DROP CONSTRAINT unique_logs_set_id_logs_order;
+ALTER TABLE sets DROP CONSTRAINT unique_sets_session_id_set_order;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- logs always take the exercise of their set
+UPDATE logs SET exercise_id = sets.exercise_id
+FROM sets
+WHERE logs.set_id = sets.id AND logs.exercise_id <> sets.exercise_id;
+
+ALTER TABLE sets
+ADD CONSTRAINT unique_sets_id_exercise_id UNIQUE (id, exercise_id);
+
+-- deferred so the exercise of a set and its logs can be changed within the same transaction
+ALTER TABLE logs
+ADD CONSTRAINT fk_set_id_exercise_id FOREIGN KEY (set_id, exercise_id)
+REFERENCES sets(id, exercise_id)
+ON DELETE CASCADE
+DEFERRABLE INITIALLY DEFERRED;
+
+-- +goose Down
+ALTER TABLE logs DROP CONSTRAINT fk_set_id_exercise_id;
+ALTER TABLE sets DROP CONSTRAINT unique_sets_id_exercise_id;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- weights are stored in kilograms, the unit only records how they were entered
+ALTER TABLE logs
+ADD COLUMN weight_unit TEXT NOT NULL DEFAULT 'kg' CHECK (weight_unit IN ('kg', 'lb'));
+
+ALTER TABLE users
+ADD COLUMN preferred_unit TEXT NOT NULL DEFAULT 'kg' CHECK (preferred_unit IN ('kg', 'lb'));
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN preferred_unit;
+ALTER TABLE logs DROP COLUMN weight_unit;
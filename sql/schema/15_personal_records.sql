@@ -0,0 +1,28 @@
+-- +goose Up
+-- every row is a record at the time it was set, the current record is the best value of its kind
+CREATE TABLE personal_records (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT timezone('utc', now()),
+    user_id UUID NOT NULL,
+    exercise_id INTEGER NOT NULL,
+    record_type TEXT NOT NULL CHECK (record_type IN ('max_weight', 'max_reps', 'e1rm', 'session_volume')),
+    value FLOAT NOT NULL,
+    weight FLOAT, -- weight the reps were performed with, only for max_reps records
+    log_id BIGINT NOT NULL,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_exercise_id FOREIGN KEY (exercise_id)
+    REFERENCES exercises(id),
+    CONSTRAINT fk_log_id FOREIGN KEY (log_id)
+    REFERENCES logs(id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX idx_personal_records_user_id_exercise_id ON personal_records (user_id, exercise_id, record_type);
+CREATE INDEX idx_personal_records_log_id ON personal_records (log_id);
+CREATE INDEX idx_logs_exercise_id ON logs (exercise_id);
+
+-- +goose Down
+DROP INDEX idx_logs_exercise_id;
+DROP TABLE personal_records;
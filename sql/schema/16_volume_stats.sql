@@ -0,0 +1,17 @@
+-- +goose Up
+-- primary muscle group trained by the exercise
+ALTER TABLE exercises ADD COLUMN muscle_group TEXT
+CHECK (muscle_group IN (
+    'chest', 'back', 'shoulders', 'biceps', 'triceps', 'forearms',
+    'quads', 'hamstrings', 'glutes', 'calves', 'core', 'full_body'
+));
+
+-- volume aggregations read the working sets of the sessions and their logs without visiting the tables
+CREATE INDEX idx_sets_session_id_working ON sets (session_id) INCLUDE (id, exercise_id)
+WHERE set_type <> 'warm_up';
+CREATE INDEX idx_logs_set_id_volume ON logs (set_id) INCLUDE (exercise_id, weight, reps, rpe, rir);
+
+-- +goose Down
+DROP INDEX idx_logs_set_id_volume;
+DROP INDEX idx_sets_session_id_working;
+ALTER TABLE exercises DROP COLUMN muscle_group;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- IANA name of the timezone of the user, days and weeks of the stats are computed in it
+ALTER TABLE users
+ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC';
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN timezone;
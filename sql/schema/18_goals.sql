@@ -0,0 +1,28 @@
+-- +goose Up
+-- targets are stored in kilograms, frequency targets are sessions per week
+CREATE TABLE goals (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT timezone('utc', now()),
+    user_id UUID NOT NULL,
+    goal_type TEXT NOT NULL CHECK (goal_type IN ('strength', 'volume', 'frequency', 'bodyweight')),
+    exercise_id INTEGER,
+    target FLOAT NOT NULL CHECK (target > 0),
+    baseline FLOAT, -- bodyweight when the goal was set, tells whether it is about losing or gaining weight
+    start_date DATE NOT NULL,
+    target_date DATE NOT NULL,
+    status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'achieved', 'missed')),
+    status_date DATE, -- day the goal was achieved or missed
+    notes TEXT,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_exercise_id FOREIGN KEY (exercise_id)
+    REFERENCES exercises(id),
+    CONSTRAINT strength_exercise CHECK (goal_type <> 'strength' OR exercise_id IS NOT NULL),
+    CONSTRAINT goal_dates CHECK (start_date <= target_date)
+);
+
+CREATE INDEX idx_goals_user_id ON goals (user_id, target_date);
+
+-- +goose Down
+DROP TABLE goals;
@@ -0,0 +1,51 @@
+-- +goose Up
+-- bodyweight is stored in kilograms, circumferences in centimeters
+CREATE TABLE body_measurements (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT timezone('utc', now()),
+    user_id UUID NOT NULL,
+    date DATE NOT NULL,
+    bodyweight FLOAT CHECK (bodyweight > 0),
+    body_fat FLOAT CHECK (body_fat > 0 AND body_fat < 100),
+    neck FLOAT CHECK (neck > 0),
+    chest FLOAT CHECK (chest > 0),
+    waist FLOAT CHECK (waist > 0),
+    hips FLOAT CHECK (hips > 0),
+    arm FLOAT CHECK (arm > 0),
+    thigh FLOAT CHECK (thigh > 0),
+    calf FLOAT CHECK (calf > 0),
+    notes TEXT,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+    CONSTRAINT unique_user_date UNIQUE (user_id, date),
+    CONSTRAINT some_measurement CHECK (
+        COALESCE(bodyweight, body_fat, neck, chest, waist, hips, arm, thigh, calf) IS NOT NULL
+    )
+);
+
+-- one bodyweight per day, the measured one or else the average of the ones logged in the sessions
+CREATE VIEW bodyweights AS
+SELECT user_id, date, bodyweight
+FROM body_measurements
+WHERE bodyweight IS NOT NULL
+UNION ALL
+SELECT user_id, date, AVG(bodyweight)
+FROM sessions
+WHERE bodyweight IS NOT NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM body_measurements
+        WHERE body_measurements.user_id = sessions.user_id
+            AND body_measurements.date = sessions.date
+            AND body_measurements.bodyweight IS NOT NULL
+    )
+GROUP BY user_id, date;
+
+-- share of the bodyweight moved by bodyweight exercises, added weight is logged on top of it
+ALTER TABLE exercises ADD COLUMN bodyweight_ratio FLOAT
+CHECK (bodyweight_ratio > 0 AND bodyweight_ratio <= 1);
+
+-- +goose Down
+ALTER TABLE exercises DROP COLUMN bodyweight_ratio;
+DROP VIEW bodyweights;
+DROP TABLE body_measurements;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- users only appear on leaderboards after opting in, under their display name and never their username.
+-- The country and age of the user are only used to filter the leaderboards when they are shared.
+ALTER TABLE users
+ADD COLUMN display_name TEXT UNIQUE,
+ADD COLUMN leaderboard_opt_in BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN leaderboard_share_country BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN leaderboard_share_age BOOLEAN NOT NULL DEFAULT FALSE,
+ADD CONSTRAINT display_name_to_opt_in CHECK (NOT leaderboard_opt_in OR display_name IS NOT NULL),
+ADD CONSTRAINT display_name_not_username CHECK (lower(display_name) <> lower(username));
+
+-- exercises selected to be ranked
+ALTER TABLE exercises ADD COLUMN leaderboard BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE exercises DROP COLUMN leaderboard;
+ALTER TABLE users
+DROP CONSTRAINT display_name_not_username,
+DROP CONSTRAINT display_name_to_opt_in,
+DROP COLUMN leaderboard_share_age,
+DROP COLUMN leaderboard_share_country,
+DROP COLUMN leaderboard_opt_in,
+DROP COLUMN display_name;
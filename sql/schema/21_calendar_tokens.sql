@@ -0,0 +1,14 @@
+-- +goose Up
+-- the token is the secret part of the calendar feed url, a user has at most one
+-- and replacing it revokes the previous url
+CREATE TABLE calendar_tokens (
+    token TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT timezone('utc', now()),
+    user_id UUID NOT NULL UNIQUE,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE calendar_tokens;
@@ -0,0 +1,234 @@
+-- +goose Up
+-- logs are resolved by the time they were last modified at
+UPDATE logs SET last_modified_at = COALESCE(created_at, timezone('utc', now()))
+WHERE last_modified_at IS NULL;
+
+ALTER TABLE logs ALTER COLUMN last_modified_at SET NOT NULL;
+
+-- Sessions, sets and logs are tracked so offline clients can pull the changes since their last sync.
+-- Every change takes the next version of the clock of the user, the row lock held on the clock until
+-- commit serializes the writers of a user so a version is never visible before the previous ones.
+CREATE TABLE sync_clocks (
+    user_id UUID PRIMARY KEY,
+    version BIGINT NOT NULL,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+);
+
+-- client_id identifies an entity across devices, sessions use their own id.
+-- Deleted entities keep their row as a tombstone. field_modified_at holds the time each field was
+-- last written at, the fields missing from it were written when the entity was created.
+CREATE TABLE sync_entities (
+    client_id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT timezone('utc', now()),
+    user_id UUID NOT NULL,
+    session_id UUID UNIQUE,
+    set_id BIGINT UNIQUE,
+    log_id BIGINT UNIQUE,
+    version BIGINT NOT NULL,
+    deleted_at TIMESTAMP,
+    field_modified_at JSONB NOT NULL DEFAULT '{}',
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+    CONSTRAINT one_entity CHECK (num_nonnulls(session_id, set_id, log_id) = 1)
+);
+
+-- versions are unique for each user, pulls resume after the last version they returned
+CREATE UNIQUE INDEX idx_sync_entities_user_id_version ON sync_entities (user_id, version);
+
+-- no version is issued while the user is being deleted, its entities go along with it
+-- +goose StatementBegin
+CREATE FUNCTION next_sync_version(owner_id UUID) RETURNS BIGINT AS $$
+    INSERT INTO sync_clocks (user_id, version)
+    SELECT id, 1 FROM users WHERE id = owner_id
+    ON CONFLICT (user_id) DO UPDATE SET version = sync_clocks.version + 1
+    RETURNING version;
+$$ LANGUAGE sql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION sync_changed_fields(old_row JSONB, new_row JSONB) RETURNS JSONB AS $$
+    SELECT COALESCE(jsonb_object_agg(new_field.key, timezone('utc', now())), '{}')
+    FROM jsonb_each(new_row) AS new_field
+    WHERE new_field.value IS DISTINCT FROM old_row -> new_field.key
+        AND new_field.key NOT IN ('id', 'created_at', 'last_modified_at');
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION sync_track_session() RETURNS trigger AS $$
+DECLARE
+    changed JSONB;
+    sync_version BIGINT;
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        changed := sync_changed_fields(to_jsonb(OLD), to_jsonb(NEW));
+        IF changed = '{}' THEN
+            RETURN NULL;
+        END IF;
+    END IF;
+    IF TG_OP = 'DELETE' THEN
+        sync_version := next_sync_version(OLD.user_id);
+    ELSE
+        sync_version := next_sync_version(NEW.user_id);
+    END IF;
+    IF sync_version IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP = 'INSERT' THEN
+        -- restored sessions take over the tombstone of their id
+        INSERT INTO sync_entities (client_id, user_id, session_id, version)
+        VALUES (NEW.id, NEW.user_id, NEW.id, sync_version)
+        ON CONFLICT (client_id) DO UPDATE
+        SET created_at = timezone('utc', now()),
+            user_id = EXCLUDED.user_id,
+            version = EXCLUDED.version,
+            deleted_at = NULL,
+            field_modified_at = '{}';
+    ELSIF TG_OP = 'UPDATE' THEN
+        UPDATE sync_entities
+        SET version = sync_version, field_modified_at = field_modified_at || changed
+        WHERE session_id = OLD.id;
+    ELSE
+        UPDATE sync_entities
+        SET version = sync_version, deleted_at = timezone('utc', now())
+        WHERE session_id = OLD.id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION sync_track_set() RETURNS trigger AS $$
+DECLARE
+    owner_id UUID;
+    changed JSONB;
+    sync_version BIGINT;
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        changed := sync_changed_fields(to_jsonb(OLD), to_jsonb(NEW));
+        IF changed = '{}' THEN
+            RETURN NULL;
+        END IF;
+    END IF;
+    IF TG_OP = 'INSERT' THEN
+        SELECT user_id INTO owner_id FROM sessions WHERE id = NEW.session_id;
+    ELSE
+        SELECT user_id INTO owner_id FROM sync_entities WHERE set_id = OLD.id;
+    END IF;
+    sync_version := next_sync_version(owner_id);
+    IF sync_version IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO sync_entities (client_id, user_id, set_id, version)
+        VALUES (gen_random_uuid(), owner_id, NEW.id, sync_version);
+    ELSIF TG_OP = 'UPDATE' THEN
+        UPDATE sync_entities
+        SET version = sync_version, field_modified_at = field_modified_at || changed
+        WHERE set_id = OLD.id;
+    ELSE
+        UPDATE sync_entities
+        SET version = sync_version, deleted_at = timezone('utc', now())
+        WHERE set_id = OLD.id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION sync_track_log() RETURNS trigger AS $$
+DECLARE
+    owner_id UUID;
+    changed JSONB;
+    sync_version BIGINT;
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        changed := sync_changed_fields(to_jsonb(OLD), to_jsonb(NEW));
+        IF changed = '{}' THEN
+            RETURN NULL;
+        END IF;
+    END IF;
+    IF TG_OP = 'INSERT' THEN
+        SELECT user_id INTO owner_id FROM sync_entities WHERE set_id = NEW.set_id;
+    ELSE
+        SELECT user_id INTO owner_id FROM sync_entities WHERE log_id = OLD.id;
+    END IF;
+    sync_version := next_sync_version(owner_id);
+    IF sync_version IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO sync_entities (client_id, user_id, log_id, version)
+        VALUES (gen_random_uuid(), owner_id, NEW.id, sync_version);
+    ELSIF TG_OP = 'UPDATE' THEN
+        UPDATE sync_entities
+        SET version = sync_version, field_modified_at = field_modified_at || changed
+        WHERE log_id = OLD.id;
+    ELSE
+        UPDATE sync_entities
+        SET version = sync_version, deleted_at = timezone('utc', now())
+        WHERE log_id = OLD.id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- the existing entities are numbered for each user, parents before their children
+WITH entities AS (
+    SELECT user_id, id AS session_id, NULL::BIGINT AS set_id, NULL::BIGINT AS log_id, 1 AS depth
+    FROM sessions
+    UNION ALL
+    SELECT sessions.user_id, NULL, sets.id, NULL, 2
+    FROM sets
+    JOIN sessions ON sessions.id = sets.session_id
+    UNION ALL
+    SELECT sessions.user_id, NULL, NULL, logs.id, 3
+    FROM logs
+    JOIN sets ON sets.id = logs.set_id
+    JOIN sessions ON sessions.id = sets.session_id
+)
+INSERT INTO sync_entities (client_id, user_id, session_id, set_id, log_id, version)
+SELECT
+    COALESCE(session_id, gen_random_uuid()), user_id, session_id, set_id, log_id,
+    row_number() OVER (PARTITION BY user_id ORDER BY depth, session_id, set_id, log_id)
+FROM entities;
+
+INSERT INTO sync_clocks (user_id, version)
+SELECT users.id, COUNT(sync_entities.client_id)
+FROM users
+LEFT JOIN sync_entities ON sync_entities.user_id = users.id
+GROUP BY users.id;
+
+CREATE TRIGGER sync_track_sessions
+AFTER INSERT OR UPDATE OR DELETE ON sessions
+FOR EACH ROW EXECUTE FUNCTION sync_track_session();
+
+CREATE TRIGGER sync_track_sets
+AFTER INSERT OR UPDATE OR DELETE ON sets
+FOR EACH ROW EXECUTE FUNCTION sync_track_set();
+
+CREATE TRIGGER sync_track_logs
+AFTER INSERT OR UPDATE OR DELETE ON logs
+FOR EACH ROW EXECUTE FUNCTION sync_track_log();
+
+-- +goose Down
+ALTER TABLE logs ALTER COLUMN last_modified_at DROP NOT NULL;
+DROP TRIGGER sync_track_logs ON logs;
+DROP TRIGGER sync_track_sets ON sets;
+DROP TRIGGER sync_track_sessions ON sessions;
+DROP FUNCTION sync_track_log;
+DROP FUNCTION sync_track_set;
+DROP FUNCTION sync_track_session;
+DROP FUNCTION sync_changed_fields;
+DROP FUNCTION next_sync_version;
+DROP TABLE sync_entities;
+DROP TABLE sync_clocks;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- the relative strength divided into the e1RM gives the bodyweight back,
+-- users are only ranked on it when they share their bodyweight
+ALTER TABLE users
+ADD COLUMN leaderboard_share_bodyweight BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN leaderboard_share_bodyweight;
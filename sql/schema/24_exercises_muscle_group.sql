@@ -0,0 +1,30 @@
+-- +goose Up
+-- primary muscle group of an exercise told by its name, NULL when the name does not tell it.
+-- Specific movements are checked first: a leg curl trains the hamstrings and a close grip bench the triceps.
+-- +goose StatementBegin
+CREATE FUNCTION exercise_muscle_group(name TEXT) RETURNS TEXT AS $$
+    SELECT CASE
+        WHEN name ~* '(romanian|stiff[- ]leg|leg curl|hamstring|good morning|nordic)' THEN 'hamstrings'
+        WHEN name ~* '(hip thrust|glute|bridge)' THEN 'glutes'
+        WHEN name ~* '(\mcalf|\mcalves)' THEN 'calves'
+        WHEN name ~* '(\mwrist|farmer)' THEN 'forearms'
+        WHEN name ~* '(\mclean|snatch|thruster|burpee|kettlebell swing)' THEN 'full_body'
+        WHEN name ~* '(tricep|skull ?crusher|push[- ]?down|close[- ]grip|\mdips?\M)' THEN 'triceps'
+        WHEN name ~* 'curl' THEN 'biceps'
+        WHEN name ~* '(overhead press|push press|shoulder|military|lateral raise|front raise|upright row|arnold|face pull|rear delt)'
+            THEN 'shoulders'
+        WHEN name ~* '(bench|chest|\mfly|push[- ]?ups?\M|\mpec)' THEN 'chest'
+        WHEN name ~* '(squat|leg press|leg extension|lunge|step[- ]?up)' THEN 'quads'
+        WHEN name ~* '(deadlift|\mrows?\M|rowing|pull[- ]?ups?\M|chin[- ]?ups?\M|pull[- ]?down|back extension)' THEN 'back'
+        WHEN name ~* '(plank|crunch|sit[- ]?ups?\M|ab wheel|leg raise|russian twist)' THEN 'core'
+    END;
+$$ LANGUAGE sql IMMUTABLE;
+-- +goose StatementEnd
+
+-- the catalogue exercises without a muscle group take the one of their name
+UPDATE exercises SET muscle_group = exercise_muscle_group(name)
+WHERE muscle_group IS NULL;
+
+-- +goose Down
+-- the filled muscle groups are kept, they can not be told apart from the ones set by hand
+DROP FUNCTION exercise_muscle_group;
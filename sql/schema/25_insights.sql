@@ -0,0 +1,37 @@
+-- +goose Up
+-- plateaus and regressions found by the analysis job in the latest window of the user,
+-- baseline and recent values are in kilograms
+CREATE TABLE insights (
+    user_id UUID NOT NULL,
+    exercise_id INTEGER NOT NULL,
+    metric TEXT NOT NULL CHECK (metric IN ('e1rm', 'top_set')),
+    status TEXT NOT NULL CHECK (status IN ('plateau', 'regression')),
+    change FLOAT NOT NULL,
+    baseline FLOAT NOT NULL,
+    recent FLOAT NOT NULL,
+    days INTEGER NOT NULL,
+    suggestions TEXT[] NOT NULL,
+    PRIMARY KEY (user_id, metric, exercise_id),
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_exercise_id FOREIGN KEY (exercise_id)
+    REFERENCES exercises(id)
+    ON DELETE CASCADE
+);
+
+-- latest run of the analysis job for the user and the window it analyzed
+CREATE TABLE insight_runs (
+    user_id UUID PRIMARY KEY,
+    weeks INTEGER NOT NULL,
+    from_date DATE NOT NULL,
+    to_date DATE NOT NULL,
+    analyzed_at TIMESTAMP NOT NULL DEFAULT timezone('utc', now()),
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE insight_runs;
+DROP TABLE insights;